@@ -5,8 +5,11 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/config"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
@@ -37,11 +40,27 @@ var (
 		Usage:    "Update policy to 'deny' addresses on list",
 		Required: false,
 	}
+	gasBudgetFlag = cli.Uint64Flag{
+		Name:     "gas",
+		Usage:    "Gas budget per sponsorship period",
+		Required: true,
+	}
 	noHeaderFlag = cli.BoolFlag{
 		Name:     "no-header",
 		Value:    false,
 		Required: false,
 	}
+	expiresFlag = cli.TimestampFlag{
+		Name:     "expires",
+		Usage:    "RFC3339 time when the added exceptions stop applying",
+		Layout:   time.RFC3339,
+		Required: false,
+	}
+	maxValueFlag = cli.StringFlag{
+		Name:     "value",
+		Usage:    "Max value in wei per tx, the limit is removed if not set",
+		Required: false,
+	}
 
 	policyActionFlags = []cli.Flag{&policyFlag}
 )
@@ -54,9 +73,9 @@ var policyCommands = cli.Command{
 	Subcommands: []*cli.Command{
 		{
 			Name:   "add",
-			Usage:  "Add address(es) to a policy exclusion list",
+			Usage:  "Add address(es), target:selector pair(s) for the call policy or IP range(s) for the ip policy to a policy exclusion list",
 			Action: addAcl,
-			Flags:  append(policyActionFlags, &csvFlag),
+			Flags:  append(policyActionFlags, &csvFlag, &expiresFlag),
 		}, {
 			Name:   "clear",
 			Usage:  "Clear the addresses listed as exceptions to a policy",
@@ -69,12 +88,22 @@ var policyCommands = cli.Command{
 			Flags:  append(policyActionFlags, &noHeaderFlag),
 		}, {
 			Name:   "remove",
-			Usage:  "Remove address(es) from a policy exclusion list",
+			Usage:  "Remove address(es), target:selector pair(s) for the call policy or IP range(s) for the ip policy from a policy exclusion list",
 			Action: removeAcl,
 			Flags:  append(policyActionFlags, &csvFlag),
+		}, {
+			Name:   "budget",
+			Usage:  "Set the gas budget per period of address(es) allowed by the sponsor policy",
+			Action: setSponsorBudget,
+			Flags:  []cli.Flag{&csvFlag, &gasBudgetFlag},
+		}, {
+			Name:   "max-value",
+			Usage:  "Set the max value per tx of the senders not allowed by the value policy",
+			Action: setMaxTxValue,
+			Flags:  []cli.Flag{&maxValueFlag},
 		}, {
 			Name:   "update",
-			Usage:  "Update the default action for a policy",
+			Usage:  "Update the default action for a policy, or for the target contract(s) of the call policy",
 			Action: updatePolicy,
 			Flags:  append(policyActionFlags, &allowFlag, &denyFlag),
 		},
@@ -106,6 +135,15 @@ func updatePolicy(cli *cli.Context) error {
 		setting = false
 	}
 
+	// the call policy has no default action, its action is set per target contract
+	if policy == pool.Call {
+		targets, err := resolveAddresses(cli, true)
+		if err != nil {
+			return err
+		}
+		return db.UpdateCallPolicy(context.Background(), targets, setting)
+	}
+
 	err = db.UpdatePolicy(context.Background(), policy, setting)
 	if err != nil {
 		return err
@@ -118,11 +156,32 @@ func addAcl(cli *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	policy, err := resolvePolicy(cli)
+	if err != nil {
+		return err
+	}
+	expiresAt := cli.Timestamp(expiresFlag.Name)
+
+	switch policy {
+	case pool.Call:
+		rules, err := resolveCallRules(cli, expiresAt)
+		if err != nil {
+			return err
+		}
+		return db.AddCallRules(context.Background(), rules)
+	case pool.IP:
+		rules, err := resolveIPRules(cli, expiresAt)
+		if err != nil {
+			return err
+		}
+		return db.AddIPRules(context.Background(), rules)
+	}
+
 	policy, addresses, err := requirePolicyAndAddresses(cli)
 	if err != nil {
 		return err
 	}
-	err = db.AddAddressesToPolicy(context.Background(), policy, addresses)
+	err = db.AddAddressesToPolicy(context.Background(), policy, addresses, expiresAt)
 	if err != nil {
 		return err
 	}
@@ -134,6 +193,26 @@ func removeAcl(cli *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	policy, err := resolvePolicy(cli)
+	if err != nil {
+		return err
+	}
+
+	switch policy {
+	case pool.Call:
+		rules, err := resolveCallRules(cli, nil)
+		if err != nil {
+			return err
+		}
+		return db.RemoveCallRules(context.Background(), rules)
+	case pool.IP:
+		rules, err := resolveIPRules(cli, nil)
+		if err != nil {
+			return err
+		}
+		return db.RemoveIPRules(context.Background(), rules)
+	}
+
 	policy, addresses, err := requirePolicyAndAddresses(cli)
 	if err != nil {
 		return err
@@ -145,6 +224,34 @@ func removeAcl(cli *cli.Context) error {
 	return nil
 }
 
+func setMaxTxValue(cli *cli.Context) error {
+	_, db, err := configAndStorage(cli)
+	if err != nil {
+		return err
+	}
+	var maxValue *big.Int
+	if cli.IsSet(maxValueFlag.Name) {
+		var ok bool
+		maxValue, ok = new(big.Int).SetString(cli.String(maxValueFlag.Name), 10)
+		if !ok || maxValue.Sign() < 0 {
+			return fmt.Errorf("invalid max value: %s", cli.String(maxValueFlag.Name))
+		}
+	}
+	return db.SetMaxTxValue(context.Background(), maxValue)
+}
+
+func setSponsorBudget(cli *cli.Context) error {
+	_, db, err := configAndStorage(cli)
+	if err != nil {
+		return err
+	}
+	addresses, err := resolveAddresses(cli, true)
+	if err != nil {
+		return err
+	}
+	return db.SetSponsorBudget(context.Background(), addresses, cli.Uint64(gasBudgetFlag.Name))
+}
+
 func clearAcl(cli *cli.Context) error {
 	_, db, err := configAndStorage(cli)
 	if err != nil {
@@ -180,19 +287,61 @@ func describePolicy(cli *cli.Context, showHeader bool) error {
 		return err
 	}
 
-	if showHeader {
+	if showHeader && policyName == pool.Call {
+		fmt.Printf("%s: %s\n", "Policy", policyName)
+	} else if showHeader {
 		policy, err := db.DescribePolicy(context.Background(), policyName)
 		if err != nil {
 			return err
 		}
 		fmt.Printf("%s: %s\n", "Policy", policy.Name)
 		fmt.Printf("%s: %s\n", "Action", policy.Desc())
+		if policy.Name == pool.Value {
+			fmt.Printf("%s: %s\n", "Max value", describeMaxValue(policy.MaxValue))
+		}
 	}
+	switch policyName {
+	case pool.Call:
+		policies, err := db.ListCallPolicies(context.Background())
+		if err != nil {
+			return err
+		}
+		if showHeader {
+			fmt.Println("Contracts:")
+		}
+		for _, p := range policies {
+			fmt.Printf("%s: %s\n", p.Target.Hex(), (&pool.Policy{Allow: p.Allow}).Desc())
+		}
+		rules, err := db.ListCallRules(context.Background())
+		if err != nil {
+			return err
+		}
+		if showHeader {
+			fmt.Println("Calls:")
+		}
+		for _, rule := range rules {
+			fmt.Printf("%s:%s%s\n", rule.Target.Hex(), rule.Selector.Hex(), describeExpiry(rule.ExpiresAt))
+		}
+		return nil
+	case pool.IP:
+		rules, err := db.ListIPRules(context.Background())
+		if err != nil {
+			return err
+		}
+		if showHeader {
+			fmt.Println("IP ranges:")
+		}
+		for _, rule := range rules {
+			fmt.Printf("%s%s\n", rule.Range.String(), describeExpiry(rule.ExpiresAt))
+		}
+		return nil
+	}
+
 	query, err := resolveAddresses(cli, false)
 	if err != nil {
 		return nil
 	}
-	list, err := db.ListAcl(context.Background(), policyName, query)
+	list, err := db.ListAclEntries(context.Background(), policyName, query)
 	if err != nil {
 		return err
 	}
@@ -200,8 +349,21 @@ func describePolicy(cli *cli.Context, showHeader bool) error {
 	if showHeader {
 		fmt.Println("Addresses:")
 	}
-	for _, address := range list {
-		fmt.Println(address.Hex())
+	for _, entry := range list {
+		fmt.Printf("%s%s\n", entry.Address.Hex(), describeExpiry(entry.ExpiresAt))
+	}
+
+	if policyName == pool.Sponsor {
+		budgets, err := db.ListSponsorBudgets(context.Background())
+		if err != nil {
+			return err
+		}
+		if showHeader {
+			fmt.Println("Budgets:")
+		}
+		for _, b := range budgets {
+			fmt.Printf("%s: %d/%d gas used since %s\n", b.Address.Hex(), b.GasUsed, b.GasBudget, b.PeriodStart.Format(time.RFC3339))
+		}
 	}
 	return nil
 }
@@ -220,8 +382,13 @@ func describePolicies(cli *cli.Context, showHeader bool) error {
 		fmt.Printf("%7s: %s\n", "Policy", "Action")
 	}
 	for _, p := range list {
+		if p.Name == pool.Value {
+			fmt.Printf("%7s: %s (max value %s)\n", p.Name, p.Desc(), describeMaxValue(p.MaxValue))
+			continue
+		}
 		fmt.Printf("%7s: %s\n", p.Name, p.Desc())
 	}
+	fmt.Printf("%7s: %s\n", pool.Call, "per contract")
 
 	return nil
 }
@@ -233,6 +400,11 @@ func configAndStorage(cli *cli.Context) (*config.Config, *pgpoolstorage.Postgres
 	}
 	setupLog(c.Log)
 
+	// the memory storage lives in the node process, so its policies can't be managed from another process
+	if c.Pool.Storage == pool.StorageMemory {
+		return nil, nil, fmt.Errorf("policies can't be managed when the pool storage is %q", pool.StorageMemory)
+	}
+
 	db, err := pgpoolstorage.NewPostgresPoolStorage(c.Pool.DB)
 	if err != nil {
 		return nil, nil, err
@@ -306,3 +478,98 @@ func resolveAddresses(cli *cli.Context, failIfEmpty bool) ([]common.Address, err
 	}
 	return ret, nil
 }
+
+// resolveArgs returns the entries of the csv file and the arguments
+func resolveArgs(cli *cli.Context) ([]string, error) {
+	var entries []string
+	if cli.IsSet("csv") {
+		fd, err := os.Open(cli.String(csvFlag.Name))
+		if err != nil {
+			return nil, err
+		}
+		defer func(fd *os.File) {
+			_ = fd.Close()
+		}(fd)
+
+		records, err := csv.NewReader(fd).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range records {
+			for _, cell := range row {
+				entries = append(entries, strings.TrimSpace(cell))
+			}
+		}
+	}
+	for _, a := range cli.Args().Slice() {
+		entries = append(entries, strings.Trim(strings.TrimSpace(a), ",|"))
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no entries given")
+	}
+	return entries, nil
+}
+
+// resolveCallRules parses the target:selector pairs of the csv file and the arguments
+func resolveCallRules(cli *cli.Context, expiresAt *time.Time) ([]pool.CallRule, error) {
+	entries, err := resolveArgs(cli)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]pool.CallRule, 0, len(entries))
+	for _, entry := range entries {
+		target, selector, found := strings.Cut(entry, ":")
+		if !found || !common.IsHexAddress(target) {
+			return nil, fmt.Errorf("invalid call %q, expected target:selector", entry)
+		}
+		s, err := pool.HexToSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector in call %q: %w", entry, err)
+		}
+		rules = append(rules, pool.CallRule{Target: common.HexToAddress(target), Selector: s, ExpiresAt: expiresAt})
+	}
+	return rules, nil
+}
+
+// resolveIPRules parses the IP ranges of the csv file and the arguments, a single IP is a range with a full mask
+func resolveIPRules(cli *cli.Context, expiresAt *time.Time) ([]pool.IPRule, error) {
+	entries, err := resolveArgs(cli)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]pool.IPRule, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			rules = append(rules, pool.IPRule{Range: net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, ExpiresAt: expiresAt})
+			continue
+		}
+		_, ipRange, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, pool.IPRule{Range: *ipRange, ExpiresAt: expiresAt})
+	}
+	return rules, nil
+}
+
+func describeExpiry(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (expires %s)", expiresAt.Format(time.RFC3339))
+}
+
+func describeMaxValue(maxValue *big.Int) string {
+	if maxValue == nil {
+		return "none"
+	}
+	return maxValue.String()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0xPolygonHermez/zkevm-node/config"
+	"github.com/0xPolygonHermez/zkevm-node/db"
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/event/nileventstorage"
+	"github.com/0xPolygonHermez/zkevm-node/event/pgeventstorage"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/urfave/cli/v2"
+)
+
+var inputFileFlag = cli.StringFlag{
+	Name:     "input",
+	Usage:    "Indicate the input file",
+	Required: true,
+}
+
+var poolCommands = cli.Command{
+	Name:  "pool",
+	Usage: "Export and import the pending transactions of the pool",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "export",
+			Usage:  "Export the pending transactions of the pool to a JSONL file",
+			Action: exportPool,
+			Flags:  []cli.Flag{&configFileFlag, &outputFileFlag},
+		}, {
+			Name:   "import",
+			Usage:  "Import the transactions of a JSONL file into the pool, validating them again",
+			Action: importPool,
+			Flags:  []cli.Flag{&configFileFlag, &networkFlag, &customNetworkFlag, &inputFileFlag},
+		},
+	},
+}
+
+func exportPool(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, false)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Log)
+
+	poolStorage, err := newPoolStorage(c.Pool)
+	if err != nil {
+		return err
+	}
+	eventLog, err := newEventLog(c.EventLog)
+	if err != nil {
+		return err
+	}
+	// the txs are only read from the storage, so the pool doesn't need the state
+	poolInstance := pool.NewPool(c.Pool, c.State.Batch.Constraints, poolStorage, nil, 0, eventLog)
+
+	file, err := os.Create(cliCtx.String(config.FlagOutputFile))
+	if err != nil {
+		return err
+	}
+	count, err := poolInstance.ExportTxs(cliCtx.Context, file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	log.Infof("%d pool txs exported to %s", count, file.Name())
+	return nil
+}
+
+func importPool(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, true)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Log)
+
+	runPoolMigrations(c.Pool.DB)
+	poolStorage, err := newPoolStorage(c.Pool)
+	if err != nil {
+		return err
+	}
+	eventLog, err := newEventLog(c.EventLog)
+	if err != nil {
+		return err
+	}
+
+	stateSqlDB, err := db.NewSQLDB(c.State.DB)
+	if err != nil {
+		return err
+	}
+	etherman, err := newEtherman(*c)
+	if err != nil {
+		return err
+	}
+	l2ChainID, err := etherman.GetL2ChainID()
+	if err != nil {
+		return err
+	}
+	// the txs are pre executed to be validated, so the executor and the state tree are needed
+	st := newState(cliCtx.Context, c, l2ChainID, []state.ForkIDInterval{}, stateSqlDB, eventLog, true, true)
+	forkIDIntervals, err := forkIDIntervals(cliCtx.Context, st, etherman, c.NetworkConfig.Genesis.GenesisBlockNum)
+	if err != nil {
+		return err
+	}
+	st.UpdateForkIDIntervalsInMemory(forkIDIntervals)
+	poolInstance := pool.NewPool(c.Pool, c.State.Batch.Constraints, poolStorage, st, l2ChainID, eventLog)
+
+	file, err := os.Open(cliCtx.String(inputFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	result, err := poolInstance.ImportTxs(cliCtx.Context, file)
+	log.Infof("pool txs read: %d imported: %d already known: %d rejected: %d", result.Read, result.Imported, result.Known, result.Rejected)
+	return err
+}
+
+func newPoolStorage(cfg pool.Config) (*pgpoolstorage.PostgresPoolStorage, error) {
+	// the memory storage lives in the node process, so its txs can't be reached from another process
+	if cfg.Storage == pool.StorageMemory {
+		return nil, fmt.Errorf("pool txs can't be exported or imported when the pool storage is %q", pool.StorageMemory)
+	}
+	return pgpoolstorage.NewPostgresPoolStorage(cfg.DB)
+}
+
+func newEventLog(cfg event.Config) (*event.EventLog, error) {
+	var (
+		eventStorage event.Storage
+		err          error
+	)
+	if cfg.DB.Name != "" {
+		eventStorage, err = pgeventstorage.NewPostgresEventStorage(cfg.DB)
+	} else {
+		eventStorage, err = nileventstorage.NewNilEventStorage()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event.NewEventLog(cfg, eventStorage), nil
+}
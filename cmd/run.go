@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"net"
@@ -30,6 +31,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
 	"github.com/0xPolygonHermez/zkevm-node/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/memorypoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer"
 	"github.com/0xPolygonHermez/zkevm-node/sequencesender"
@@ -203,6 +205,7 @@ func start(cliCtx *cli.Context) error {
 				poolInstance.StartPollingMinSuggestedGasPrice(cliCtx.Context)
 			}
 			poolInstance.StartRefreshingBlockedAddressesPeriodically()
+			poolInstance.StartEvictingTxs(cliCtx.Context)
 			apis := map[string]bool{}
 			for _, a := range cliCtx.StringSlice(config.FlagHTTPAPI) {
 				apis[a] = true
@@ -218,6 +221,8 @@ func start(cliCtx *cli.Context) error {
 			if poolInstance == nil {
 				poolInstance = createPool(c.Pool, c.State.Batch.Constraints, l2ChainID, st, eventLog)
 			}
+			// the synchronizer is the component writing the state, so it's the one pruning it
+			st.StartPruning(cliCtx.Context)
 			go runSynchronizer(*c, etherman, ethTxManagerStorage, st, poolInstance, eventLog)
 		case ETHTXMANAGER:
 			ev.Component = event.Component_EthTxManager
@@ -344,11 +349,17 @@ func runJSONRPCServer(c config.Config, etherman *etherman.Client, chainID uint64
 		log.Debug("SequencerNodeURI ", c.RPC.SequencerNodeURI)
 	}
 
+	// the relay is shared by all the endpoints so the health of the upstreams is tracked only once
+	var relay *jsonrpc.SequencerRelay
+	if c.RPC.SequencerNodeURI != "" {
+		relay = jsonrpc.NewSequencerRelay(c.RPC.SequencerNodeURI, c.RPC.SequencerRelay)
+	}
+
 	services := []jsonrpc.Service{}
 	if _, ok := apis[jsonrpc.APIEth]; ok {
 		services = append(services, jsonrpc.Service{
 			Name:    jsonrpc.APIEth,
-			Service: jsonrpc.NewEthEndpoints(c.RPC, chainID, pool, st, etherman, storage),
+			Service: jsonrpc.NewEthEndpoints(c.RPC, chainID, pool, st, etherman, storage, relay),
 		})
 	}
 
@@ -362,7 +373,7 @@ func runJSONRPCServer(c config.Config, etherman *etherman.Client, chainID uint64
 	if _, ok := apis[jsonrpc.APIZKEVM]; ok {
 		services = append(services, jsonrpc.Service{
 			Name:    jsonrpc.APIZKEVM,
-			Service: jsonrpc.NewZKEVMEndpoints(c.RPC, st, etherman),
+			Service: jsonrpc.NewZKEVMEndpoints(c.RPC, pool, st, etherman, relay),
 		})
 	}
 
@@ -387,7 +398,17 @@ func runJSONRPCServer(c config.Config, etherman *etherman.Client, chainID uint64
 		})
 	}
 
-	if err := jsonrpc.NewServer(c.RPC, chainID, pool, st, storage, services).Start(); err != nil {
+	if _, ok := apis[jsonrpc.APIAdmin]; ok {
+		if c.RPC.AdminAPIKey == "" {
+			log.Fatal("the admin API requires an AdminAPIKey")
+		}
+		services = append(services, jsonrpc.Service{
+			Name:    jsonrpc.APIAdmin,
+			Service: jsonrpc.NewAdminEndpoints(c.RPC.AdminAPIKey, pool),
+		})
+	}
+
+	if err := jsonrpc.NewServer(c.RPC, chainID, pool, st, storage, relay, services).Start(); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -398,7 +419,15 @@ func createSequencer(cfg config.Config, pool *pool.Pool, st *state.State, eventL
 		log.Fatal(err)
 	}
 
-	seq, err := sequencer.New(cfg.Sequencer, cfg.State.Batch, cfg.Pool, pool, st, etherman, eventLog)
+	var preconfKey *ecdsa.PrivateKey
+	if cfg.Sequencer.Preconfirmations.Enabled {
+		_, preconfKey, err = etherman.LoadAuthFromKeyStore(cfg.Sequencer.Preconfirmations.PrivateKey.Path, cfg.Sequencer.Preconfirmations.PrivateKey.Password)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	seq, err := sequencer.New(cfg.Sequencer, cfg.State.Batch, cfg.Pool, pool, st, etherman, eventLog, preconfKey)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -470,7 +499,15 @@ func newState(ctx context.Context, c *config.Config, l2ChainID uint64, forkIDInt
 	// Executor
 	var executorClient executor.ExecutorServiceClient
 	if needsExecutor {
-		executorClient, _, _ = executor.NewExecutorClient(ctx, c.Executor)
+		if c.Executor.HasPools() {
+			executorClientPool, err := executor.NewClientPool(ctx, c.Executor)
+			if err != nil {
+				log.Fatal(err)
+			}
+			executorClient = executorClientPool
+		} else {
+			executorClient, _, _ = executor.NewExecutorClient(ctx, c.Executor)
+		}
 	}
 
 	// State Tree
@@ -491,6 +528,7 @@ func newState(ctx context.Context, c *config.Config, l2ChainID uint64, forkIDInt
 		MaxLogsCount:                 c.RPC.MaxLogsCount,
 		MaxLogsBlockRange:            c.RPC.MaxLogsBlockRange,
 		MaxNativeBlockHashBlockRange: c.RPC.MaxNativeBlockHashBlockRange,
+		Pruning:                      c.State.Pruning,
 	}
 
 	st := state.NewState(stateCfg, stateDb, executorClient, stateTree, eventLog)
@@ -498,13 +536,21 @@ func newState(ctx context.Context, c *config.Config, l2ChainID uint64, forkIDInt
 }
 
 func createPool(cfgPool pool.Config, constraintsCfg state.BatchConstraintsCfg, l2ChainID uint64, st *state.State, eventLog *event.EventLog) *pool.Pool {
-	runPoolMigrations(cfgPool.DB)
-	poolStorage, err := pgpoolstorage.NewPostgresPoolStorage(cfgPool.DB)
-	if err != nil {
-		log.Fatal(err)
+	switch cfgPool.Storage {
+	case "", pool.StoragePostgres:
+		runPoolMigrations(cfgPool.DB)
+		poolStorage, err := pgpoolstorage.NewPostgresPoolStorage(cfgPool.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return pool.NewPool(cfgPool, constraintsCfg, poolStorage, st, l2ChainID, eventLog)
+	case pool.StorageMemory:
+		log.Warn("pool txs are stored in memory, they will be lost when the node stops")
+		return pool.NewPool(cfgPool, constraintsCfg, memorypoolstorage.NewMemoryPoolStorage(), st, l2ChainID, eventLog)
+	default:
+		log.Fatalf("unknown pool storage: %s", cfgPool.Storage)
+		return nil
 	}
-	poolInstance := pool.NewPool(cfgPool, constraintsCfg, poolStorage, st, l2ChainID, eventLog)
-	return poolInstance
 }
 
 func createEthTxManager(cfg config.Config, etmStorage *ethtxmanager.PostgresStorage, st *state.State) *ethtxmanager.Client {
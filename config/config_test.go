@@ -72,6 +72,38 @@ func Test_Defaults(t *testing.T) {
 			path:          "Sequencer.MaxTxLifetime",
 			expectedValue: types.NewDuration(3 * time.Hour),
 		},
+		{
+			path:          "Sequencer.TxOrdering.Policy",
+			expectedValue: "gasprice",
+		},
+		{
+			path:          "Sequencer.TxOrdering.FairShareWindowSize",
+			expectedValue: uint64(100),
+		},
+		{
+			path:          "Sequencer.Quotas.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Sequencer.Quotas.WindowSize",
+			expectedValue: uint64(10),
+		},
+		{
+			path:          "Sequencer.Quotas.MaxTxsPerSender",
+			expectedValue: uint64(0),
+		},
+		{
+			path:          "Sequencer.Quotas.MaxTxsPerIP",
+			expectedValue: uint64(0),
+		},
+		{
+			path:          "Sequencer.Quotas.MaxZKCountersPercentagePerSender",
+			expectedValue: uint32(0),
+		},
+		{
+			path:          "Sequencer.Quotas.MaxZKCountersPercentagePerIP",
+			expectedValue: uint32(0),
+		},
 		{
 			path:          "Sequencer.Finalizer.GERDeadlineTimeout",
 			expectedValue: types.NewDuration(5 * time.Second),
@@ -116,6 +148,22 @@ func Test_Defaults(t *testing.T) {
 			path:          "Sequencer.Finalizer.TimestampResolution",
 			expectedValue: types.NewDuration(10 * time.Second),
 		},
+		{
+			path:          "Sequencer.Finalizer.BatchClosing.Strategies",
+			expectedValue: []string{"resourcethreshold"},
+		},
+		{
+			path:          "Sequencer.Finalizer.BatchClosing.TargetBatchTime",
+			expectedValue: types.NewDuration(0),
+		},
+		{
+			path:          "Sequencer.Finalizer.BatchClosing.TargetL1DataSize",
+			expectedValue: uint64(0),
+		},
+		{
+			path:          "Sequencer.Finalizer.BatchClosing.TargetGas",
+			expectedValue: uint64(0),
+		},
 		{
 			path:          "Sequencer.DBManager.PoolRetrievalInterval",
 			expectedValue: types.NewDuration(500 * time.Millisecond),
@@ -136,6 +184,46 @@ func Test_Defaults(t *testing.T) {
 			path:          "Sequencer.StreamServer.Enabled",
 			expectedValue: false,
 		},
+		{
+			path:          "Sequencer.Admin.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Sequencer.Admin.Host",
+			expectedValue: "127.0.0.1",
+		},
+		{
+			path:          "Sequencer.Admin.Port",
+			expectedValue: 8547,
+		},
+		{
+			path:          "Sequencer.Admin.APIKey",
+			expectedValue: "",
+		},
+		{
+			path:          "Sequencer.Preconfirmations.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Sequencer.Preconfirmations.PrivateKey",
+			expectedValue: types.KeystoreFileConfig{Path: "/pk/sequencer.keystore", Password: "testonly"},
+		},
+		{
+			path:          "Sequencer.LeaderElection.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Sequencer.LeaderElection.InstanceID",
+			expectedValue: "",
+		},
+		{
+			path:          "Sequencer.LeaderElection.LeaseDuration",
+			expectedValue: types.NewDuration(15 * time.Second),
+		},
+		{
+			path:          "Sequencer.LeaderElection.RenewInterval",
+			expectedValue: types.NewDuration(5 * time.Second),
+		},
 		{
 			path:          "SequenceSender.WaitPeriodSendSequence",
 			expectedValue: types.NewDuration(5 * time.Second),
@@ -260,6 +348,10 @@ func Test_Defaults(t *testing.T) {
 			path:          "Pool.MaxTxDataBytesSize",
 			expectedValue: 100000,
 		},
+		{
+			path:          "Pool.Storage",
+			expectedValue: "postgres",
+		},
 
 		{
 			path:          "Pool.DefaultMinGasPriceAllowed",
@@ -281,6 +373,62 @@ func Test_Defaults(t *testing.T) {
 			path:          "Pool.GlobalQueue",
 			expectedValue: uint64(1024),
 		},
+		{
+			path:          "Pool.PriceBump",
+			expectedValue: uint64(10),
+		},
+		{
+			path:          "Pool.MaxTxsPerBundle",
+			expectedValue: uint64(16),
+		},
+		{
+			path:          "Pool.Sponsorship.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Pool.Sponsorship.Period",
+			expectedValue: types.NewDuration(24 * time.Hour),
+		},
+		{
+			path:          "Pool.Reputation.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Pool.Reputation.Window",
+			expectedValue: types.NewDuration(time.Hour),
+		},
+		{
+			path:          "Pool.Reputation.LimitThreshold",
+			expectedValue: uint64(10),
+		},
+		{
+			path:          "Pool.Reputation.LimitedAccountQueue",
+			expectedValue: uint64(4),
+		},
+		{
+			path:          "Pool.Reputation.BanThreshold",
+			expectedValue: uint64(50),
+		},
+		{
+			path:          "Pool.Reputation.BanDuration",
+			expectedValue: types.NewDuration(5 * time.Minute),
+		},
+		{
+			path:          "Pool.Reputation.MaxBanDuration",
+			expectedValue: types.NewDuration(24 * time.Hour),
+		},
+		{
+			path:          "Pool.Eviction.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "Pool.Eviction.Interval",
+			expectedValue: types.NewDuration(time.Minute),
+		},
+		{
+			path:          "Pool.Eviction.TTL",
+			expectedValue: types.NewDuration(3 * time.Hour),
+		},
 		{
 			path:          "Pool.EffectiveGasPrice.Enabled",
 			expectedValue: false,
@@ -389,6 +537,46 @@ func Test_Defaults(t *testing.T) {
 			path:          "RPC.EnableHttpLog",
 			expectedValue: true,
 		},
+		{
+			path:          "RPC.TrustedProxies",
+			expectedValue: []string{},
+		},
+		{
+			path:          "RPC.AdminAPIKey",
+			expectedValue: "",
+		},
+		{
+			path:          "RPC.SequencerRelay.UpstreamURIs",
+			expectedValue: []string{},
+		},
+		{
+			path:          "RPC.SequencerRelay.HealthCheckInterval",
+			expectedValue: types.NewDuration(10 * time.Second),
+		},
+		{
+			path:          "RPC.SequencerRelay.FailureThreshold",
+			expectedValue: uint64(3),
+		},
+		{
+			path:          "RPC.SequencerRelay.CircuitOpenDuration",
+			expectedValue: types.NewDuration(30 * time.Second),
+		},
+		{
+			path:          "RPC.AccessLog.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "RPC.AccessLog.Sink",
+			expectedValue: "log",
+		},
+		{
+			path:          "RPC.AccessLog.FilePath",
+			expectedValue: "",
+		},
+		{
+			path:          "RPC.AccessLog.APIKeyHeader",
+			expectedValue: "",
+		},
 		{
 			path:          "RPC.WebSockets.Enabled",
 			expectedValue: true,
@@ -421,6 +609,34 @@ func Test_Defaults(t *testing.T) {
 			path:          "Executor.MaxGRPCMessageSize",
 			expectedValue: int(100000000),
 		},
+		{
+			path:          "Executor.URIs",
+			expectedValue: []string{},
+		},
+		{
+			path:          "Executor.SequencerURIs",
+			expectedValue: []string{},
+		},
+		{
+			path:          "Executor.SynchronizerURIs",
+			expectedValue: []string{},
+		},
+		{
+			path:          "Executor.RPCURIs",
+			expectedValue: []string{},
+		},
+		{
+			path:          "Executor.HealthCheckInterval",
+			expectedValue: types.NewDuration(10 * time.Second),
+		},
+		{
+			path:          "Executor.MaxRetries",
+			expectedValue: 2,
+		},
+		{
+			path:          "Executor.RetryDelay",
+			expectedValue: types.NewDuration(100 * time.Millisecond),
+		},
 		{
 			path:          "Metrics.Host",
 			expectedValue: "0.0.0.0",
@@ -509,6 +725,22 @@ func Test_Defaults(t *testing.T) {
 			path:          "State.Batch.Constraints.MaxBinaries",
 			expectedValue: uint32(473170),
 		},
+		{
+			path:          "State.Pruning.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "State.Pruning.Interval",
+			expectedValue: types.NewDuration(10 * time.Minute),
+		},
+		{
+			path:          "State.Pruning.RetainedBatches",
+			expectedValue: uint64(10000),
+		},
+		{
+			path:          "State.Pruning.MaxBatchesPerPruning",
+			expectedValue: uint64(1000),
+		},
 	}
 	file, err := os.CreateTemp("", "genesisConfig")
 	require.NoError(t, err)
@@ -32,17 +32,40 @@ Outputs = ["stderr"]
 		MaxArithmetics = 236585
 		MaxBinaries = 473170
 		MaxSteps = 7570538
+	[State.Pruning]
+	Enabled = false
+	Interval = "10m"
+	RetainedBatches = 10000
+	MaxBatchesPerPruning = 1000
 
 [Pool]
 IntervalToRefreshBlockedAddresses = "5m"
 IntervalToRefreshGasPrices = "5s"
 MaxTxBytesSize=100132
 MaxTxDataBytesSize=100000
+Storage = "postgres"
 DefaultMinGasPriceAllowed = 1000000000
 MinAllowedGasPriceInterval = "5m"
 PollMinAllowedGasPriceInterval = "15s"
 AccountQueue = 64
 GlobalQueue = 1024
+PriceBump = 10
+MaxTxsPerBundle = 16
+    [Pool.Sponsorship]
+    Enabled = false
+    Period = "24h"
+    [Pool.Reputation]
+    Enabled = false
+    Window = "1h"
+    LimitThreshold = 10
+    LimitedAccountQueue = 4
+    BanThreshold = 50
+    BanDuration = "5m"
+    MaxBanDuration = "24h"
+    [Pool.Eviction]
+    Enabled = false
+    Interval = "1m"
+    TTL = "3h"
     [Pool.EffectiveGasPrice]
 	Enabled = false
 	L1GasPriceFactor = 0.25
@@ -83,6 +106,8 @@ ReadTimeout = "60s"
 WriteTimeout = "60s"
 MaxRequestsPerIPAndSecond = 500
 SequencerNodeURI = ""
+TrustedProxies = []
+AdminAPIKey = ""
 EnableL2SuggestedGasPricePolling = true
 BatchRequestsEnabled = false
 BatchRequestsLimit = 20
@@ -90,6 +115,16 @@ MaxLogsCount = 10000
 MaxLogsBlockRange = 10000
 MaxNativeBlockHashBlockRange = 60000
 EnableHttpLog = true
+	[RPC.SequencerRelay]
+		UpstreamURIs = []
+		HealthCheckInterval = "10s"
+		FailureThreshold = 3
+		CircuitOpenDuration = "30s"
+	[RPC.AccessLog]
+		Enabled = false
+		Sink = "log"
+		FilePath = ""
+		APIKeyHeader = ""
 	[RPC.WebSockets]
 		Enabled = true
 		Host = "0.0.0.0"
@@ -122,6 +157,16 @@ FrequencyToCheckTxsForDelete = "12h"
 TxLifetimeCheckTimeout = "10m"
 MaxTxLifetime = "3h"
 L2Coinbase = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
+	[Sequencer.TxOrdering]
+		Policy = "gasprice"
+		FairShareWindowSize = 100
+	[Sequencer.Quotas]
+		Enabled = false
+		WindowSize = 10
+		MaxTxsPerSender = 0
+		MaxTxsPerIP = 0
+		MaxZKCountersPercentagePerSender = 0
+		MaxZKCountersPercentagePerIP = 0
 	[Sequencer.Finalizer]
 		GERDeadlineTimeout = "5s"
 		ForcedBatchDeadlineTimeout = "60s"
@@ -135,6 +180,11 @@ L2Coinbase = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
 		TimestampResolution = "10s"
 		StopSequencerOnBatchNum = 0
 		SequentialReprocessFullBatch = false
+		[Sequencer.Finalizer.BatchClosing]
+			Strategies = ["resourcethreshold"]
+			TargetBatchTime = "0s"
+			TargetL1DataSize = 0
+			TargetGas = 0
 	[Sequencer.DBManager]
 		PoolRetrievalInterval = "500ms"
 		L2ReorgRetrievalInterval = "5s"
@@ -142,6 +192,19 @@ L2Coinbase = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
 		Port = 0
 		Filename = ""
 		Enabled = false
+	[Sequencer.Admin]
+		Enabled = false
+		Host = "127.0.0.1"
+		Port = 8547
+		APIKey = ""
+	[Sequencer.Preconfirmations]
+		Enabled = false
+		PrivateKey = {Path = "/pk/sequencer.keystore", Password = "testonly"}
+	[Sequencer.LeaderElection]
+		Enabled = false
+		InstanceID = ""
+		LeaseDuration = "15s"
+		RenewInterval = "5s"
 
 [SequenceSender]
 WaitPeriodSendSequence = "5s"
@@ -183,6 +246,13 @@ URI = "zkevm-prover:50071"
 MaxResourceExhaustedAttempts = 3
 WaitOnResourceExhaustion = "1s"
 MaxGRPCMessageSize = 100000000
+URIs = []
+SequencerURIs = []
+SynchronizerURIs = []
+RPCURIs = []
+HealthCheckInterval = "10s"
+MaxRetries = 2
+RetryDelay = "100ms"
 
 [Metrics]
 Host = "0.0.0.0"
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE pool.transaction ADD COLUMN bundle_hash VARCHAR;
+ALTER TABLE pool.transaction ADD COLUMN bundle_index INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_bundle_hash ON pool.transaction (bundle_hash);
+
+-- +migrate Down
+DROP INDEX IF EXISTS pool.idx_transaction_bundle_hash;
+
+ALTER TABLE pool.transaction DROP COLUMN bundle_hash;
+ALTER TABLE pool.transaction DROP COLUMN bundle_index;
@@ -0,0 +1,18 @@
+-- +migrate Down
+DELETE FROM pool.acl WHERE policy = 'sponsor';
+DELETE FROM pool.policy WHERE name = 'sponsor';
+DROP TABLE IF EXISTS pool.sponsor_budget;
+ALTER TABLE pool.transaction DROP COLUMN IF EXISTS sponsor;
+
+-- +migrate Up
+INSERT INTO pool.policy (name, allow) VALUES ('sponsor', true) ON CONFLICT DO NOTHING;
+
+CREATE TABLE pool.sponsor_budget
+(
+    address      VARCHAR PRIMARY KEY,
+    gas_budget   BIGINT NOT NULL,
+    gas_used     BIGINT NOT NULL DEFAULT 0,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+ALTER TABLE pool.transaction ADD COLUMN sponsor VARCHAR;
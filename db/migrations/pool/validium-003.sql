@@ -0,0 +1,36 @@
+-- +migrate Down
+DELETE FROM pool.acl WHERE policy IN ('call', 'value', 'ip');
+DELETE FROM pool.policy WHERE name IN ('call', 'value', 'ip');
+DROP TABLE IF EXISTS pool.call_acl;
+DROP TABLE IF EXISTS pool.call_policy;
+DROP TABLE IF EXISTS pool.ip_acl;
+ALTER TABLE pool.policy DROP COLUMN IF EXISTS max_value;
+ALTER TABLE pool.acl DROP COLUMN IF EXISTS expires_at;
+
+-- +migrate Up
+INSERT INTO pool.policy (name, allow) VALUES ('value', true) ON CONFLICT DO NOTHING;
+INSERT INTO pool.policy (name, allow) VALUES ('ip', false) ON CONFLICT DO NOTHING;
+
+ALTER TABLE pool.policy ADD COLUMN max_value NUMERIC(80, 0);
+ALTER TABLE pool.acl ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE pool.call_acl
+(
+    target     VARCHAR,
+    selector   VARCHAR,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (target, selector)
+);
+
+-- the mode of the call policy is set per target contract
+CREATE TABLE pool.call_policy
+(
+    target VARCHAR PRIMARY KEY,
+    allow  BOOLEAN NOT NULL
+);
+
+CREATE TABLE pool.ip_acl
+(
+    ip_range   CIDR PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
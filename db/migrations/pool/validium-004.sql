@@ -0,0 +1,15 @@
+-- +migrate Down
+DROP TABLE IF EXISTS pool.reputation;
+
+-- +migrate Up
+CREATE TABLE pool.reputation
+(
+    subject      VARCHAR PRIMARY KEY,
+    invalid      BIGINT NOT NULL DEFAULT 0,
+    reverted     BIGINT NOT NULL DEFAULT 0,
+    ooc          BIGINT NOT NULL DEFAULT 0,
+    underpriced  BIGINT NOT NULL DEFAULT 0,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    bans         BIGINT NOT NULL DEFAULT 0,
+    banned_until TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- the preconfirmations don't reference state.transaction, they are the evidence of the promised
+-- inclusion, so they must be kept when the tx is removed from the state by a reorg or the pruning.
+-- A tx reprocessed after a sequencer restart gets a new preconfirmation, all of them are kept
+CREATE TABLE IF NOT EXISTS state.preconfirmation
+(
+    id           SERIAL PRIMARY KEY,
+    tx_hash      VARCHAR NOT NULL,
+    batch_num    BIGINT  NOT NULL,
+    l2_block_num BIGINT  NOT NULL,
+    position     BIGINT  NOT NULL,
+    state_root   VARCHAR NOT NULL,
+    signature    BYTEA   NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS preconfirmation_tx_hash_idx ON state.preconfirmation (tx_hash);
+
+-- +migrate Down
+DROP TABLE IF EXISTS state.preconfirmation;
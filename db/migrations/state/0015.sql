@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS state.prune_info
+(
+    id           INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    batch_num    BIGINT                   NOT NULL,
+    l2_block_num BIGINT                   NOT NULL,
+    pruned_at    TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS state.pruned_transaction
+(
+    hash         VARCHAR PRIMARY KEY,
+    l2_block_num BIGINT NOT NULL REFERENCES state.l2block (block_num) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS state.pruned_transaction;
+DROP TABLE IF EXISTS state.prune_info;
@@ -0,0 +1,35 @@
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// this migration adds the tables with the last pruned batch and l2 block and the pruned transactions
+type migrationTest0015 struct{}
+
+func (m migrationTest0015) InsertData(db *sql.DB) error {
+	return nil
+}
+
+func (m migrationTest0015) RunAssertsAfterMigrationUp(t *testing.T, db *sql.DB) {
+	const getTables = `SELECT count(*) FROM information_schema.tables WHERE table_schema = 'state' AND table_name IN ('prune_info', 'pruned_transaction')`
+	row := db.QueryRow(getTables)
+	var result int
+	assert.NoError(t, row.Scan(&result))
+	assert.Equal(t, 2, result)
+}
+
+func (m migrationTest0015) RunAssertsAfterMigrationDown(t *testing.T, db *sql.DB) {
+	const getTables = `SELECT count(*) FROM information_schema.tables WHERE table_schema = 'state' AND table_name IN ('prune_info', 'pruned_transaction')`
+	row := db.QueryRow(getTables)
+	var result int
+	assert.NoError(t, row.Scan(&result))
+	assert.Equal(t, 0, result)
+}
+
+func TestMigration0015(t *testing.T) {
+	runMigrationTest(t, 15, migrationTest0015{})
+}
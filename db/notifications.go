@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Listen listens to the postgres channel in a dedicated connection and sends the notifications received to the
+// returned channel. The connection is taken out of the db pool so the LISTEN isn't inherited by other queries.
+// The returned channel is closed when the connection drops or the context is done
+func Listen(ctx context.Context, pool *pgxpool.Pool, channel string) (<-chan *pgconn.Notification, error) {
+	poolConn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := poolConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		poolConn.Release()
+		return nil, err
+	}
+	conn := poolConn.Hijack()
+
+	notifications := make(chan *pgconn.Notification)
+	go func() {
+		defer close(notifications)
+		defer conn.Close(context.Background()) //nolint:errcheck
+
+		for {
+			notification, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("failed to wait for %s notification: %v", channel, err)
+				}
+				return
+			}
+			select {
+			case notifications <- notification:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notifications, nil
+}
@@ -36,6 +36,16 @@ const (
 	EventID_FinalizerRestart EventID = "FINALIZER RESTART"
 	// EventID_FinalizerBreakEvenGasPriceBigDifference is triggered when the finalizer recalculates the break even gas price and detects a big difference
 	EventID_FinalizerBreakEvenGasPriceBigDifference EventID = "FINALIZER BREAK EVEN GAS PRICE BIG DIFFERENCE"
+	// EventID_SequencerAdminAction is triggered when an operator steers the sequencer through the admin API
+	EventID_SequencerAdminAction EventID = "SEQUENCER ADMIN ACTION"
+	// EventID_SequencerLeadershipAcquired is triggered when a sequencer instance becomes the active sequencer
+	EventID_SequencerLeadershipAcquired EventID = "SEQUENCER LEADERSHIP ACQUIRED"
+	// EventID_SequencerLeadershipLost is triggered when the active sequencer instance can't keep its leadership lease
+	EventID_SequencerLeadershipLost EventID = "SEQUENCER LEADERSHIP LOST"
+	// EventID_TxReplacementRejected is triggered when a tx with the same sender and nonce of another tx is rejected as its replacement
+	EventID_TxReplacementRejected EventID = "TX REPLACEMENT REJECTED"
+	// EventID_PoolTxEvicted is triggered when a pending tx is evicted from the pool
+	EventID_PoolTxEvicted EventID = "POOL TX EVICTED"
 	// EventID_SynchronizerRestart is triggered when the Synchonizer restarts
 	EventID_SynchronizerRestart EventID = "SYNCHRONIZER RESTART"
 	// EventID_SynchronizerHalt is triggered when the synchronizer halts
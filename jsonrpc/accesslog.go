@@ -0,0 +1,131 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+)
+
+const (
+	// AccessLogSinkLog writes the access log entries through the node logger
+	AccessLogSinkLog = "log"
+	// AccessLogSinkStdout writes the access log entries as json lines to the stdout
+	AccessLogSinkStdout = "stdout"
+	// AccessLogSinkFile writes the access log entries as json lines to the file set in FilePath
+	AccessLogSinkFile = "file"
+
+	accessLogStatusSuccess = "success"
+	accessLogStatusError   = "error"
+)
+
+// accessLogEntry is the information recorded for each jsonrpc request,
+// batch requests produce one entry per item
+type accessLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	ParamsSize int       `json:"paramsSize"`
+	APIKey     string    `json:"apiKey,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Latency    float64   `json:"latencyMs"`
+	Status     string    `json:"status"`
+	ErrorCode  int       `json:"errorCode,omitempty"`
+	WebSocket  bool      `json:"ws,omitempty"`
+}
+
+// accessLogger records the access log entries in the configured sink
+type accessLogger struct {
+	cfg    AccessLogConfig
+	ips    *ipResolver
+	mutex  sync.Mutex
+	writer io.Writer
+	file   *os.File
+}
+
+// newAccessLogger creates an access logger for the configured sink, the IPs of the clients are
+// resolved with the provided resolver
+func newAccessLogger(cfg AccessLogConfig, ips *ipResolver) (*accessLogger, error) {
+	l := &accessLogger{cfg: cfg, ips: ips}
+	switch cfg.Sink {
+	case "", AccessLogSinkLog:
+	case AccessLogSinkStdout:
+		l.writer = os.Stdout
+	case AccessLogSinkFile:
+		f, err := os.OpenFile(cfg.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec,gomnd
+		if err != nil {
+			return nil, fmt.Errorf("failed to open access log file %s: %w", cfg.FilePath, err)
+		}
+		l.writer = f
+		l.file = f
+	default:
+		return nil, fmt.Errorf("invalid access log sink: %s", cfg.Sink)
+	}
+	return l, nil
+}
+
+// record builds the access log entry for the request and writes it to the sink
+func (l *accessLogger) record(req handleRequest, res types.Response, start time.Time) {
+	entry := accessLogEntry{
+		Time:       start,
+		Method:     req.Method,
+		ParamsSize: len(req.Params),
+		Latency:    float64(time.Since(start)) / float64(time.Millisecond),
+		Status:     accessLogStatusSuccess,
+		WebSocket:  req.wsConn != nil,
+	}
+	if res.Error != nil {
+		entry.Status = accessLogStatusError
+		entry.ErrorCode = res.Error.Code
+	}
+	if req.HttpRequest != nil {
+		if l.cfg.APIKeyHeader != "" {
+			entry.APIKey = req.HttpRequest.Header.Get(l.cfg.APIKeyHeader)
+		}
+		if entry.APIKey == "" {
+			entry.IP = l.ips.clientIP(req.HttpRequest)
+		}
+	}
+
+	if l.writer == nil {
+		log.Infow("jsonrpc access",
+			"method", entry.Method,
+			"paramsSize", entry.ParamsSize,
+			"apiKey", entry.APIKey,
+			"ip", entry.IP,
+			"latencyMs", entry.Latency,
+			"status", entry.Status,
+			"errorCode", entry.ErrorCode,
+			"ws", entry.WebSocket,
+		)
+		return
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Errorf("failed to encode access log entry: %v", err)
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.writer.Write(append(b, '\n')); err != nil {
+		log.Errorf("failed to write access log entry: %v", err)
+	}
+}
+
+// close closes the file sink, the entries recorded afterwards are dropped
+func (l *accessLogger) close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.writer = io.Discard
+	return err
+}
@@ -0,0 +1,78 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/client"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	const apiKeyHeader = "X-Api-Key"
+	filePath := filepath.Join(t.TempDir(), "access.log")
+
+	cfg := getSequencerDefaultConfig()
+	cfg.AccessLog = AccessLogConfig{
+		Enabled:      true,
+		Sink:         AccessLogSinkFile,
+		FilePath:     filePath,
+		APIKeyHeader: apiKeyHeader,
+	}
+	s, _, _ := newMockedServerWithCustomConfig(t, cfg)
+	defer s.Stop()
+
+	// the X-Forwarded-For header is ignored when the request doesn't come from a trusted proxy
+	headers := http.Header{}
+	headers.Set(forwardedForHeader, "1.2.3.4")
+	_, err := client.JSONRPCCallWithHeaders(s.ServerURL, headers, "web3_clientVersion")
+	require.NoError(t, err)
+
+	_, err = s.JSONRPCBatchCall(
+		client.BatchCall{Method: "web3_sha3", Parameters: []interface{}{"0x68656c6c6f20776f726c64"}},
+		client.BatchCall{Method: "web3_unknown"},
+	)
+	require.NoError(t, err)
+
+	f, err := os.Open(filePath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	entries := []accessLogEntry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry accessLogEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "web3_clientVersion", entries[0].Method)
+	assert.Equal(t, accessLogStatusSuccess, entries[0].Status)
+	assert.Equal(t, 0, entries[0].ErrorCode)
+	assert.Equal(t, "127.0.0.1", entries[0].IP)
+
+	assert.Equal(t, "web3_sha3", entries[1].Method)
+	assert.Equal(t, len(`["0x68656c6c6f20776f726c64"]`), entries[1].ParamsSize)
+	assert.Equal(t, accessLogStatusSuccess, entries[1].Status)
+
+	assert.Equal(t, "web3_unknown", entries[2].Method)
+	assert.Equal(t, accessLogStatusError, entries[2].Status)
+	assert.Equal(t, types.NotFoundErrorCode, entries[2].ErrorCode)
+
+	// the file sink is closed when the server stops
+	s.Stop()
+	assert.Nil(t, s.Server.handler.accessLog.file)
+}
+
+func TestNewAccessLoggerInvalidSink(t *testing.T) {
+	_, err := newAccessLogger(AccessLogConfig{Enabled: true, Sink: "kafka"}, newIPResolver(nil))
+	require.Error(t, err)
+}
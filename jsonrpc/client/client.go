@@ -28,6 +28,12 @@ func NewClient(url string) *Client {
 // the provided method and parameters, which is compatible with the Ethereum
 // JSON RPC Server.
 func JSONRPCCall(url, method string, parameters ...interface{}) (types.Response, error) {
+	return JSONRPCCallWithHeaders(url, nil, method, parameters...)
+}
+
+// JSONRPCCallWithHeaders executes a 2.0 JSON RPC HTTP Post Request like JSONRPCCall,
+// adding the provided headers to the HTTP request
+func JSONRPCCallWithHeaders(url string, headers http.Header, method string, parameters ...interface{}) (types.Response, error) {
 	params, err := json.Marshal(parameters)
 	if err != nil {
 		return types.Response{}, err
@@ -40,7 +46,7 @@ func JSONRPCCall(url, method string, parameters ...interface{}) (types.Response,
 		Params:  params,
 	}
 
-	httpRes, err := sendJSONRPC_HTTPRequest(url, request)
+	httpRes, err := sendJSONRPC_HTTPRequest(url, headers, request)
 	if err != nil {
 		return types.Response{}, err
 	}
@@ -91,7 +97,7 @@ func JSONRPCBatchCall(url string, calls ...BatchCall) ([]types.Response, error)
 		requests = append(requests, req)
 	}
 
-	httpRes, err := sendJSONRPC_HTTPRequest(url, requests)
+	httpRes, err := sendJSONRPC_HTTPRequest(url, nil, requests)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +122,7 @@ func JSONRPCBatchCall(url string, calls ...BatchCall) ([]types.Response, error)
 	return res, nil
 }
 
-func sendJSONRPC_HTTPRequest(url string, payload interface{}) (*http.Response, error) {
+func sendJSONRPC_HTTPRequest(url string, headers http.Header, payload interface{}) (*http.Response, error) {
 	reqBody, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
@@ -128,6 +134,11 @@ func sendJSONRPC_HTTPRequest(url string, payload interface{}) (*http.Response, e
 		return nil, err
 	}
 
+	for key, values := range headers {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
 	httpReq.Header.Add("Content-type", "application/json")
 
 	httpRes, err := http.DefaultClient.Do(httpReq)
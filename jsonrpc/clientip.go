@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+// ipResolver gets the IP of the client that sent a request. The X-Forwarded-For header is only
+// trusted when the request comes from one of the trusted proxies
+type ipResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+// newIPResolver creates an ipResolver trusting the proxies with the provided IPs or CIDR ranges
+func newIPResolver(trustedProxies []string) *ipResolver {
+	r := &ipResolver{}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Fatalf("invalid trusted proxy %s: %v", proxy, err)
+		}
+		r.trustedProxies = append(r.trustedProxies, ipRange)
+	}
+	return r
+}
+
+// clientIP returns the IP of the client that sent the request, an empty string if it's unknown. It's the IP the
+// request comes from unless it's a trusted proxy, then the X-Forwarded-For header is walked from the proxy closest
+// to the server and the first IP that is not a trusted proxy is returned
+func (r *ipResolver) clientIP(httpRequest *http.Request) string {
+	if httpRequest == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(httpRequest.RemoteAddr)
+	if err != nil {
+		host = httpRequest.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	hops := strings.Split(strings.Join(httpRequest.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(hops) - 1; i >= 0 && r.isTrusted(ip); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// the header was not set by a trusted proxy beyond this point
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
+
+func (r *ipResolver) isTrusted(ip net.IP) bool {
+	for _, ipRange := range r.trustedProxies {
+		if ipRange.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPResolverClientIP(t *testing.T) {
+	r := newIPResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expectedIP   string
+	}{
+		{"no proxy", "101.1.50.20:1234", nil, "101.1.50.20"},
+		{"untrusted proxy", "101.1.50.20:1234", []string{"1.2.3.4"}, "101.1.50.20"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"1.2.3.4"}, "1.2.3.4"},
+		{"trusted proxy without header", "192.168.1.1:1234", nil, "192.168.1.1"},
+		{"chain of trusted proxies", "10.0.0.1:1234", []string{"6.6.6.6, 1.2.3.4, 10.0.0.2", "192.168.1.1"}, "1.2.3.4"},
+		{"spoofed header through a trusted proxy", "10.0.0.1:1234", []string{"10.0.0.3, 1.2.3.4"}, "1.2.3.4"},
+		{"invalid header", "10.0.0.1:1234", []string{"unknown"}, "10.0.0.1"},
+		{"unknown remote address", "", []string{"1.2.3.4"}, ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			httpRequest := &http.Request{RemoteAddr: testCase.remoteAddr, Header: http.Header{}}
+			for _, value := range testCase.forwardedFor {
+				httpRequest.Header.Add(forwardedForHeader, value)
+			}
+			assert.Equal(t, testCase.expectedIP, r.clientIP(httpRequest))
+		})
+	}
+}
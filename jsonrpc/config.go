@@ -29,6 +29,19 @@ type Config struct {
 	// to relay transactions to the Sequencer node
 	SequencerNodeURI string `mapstructure:"SequencerNodeURI"`
 
+	// TrustedProxies are the IPs or CIDR ranges of the proxies, load balancers and Non-Sequencer nodes
+	// whose X-Forwarded-For header is trusted to get the IP of the client that sent a request. The header
+	// of the requests coming from other IPs is ignored and the IP they come from is used instead
+	TrustedProxies []string `mapstructure:"TrustedProxies"`
+
+	// AdminAPIKey must be sent in the "Authorization: Bearer <AdminAPIKey>" header of every request to the
+	// admin endpoints, the admin API can't be enabled without it
+	AdminAPIKey string `mapstructure:"AdminAPIKey"`
+
+	// SequencerRelay configures the upstreams used by Non-Sequencer nodes
+	// to relay requests to the Sequencer node
+	SequencerRelay RelayConfig `mapstructure:"SequencerRelay"`
+
 	// MaxCumulativeGasUsed is the max gas allowed per batch
 	MaxCumulativeGasUsed uint64
 
@@ -62,6 +75,27 @@ type Config struct {
 	// EnableHttpLog allows the user to enable or disable the logs related to the HTTP
 	// requests to be captured by the server.
 	EnableHttpLog bool `mapstructure:"EnableHttpLog"`
+
+	// AccessLog configures the structured logging of every jsonrpc request,
+	// including each item of the batch requests
+	AccessLog AccessLogConfig `mapstructure:"AccessLog"`
+}
+
+// AccessLogConfig has parameters to config the structured access log of the jsonrpc requests
+type AccessLogConfig struct {
+	// Enabled defines if each jsonrpc request must be recorded in the access log
+	Enabled bool `mapstructure:"Enabled"`
+
+	// Sink defines where the entries are written: "log" to use the node logger,
+	// "stdout" or "file" to write them as json lines
+	Sink string `mapstructure:"Sink"`
+
+	// FilePath is the file where the entries are appended when Sink is "file"
+	FilePath string `mapstructure:"FilePath"`
+
+	// APIKeyHeader is the HTTP header containing the API key of the client, when it is
+	// present the API key is recorded instead of the client IP
+	APIKeyHeader string `mapstructure:"APIKeyHeader"`
 }
 
 // WebSocketsConfig has parameters to config the rpc websocket support
@@ -78,3 +112,20 @@ type WebSocketsConfig struct {
 	// ReadLimit defines the maximum size of a message read from the client (in bytes)
 	ReadLimit int64 `mapstructure:"ReadLimit"`
 }
+
+// RelayConfig has parameters to config how Non-Sequencer nodes relay requests to the Sequencer node
+type RelayConfig struct {
+	// UpstreamURIs are additional Sequencer node URIs used when SequencerNodeURI is not available,
+	// they are tried in the configured order
+	UpstreamURIs []string `mapstructure:"UpstreamURIs"`
+
+	// HealthCheckInterval is the interval to check the health of the upstreams, 0 disables the checks
+	HealthCheckInterval types.Duration `mapstructure:"HealthCheckInterval"`
+
+	// FailureThreshold is the number of consecutive failures after which an upstream is skipped,
+	// 0 means the upstreams are never skipped
+	FailureThreshold uint64 `mapstructure:"FailureThreshold"`
+
+	// CircuitOpenDuration is the time an upstream is skipped after reaching FailureThreshold
+	CircuitOpenDuration types.Duration `mapstructure:"CircuitOpenDuration"`
+}
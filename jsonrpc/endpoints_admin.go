@@ -0,0 +1,98 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+)
+
+const apiKeyAuthorizationPrefix = "Bearer "
+
+// AdminEndpoints contains implementations for the "admin" RPC endpoints, they are
+// meant to be enabled only in the nodes reachable by the operators of the network.
+// All the requests must be authenticated with the "Authorization: Bearer <AdminAPIKey>" header
+type AdminEndpoints struct {
+	apiKey string
+	pool   types.PoolInterface
+}
+
+// NewAdminEndpoints returns AdminEndpoints
+func NewAdminEndpoints(apiKey string, p types.PoolInterface) *AdminEndpoints {
+	return &AdminEndpoints{apiKey: apiKey, pool: p}
+}
+
+// AuthorizeAPIKey checks the request carries the API key in the "Authorization: Bearer <apiKey>" header,
+// the requests are never authorized with an empty API key
+func AuthorizeAPIKey(httpRequest *http.Request, apiKey string) types.Error {
+	if httpRequest == nil {
+		return types.NewRPCError(types.AccessDeniedCode, "missing authorization")
+	}
+	authorization := httpRequest.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, apiKeyAuthorizationPrefix) {
+		return types.NewRPCError(types.AccessDeniedCode, "missing authorization")
+	}
+	key := strings.TrimPrefix(authorization, apiKeyAuthorizationPrefix)
+	if apiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+		return types.NewRPCError(types.AccessDeniedCode, "invalid API key")
+	}
+	return nil
+}
+
+// GetReputation returns the spam reputation of the provided source IP or sender address
+func (a *AdminEndpoints) GetReputation(httpRequest *http.Request, input string) (interface{}, types.Error) {
+	if err := AuthorizeAPIKey(httpRequest, a.apiKey); err != nil {
+		return nil, err
+	}
+	subject := pool.ReputationSubject(input)
+	if subject == "" {
+		return RPCErrorResponse(types.InvalidParamsErrorCode, "invalid ip or address", nil, false)
+	}
+
+	reputation, err := a.pool.GetReputation(context.Background(), subject)
+	if errors.Is(err, pool.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, "failed to get reputation from the pool", err, true)
+	}
+
+	return types.NewReputation(*reputation), nil
+}
+
+// ListReputations returns the spam reputation of all the source IPs and senders with
+// offenses, the banned ones first
+func (a *AdminEndpoints) ListReputations(httpRequest *http.Request) (interface{}, types.Error) {
+	if err := AuthorizeAPIKey(httpRequest, a.apiKey); err != nil {
+		return nil, err
+	}
+	reputations, err := a.pool.ListReputations(context.Background())
+	if err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, "failed to list reputations from the pool", err, true)
+	}
+
+	result := make([]types.Reputation, 0, len(reputations))
+	for _, reputation := range reputations {
+		result = append(result, types.NewReputation(reputation))
+	}
+	return result, nil
+}
+
+// ClearReputation forgets the offenses and lifts the ban of the provided source IP or sender address
+func (a *AdminEndpoints) ClearReputation(httpRequest *http.Request, input string) (interface{}, types.Error) {
+	if err := AuthorizeAPIKey(httpRequest, a.apiKey); err != nil {
+		return nil, err
+	}
+	subject := pool.ReputationSubject(input)
+	if subject == "" {
+		return RPCErrorResponse(types.InvalidParamsErrorCode, "invalid ip or address", nil, false)
+	}
+
+	if err := a.pool.DeleteReputation(context.Background(), subject); err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, "failed to clear reputation in the pool", err, true)
+	}
+	return true, nil
+}
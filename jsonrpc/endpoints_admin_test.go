@@ -0,0 +1,176 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/client"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReputation(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	bannedUntil := time.Now().Add(time.Hour).UTC()
+	reputation := &pool.Reputation{
+		Subject:     "0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D",
+		OOC:         2,
+		Underpriced: 1,
+		WindowStart: time.Now().UTC(),
+		Bans:        1,
+		BannedUntil: &bannedUntil,
+	}
+
+	type testCase struct {
+		Name           string
+		Input          string
+		ExpectedResult *types.Reputation
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	expectedResult := types.NewReputation(*reputation)
+	testCases := []testCase{
+		{
+			Name:           "Reputation found by lowercase address",
+			Input:          "0x617b3a3528f9cdd6630fd3301b9c8911f7bf063d",
+			ExpectedResult: &expectedResult,
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.On("GetReputation", context.Background(), reputation.Subject).Return(reputation, nil).Once()
+			},
+		},
+		{
+			Name:  "Reputation not found",
+			Input: "::ffff:10.0.0.1",
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.On("GetReputation", context.Background(), "10.0.0.1").Return(nil, pool.ErrNotFound).Once()
+			},
+		},
+		{
+			Name:          "Failed to get reputation",
+			Input:         "10.0.0.1",
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to get reputation from the pool"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.On("GetReputation", context.Background(), "10.0.0.1").Return(nil, errors.New("failed")).Once()
+			},
+		},
+		{
+			Name:          "Invalid subject",
+			Input:         "not an ip",
+			ExpectedError: types.NewRPCError(types.InvalidParamsErrorCode, "invalid ip or address"),
+			SetupMocks:    func(m *mocksWrapper) {},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.AdminJSONRPCCall("admin_getReputation", tc.Input)
+			require.NoError(t, err)
+
+			if tc.ExpectedResult != nil {
+				require.Nil(t, res.Error)
+				var result types.Reputation
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, result)
+				assert.True(t, result.Banned)
+			} else if tc.ExpectedError != nil {
+				require.NotNil(t, res.Error)
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			} else {
+				require.Nil(t, res.Error)
+				assert.Equal(t, "null", string(res.Result))
+			}
+		})
+	}
+}
+
+func TestListReputations(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	reputations := []pool.Reputation{
+		{Subject: "10.0.0.1", Invalid: 3, WindowStart: time.Now().UTC()},
+		{Subject: "10.0.0.2", Reverted: 1, WindowStart: time.Now().UTC()},
+	}
+	m.Pool.On("ListReputations", context.Background()).Return(reputations, nil).Once()
+
+	res, err := s.AdminJSONRPCCall("admin_listReputations")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result []types.Reputation
+	err = json.Unmarshal(res.Result, &result)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, types.NewReputation(reputations[0]), result[0])
+	assert.Equal(t, types.ArgUint64(3), result[0].Offenses)
+	assert.Equal(t, types.NewReputation(reputations[1]), result[1])
+}
+
+func TestClearReputation(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	m.Pool.On("DeleteReputation", context.Background(), "10.0.0.1").Return(nil).Once()
+
+	res, err := s.AdminJSONRPCCall("admin_clearReputation", "10.0.0.1")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, "true", string(res.Result))
+
+	m.Pool.On("DeleteReputation", context.Background(), "10.0.0.2").Return(errors.New("failed")).Once()
+
+	res, err = s.AdminJSONRPCCall("admin_clearReputation", "10.0.0.2")
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, types.DefaultErrorCode, res.Error.Code)
+	assert.Equal(t, "failed to clear reputation in the pool", res.Error.Message)
+}
+
+func TestAdminEndpointsAuthorization(t *testing.T) {
+	s, _, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	testCases := []struct {
+		name          string
+		authorization string
+		expectedError string
+	}{
+		{name: "missing authorization", expectedError: "missing authorization"},
+		{name: "wrong scheme", authorization: testAdminAPIKey, expectedError: "missing authorization"},
+		{name: "wrong key", authorization: "Bearer other", expectedError: "invalid API key"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := http.Header{}
+			if tc.authorization != "" {
+				headers.Set("Authorization", tc.authorization)
+			}
+			res, err := client.JSONRPCCallWithHeaders(s.ServerURL, headers, "admin_clearReputation", "10.0.0.1")
+			require.NoError(t, err)
+			require.NotNil(t, res.Error)
+			assert.Equal(t, types.AccessDeniedCode, res.Error.Code)
+			assert.Equal(t, tc.expectedError, res.Error.Message)
+		})
+	}
+}
+
+func TestAuthorizeAPIKeyWithoutKey(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer ")
+	assert.NotNil(t, AuthorizeAPIKey(req, ""))
+}
@@ -109,6 +109,9 @@ func (d *DebugEndpoints) TraceBlockByNumber(number types.BlockNumber, cfg *trace
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get block by number", err, true)
 		}
+		if _, rpcErr := getPrunedBlockTxHashes(ctx, d.state, block, true, dbTx); rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		traces, rpcErr := d.buildTraceBlock(ctx, block.Transactions(), cfg, dbTx)
 		if rpcErr != nil {
@@ -129,6 +132,9 @@ func (d *DebugEndpoints) TraceBlockByHash(hash types.ArgHash, cfg *traceConfig)
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get block by hash", err, true)
 		}
+		if _, rpcErr := getPrunedBlockTxHashes(ctx, d.state, block, true, dbTx); rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		traces, rpcErr := d.buildTraceBlock(ctx, block.Transactions(), cfg, dbTx)
 		if rpcErr != nil {
@@ -7,15 +7,14 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
-	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/client"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/ethereum/go-ethereum/common"
@@ -42,12 +41,18 @@ type EthEndpoints struct {
 	etherman types.EthermanInterface
 	storage  storageInterface
 	txMan    DBTxManager
+	relay    *SequencerRelay
+	ips      *ipResolver
 }
 
 // NewEthEndpoints creates an new instance of Eth
-func NewEthEndpoints(cfg Config, chainID uint64, p types.PoolInterface, s types.StateInterface, etherman types.EthermanInterface, storage storageInterface) *EthEndpoints {
-	e := &EthEndpoints{cfg: cfg, chainID: chainID, pool: p, state: s, etherman: etherman, storage: storage}
+func NewEthEndpoints(cfg Config, chainID uint64, p types.PoolInterface, s types.StateInterface, etherman types.EthermanInterface, storage storageInterface, relay *SequencerRelay) *EthEndpoints {
+	e := &EthEndpoints{cfg: cfg, chainID: chainID, pool: p, state: s, etherman: etherman, storage: storage, relay: relay, ips: newIPResolver(cfg.TrustedProxies)}
 	s.RegisterNewL2BlockEventHandler(e.onNewL2Block)
+	if cfg.SequencerNodeURI == "" {
+		// the preconfirmations are only notified by the trusted sequencer
+		s.RegisterPreconfirmationEventHandler(e.onPreconfirmation)
+	}
 
 	return e
 }
@@ -138,7 +143,7 @@ func (e *EthEndpoints) Coinbase() (interface{}, types.Error) { //nolint:revive
 }
 
 func (e *EthEndpoints) getCoinbaseFromSequencerNode() (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_coinbase")
+	res, err := e.relay.relay(true, "eth_coinbase")
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get coinbase from sequencer node", err, true)
 	}
@@ -215,7 +220,7 @@ func (e *EthEndpoints) GasPrice() (interface{}, types.Error) {
 }
 
 func (e *EthEndpoints) getPriceFromSequencerNode() (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_gasPrice")
+	res, err := e.relay.relay(true, "eth_gasPrice")
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get gas price from sequencer node", err, true)
 	}
@@ -287,6 +292,41 @@ func (e *EthEndpoints) getBlockByArg(ctx context.Context, blockArg *types.BlockN
 	return block, nil
 }
 
+// getPrunedBlockTxHashes returns the hashes of the transactions of the block if they have been pruned, so
+// a pruned block requested without its transactions is served with their hashes. It returns an error if the
+// full transactions of a pruned block were requested
+func getPrunedBlockTxHashes(ctx context.Context, st types.StateInterface, block *ethTypes.Block, fullTx bool, dbTx pgx.Tx) ([]common.Hash, types.Error) {
+	if len(block.Transactions()) > 0 {
+		return nil, nil
+	}
+	err := st.CheckL2BlockNotPruned(ctx, block.NumberU64(), dbTx)
+	if errors.Is(err, state.ErrPruned) {
+		if fullTx {
+			_, rpcErr := RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+			return nil, rpcErr
+		}
+		hashes, err := st.GetPrunedTransactionHashesByL2BlockNumber(ctx, block.NumberU64(), dbTx)
+		if err != nil {
+			_, rpcErr := RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("failed to get the pruned transactions of the block %v", block.NumberU64()), err, true)
+			return nil, rpcErr
+		}
+		return hashes, nil
+	} else if err != nil {
+		_, rpcErr := RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("failed to check if the block %v was pruned", block.NumberU64()), err, true)
+		return nil, rpcErr
+	}
+	return nil, nil
+}
+
+// txHashes returns the transaction hashes as transactions of a block or batch response
+func txHashes(hashes []common.Hash) []types.TransactionOrHash {
+	txs := make([]types.TransactionOrHash, 0, len(hashes))
+	for i := range hashes {
+		txs = append(txs, types.TransactionOrHash{Hash: &hashes[i]})
+	}
+	return txs
+}
+
 // GetBlockByHash returns information about a block by hash
 func (e *EthEndpoints) GetBlockByHash(hash types.ArgHash, fullTx bool) (interface{}, types.Error) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
@@ -296,6 +336,10 @@ func (e *EthEndpoints) GetBlockByHash(hash types.ArgHash, fullTx bool) (interfac
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get block by hash from state", err, true)
 		}
+		prunedTxHashes, rpcErr := getPrunedBlockTxHashes(ctx, e.state, block, fullTx, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		txs := block.Transactions()
 		receipts := make([]ethTypes.Receipt, 0, len(txs))
@@ -311,6 +355,7 @@ func (e *EthEndpoints) GetBlockByHash(hash types.ArgHash, fullTx bool) (interfac
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't build block response for block by hash %v", hash.Hash()), err, true)
 		}
+		rpcBlock.Transactions = append(rpcBlock.Transactions, txHashes(prunedTxHashes)...)
 
 		return rpcBlock, nil
 	})
@@ -349,6 +394,10 @@ func (e *EthEndpoints) GetBlockByNumber(number types.BlockNumber, fullTx bool) (
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't load block from state by number %v", blockNumber), err, true)
 		}
+		prunedTxHashes, rpcErr := getPrunedBlockTxHashes(ctx, e.state, block, fullTx, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		txs := block.Transactions()
 		receipts := make([]ethTypes.Receipt, 0, len(txs))
@@ -364,6 +413,7 @@ func (e *EthEndpoints) GetBlockByNumber(number types.BlockNumber, fullTx bool) (
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't build block response for block by number %v", blockNumber), err, true)
 		}
+		rpcBlock.Transactions = append(rpcBlock.Transactions, txHashes(prunedTxHashes)...)
 
 		return rpcBlock, nil
 	})
@@ -500,6 +550,8 @@ func (e *EthEndpoints) internalGetLogs(ctx context.Context, dbTx pgx.Tx, filter
 	} else if errors.Is(err, state.ErrMaxLogsBlockRangeLimitExceeded) {
 		errMsg := fmt.Sprintf(state.ErrMaxLogsBlockRangeLimitExceeded.Error(), e.cfg.MaxLogsBlockRange)
 		return RPCErrorResponse(types.InvalidParamsErrorCode, errMsg, nil, false)
+	} else if errors.Is(err, state.ErrPruned) {
+		return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
 	} else if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get logs from state", err, true)
 	}
@@ -544,6 +596,8 @@ func (e *EthEndpoints) GetTransactionByBlockHashAndIndex(hash types.ArgHash, ind
 		tx, err := e.state.GetTransactionByL2BlockHashAndIndex(ctx, hash.Hash(), uint64(index), dbTx)
 		if errors.Is(err, state.ErrNotFound) {
 			return nil, nil
+		} else if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get transaction", err, true)
 		}
@@ -577,6 +631,8 @@ func (e *EthEndpoints) GetTransactionByBlockNumberAndIndex(number *types.BlockNu
 		tx, err := e.state.GetTransactionByL2BlockNumberAndIndex(ctx, blockNumber, uint64(index), dbTx)
 		if errors.Is(err, state.ErrNotFound) {
 			return nil, nil
+		} else if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get transaction", err, true)
 		}
@@ -602,7 +658,9 @@ func (e *EthEndpoints) GetTransactionByHash(hash types.ArgHash) (interface{}, ty
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
 		// try to get tx from state
 		tx, err := e.state.GetTransactionByHash(ctx, hash.Hash(), dbTx)
-		if err != nil && !errors.Is(err, state.ErrNotFound) {
+		if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+		} else if err != nil && !errors.Is(err, state.ErrNotFound) {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to load transaction by hash from state", err, true)
 		}
 		if tx != nil {
@@ -644,7 +702,7 @@ func (e *EthEndpoints) GetTransactionByHash(hash types.ArgHash) (interface{}, ty
 }
 
 func (e *EthEndpoints) getTransactionByHashFromSequencerNode(hash common.Hash) (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_getTransactionByHash", hash.String())
+	res, err := e.relay.relay(true, "eth_getTransactionByHash", hash.String())
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get tx from sequencer node", err, true)
 	}
@@ -705,7 +763,7 @@ func (e *EthEndpoints) GetTransactionCount(address types.ArgAddress, blockArg *t
 }
 
 func (e *EthEndpoints) getTransactionCountFromSequencerNode(address common.Address, number *types.BlockNumber) (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_getTransactionCount", address.String(), number.StringOrHex())
+	res, err := e.relay.relay(true, "eth_getTransactionCount", address.String(), number.StringOrHex())
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get nonce from sequencer node", err, true)
 	}
@@ -727,7 +785,9 @@ func (e *EthEndpoints) getTransactionCountFromSequencerNode(address common.Addre
 func (e *EthEndpoints) GetBlockTransactionCountByHash(hash types.ArgHash) (interface{}, types.Error) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
 		c, err := e.state.GetL2BlockTransactionCountByHash(ctx, hash.Hash(), dbTx)
-		if err != nil {
+		if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to count transactions", err, true)
 		}
 
@@ -757,7 +817,9 @@ func (e *EthEndpoints) GetBlockTransactionCountByNumber(number *types.BlockNumbe
 		}
 
 		c, err := e.state.GetL2BlockTransactionCountByNumber(ctx, blockNumber, dbTx)
-		if err != nil {
+		if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to count transactions", err, true)
 		}
 
@@ -766,7 +828,7 @@ func (e *EthEndpoints) GetBlockTransactionCountByNumber(number *types.BlockNumbe
 }
 
 func (e *EthEndpoints) getBlockTransactionCountByNumberFromSequencerNode(number *types.BlockNumber) (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_getBlockTransactionCountByNumber", number.StringOrHex())
+	res, err := e.relay.relay(true, "eth_getBlockTransactionCountByNumber", number.StringOrHex())
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to get tx count by block number from sequencer node", err, true)
 	}
@@ -789,6 +851,8 @@ func (e *EthEndpoints) GetTransactionReceipt(hash types.ArgHash) (interface{}, t
 		tx, err := e.state.GetTransactionByHash(ctx, hash.Hash(), dbTx)
 		if errors.Is(err, state.ErrNotFound) {
 			return nil, nil
+		} else if errors.Is(err, state.ErrPruned) {
+			return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get tx from state", err, true)
 		}
@@ -872,33 +936,38 @@ func (e *EthEndpoints) newPendingTransactionFilter(wsConn *concurrentWsConn) (in
 	// return id, nil
 }
 
+// newPreconfirmationFilter creates a filter to notify the preconfirmations signed
+// by the trusted sequencer, they are only available in the trusted RPC
+func (e *EthEndpoints) newPreconfirmationFilter(wsConn *concurrentWsConn) (interface{}, types.Error) {
+	if e.cfg.SequencerNodeURI != "" {
+		return RPCErrorResponse(types.DefaultErrorCode, "preconfirmations are only available in the trusted sequencer RPC", nil, false)
+	}
+
+	id, err := e.storage.NewPreconfirmationFilter(wsConn)
+	if err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, "failed to create new preconfirmation filter", err, true)
+	}
+
+	return id, nil
+}
+
 // SendRawTransaction has two different ways to handle new transactions:
 // - for Sequencer nodes it tries to add the tx to the pool
 // - for Non-Sequencer nodes it relays the Tx to the Sequencer node
 func (e *EthEndpoints) SendRawTransaction(httpRequest *http.Request, input string) (interface{}, types.Error) {
+	ip := e.ips.clientIP(httpRequest)
 	if e.cfg.SequencerNodeURI != "" {
-		return e.relayTxToSequencerNode(input)
+		return e.relayTxToSequencerNode(input, ip)
 	} else {
-		if err := checkPolicy(context.Background(), e.pool, input); err != nil {
+		if err := checkPolicy(context.Background(), e.pool, input, ip); err != nil {
 			return RPCErrorResponse(types.AccessDeniedCode, err.Error(), nil, false)
 		}
-		ip := ""
-		ips := httpRequest.Header.Get("X-Forwarded-For")
-
-		// TODO: this is temporary patch remove this log
-		realIp := httpRequest.Header.Get("X-Real-IP")
-		log.Infof("X-Forwarded-For: %s, X-Real-IP: %s", ips, realIp)
-
-		if ips != "" {
-			ip = strings.Split(ips, ",")[0]
-		}
-
 		return e.tryToAddTxToPool(input, ip)
 	}
 }
 
-func (e *EthEndpoints) relayTxToSequencerNode(input string) (interface{}, types.Error) {
-	res, err := client.JSONRPCCall(e.cfg.SequencerNodeURI, "eth_sendRawTransaction", input)
+func (e *EthEndpoints) relayTxToSequencerNode(input string, ip string) (interface{}, types.Error) {
+	res, err := e.relay.relayFrom(ip, "eth_sendRawTransaction", input)
 	if err != nil {
 		return RPCErrorResponse(types.DefaultErrorCode, "failed to relay tx to the sequencer node", err, true)
 	}
@@ -956,18 +1025,30 @@ func (e *EthEndpoints) Syncing() (interface{}, types.Error) {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get syncing info from state", err, true)
 		}
 
-		if syncInfo.CurrentBlockNumber >= syncInfo.LastBlockNumberSeen {
+		synced := syncInfo.CurrentBlockNumber >= syncInfo.LastBlockNumberSeen
+		pruned := syncInfo.LastPrunedBlockNumber > 0 || syncInfo.LastPrunedBatchNumber > 0
+		if synced && !pruned {
 			return false, nil
 		}
 
+		// a synced node whose state was pruned reports the pruned blocks and batches, with
+		// the current block being the highest one
+		highestBlock := syncInfo.LastBlockNumberSeen
+		if synced {
+			highestBlock = syncInfo.CurrentBlockNumber
+		}
 		return struct {
-			S types.ArgUint64 `json:"startingBlock"`
-			C types.ArgUint64 `json:"currentBlock"`
-			H types.ArgUint64 `json:"highestBlock"`
+			S  types.ArgUint64 `json:"startingBlock"`
+			C  types.ArgUint64 `json:"currentBlock"`
+			H  types.ArgUint64 `json:"highestBlock"`
+			PB types.ArgUint64 `json:"prunedBlock,omitempty"`
+			PT types.ArgUint64 `json:"prunedBatch,omitempty"`
 		}{
-			S: types.ArgUint64(syncInfo.InitialSyncingBlock),
-			C: types.ArgUint64(syncInfo.CurrentBlockNumber),
-			H: types.ArgUint64(syncInfo.LastBlockNumberSeen),
+			S:  types.ArgUint64(syncInfo.InitialSyncingBlock),
+			C:  types.ArgUint64(syncInfo.CurrentBlockNumber),
+			H:  types.ArgUint64(highestBlock),
+			PB: types.ArgUint64(syncInfo.LastPrunedBlockNumber),
+			PT: types.ArgUint64(syncInfo.LastPrunedBatchNumber),
 		}, nil
 	})
 }
@@ -1042,6 +1123,8 @@ func (e *EthEndpoints) Subscribe(wsConn *concurrentWsConn, name string, logFilte
 		})
 	case "pendingTransactions", "newPendingTransactions":
 		return e.newPendingTransactionFilter(wsConn)
+	case "preconfirmations":
+		return e.newPreconfirmationFilter(wsConn)
 	case "syncing":
 		return nil, types.NewRPCError(types.DefaultErrorCode, "not supported yet")
 	default:
@@ -1144,6 +1227,28 @@ func (e *EthEndpoints) notifyNewLogs(wg *sync.WaitGroup, event state.NewL2BlockE
 	log.Infof("[notifyNewLogs] new l2 block event for block %v took %v to send all the messages for log filters", event.Block.NumberU64(), time.Since(start))
 }
 
+// onPreconfirmation is triggered when the trusted sequencer signs a preconfirmation, before
+// the tx is stored, so the subscribers get it before the receipt is available
+func (e *EthEndpoints) onPreconfirmation(preconfirmation *preconf.Preconfirmation) {
+	filters := e.storage.GetAllPreconfirmationFiltersWithWSConn()
+	if len(filters) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(preconfirmation)
+	if err != nil {
+		log.Errorf("failed to marshal preconfirmation response to subscription: %v", err)
+		return
+	}
+
+	const maxWorkers = 32
+	parallelize(maxWorkers, filters, func(worker int, filters []*Filter) {
+		for _, filter := range filters {
+			filter.EnqueueSubscriptionDataToBeSent(data)
+		}
+	})
+}
+
 // shouldSkipLogFilter checks if the log filter can be skipped while notifying new logs.
 // it checks the log filter information against the block in the event to decide if the
 // information in the event is required by the filter or can be ignored to save resources.
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"strings"
 	"sync"
 	"testing"
@@ -1137,6 +1138,33 @@ func TestGetL2BlockByNumber(t *testing.T) {
 					Return(nil, state.ErrNotFound)
 			},
 		},
+		{
+			Name:           "Block pruned",
+			Number:         big.NewInt(5),
+			ExpectedResult: nil,
+			ExpectedError:  types.NewRPCError(types.DefaultErrorCode, "data pruned, the oldest l2 block with transactions, receipts and logs is 11"),
+			SetupMocks: func(m *mocksWrapper, tc *testCase) {
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetL2BlockByNumber", context.Background(), tc.Number.Uint64(), m.DbTx).
+					Return(ethTypes.NewBlockWithHeader(&ethTypes.Header{Number: tc.Number}), nil).
+					Once()
+
+				m.State.
+					On("CheckL2BlockNotPruned", context.Background(), tc.Number.Uint64(), m.DbTx).
+					Return(fmt.Errorf("%w, the oldest l2 block with transactions, receipts and logs is %d", state.ErrPruned, 11)).
+					Once()
+			},
+		},
 		{
 			Name:   "get specific block successfully",
 			Number: big.NewInt(345),
@@ -1354,6 +1382,51 @@ func TestGetL2BlockByNumber(t *testing.T) {
 	}
 }
 
+func TestGetL2BlockByNumberPrunedTxHashes(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	header := &ethTypes.Header{Number: big.NewInt(5), UncleHash: ethTypes.EmptyUncleHash, Root: common.HexToHash("0x123")}
+	m.DbTx.
+		On("Commit", context.Background()).
+		Return(nil).
+		Once()
+
+	m.State.
+		On("BeginStateTransaction", context.Background()).
+		Return(m.DbTx, nil).
+		Once()
+
+	m.State.
+		On("GetL2BlockByNumber", context.Background(), uint64(5), m.DbTx).
+		Return(ethTypes.NewBlockWithHeader(header), nil).
+		Once()
+
+	m.State.
+		On("CheckL2BlockNotPruned", context.Background(), uint64(5), m.DbTx).
+		Return(fmt.Errorf("%w, the oldest l2 block with transactions, receipts and logs is %d", state.ErrPruned, 6)).
+		Once()
+
+	txHash := common.HexToHash("0x456")
+	m.State.
+		On("GetPrunedTransactionHashesByL2BlockNumber", context.Background(), uint64(5), m.DbTx).
+		Return([]common.Hash{txHash}, nil).
+		Once()
+
+	// the header of a pruned block is served with the hashes of its transactions when they are not requested
+	res, err := s.JSONRPCCall("eth_getBlockByNumber", "0x5", false)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result types.Block
+	require.NoError(t, json.Unmarshal(res.Result, &result))
+	assert.Equal(t, uint64(5), uint64(result.Number))
+	assert.Equal(t, header.Root, result.StateRoot)
+	require.Len(t, result.Transactions, 1)
+	require.NotNil(t, result.Transactions[0].Hash)
+	assert.Equal(t, txHash, *result.Transactions[0].Hash)
+}
+
 func TestGetUncleByBlockHashAndIndex(t *testing.T) {
 	s, _, _ := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -1999,6 +2072,63 @@ func TestSyncing(t *testing.T) {
 	}
 }
 
+func TestSyncingReportsPrunedData(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	testCases := []struct {
+		name           string
+		syncInfo       state.SyncingInfo
+		expectedResult string
+	}{
+		{
+			name: "syncing",
+			syncInfo: state.SyncingInfo{
+				InitialSyncingBlock: 1, CurrentBlockNumber: 20, LastBlockNumberSeen: 30, LastBlockNumberConsolidated: 30,
+				LastPrunedBlockNumber: 10, LastPrunedBatchNumber: 4,
+			},
+			expectedResult: `{"startingBlock":"0x1","currentBlock":"0x14","highestBlock":"0x1e","prunedBlock":"0xa","prunedBatch":"0x4"}`,
+		},
+		{
+			name: "synced",
+			syncInfo: state.SyncingInfo{
+				InitialSyncingBlock: 1, CurrentBlockNumber: 30, LastBlockNumberSeen: 30, LastBlockNumberConsolidated: 30,
+				LastPrunedBlockNumber: 10, LastPrunedBatchNumber: 4,
+			},
+			expectedResult: `{"startingBlock":"0x1","currentBlock":"0x1e","highestBlock":"0x1e","prunedBlock":"0xa","prunedBatch":"0x4"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m.DbTx.
+				On("Commit", context.Background()).
+				Return(nil).
+				Once()
+
+			m.State.
+				On("BeginStateTransaction", context.Background()).
+				Return(m.DbTx, nil).
+				Once()
+
+			m.State.
+				On("GetLastL2BlockNumber", context.Background(), m.DbTx).
+				Return(uint64(10), nil).
+				Once()
+
+			m.State.
+				On("GetSyncingInfo", context.Background(), m.DbTx).
+				Return(tc.syncInfo, nil).
+				Once()
+
+			res, err := s.JSONRPCCall("eth_syncing")
+			require.NoError(t, err)
+			require.Nil(t, res.Error)
+			assert.JSONEq(t, tc.expectedResult, string(res.Result))
+		})
+	}
+}
+
 func TestGetTransactionL2onByBlockHashAndIndex(t *testing.T) {
 	s, m, c := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -3328,7 +3458,7 @@ func TestSendRawTransactionViaGeth(t *testing.T) {
 				})
 
 				m.Pool.
-					On("AddTx", context.Background(), txMatchByHash, "").
+					On("AddTx", context.Background(), txMatchByHash, "127.0.0.1").
 					Return(nil).
 					Once()
 			},
@@ -3345,7 +3475,7 @@ func TestSendRawTransactionViaGeth(t *testing.T) {
 				})
 
 				m.Pool.
-					On("AddTx", context.Background(), txMatchByHash, "").
+					On("AddTx", context.Background(), txMatchByHash, "127.0.0.1").
 					Return(errors.New("failed to add TX to the pool")).
 					Once()
 			},
@@ -3403,7 +3533,7 @@ func TestSendRawTransactionJSONRPCCall(t *testing.T) {
 			},
 			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
 				m.Pool.
-					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "").
+					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "127.0.0.1").
 					Return(nil).
 					Once()
 			},
@@ -3425,7 +3555,7 @@ func TestSendRawTransactionJSONRPCCall(t *testing.T) {
 			},
 			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
 				m.Pool.
-					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "").
+					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "127.0.0.1").
 					Return(errors.New("failed to add TX to the pool")).
 					Once()
 			},
@@ -3493,7 +3623,7 @@ func TestSendRawTransactionViaGethForNonSequencerNode(t *testing.T) {
 				})
 
 				m.Pool.
-					On("AddTx", context.Background(), txMatchByHash, "").
+					On("AddTx", context.Background(), txMatchByHash, "127.0.0.1").
 					Return(nil).
 					Once()
 			},
@@ -3510,7 +3640,7 @@ func TestSendRawTransactionViaGethForNonSequencerNode(t *testing.T) {
 				})
 
 				m.Pool.
-					On("AddTx", context.Background(), txMatchByHash, "").
+					On("AddTx", context.Background(), txMatchByHash, "127.0.0.1").
 					Return(errors.New("failed to add TX to the pool")).
 					Once()
 			},
@@ -4134,6 +4264,35 @@ func TestGetLogs(t *testing.T) {
 					Once()
 			},
 		},
+		{
+			Name: "Get logs fails due to pruned blocks",
+			Prepare: func(t *testing.T, tc *testCase) {
+				tc.Filter = ethereum.FilterQuery{
+					FromBlock: big.NewInt(1), ToBlock: big.NewInt(2),
+					Addresses: []common.Address{common.HexToAddress("0x111")},
+					Topics:    [][]common.Hash{{common.HexToHash("0x222")}},
+				}
+				tc.ExpectedResult = nil
+				tc.ExpectedError = types.NewRPCError(types.DefaultErrorCode, "data pruned, the oldest l2 block with transactions, receipts and logs is 11")
+			},
+			SetupMocks: func(m *mocksWrapper, tc testCase) {
+				var since *time.Time
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLogs", context.Background(), tc.Filter.FromBlock.Uint64(), tc.Filter.ToBlock.Uint64(), tc.Filter.Addresses, tc.Filter.Topics, tc.Filter.BlockHash, since, m.DbTx).
+					Return(nil, fmt.Errorf("%w, the oldest l2 block with transactions, receipts and logs is %d", state.ErrPruned, 11)).
+					Once()
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -5202,6 +5361,9 @@ func TestSendRawTransactionJSONRPCCallWithPolicyApplied(t *testing.T) {
 	senderDenied := types.NewRPCError(types.AccessDeniedCode, "sender disallowed send_tx by policy")
 	contractDenied := types.NewRPCError(types.AccessDeniedCode, "contract disallowed send_tx by policy")
 	deployDenied := types.NewRPCError(types.AccessDeniedCode, "sender disallowed deploy by policy")
+	callDenied := types.NewRPCError(types.AccessDeniedCode, "function selector disallowed call by policy")
+	valueDenied := types.NewRPCError(types.AccessDeniedCode, "sender disallowed value over max value by policy")
+	selector := pool.Selector{0xa9, 0x05, 0x9c, 0xbb}
 
 	cfg := getSequencerDefaultConfig()
 	s, m, _ := newMockedServerWithCustomConfig(t, cfg)
@@ -5237,7 +5399,7 @@ func TestSendRawTransactionJSONRPCCallWithPolicyApplied(t *testing.T) {
 			},
 			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
 				m.Pool.
-					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "").
+					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "127.0.0.1").
 					Return(nil).
 					Once()
 				m.Pool.
@@ -5248,6 +5410,88 @@ func TestSendRawTransactionJSONRPCCallWithPolicyApplied(t *testing.T) {
 					On("CheckPolicy", context.Background(), pool.SendTx, allowed.From).
 					Return(true, nil).
 					Once()
+				m.Pool.
+					On("CheckCallPolicy", context.Background(), allowedContract, mock.Anything).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("GetMaxTxValue", context.Background()).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			Name: "Function selector not on call allow list, rejected",
+			Prepare: func(t *testing.T, tc *testCase) {
+				tx := ethTypes.NewTransaction(1, allowedContract, big.NewInt(0), uint64(1), big.NewInt(1), selector[:])
+
+				signedTx, err := allowed.Signer(allowed.From, tx)
+				require.NoError(t, err)
+
+				txBinary, err := signedTx.MarshalBinary()
+				require.NoError(t, err)
+
+				rawTx := hex.EncodeToHex(txBinary)
+				require.NoError(t, err)
+
+				tc.Input = rawTx
+				tc.ExpectedResult = nil
+				tc.ExpectedError = callDenied
+			},
+			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
+				m.Pool.
+					On("CheckPolicy", context.Background(), pool.SendTx, allowedContract).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("CheckPolicy", context.Background(), pool.SendTx, allowed.From).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("CheckCallPolicy", context.Background(), allowedContract, selector[:]).
+					Return(false, nil).
+					Once()
+			},
+		},
+		{
+			Name: "Value over max value of sender not on value allow list, rejected",
+			Prepare: func(t *testing.T, tc *testCase) {
+				tx := ethTypes.NewTransaction(1, allowedContract, big.NewInt(11), uint64(1), big.NewInt(1), []byte{})
+
+				signedTx, err := disallowed.Signer(disallowed.From, tx)
+				require.NoError(t, err)
+
+				txBinary, err := signedTx.MarshalBinary()
+				require.NoError(t, err)
+
+				rawTx := hex.EncodeToHex(txBinary)
+				require.NoError(t, err)
+
+				tc.Input = rawTx
+				tc.ExpectedResult = nil
+				tc.ExpectedError = valueDenied
+			},
+			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
+				m.Pool.
+					On("CheckPolicy", context.Background(), pool.SendTx, allowedContract).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("CheckPolicy", context.Background(), pool.SendTx, disallowed.From).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("CheckCallPolicy", context.Background(), allowedContract, mock.Anything).
+					Return(true, nil).
+					Once()
+				m.Pool.
+					On("GetMaxTxValue", context.Background()).
+					Return(big.NewInt(10), nil).
+					Once()
+				m.Pool.
+					On("CheckPolicy", context.Background(), pool.Value, disallowed.From).
+					Return(false, nil).
+					Once()
 			},
 		},
 		{
@@ -5321,7 +5565,7 @@ func TestSendRawTransactionJSONRPCCallWithPolicyApplied(t *testing.T) {
 			},
 			SetupMocks: func(t *testing.T, m *mocksWrapper, tc testCase) {
 				m.Pool.
-					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "").
+					On("AddTx", context.Background(), mock.IsType(ethTypes.Transaction{}), "127.0.0.1").
 					Return(nil).
 					Once()
 				// policy does not reject this case for backward compat
@@ -5389,6 +5633,12 @@ func TestSendRawTransactionJSONRPCCallWithPolicyApplied(t *testing.T) {
 		},
 	}
 
+	// the txs are sent from the local IP, which is allowed by the ip policy
+	m.Pool.
+		On("CheckIPPolicy", context.Background(), mock.MatchedBy(func(ip net.IP) bool { return ip.Equal(net.IPv4(127, 0, 0, 1)) })).
+		Return(true, nil).
+		Maybe()
+
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
 			tc := testCase
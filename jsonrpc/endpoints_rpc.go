@@ -0,0 +1,41 @@
+package jsonrpc
+
+import (
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+)
+
+const (
+	// rpcModuleVersion is the version reported by rpc_modules for every namespace
+	rpcModuleVersion = "1.0"
+
+	rpcDiscoverMethod   = "rpc.discover"
+	rpcDiscoverFuncName = "discover"
+)
+
+// RPCEndpoints contains implementations for the "rpc" RPC endpoints,
+// it describes the services registered in the handler
+type RPCEndpoints struct {
+	handler *Handler
+}
+
+// NewRPCEndpoints returns RPCEndpoints
+func NewRPCEndpoints(handler *Handler) *RPCEndpoints {
+	return &RPCEndpoints{
+		handler: handler,
+	}
+}
+
+// Modules returns the namespaces exposed by the server and their versions
+func (r *RPCEndpoints) Modules() (interface{}, types.Error) {
+	modules := make(map[string]string, len(r.handler.serviceMap))
+	for name := range r.handler.serviceMap {
+		modules[name] = rpcModuleVersion
+	}
+	return modules, nil
+}
+
+// Discover returns the OpenRPC document describing the exposed methods,
+// it is exposed as rpc.discover
+func (r *RPCEndpoints) Discover() (interface{}, types.Error) {
+	return r.handler.openRPCDocument(), nil
+}
@@ -0,0 +1,116 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModules(t *testing.T) {
+	s, _, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	res, err := s.JSONRPCCall("rpc_modules")
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), res.ID)
+	assert.Equal(t, "2.0", res.JSONRPC)
+	assert.Nil(t, res.Error)
+
+	var result map[string]string
+	err = json.Unmarshal(res.Result, &result)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		APIEth:    rpcModuleVersion,
+		APINet:    rpcModuleVersion,
+		APIDebug:  rpcModuleVersion,
+		APIZKEVM:  rpcModuleVersion,
+		APITxPool: rpcModuleVersion,
+		APIWeb3:   rpcModuleVersion,
+		APIAdmin:  rpcModuleVersion,
+		APIRPC:    rpcModuleVersion,
+	}
+	assert.Equal(t, expected, result)
+}
+
+func TestDiscover(t *testing.T) {
+	s, _, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	res, err := s.JSONRPCCall(rpcDiscoverMethod)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), res.ID)
+	assert.Equal(t, "2.0", res.JSONRPC)
+	require.Nil(t, res.Error)
+
+	var doc OpenRPCDocument
+	err = json.Unmarshal(res.Result, &doc)
+	require.NoError(t, err)
+
+	// every method registered in the handler must be described by the document and vice versa
+	expected := []string{}
+	for serviceName, service := range s.Server.handler.serviceMap {
+		for funcName := range service.funcMap {
+			expected = append(expected, methodName(serviceName, funcName))
+		}
+	}
+	sort.Strings(expected)
+
+	actual := []string{}
+	for _, method := range doc.Methods {
+		actual = append(actual, method.Name)
+	}
+	sort.Strings(actual)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestDiscoverOnlyEnabledAPIs(t *testing.T) {
+	h := newJSONRpcHandler()
+	h.registerService(Service{Name: APIWeb3, Service: &Web3Endpoints{}})
+
+	doc := h.openRPCDocument()
+	for _, method := range doc.Methods {
+		assert.True(t, strings.HasPrefix(method.Name, APIWeb3+"_") || strings.HasPrefix(method.Name, APIRPC), method.Name)
+	}
+}
+
+// TestZKEVMOpenRPCFileMatchesRegistry fails when the endpoints described in
+// endpoints_zkevm.openrpc.json drift from the ones implemented in ZKEVMEndpoints
+func TestZKEVMOpenRPCFileMatchesRegistry(t *testing.T) {
+	type staticMethod struct {
+		Name   string            `json:"name"`
+		Params []json.RawMessage `json:"params"`
+	}
+	var staticDoc struct {
+		Methods []staticMethod `json:"methods"`
+	}
+	b, err := os.ReadFile("endpoints_zkevm.openrpc.json")
+	require.NoError(t, err)
+	err = json.Unmarshal(b, &staticDoc)
+	require.NoError(t, err)
+
+	h := newJSONRpcHandler()
+	h.registerService(Service{Name: APIZKEVM, Service: &ZKEVMEndpoints{}})
+
+	generated := map[string]int{}
+	for _, method := range h.openRPCDocument().Methods {
+		if strings.HasPrefix(method.Name, APIZKEVM+"_") {
+			generated[method.Name] = len(method.Params)
+		}
+	}
+
+	static := map[string]int{}
+	for _, method := range staticDoc.Methods {
+		static[method.Name] = len(method.Params)
+	}
+
+	assert.Equal(t, generated, static)
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/jackc/pgx/v4"
 )
@@ -17,18 +19,26 @@ import (
 // ZKEVMEndpoints contains implementations for the "zkevm" RPC endpoints
 type ZKEVMEndpoints struct {
 	cfg      Config
+	pool     types.PoolInterface
 	state    types.StateInterface
 	etherman types.EthermanInterface
 	txMan    DBTxManager
+	relay    *SequencerRelay
+	ips      *ipResolver
 }
 
 // NewZKEVMEndpoints returns ZKEVMEndpoints
-func NewZKEVMEndpoints(cfg Config, state types.StateInterface, etherman types.EthermanInterface) *ZKEVMEndpoints {
-	return &ZKEVMEndpoints{
+func NewZKEVMEndpoints(cfg Config, pool types.PoolInterface, state types.StateInterface, etherman types.EthermanInterface, relay *SequencerRelay) *ZKEVMEndpoints {
+	z := &ZKEVMEndpoints{
 		cfg:      cfg,
+		pool:     pool,
 		state:    state,
 		etherman: etherman,
+		relay:    relay,
+		ips:      newIPResolver(cfg.TrustedProxies),
 	}
+
+	return z
 }
 
 // ConsolidatedBlockNumber returns last block number related to the last verified batch
@@ -175,12 +185,21 @@ func (z *ZKEVMEndpoints) GetBatchByNumber(batchNumber types.BatchNumber, fullTx
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't load blocks associated to the batch %v", batchNumber), err, true)
 		}
+		var prunedTxHashes []common.Hash
+		for i := range blocks {
+			hashes, rpcErr := getPrunedBlockTxHashes(ctx, z.state, &blocks[i], fullTx, dbTx)
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			prunedTxHashes = append(prunedTxHashes, hashes...)
+		}
 
 		batch.Transactions = txs
 		rpcBatch, err := types.NewBatch(batch, virtualBatch, verifiedBatch, blocks, receipts, fullTx, true, ger)
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't build the batch %v response", batchNumber), err, true)
 		}
+		rpcBatch.Transactions = append(rpcBatch.Transactions, txHashes(prunedTxHashes)...)
 		return rpcBatch, nil
 	})
 }
@@ -218,6 +237,10 @@ func (z *ZKEVMEndpoints) GetFullBlockByNumber(number types.BlockNumber, fullTx b
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't load block from state by number %v", blockNumber), err, true)
 		}
+		prunedTxHashes, rpcErr := getPrunedBlockTxHashes(ctx, z.state, block, fullTx, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		txs := block.Transactions()
 		receipts := make([]ethTypes.Receipt, 0, len(txs))
@@ -233,6 +256,7 @@ func (z *ZKEVMEndpoints) GetFullBlockByNumber(number types.BlockNumber, fullTx b
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't build block response for block by number %v", blockNumber), err, true)
 		}
+		rpcBlock.Transactions = append(rpcBlock.Transactions, txHashes(prunedTxHashes)...)
 
 		return rpcBlock, nil
 	})
@@ -247,6 +271,10 @@ func (z *ZKEVMEndpoints) GetFullBlockByHash(hash types.ArgHash, fullTx bool) (in
 		} else if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, "failed to get block by hash from state", err, true)
 		}
+		prunedTxHashes, rpcErr := getPrunedBlockTxHashes(ctx, z.state, block, fullTx, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
 
 		txs := block.Transactions()
 		receipts := make([]ethTypes.Receipt, 0, len(txs))
@@ -262,6 +290,7 @@ func (z *ZKEVMEndpoints) GetFullBlockByHash(hash types.ArgHash, fullTx bool) (in
 		if err != nil {
 			return RPCErrorResponse(types.DefaultErrorCode, fmt.Sprintf("couldn't build block response for block by hash %v", hash.Hash()), err, true)
 		}
+		rpcBlock.Transactions = append(rpcBlock.Transactions, txHashes(prunedTxHashes)...)
 
 		return rpcBlock, nil
 	})
@@ -288,3 +317,133 @@ func (z *ZKEVMEndpoints) GetNativeBlockHashesInRange(filter NativeBlockHashBlock
 		return nativeBlockHashes, nil
 	})
 }
+
+// GetPreconfirmation returns the inclusion preconfirmation signed by the trusted
+// sequencer for the provided tx hash
+func (z *ZKEVMEndpoints) GetPreconfirmation(hash types.ArgHash) (interface{}, types.Error) {
+	if z.cfg.SequencerNodeURI != "" {
+		res, err := z.relay.relay(true, "zkevm_getPreconfirmation", hash.Hash().String())
+		if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to relay request to the sequencer node", err, true)
+		}
+		if res.Error != nil {
+			return RPCErrorResponse(res.Error.Code, res.Error.Message, nil, false)
+		}
+		return res.Result, nil
+	}
+
+	return z.txMan.NewDbTxScope(z.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
+		preconfirmation, err := z.state.GetPreconfirmation(ctx, hash.Hash(), dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to get preconfirmation from state", err, true)
+		}
+
+		return preconfirmation, nil
+	})
+}
+
+// GetPruneInfo returns the last l2 block and batch whose transactions, receipts and logs have
+// been pruned, or null if the state was never pruned
+func (z *ZKEVMEndpoints) GetPruneInfo() (interface{}, types.Error) {
+	return z.txMan.NewDbTxScope(z.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
+		pruneInfo, err := z.state.GetPruneInfo(ctx, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to get prune info from state", err, true)
+		}
+
+		return types.NewPruneInfo(pruneInfo), nil
+	})
+}
+
+// EstimateEffectiveGasPrice pre-executes the provided signed tx and returns the effective gas price
+// that would be applied to it with the current gas prices and the values used to calculate it
+func (z *ZKEVMEndpoints) EstimateEffectiveGasPrice(input string) (interface{}, types.Error) {
+	if z.cfg.SequencerNodeURI != "" {
+		res, err := z.relay.relay(true, "zkevm_estimateEffectiveGasPrice", input)
+		if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to relay request to the sequencer node", err, true)
+		}
+		if res.Error != nil {
+			return RPCErrorResponse(res.Error.Code, res.Error.Message, nil, false)
+		}
+		return res.Result, nil
+	}
+
+	tx, err := hexToTx(input)
+	if err != nil {
+		return RPCErrorResponse(types.InvalidParamsErrorCode, "invalid tx input", err, false)
+	}
+
+	estimation, err := z.pool.EstimateEffectiveGasPrice(context.Background(), *tx)
+	if err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+	}
+
+	return types.NewEffectiveGasPriceEstimation(estimation), nil
+}
+
+// GetEffectiveGasPriceLog returns the effective gas price log stored by the trusted
+// sequencer when the tx with the provided hash was mined
+func (z *ZKEVMEndpoints) GetEffectiveGasPriceLog(hash types.ArgHash) (interface{}, types.Error) {
+	if z.cfg.SequencerNodeURI != "" {
+		res, err := z.relay.relay(true, "zkevm_getEffectiveGasPriceLog", hash.Hash().String())
+		if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to relay request to the sequencer node", err, true)
+		}
+		if res.Error != nil {
+			return RPCErrorResponse(res.Error.Code, res.Error.Message, nil, false)
+		}
+		return res.Result, nil
+	}
+
+	return z.txMan.NewDbTxScope(z.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, types.Error) {
+		egpLog, err := z.state.GetTransactionEGPLogByHash(ctx, hash.Hash(), dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to get effective gas price log from state", err, true)
+		}
+
+		return types.NewEffectiveGasPriceLog(*egpLog), nil
+	})
+}
+
+// SendBundle adds a list of signed txs to the pool as an atomic bundle, the txs
+// are sequenced together and in the provided order in the same batch or not at all
+func (z *ZKEVMEndpoints) SendBundle(httpRequest *http.Request, inputs []string) (interface{}, types.Error) {
+	ip := z.ips.clientIP(httpRequest)
+	if z.cfg.SequencerNodeURI != "" {
+		res, err := z.relay.relayFrom(ip, "zkevm_sendBundle", inputs)
+		if err != nil {
+			return RPCErrorResponse(types.DefaultErrorCode, "failed to relay bundle to the sequencer node", err, true)
+		}
+		if res.Error != nil {
+			return RPCErrorResponse(res.Error.Code, res.Error.Message, nil, false)
+		}
+		return res.Result, nil
+	}
+
+	txs := make([]ethTypes.Transaction, 0, len(inputs))
+	for _, input := range inputs {
+		if err := checkPolicy(context.Background(), z.pool, input, ip); err != nil {
+			return RPCErrorResponse(types.AccessDeniedCode, err.Error(), nil, false)
+		}
+		tx, err := hexToTx(input)
+		if err != nil {
+			return RPCErrorResponse(types.InvalidParamsErrorCode, "invalid tx input", err, false)
+		}
+		txs = append(txs, *tx)
+	}
+
+	bundleHash, err := z.pool.AddBundle(context.Background(), txs, ip)
+	if err != nil {
+		return RPCErrorResponse(types.DefaultErrorCode, err.Error(), nil, false)
+	}
+	log.Infof("bundle %s added to the pool with %d txs", bundleHash.Hex(), len(txs))
+
+	return bundleHash.Hex(), nil
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -18,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1728,3 +1731,439 @@ func signTx(tx *ethTypes.Transaction, chainID uint64) *ethTypes.Transaction {
 	signedTx, _ := auth.Signer(auth.From, tx)
 	return signedTx
 }
+
+func TestGetPreconfirmation(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	txHash := common.HexToHash("0x1")
+	preconfirmation := &preconf.Preconfirmation{
+		TxHash:        txHash,
+		BatchNumber:   2,
+		L2BlockNumber: 3,
+		Position:      4,
+		StateRoot:     common.HexToHash("0x5"),
+	}
+	require.NoError(t, preconfirmation.Sign(privateKey))
+
+	type testCase struct {
+		Name           string
+		ExpectedResult *preconf.Preconfirmation
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Preconfirmation found",
+			ExpectedResult: preconfirmation,
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPreconfirmation", context.Background(), txHash, m.DbTx).Return(preconfirmation, nil).Once()
+			},
+		},
+		{
+			Name:           "Preconfirmation not found",
+			ExpectedResult: nil,
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPreconfirmation", context.Background(), txHash, m.DbTx).Return(nil, state.ErrNotFound).Once()
+			},
+		},
+		{
+			Name:          "Failed to get preconfirmation",
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to get preconfirmation from state"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPreconfirmation", context.Background(), txHash, m.DbTx).Return(nil, errors.New("failed")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("zkevm_getPreconfirmation", txHash.String())
+			require.NoError(t, err)
+
+			if tc.ExpectedResult != nil {
+				require.Nil(t, res.Error)
+				var result preconf.Preconfirmation
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, result)
+				assert.NoError(t, result.Verify(crypto.PubkeyToAddress(privateKey.PublicKey)))
+			} else if tc.ExpectedError != nil {
+				require.NotNil(t, res.Error)
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			} else {
+				require.Nil(t, res.Error)
+				assert.Equal(t, "null", string(res.Result))
+			}
+		})
+	}
+}
+
+func TestGetPruneInfo(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	pruneInfo := state.PruneInfo{BatchNumber: 2, L2BlockNumber: 3, PrunedAt: time.Unix(1700000000, 0).UTC()}
+
+	type testCase struct {
+		Name           string
+		ExpectedResult *types.PruneInfo
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "State pruned",
+			ExpectedResult: &types.PruneInfo{LastPrunedBlock: 3, LastPrunedBatch: 2, PrunedAt: pruneInfo.PrunedAt},
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPruneInfo", context.Background(), m.DbTx).Return(pruneInfo, nil).Once()
+			},
+		},
+		{
+			Name:           "State never pruned",
+			ExpectedResult: nil,
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPruneInfo", context.Background(), m.DbTx).Return(state.PruneInfo{}, state.ErrNotFound).Once()
+			},
+		},
+		{
+			Name:          "Failed to get prune info",
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to get prune info from state"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetPruneInfo", context.Background(), m.DbTx).Return(state.PruneInfo{}, errors.New("failed")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("zkevm_getPruneInfo")
+			require.NoError(t, err)
+
+			if tc.ExpectedResult != nil {
+				require.Nil(t, res.Error)
+				var result types.PruneInfo
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, result)
+			} else if tc.ExpectedError != nil {
+				require.NotNil(t, res.Error)
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			} else {
+				require.Nil(t, res.Error)
+				assert.Equal(t, "null", string(res.Result))
+			}
+		})
+	}
+}
+
+func TestEstimateEffectiveGasPrice(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	tx := ethTypes.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), uint64(21000), big.NewInt(10), []byte{})
+	txBinary, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	input := hex.EncodeToHex(txBinary)
+
+	txMatchByHash := mock.MatchedBy(func(estimatedTx ethTypes.Transaction) bool {
+		return estimatedTx.Hash() == tx.Hash()
+	})
+
+	estimation := pool.EffectiveGasPriceEstimation{
+		Enabled:    true,
+		GasPrices:  pool.GasPrices{L1GasPrice: 100, L2GasPrice: 25},
+		GasUsed:    21000,
+		TxGasPrice: big.NewInt(10),
+		BreakEven: pool.BreakEvenGasPriceBreakdown{
+			L2MinGasPrice:     25,
+			ZeroBytes:         2,
+			NonZeroBytes:      80,
+			BytesCost:         128800,
+			ExecutionCost:     525000,
+			BreakEvenGasPrice: big.NewInt(31),
+		},
+		BreakEvenGasPriceWithFactor: big.NewInt(34),
+		EffectiveGasPrice:           big.NewInt(10),
+		EffectivePercentage:         255,
+		RejectReason:                pool.ErrEffectiveGasPriceGasPriceTooLow.Error(),
+	}
+
+	type testCase struct {
+		Name           string
+		Input          string
+		ExpectedResult *types.EffectiveGasPriceEstimation
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	sponsoredEstimation := estimation
+	sponsoredEstimation.TxGasPrice = big.NewInt(0)
+	sponsoredEstimation.EffectiveGasPrice = big.NewInt(0)
+	sponsoredEstimation.Sponsored = true
+	sponsoredEstimation.Accepted = true
+	sponsoredEstimation.RejectReason = ""
+
+	expectedResult := types.NewEffectiveGasPriceEstimation(estimation)
+	expectedSponsoredResult := types.NewEffectiveGasPriceEstimation(sponsoredEstimation)
+	testCases := []testCase{
+		{
+			Name:           "Estimate effective gas price successfully",
+			Input:          input,
+			ExpectedResult: &expectedResult,
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.
+					On("EstimateEffectiveGasPrice", context.Background(), txMatchByHash).
+					Return(estimation, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "Estimate effective gas price of a sponsored tx",
+			Input:          input,
+			ExpectedResult: &expectedSponsoredResult,
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.
+					On("EstimateEffectiveGasPrice", context.Background(), txMatchByHash).
+					Return(sponsoredEstimation, nil).
+					Once()
+			},
+		},
+		{
+			Name:          "Estimate effective gas price failed to pre execute",
+			Input:         input,
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to pre execute"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.
+					On("EstimateEffectiveGasPrice", context.Background(), txMatchByHash).
+					Return(pool.EffectiveGasPriceEstimation{}, errors.New("failed to pre execute")).
+					Once()
+			},
+		},
+		{
+			Name:          "Estimate effective gas price with invalid tx input",
+			Input:         "0x1234",
+			ExpectedError: types.NewRPCError(types.InvalidParamsErrorCode, "invalid tx input"),
+			SetupMocks:    func(m *mocksWrapper) {},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("zkevm_estimateEffectiveGasPrice", tc.Input)
+			require.NoError(t, err)
+
+			if tc.ExpectedResult != nil {
+				require.Nil(t, res.Error)
+				expectedResult, err := json.Marshal(tc.ExpectedResult)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expectedResult), string(res.Result))
+			} else {
+				require.NotNil(t, res.Error)
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveGasPriceLog(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	txHash := common.HexToHash("0x1")
+	egpLog := &state.EffectiveGasPriceLog{
+		Enabled:        true,
+		ValueFinal:     big.NewInt(9),
+		ValueFirst:     big.NewInt(9),
+		ValueSecond:    big.NewInt(0),
+		FinalDeviation: big.NewInt(0),
+		MaxDeviation:   big.NewInt(1),
+		GasUsedFirst:   21000,
+		GasPrice:       big.NewInt(10),
+		Percentage:     229,
+		L1GasPrice:     100,
+		L2GasPrice:     25,
+	}
+
+	type testCase struct {
+		Name           string
+		ExpectedResult *types.EffectiveGasPriceLog
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	expectedResult := types.NewEffectiveGasPriceLog(*egpLog)
+	testCases := []testCase{
+		{
+			Name:           "Effective gas price log found",
+			ExpectedResult: &expectedResult,
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetTransactionEGPLogByHash", context.Background(), txHash, m.DbTx).Return(egpLog, nil).Once()
+			},
+		},
+		{
+			Name:           "Effective gas price log not found",
+			ExpectedResult: nil,
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetTransactionEGPLogByHash", context.Background(), txHash, m.DbTx).Return(nil, state.ErrNotFound).Once()
+			},
+		},
+		{
+			Name:          "Failed to get effective gas price log",
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to get effective gas price log from state"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetTransactionEGPLogByHash", context.Background(), txHash, m.DbTx).Return(nil, errors.New("failed")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("zkevm_getEffectiveGasPriceLog", txHash.String())
+			require.NoError(t, err)
+
+			if tc.ExpectedResult != nil {
+				require.Nil(t, res.Error)
+				expectedResult, err := json.Marshal(tc.ExpectedResult)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expectedResult), string(res.Result))
+			} else if tc.ExpectedError != nil {
+				require.NotNil(t, res.Error)
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			} else {
+				require.Nil(t, res.Error)
+				assert.Equal(t, "null", string(res.Result))
+			}
+		})
+	}
+}
+
+func TestSendBundle(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	txs := []*ethTypes.Transaction{
+		ethTypes.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), uint64(1), big.NewInt(1), []byte{}),
+		ethTypes.NewTransaction(2, common.HexToAddress("0x1"), big.NewInt(1), uint64(1), big.NewInt(1), []byte{}),
+	}
+	inputs := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		txBinary, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		inputs = append(inputs, hex.EncodeToHex(txBinary))
+	}
+	bundleHash := common.HexToHash("0x123")
+
+	txsMatchByHash := mock.MatchedBy(func(bundleTxs []ethTypes.Transaction) bool {
+		if len(bundleTxs) != len(txs) {
+			return false
+		}
+		for i := range bundleTxs {
+			if bundleTxs[i].Hash() != txs[i].Hash() {
+				return false
+			}
+		}
+		return true
+	})
+
+	type testCase struct {
+		Name           string
+		Inputs         []string
+		ExpectedResult *common.Hash
+		ExpectedError  types.Error
+		SetupMocks     func(m *mocksWrapper)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Send bundle successfully",
+			Inputs:         inputs,
+			ExpectedResult: &bundleHash,
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.
+					On("AddBundle", context.Background(), txsMatchByHash, "127.0.0.1").
+					Return(bundleHash, nil).
+					Once()
+			},
+		},
+		{
+			Name:          "Send bundle failed to add to the pool",
+			Inputs:        inputs,
+			ExpectedError: types.NewRPCError(types.DefaultErrorCode, "failed to add bundle to the pool"),
+			SetupMocks: func(m *mocksWrapper) {
+				m.Pool.
+					On("AddBundle", context.Background(), txsMatchByHash, "127.0.0.1").
+					Return(common.Hash{}, errors.New("failed to add bundle to the pool")).
+					Once()
+			},
+		},
+		{
+			Name:          "Send bundle with invalid tx input",
+			Inputs:        []string{inputs[0], "0x1234"},
+			ExpectedError: types.NewRPCError(types.InvalidParamsErrorCode, "invalid tx input"),
+			SetupMocks:    func(m *mocksWrapper) {},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("zkevm_sendBundle", tc.Inputs)
+			require.NoError(t, err)
+
+			assert.Equal(t, float64(1), res.ID)
+			assert.Equal(t, "2.0", res.JSONRPC)
+
+			if res.Result != nil || tc.ExpectedResult != nil {
+				var result common.Hash
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, result)
+			}
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
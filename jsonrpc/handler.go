@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
 
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 )
@@ -62,18 +64,39 @@ type handleRequest struct {
 // check the `eth.go` file for more example on how the methods are implemented
 type Handler struct {
 	serviceMap map[string]*serviceData
+	accessLog  *accessLogger
 }
 
 func newJSONRpcHandler() *Handler {
 	handler := &Handler{
 		serviceMap: map[string]*serviceData{},
 	}
+	handler.registerService(Service{
+		Name:    APIRPC,
+		Service: NewRPCEndpoints(handler),
+	})
 	return handler
 }
 
 // Handle is the function that knows which and how a function should
 // be executed when a JSON RPC request is received
 func (h *Handler) Handle(req handleRequest) types.Response {
+	start := time.Now()
+	res := h.handle(req)
+
+	method := h.methodLabel(req.Method)
+	metrics.MethodDuration(method, start)
+	if res.Error != nil {
+		metrics.MethodError(method, res.Error.Code)
+	}
+	if h.accessLog != nil {
+		h.accessLog.record(req, res, start)
+	}
+
+	return res
+}
+
+func (h *Handler) handle(req handleRequest) types.Response {
 	log := log.WithFields("method", req.Method, "requestId", req.ID)
 	log.Debugf("request params %v", string(req.Params))
 
@@ -224,8 +247,13 @@ func (h *Handler) registerService(service Service) {
 func (h *Handler) getFnHandler(req types.Request) (*serviceData, *funcData, types.Error) {
 	methodNotFoundErrorMessage := fmt.Sprintf("the method %s does not exist/is not available", req.Method)
 
-	callName := strings.SplitN(req.Method, "_", 2) //nolint:gomnd
-	if len(callName) != 2 {                        //nolint:gomnd
+	method := req.Method
+	if method == rpcDiscoverMethod {
+		method = APIRPC + "_" + rpcDiscoverFuncName
+	}
+
+	callName := strings.SplitN(method, "_", 2) //nolint:gomnd
+	if len(callName) != 2 {                    //nolint:gomnd
 		return nil, nil, types.NewRPCError(types.NotFoundErrorCode, methodNotFoundErrorMessage)
 	}
 
@@ -243,6 +271,26 @@ func (h *Handler) getFnHandler(req types.Request) (*serviceData, *funcData, type
 	return service, fd, nil
 }
 
+// methodLabel returns the method name to be used as metric label, the methods
+// not exposed by the server are grouped under the same label
+func (h *Handler) methodLabel(method string) string {
+	if method == rpcDiscoverMethod {
+		return method
+	}
+	callName := strings.SplitN(method, "_", 2) //nolint:gomnd
+	if len(callName) != 2 {                    //nolint:gomnd
+		return metrics.UnknownMethodLabel
+	}
+	service, ok := h.serviceMap[callName[0]]
+	if !ok {
+		return metrics.UnknownMethodLabel
+	}
+	if _, ok := service.funcMap[callName[1]]; !ok {
+		return metrics.UnknownMethodLabel
+	}
+	return method
+}
+
 func validateFunc(funcName string, fv reflect.Value, isMethod bool) (inNum int, reqt []reflect.Type, err error) {
 	if funcName == "" {
 		err = fmt.Errorf("getBlockNumByArg cannot be empty")
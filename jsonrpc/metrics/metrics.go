@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/metrics"
@@ -13,7 +14,22 @@ const (
 	requestsHandledName = requestPrefix + "handled"
 	requestDurationName = requestPrefix + "duration"
 
+	methodDurationName = requestPrefix + "method_duration"
+	methodErrorsName   = requestPrefix + "method_errors"
+
+	relayPrefix             = prefix + "relay_"
+	relayRequestsName       = relayPrefix + "requests"
+	relayCircuitsOpenedName = relayPrefix + "circuits_opened"
+
 	requestHandledTypeLabelName = "type"
+	methodLabelName             = "method"
+	errorCodeLabelName          = "code"
+	upstreamLabelName           = "upstream"
+	resultLabelName             = "result"
+
+	// UnknownMethodLabel is the method label used for the requests to methods
+	// that are not exposed by the server, to avoid unbounded label values
+	UnknownMethodLabel = "unknown"
 )
 
 // RequestHandledLabel represents the possible values for the
@@ -31,11 +47,23 @@ const (
 	RequestHandledLabelBatch RequestHandledLabel = "batch"
 )
 
+// RelayResultLabel represents the possible values for the
+// `jsonrpc_relay_requests` metric `result` label.
+type RelayResultLabel string
+
+const (
+	// RelayResultSuccess represents a request relayed successfully to the upstream
+	RelayResultSuccess RelayResultLabel = "success"
+	// RelayResultError represents a request that failed to be relayed to the upstream
+	RelayResultError RelayResultLabel = "error"
+)
+
 // Register the metrics for the jsonrpc package.
 func Register() {
 	var (
-		counterVecs []metrics.CounterVecOpts
-		histograms  []prometheus.HistogramOpts
+		counterVecs   []metrics.CounterVecOpts
+		histograms    []prometheus.HistogramOpts
+		histogramVecs []metrics.HistogramVecOpts
 	)
 
 	counterVecs = []metrics.CounterVecOpts{
@@ -46,6 +74,27 @@ func Register() {
 			},
 			Labels: []string{requestHandledTypeLabelName},
 		},
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: methodErrorsName,
+				Help: "[JSONRPC] number of requests that returned an error by method and error code",
+			},
+			Labels: []string{methodLabelName, errorCodeLabelName},
+		},
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: relayRequestsName,
+				Help: "[JSONRPC] number of requests relayed to the sequencer by upstream and result",
+			},
+			Labels: []string{upstreamLabelName, resultLabelName},
+		},
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: relayCircuitsOpenedName,
+				Help: "[JSONRPC] number of times a sequencer upstream has been skipped due to consecutive failures",
+			},
+			Labels: []string{upstreamLabelName},
+		},
 	}
 
 	start := 0.1
@@ -59,8 +108,20 @@ func Register() {
 		},
 	}
 
+	histogramVecs = []metrics.HistogramVecOpts{
+		{
+			HistogramOpts: prometheus.HistogramOpts{
+				Name:    methodDurationName,
+				Help:    "[JSONRPC] Histogram for the runtime of requests by method",
+				Buckets: prometheus.ExponentialBuckets(0.001, 2, 16), //nolint:gomnd
+			},
+			Labels: []string{methodLabelName},
+		},
+	}
+
 	metrics.RegisterCounterVecs(counterVecs...)
 	metrics.RegisterHistograms(histograms...)
+	metrics.RegisterHistogramVecs(histogramVecs...)
 }
 
 // RequestHandled increments the requests handled counter vector by one for the
@@ -74,3 +135,31 @@ func RequestHandled(label RequestHandledLabel) {
 func RequestDuration(start time.Time) {
 	metrics.HistogramObserve(requestDurationName, time.Since(start).Seconds())
 }
+
+// MethodDuration observes (histogram) the duration of a request to the given
+// method from the provided starting time.
+func MethodDuration(method string, start time.Time) {
+	metrics.HistogramVecObserve(methodDurationName, method, time.Since(start).Seconds())
+}
+
+// MethodError increments the errors counter vector by one for the given
+// method and error code.
+func MethodError(method string, code int) {
+	if cv, ok := metrics.CounterVec(methodErrorsName); ok {
+		cv.WithLabelValues(method, strconv.Itoa(code)).Inc()
+	}
+}
+
+// RelayRequest increments the relayed requests counter vector by one for the
+// given upstream and result.
+func RelayRequest(upstream string, result RelayResultLabel) {
+	if cv, ok := metrics.CounterVec(relayRequestsName); ok {
+		cv.WithLabelValues(upstream, string(result)).Inc()
+	}
+}
+
+// RelayCircuitOpened increments the opened circuits counter vector by one for
+// the given upstream.
+func RelayCircuitOpened(upstream string) {
+	metrics.CounterVecInc(relayCircuitsOpenedName, upstream)
+}
@@ -5,10 +5,14 @@ package mocks
 import (
 	context "context"
 
+	big "math/big"
+
 	common "github.com/ethereum/go-ethereum/common"
 
 	mock "github.com/stretchr/testify/mock"
 
+	net "net"
+
 	pool "github.com/0xPolygonHermez/zkevm-node/pool"
 
 	time "time"
@@ -21,6 +25,32 @@ type PoolMock struct {
 	mock.Mock
 }
 
+// AddBundle provides a mock function with given fields: ctx, txs, ip
+func (_m *PoolMock) AddBundle(ctx context.Context, txs []types.Transaction, ip string) (common.Hash, error) {
+	ret := _m.Called(ctx, txs, ip)
+
+	var r0 common.Hash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []types.Transaction, string) (common.Hash, error)); ok {
+		return rf(ctx, txs, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []types.Transaction, string) common.Hash); ok {
+		r0 = rf(ctx, txs, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(common.Hash)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []types.Transaction, string) error); ok {
+		r1 = rf(ctx, txs, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddTx provides a mock function with given fields: ctx, tx, ip
 func (_m *PoolMock) AddTx(ctx context.Context, tx types.Transaction, ip string) error {
 	ret := _m.Called(ctx, tx, ip)
@@ -35,6 +65,54 @@ func (_m *PoolMock) AddTx(ctx context.Context, tx types.Transaction, ip string)
 	return r0
 }
 
+// CheckCallPolicy provides a mock function with given fields: ctx, target, data
+func (_m *PoolMock) CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error) {
+	ret := _m.Called(ctx, target, data)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, []byte) (bool, error)); ok {
+		return rf(ctx, target, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, []byte) bool); ok {
+		r0 = rf(ctx, target, data)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, []byte) error); ok {
+		r1 = rf(ctx, target, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckIPPolicy provides a mock function with given fields: ctx, ip
+func (_m *PoolMock) CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error) {
+	ret := _m.Called(ctx, ip)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, net.IP) (bool, error)); ok {
+		return rf(ctx, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, net.IP) bool); ok {
+		r0 = rf(ctx, ip)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, net.IP) error); ok {
+		r1 = rf(ctx, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckPolicy provides a mock function with given fields: ctx, policy, address
 func (_m *PoolMock) CheckPolicy(ctx context.Context, policy pool.PolicyName, address common.Address) (bool, error) {
 	ret := _m.Called(ctx, policy, address)
@@ -83,6 +161,44 @@ func (_m *PoolMock) CountPendingTransactions(ctx context.Context) (uint64, error
 	return r0, r1
 }
 
+// DeleteReputation provides a mock function with given fields: ctx, subject
+func (_m *PoolMock) DeleteReputation(ctx context.Context, subject string) error {
+	ret := _m.Called(ctx, subject)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EstimateEffectiveGasPrice provides a mock function with given fields: ctx, tx
+func (_m *PoolMock) EstimateEffectiveGasPrice(ctx context.Context, tx types.Transaction) (pool.EffectiveGasPriceEstimation, error) {
+	ret := _m.Called(ctx, tx)
+
+	var r0 pool.EffectiveGasPriceEstimation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.Transaction) (pool.EffectiveGasPriceEstimation, error)); ok {
+		return rf(ctx, tx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.Transaction) pool.EffectiveGasPriceEstimation); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		r0 = ret.Get(0).(pool.EffectiveGasPriceEstimation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.Transaction) error); ok {
+		r1 = rf(ctx, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetGasPrices provides a mock function with given fields: ctx
 func (_m *PoolMock) GetGasPrices(ctx context.Context) (pool.GasPrices, error) {
 	ret := _m.Called(ctx)
@@ -107,6 +223,32 @@ func (_m *PoolMock) GetGasPrices(ctx context.Context) (pool.GasPrices, error) {
 	return r0, r1
 }
 
+// GetMaxTxValue provides a mock function with given fields: ctx
+func (_m *PoolMock) GetMaxTxValue(ctx context.Context) (*big.Int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *big.Int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*big.Int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *big.Int); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNonce provides a mock function with given fields: ctx, address
 func (_m *PoolMock) GetNonce(ctx context.Context, address common.Address) (uint64, error) {
 	ret := _m.Called(ctx, address)
@@ -183,6 +325,32 @@ func (_m *PoolMock) GetPendingTxs(ctx context.Context, limit uint64) ([]pool.Tra
 	return r0, r1
 }
 
+// GetReputation provides a mock function with given fields: ctx, subject
+func (_m *PoolMock) GetReputation(ctx context.Context, subject string) (*pool.Reputation, error) {
+	ret := _m.Called(ctx, subject)
+
+	var r0 *pool.Reputation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*pool.Reputation, error)); ok {
+		return rf(ctx, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *pool.Reputation); ok {
+		r0 = rf(ctx, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pool.Reputation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTxByHash provides a mock function with given fields: ctx, hash
 func (_m *PoolMock) GetTxByHash(ctx context.Context, hash common.Hash) (*pool.Transaction, error) {
 	ret := _m.Called(ctx, hash)
@@ -209,6 +377,32 @@ func (_m *PoolMock) GetTxByHash(ctx context.Context, hash common.Hash) (*pool.Tr
 	return r0, r1
 }
 
+// ListReputations provides a mock function with given fields: ctx
+func (_m *PoolMock) ListReputations(ctx context.Context) ([]pool.Reputation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []pool.Reputation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]pool.Reputation, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []pool.Reputation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pool.Reputation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewPoolMock creates a new instance of PoolMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPoolMock(t interface {
@@ -14,6 +14,8 @@ import (
 
 	pgx "github.com/jackc/pgx/v4"
 
+	preconf "github.com/0xPolygonHermez/zkevm-node/preconf"
+
 	runtime "github.com/0xPolygonHermez/zkevm-node/state/runtime"
 
 	state "github.com/0xPolygonHermez/zkevm-node/state"
@@ -76,6 +78,20 @@ func (_m *StateMock) BeginStateTransaction(ctx context.Context) (pgx.Tx, error)
 	return r0, r1
 }
 
+// CheckL2BlockNotPruned provides a mock function with given fields: ctx, blockNumber, dbTx
+func (_m *StateMock) CheckL2BlockNotPruned(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) error {
+	ret := _m.Called(ctx, blockNumber, dbTx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r0 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DebugTransaction provides a mock function with given fields: ctx, transactionHash, traceConfig, dbTx
 func (_m *StateMock) DebugTransaction(ctx context.Context, transactionHash common.Hash, traceConfig state.TraceConfig, dbTx pgx.Tx) (*runtime.ExecutionResult, error) {
 	ret := _m.Called(ctx, transactionHash, traceConfig, dbTx)
@@ -737,6 +753,32 @@ func (_m *StateMock) GetNonce(ctx context.Context, address common.Address, root
 	return r0, r1
 }
 
+// GetPreconfirmation provides a mock function with given fields: ctx, transactionHash, dbTx
+func (_m *StateMock) GetPreconfirmation(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*preconf.Preconfirmation, error) {
+	ret := _m.Called(ctx, transactionHash, dbTx)
+
+	var r0 *preconf.Preconfirmation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, pgx.Tx) (*preconf.Preconfirmation, error)); ok {
+		return rf(ctx, transactionHash, dbTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, pgx.Tx) *preconf.Preconfirmation); ok {
+		r0 = rf(ctx, transactionHash, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*preconf.Preconfirmation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, pgx.Tx) error); ok {
+		r1 = rf(ctx, transactionHash, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStorageAt provides a mock function with given fields: ctx, address, position, root
 func (_m *StateMock) GetStorageAt(ctx context.Context, address common.Address, position *big.Int, root common.Hash) (*big.Int, error) {
 	ret := _m.Called(ctx, address, position, root)
@@ -763,6 +805,56 @@ func (_m *StateMock) GetStorageAt(ctx context.Context, address common.Address, p
 	return r0, r1
 }
 
+// GetPruneInfo provides a mock function with given fields: ctx, dbTx
+func (_m *StateMock) GetPruneInfo(ctx context.Context, dbTx pgx.Tx) (state.PruneInfo, error) {
+	ret := _m.Called(ctx, dbTx)
+
+	var r0 state.PruneInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) (state.PruneInfo, error)); ok {
+		return rf(ctx, dbTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) state.PruneInfo); ok {
+		r0 = rf(ctx, dbTx)
+	} else {
+		r0 = ret.Get(0).(state.PruneInfo)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(ctx, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrunedTransactionHashesByL2BlockNumber provides a mock function with given fields: ctx, blockNumber, dbTx
+func (_m *StateMock) GetPrunedTransactionHashesByL2BlockNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) ([]common.Hash, error) {
+	ret := _m.Called(ctx, blockNumber, dbTx)
+
+	var r0 []common.Hash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) ([]common.Hash, error)); ok {
+		return rf(ctx, blockNumber, dbTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) []common.Hash); ok {
+		r0 = rf(ctx, blockNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]common.Hash)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSyncingInfo provides a mock function with given fields: ctx, dbTx
 func (_m *StateMock) GetSyncingInfo(ctx context.Context, dbTx pgx.Tx) (state.SyncingInfo, error) {
 	ret := _m.Called(ctx, dbTx)
@@ -865,6 +957,32 @@ func (_m *StateMock) GetTransactionByL2BlockNumberAndIndex(ctx context.Context,
 	return r0, r1
 }
 
+// GetTransactionEGPLogByHash provides a mock function with given fields: ctx, transactionHash, dbTx
+func (_m *StateMock) GetTransactionEGPLogByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*state.EffectiveGasPriceLog, error) {
+	ret := _m.Called(ctx, transactionHash, dbTx)
+
+	var r0 *state.EffectiveGasPriceLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, pgx.Tx) (*state.EffectiveGasPriceLog, error)); ok {
+		return rf(ctx, transactionHash, dbTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, pgx.Tx) *state.EffectiveGasPriceLog); ok {
+		r0 = rf(ctx, transactionHash, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.EffectiveGasPriceLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, pgx.Tx) error); ok {
+		r1 = rf(ctx, transactionHash, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransactionReceipt provides a mock function with given fields: ctx, transactionHash, dbTx
 func (_m *StateMock) GetTransactionReceipt(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*coretypes.Receipt, error) {
 	ret := _m.Called(ctx, transactionHash, dbTx)
@@ -1057,6 +1175,11 @@ func (_m *StateMock) RegisterNewL2BlockEventHandler(h state.NewL2BlockEventHandl
 	_m.Called(h)
 }
 
+// RegisterPreconfirmationEventHandler provides a mock function with given fields: h
+func (_m *StateMock) RegisterPreconfirmationEventHandler(h state.PreconfirmationEventHandler) {
+	_m.Called(h)
+}
+
 // StartToMonitorNewL2Blocks provides a mock function with given fields:
 func (_m *StateMock) StartToMonitorNewL2Blocks() {
 	_m.Called()
@@ -0,0 +1,160 @@
+package jsonrpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-node"
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	openRPCVersion = "1.2.6"
+	openRPCTitle   = "zkEVM Node JSON-RPC"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	wsConnType          = reflect.TypeOf(&concurrentWsConn{})
+	httpRequestType     = reflect.TypeOf(&http.Request{})
+	emptyInterfaceType  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// OpenRPCDocument is the service discovery document returned by rpc.discover,
+// it follows the OpenRPC specification: https://spec.open-rpc.org
+type OpenRPCDocument struct {
+	OpenRPC string          `json:"openrpc"`
+	Info    OpenRPCInfo     `json:"info"`
+	Methods []OpenRPCMethod `json:"methods"`
+}
+
+// OpenRPCInfo contains the metadata about the API
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a single JSON RPC method
+type OpenRPCMethod struct {
+	Name   string                     `json:"name"`
+	Params []OpenRPCContentDescriptor `json:"params"`
+	Result OpenRPCContentDescriptor   `json:"result"`
+}
+
+// OpenRPCContentDescriptor describes the content of a param or of a result
+type OpenRPCContentDescriptor struct {
+	Name     string             `json:"name"`
+	Required bool               `json:"required,omitempty"`
+	Schema   *jsonschema.Schema `json:"schema"`
+}
+
+// openRPCDocument builds the OpenRPC document describing all the methods
+// registered in the handler, the params and results are introspected from
+// the go types of the functions implementing them
+func (h *Handler) openRPCDocument() OpenRPCDocument {
+	r := &jsonschema.Reflector{
+		Anonymous:                  true,
+		DoNotReference:             true,
+		AllowAdditionalProperties:  true,
+		RequiredFromJSONSchemaTags: true,
+		Mapper:                     openRPCTypeMapper,
+	}
+
+	doc := OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info: OpenRPCInfo{
+			Title:   openRPCTitle,
+			Version: zkevm.Version,
+		},
+		Methods: []OpenRPCMethod{},
+	}
+
+	for _, serviceName := range h.serviceNames() {
+		service := h.serviceMap[serviceName]
+		funcNames := make([]string, 0, len(service.funcMap))
+		for funcName := range service.funcMap {
+			funcNames = append(funcNames, funcName)
+		}
+		sort.Strings(funcNames)
+
+		for _, funcName := range funcNames {
+			fd := service.funcMap[funcName]
+			doc.Methods = append(doc.Methods, OpenRPCMethod{
+				Name:   methodName(serviceName, funcName),
+				Params: openRPCParams(r, fd),
+				Result: OpenRPCContentDescriptor{
+					Name:   "result",
+					Schema: openRPCSchema(r, fd.fv.Type().Out(0)),
+				},
+			})
+		}
+	}
+
+	return doc
+}
+
+// openRPCParams returns the content descriptors of the params a client must
+// provide to call the function, ignoring the receiver and the params injected
+// by the server like the websocket connection or the http request
+func openRPCParams(r *jsonschema.Reflector, fd *funcData) []OpenRPCContentDescriptor {
+	params := []OpenRPCContentDescriptor{}
+	for i := 1; i < fd.inNum; i++ {
+		pt := fd.reqt[i]
+		if i == 1 && (pt.AssignableTo(wsConnType) || pt.AssignableTo(httpRequestType)) {
+			continue
+		}
+		params = append(params, OpenRPCContentDescriptor{
+			Name:     fmt.Sprintf("param%d", len(params)+1),
+			Required: pt.Kind() != reflect.Ptr,
+			Schema:   openRPCSchema(r, pt),
+		})
+	}
+	return params
+}
+
+func openRPCSchema(r *jsonschema.Reflector, t reflect.Type) *jsonschema.Schema {
+	if t == emptyInterfaceType {
+		return &jsonschema.Schema{}
+	}
+	s := r.ReflectFromType(t)
+	s.Version = ""
+	return s
+}
+
+// openRPCTypeMapper provides the schema for the types that are encoded in a
+// custom way, most of the jsonrpc types are hex encoded strings
+func openRPCTypeMapper(t reflect.Type) *jsonschema.Schema {
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return &jsonschema.Schema{Type: "string"}
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		if t.Kind() == reflect.Struct {
+			// structs with custom decoding accept more than one representation
+			return &jsonschema.Schema{}
+		}
+		return &jsonschema.Schema{Type: "string"}
+	}
+	return nil
+}
+
+func methodName(serviceName, funcName string) string {
+	if serviceName == APIRPC && funcName == rpcDiscoverFuncName {
+		return rpcDiscoverMethod
+	}
+	return serviceName + "_" + funcName
+}
+
+func (h *Handler) serviceNames() []string {
+	names := make([]string, 0, len(h.serviceMap))
+	for name := range h.serviceMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -7,10 +7,9 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
-	ethTypes "github.com/ethereum/go-ethereum/core/types"
 )
 
-func checkPolicy(ctx context.Context, p types.PoolInterface, input string) error {
+func checkPolicy(ctx context.Context, p types.PoolInterface, input string, ip string) error {
 	tx, err := hexToTx(input)
 	if err != nil {
 		// ignore it, let the later processing reject
@@ -25,37 +24,5 @@ func checkPolicy(ctx context.Context, p types.PoolInterface, input string) error
 		return nil
 	}
 
-	switch resolvePolicy(tx) {
-	case pool.SendTx:
-		var allow bool
-		if allow, err = p.CheckPolicy(ctx, pool.SendTx, *tx.To()); err != nil {
-			return err
-		}
-		if !allow {
-			return pool.ErrContractDisallowedSendTx
-		}
-		if allow, err = p.CheckPolicy(ctx, pool.SendTx, from); err != nil {
-			return err
-		}
-		if !allow {
-			return pool.ErrSenderDisallowedSendTx
-		}
-	case pool.Deploy:
-		var allow bool
-		// check that sender may deploy contracts
-		if allow, err = p.CheckPolicy(ctx, pool.Deploy, from); err != nil {
-			return err
-		}
-		if !allow {
-			return pool.ErrSenderDisallowedDeploy
-		}
-	}
-	return nil
-}
-
-func resolvePolicy(tx *ethTypes.Transaction) pool.PolicyName {
-	if tx.To() == nil || tx.To().Hex() == common.HexToAddress("0x0").Hex() {
-		return pool.Deploy
-	}
-	return pool.SendTx
+	return pool.CheckTxPolicies(ctx, p, *tx, from, ip)
 }
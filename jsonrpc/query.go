@@ -23,6 +23,8 @@ const (
 	FilterTypeBlock = "block"
 	// FilterTypePendingTx represent a filter of type pending Tx.
 	FilterTypePendingTx = "pendingTx"
+	// FilterTypePreconfirmation represents a filter of type preconfirmation.
+	FilterTypePreconfirmation = "preconfirmation"
 )
 
 // Filter represents a filter.
@@ -0,0 +1,157 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/client"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+)
+
+const relayHealthCheckMethod = "eth_chainId"
+
+// errNoUpstreamAvailable is returned when there is no upstream to relay the request to
+var errNoUpstreamAvailable = errors.New("no sequencer upstream available")
+
+// relayUpstream keeps track of the health of a sequencer node used to relay requests
+type relayUpstream struct {
+	url                 string
+	consecutiveFailures uint64
+	openUntil           time.Time
+}
+
+// SequencerRelay relays the requests that must be answered by the trusted sequencer
+// to a list of upstreams. Upstreams failing FailureThreshold consecutive times are
+// skipped (circuit open) during CircuitOpenDuration, idempotent calls are retried
+// on the next available upstream
+type SequencerRelay struct {
+	cfg       RelayConfig
+	mutex     sync.Mutex
+	upstreams []*relayUpstream
+	now       func() time.Time
+	call      func(url string, headers http.Header, method string, parameters ...interface{}) (types.Response, error)
+}
+
+// NewSequencerRelay creates a relay for the provided primary sequencer URI followed by the
+// additional upstreams in the config, duplicated and empty URIs are ignored
+func NewSequencerRelay(primaryURI string, cfg RelayConfig) *SequencerRelay {
+	r := &SequencerRelay{
+		cfg:  cfg,
+		now:  time.Now,
+		call: client.JSONRPCCallWithHeaders,
+	}
+	seen := map[string]bool{}
+	for _, url := range append([]string{primaryURI}, cfg.UpstreamURIs...) {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		r.upstreams = append(r.upstreams, &relayUpstream{url: url})
+	}
+	return r
+}
+
+// startHealthChecks checks periodically the health of the upstreams until the context is done
+func (r *SequencerRelay) startHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.HealthCheckInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkHealth()
+		}
+	}
+}
+
+func (r *SequencerRelay) checkHealth() {
+	for _, u := range r.upstreams {
+		_, err := r.call(u.url, nil, relayHealthCheckMethod)
+		r.report(u, err)
+	}
+}
+
+// relay sends the request to the upstreams in order of preference. Non idempotent
+// requests are sent only once, idempotent requests are retried on the next upstream
+// when the previous one could not be reached
+func (r *SequencerRelay) relay(idempotent bool, method string, parameters ...interface{}) (types.Response, error) {
+	return r.send(idempotent, nil, method, parameters...)
+}
+
+// relayFrom relays a non idempotent request sent by the client with the ip, the ip is forwarded in the
+// X-Forwarded-For header so the sequencer node can apply its policies to it, see Config.TrustedProxies
+func (r *SequencerRelay) relayFrom(ip string, method string, parameters ...interface{}) (types.Response, error) {
+	var headers http.Header
+	if ip != "" {
+		headers = http.Header{forwardedForHeader: []string{ip}}
+	}
+	return r.send(false, headers, method, parameters...)
+}
+
+func (r *SequencerRelay) send(idempotent bool, headers http.Header, method string, parameters ...interface{}) (types.Response, error) {
+	err := errNoUpstreamAvailable
+	for _, u := range r.candidates() {
+		var res types.Response
+		res, err = r.call(u.url, headers, method, parameters...)
+		r.report(u, err)
+		if err == nil {
+			metrics.RelayRequest(u.url, metrics.RelayResultSuccess)
+			return res, nil
+		}
+		metrics.RelayRequest(u.url, metrics.RelayResultError)
+		log.Warnf("failed to relay %s to sequencer upstream %s: %v", method, u.url, err)
+		if !idempotent {
+			break
+		}
+	}
+	return types.Response{}, err
+}
+
+// candidates returns the upstreams with the circuit closed in the configured order,
+// if all of them are open, all the upstreams are returned to give them a new try
+func (r *SequencerRelay) candidates() []*relayUpstream {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	candidates := make([]*relayUpstream, 0, len(r.upstreams))
+	for _, u := range r.upstreams {
+		if !now.Before(u.openUntil) {
+			candidates = append(candidates, u)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = append(candidates, r.upstreams...)
+	}
+	return candidates
+}
+
+// report updates the circuit of the upstream with the result of a call
+func (r *SequencerRelay) report(u *relayUpstream, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err == nil {
+		if !u.openUntil.IsZero() {
+			log.Infof("sequencer upstream %s is available again", u.url)
+		}
+		u.consecutiveFailures = 0
+		u.openUntil = time.Time{}
+		return
+	}
+
+	u.consecutiveFailures++
+	if r.cfg.FailureThreshold > 0 && u.consecutiveFailures >= r.cfg.FailureThreshold {
+		if r.now().After(u.openUntil) {
+			log.Warnf("sequencer upstream %s failed %d consecutive times, skipping it for %v", u.url, u.consecutiveFailures, r.cfg.CircuitOpenDuration.Duration)
+			metrics.RelayCircuitOpened(u.url)
+		}
+		u.openUntil = r.now().Add(r.cfg.CircuitOpenDuration.Duration)
+	}
+}
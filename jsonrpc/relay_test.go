@@ -0,0 +1,152 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	rpcTypes "github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpstreams simulates the upstreams of a relay, the upstreams
+// in the down map fail when called
+type fakeUpstreams struct {
+	down    map[string]bool
+	calls   []string
+	headers []http.Header
+}
+
+func (f *fakeUpstreams) call(url string, headers http.Header, method string, parameters ...interface{}) (rpcTypes.Response, error) {
+	f.calls = append(f.calls, url)
+	f.headers = append(f.headers, headers)
+	if f.down[url] {
+		return rpcTypes.Response{}, errors.New("connection refused")
+	}
+	return rpcTypes.Response{Result: []byte(`"` + url + `"`)}, nil
+}
+
+func newTestRelay(down map[string]bool) (*SequencerRelay, *fakeUpstreams, *time.Time) {
+	cfg := RelayConfig{
+		UpstreamURIs:        []string{"b", "a", "c"},
+		FailureThreshold:    2,
+		CircuitOpenDuration: types.NewDuration(time.Minute),
+	}
+	now := time.Unix(0, 0)
+	upstreams := &fakeUpstreams{down: down}
+	r := NewSequencerRelay("a", cfg)
+	r.call = upstreams.call
+	r.now = func() time.Time { return now }
+	return r, upstreams, &now
+}
+
+func TestSequencerRelayUpstreams(t *testing.T) {
+	r, _, _ := newTestRelay(nil)
+	urls := []string{}
+	for _, u := range r.upstreams {
+		urls = append(urls, u.url)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, urls)
+}
+
+func TestSequencerRelayIdempotentFailover(t *testing.T) {
+	r, upstreams, _ := newTestRelay(map[string]bool{"a": true})
+
+	res, err := r.relay(true, "eth_gasPrice")
+	require.NoError(t, err)
+	assert.Equal(t, `"b"`, string(res.Result))
+	assert.Equal(t, []string{"a", "b"}, upstreams.calls)
+}
+
+func TestSequencerRelayNonIdempotentIsNotRetried(t *testing.T) {
+	r, upstreams, _ := newTestRelay(map[string]bool{"a": true})
+
+	_, err := r.relay(false, "eth_sendRawTransaction", "0x00")
+	require.Error(t, err)
+	assert.Equal(t, []string{"a"}, upstreams.calls)
+}
+
+func TestSequencerRelayForwardsClientIP(t *testing.T) {
+	r, upstreams, _ := newTestRelay(nil)
+
+	_, err := r.relayFrom("101.1.50.20", "eth_sendRawTransaction", "0x00")
+	require.NoError(t, err)
+	_, err = r.relayFrom("", "eth_sendRawTransaction", "0x00")
+	require.NoError(t, err)
+	require.Len(t, upstreams.headers, 2)
+	assert.Equal(t, "101.1.50.20", upstreams.headers[0].Get(forwardedForHeader))
+	assert.Empty(t, upstreams.headers[1].Get(forwardedForHeader))
+}
+
+func TestSequencerRelayCircuitBreaker(t *testing.T) {
+	r, upstreams, now := newTestRelay(map[string]bool{"a": true})
+
+	// the circuit of "a" opens after two consecutive failures
+	for i := 0; i < 2; i++ {
+		_, err := r.relay(false, "eth_sendRawTransaction", "0x00")
+		require.Error(t, err)
+	}
+	upstreams.calls = nil
+	res, err := r.relay(false, "eth_sendRawTransaction", "0x00")
+	require.NoError(t, err)
+	assert.Equal(t, `"b"`, string(res.Result))
+	assert.Equal(t, []string{"b"}, upstreams.calls)
+
+	// once the circuit open duration expires "a" is tried again
+	upstreams.down["a"] = false
+	*now = now.Add(time.Minute)
+	upstreams.calls = nil
+	res, err = r.relay(false, "eth_sendRawTransaction", "0x00")
+	require.NoError(t, err)
+	assert.Equal(t, `"a"`, string(res.Result))
+	assert.Equal(t, []string{"a"}, upstreams.calls)
+}
+
+func TestSequencerRelayAllCircuitsOpen(t *testing.T) {
+	r, upstreams, _ := newTestRelay(map[string]bool{"a": true, "b": true, "c": true})
+	r.checkHealth()
+	r.checkHealth()
+	for _, u := range r.upstreams {
+		assert.Equal(t, uint64(2), u.consecutiveFailures)
+	}
+
+	// all the upstreams are tried when all the circuits are open
+	upstreams.down["c"] = false
+	upstreams.calls = nil
+	res, err := r.relay(true, "eth_gasPrice")
+	require.NoError(t, err)
+	assert.Equal(t, `"c"`, string(res.Result))
+	assert.Equal(t, []string{"a", "b", "c"}, upstreams.calls)
+}
+
+func TestSequencerRelayHealthChecksStop(t *testing.T) {
+	r, _, _ := newTestRelay(nil)
+	r.cfg.HealthCheckInterval = types.NewDuration(time.Millisecond)
+	checked := make(chan struct{}, 1)
+	r.call = func(url string, headers http.Header, method string, parameters ...interface{}) (rpcTypes.Response, error) {
+		select {
+		case checked <- struct{}{}:
+		default:
+		}
+		return rpcTypes.Response{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		r.startHealthChecks(ctx)
+		close(stopped)
+	}()
+
+	<-checked
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("health checks not stopped after the context was cancelled")
+	}
+}
@@ -34,6 +34,11 @@ const (
 	APITxPool = "txpool"
 	// APIWeb3 represents the web3 API prefix.
 	APIWeb3 = "web3"
+	// APIAdmin represents the admin API prefix.
+	APIAdmin = "admin"
+	// APIRPC represents the rpc API prefix, it is always exposed
+	// and describes the other APIs enabled in the server.
+	APIRPC = "rpc"
 
 	wsBufferSizeLimitInBytes = 1024
 	maxRequestContentLength  = 1024 * 1024 * 5
@@ -52,6 +57,9 @@ type Server struct {
 	wsSrv      *http.Server
 	wsUpgrader websocket.Upgrader
 
+	relay                 *SequencerRelay
+	stopRelayHealthChecks context.CancelFunc
+
 	connCounterMutex *sync.Mutex
 	httpConnCounter  int64
 	wsConnCounter    int64
@@ -77,6 +85,7 @@ func NewServer(
 	p types.PoolInterface,
 	s types.StateInterface,
 	storage storageInterface,
+	relay *SequencerRelay,
 	services []Service,
 ) *Server {
 	if cfg.WebSockets.Enabled {
@@ -89,10 +98,19 @@ func NewServer(
 		handler.registerService(service)
 	}
 
+	if cfg.AccessLog.Enabled {
+		accessLog, err := newAccessLogger(cfg.AccessLog, newIPResolver(cfg.TrustedProxies))
+		if err != nil {
+			log.Fatalf("failed to create the jsonrpc access log: %v", err)
+		}
+		handler.accessLog = accessLog
+	}
+
 	srv := &Server{
 		config:           cfg,
 		handler:          handler,
 		chainID:          chainID,
+		relay:            relay,
 		connCounterMutex: &sync.Mutex{},
 	}
 	return srv
@@ -102,6 +120,12 @@ func NewServer(
 func (s *Server) Start() error {
 	metrics.Register()
 
+	if s.relay != nil && s.config.SequencerRelay.HealthCheckInterval.Duration > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopRelayHealthChecks = cancel
+		go s.relay.startHealthChecks(ctx)
+	}
+
 	if s.config.WebSockets.Enabled {
 		go s.startWS()
 	}
@@ -211,6 +235,17 @@ func (s *Server) Stop() error {
 		s.wsSrv = nil
 	}
 
+	if s.stopRelayHealthChecks != nil {
+		s.stopRelayHealthChecks()
+		s.stopRelayHealthChecks = nil
+	}
+
+	if s.handler.accessLog != nil {
+		if err := s.handler.accessLog.close(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
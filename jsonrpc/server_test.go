@@ -15,6 +15,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/client"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/mocks"
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
@@ -28,6 +29,7 @@ import (
 const (
 	maxRequestsPerIPAndSecond        = 1000
 	chainID                   uint64 = 1000
+	testAdminAPIKey                  = "test-admin-key"
 )
 
 type mockedServer struct {
@@ -58,17 +60,27 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocksWrapper, *e
 		APIZKEVM:  true,
 		APITxPool: true,
 		APIWeb3:   true,
+		APIAdmin:  true,
 	}
 
 	var newL2BlockEventHandler state.NewL2BlockEventHandler = func(e state.NewL2BlockEvent) {}
 	st.On("RegisterNewL2BlockEventHandler", mock.IsType(newL2BlockEventHandler)).Once()
+	if cfg.SequencerNodeURI == "" {
+		var preconfirmationEventHandler state.PreconfirmationEventHandler = func(p *preconf.Preconfirmation) {}
+		st.On("RegisterPreconfirmationEventHandler", mock.IsType(preconfirmationEventHandler)).Once()
+	}
 	st.On("StartToMonitorNewL2Blocks").Once()
 
+	var relay *SequencerRelay
+	if cfg.SequencerNodeURI != "" {
+		relay = NewSequencerRelay(cfg.SequencerNodeURI, cfg.SequencerRelay)
+	}
+
 	services := []Service{}
 	if _, ok := apis[APIEth]; ok {
 		services = append(services, Service{
 			Name:    APIEth,
-			Service: NewEthEndpoints(cfg, chainID, pool, st, etherman, storage),
+			Service: NewEthEndpoints(cfg, chainID, pool, st, etherman, storage, relay),
 		})
 	}
 
@@ -82,7 +94,7 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocksWrapper, *e
 	if _, ok := apis[APIZKEVM]; ok {
 		services = append(services, Service{
 			Name:    APIZKEVM,
-			Service: NewZKEVMEndpoints(cfg, st, etherman),
+			Service: NewZKEVMEndpoints(cfg, pool, st, etherman, relay),
 		})
 	}
 
@@ -106,7 +118,14 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocksWrapper, *e
 			Service: &Web3Endpoints{},
 		})
 	}
-	server := NewServer(cfg, chainID, pool, st, storage, services)
+
+	if _, ok := apis[APIAdmin]; ok {
+		services = append(services, Service{
+			Name:    APIAdmin,
+			Service: NewAdminEndpoints(cfg.AdminAPIKey, pool),
+		})
+	}
+	server := NewServer(cfg, chainID, pool, st, storage, relay, services)
 
 	go func() {
 		err := server.Start()
@@ -159,6 +178,7 @@ func getSequencerDefaultConfig() Config {
 		MaxLogsCount:                 10000,
 		MaxLogsBlockRange:            10000,
 		MaxNativeBlockHashBlockRange: 60000,
+		AdminAPIKey:                  testAdminAPIKey,
 		WebSockets: WebSocketsConfig{
 			Enabled:   true,
 			Host:      "0.0.0.0",
@@ -210,6 +230,12 @@ func (s *mockedServer) JSONRPCCall(method string, parameters ...interface{}) (ty
 	return client.JSONRPCCall(s.ServerURL, method, parameters...)
 }
 
+func (s *mockedServer) AdminJSONRPCCall(method string, parameters ...interface{}) (types.Response, error) {
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+testAdminAPIKey)
+	return client.JSONRPCCallWithHeaders(s.ServerURL, headers, method, parameters...)
+}
+
 func (s *mockedServer) JSONRPCBatchCall(calls ...client.BatchCall) ([]types.Response, error) {
 	return client.JSONRPCBatchCall(s.ServerURL, calls...)
 }
@@ -3,9 +3,11 @@ package types
 import (
 	"context"
 	"math/big"
+	"net"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,6 +18,7 @@ import (
 // PoolInterface contains the methods required to interact with the tx pool.
 type PoolInterface interface {
 	AddTx(ctx context.Context, tx types.Transaction, ip string) error
+	AddBundle(ctx context.Context, txs []types.Transaction, ip string) (common.Hash, error)
 	GetGasPrices(ctx context.Context) (pool.GasPrices, error)
 	GetNonce(ctx context.Context, address common.Address) (uint64, error)
 	GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error)
@@ -23,6 +26,13 @@ type PoolInterface interface {
 	CountPendingTransactions(ctx context.Context) (uint64, error)
 	GetTxByHash(ctx context.Context, hash common.Hash) (*pool.Transaction, error)
 	CheckPolicy(ctx context.Context, policy pool.PolicyName, address common.Address) (bool, error)
+	CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error)
+	CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error)
+	GetMaxTxValue(ctx context.Context) (*big.Int, error)
+	EstimateEffectiveGasPrice(ctx context.Context, tx types.Transaction) (pool.EffectiveGasPriceEstimation, error)
+	GetReputation(ctx context.Context, subject string) (*pool.Reputation, error)
+	ListReputations(ctx context.Context) ([]pool.Reputation, error)
+	DeleteReputation(ctx context.Context, subject string) error
 }
 
 // StateInterface gathers the methods required to interact with the state.
@@ -35,6 +45,8 @@ type StateInterface interface {
 	GetCode(ctx context.Context, address common.Address, root common.Hash) ([]byte, error)
 	GetL2BlockByHash(ctx context.Context, hash common.Hash, dbTx pgx.Tx) (*types.Block, error)
 	GetL2BlockByNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (*types.Block, error)
+	CheckL2BlockNotPruned(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) error
+	GetPrunedTransactionHashesByL2BlockNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) ([]common.Hash, error)
 	BatchNumberByL2BlockNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (uint64, error)
 	GetL2BlockHashesSince(ctx context.Context, since time.Time, dbTx pgx.Tx) ([]common.Hash, error)
 	GetL2BlockHeaderByNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (*types.Header, error)
@@ -46,9 +58,12 @@ type StateInterface interface {
 	GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetLogs(ctx context.Context, fromBlock uint64, toBlock uint64, addresses []common.Address, topics [][]common.Hash, blockHash *common.Hash, since *time.Time, dbTx pgx.Tx) ([]*types.Log, error)
 	GetNonce(ctx context.Context, address common.Address, root common.Hash) (uint64, error)
+	GetPreconfirmation(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*preconf.Preconfirmation, error)
 	GetStorageAt(ctx context.Context, address common.Address, position *big.Int, root common.Hash) (*big.Int, error)
 	GetSyncingInfo(ctx context.Context, dbTx pgx.Tx) (state.SyncingInfo, error)
+	GetPruneInfo(ctx context.Context, dbTx pgx.Tx) (state.PruneInfo, error)
 	GetTransactionByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Transaction, error)
+	GetTransactionEGPLogByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*state.EffectiveGasPriceLog, error)
 	GetTransactionByL2BlockHashAndIndex(ctx context.Context, blockHash common.Hash, index uint64, dbTx pgx.Tx) (*types.Transaction, error)
 	GetTransactionByL2BlockNumberAndIndex(ctx context.Context, blockNumber uint64, index uint64, dbTx pgx.Tx) (*types.Transaction, error)
 	GetTransactionReceipt(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Receipt, error)
@@ -56,6 +71,7 @@ type StateInterface interface {
 	IsL2BlockVirtualized(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error)
 	ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, l2BlockNumber *uint64, noZKEVMCounters bool, dbTx pgx.Tx) (*runtime.ExecutionResult, error)
 	RegisterNewL2BlockEventHandler(h state.NewL2BlockEventHandler)
+	RegisterPreconfirmationEventHandler(h state.PreconfirmationEventHandler)
 	GetLastVirtualBatchNum(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetLastVerifiedBatch(ctx context.Context, dbTx pgx.Tx) (*state.VerifiedBatch, error)
 	GetLastBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
@@ -7,8 +7,10 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -111,6 +113,15 @@ func (b ArgBig) Hex() string {
 	return string(bb)
 }
 
+// ArgBigPtr returns the pointer of the provided big.Int as an ArgBig, nil if it's nil
+func ArgBigPtr(b *big.Int) *ArgBig {
+	if b == nil {
+		return nil
+	}
+	a := ArgBig(*b)
+	return &a
+}
+
 func decodeToHex(b []byte) ([]byte, error) {
 	str := string(b)
 	str = strings.TrimPrefix(str, "0x")
@@ -668,6 +679,138 @@ func NewLog(l types.Log) Log {
 	}
 }
 
+// EffectiveGasPriceEstimation structure
+type EffectiveGasPriceEstimation struct {
+	Enabled                     bool      `json:"enabled"`
+	GasUsed                     ArgUint64 `json:"gasUsed"`
+	L1GasPrice                  ArgUint64 `json:"l1GasPrice"`
+	L2GasPrice                  ArgUint64 `json:"l2GasPrice"`
+	L2MinGasPrice               ArgUint64 `json:"l2MinGasPrice"`
+	GasPrice                    *ArgBig   `json:"gasPrice"`
+	ZeroBytes                   ArgUint64 `json:"zeroBytes"`
+	NonZeroBytes                ArgUint64 `json:"nonZeroBytes"`
+	BytesCost                   ArgUint64 `json:"bytesCost"`
+	ExecutionCost               ArgUint64 `json:"executionCost"`
+	BreakEvenGasPrice           *ArgBig   `json:"breakEvenGasPrice"`
+	BreakEvenGasPriceWithFactor *ArgBig   `json:"breakEvenGasPriceWithFactor"`
+	EffectiveGasPrice           *ArgBig   `json:"effectiveGasPrice"`
+	EffectivePercentage         ArgUint64 `json:"effectivePercentage"`
+	Sponsored                   bool      `json:"sponsored"`
+	Accepted                    bool      `json:"accepted"`
+	RejectReason                string    `json:"rejectReason,omitempty"`
+}
+
+// NewEffectiveGasPriceEstimation creates a new instance of EffectiveGasPriceEstimation
+func NewEffectiveGasPriceEstimation(e pool.EffectiveGasPriceEstimation) EffectiveGasPriceEstimation {
+	return EffectiveGasPriceEstimation{
+		Enabled:                     e.Enabled,
+		GasUsed:                     ArgUint64(e.GasUsed),
+		L1GasPrice:                  ArgUint64(e.GasPrices.L1GasPrice),
+		L2GasPrice:                  ArgUint64(e.GasPrices.L2GasPrice),
+		L2MinGasPrice:               ArgUint64(e.BreakEven.L2MinGasPrice),
+		GasPrice:                    ArgBigPtr(e.TxGasPrice),
+		ZeroBytes:                   ArgUint64(e.BreakEven.ZeroBytes),
+		NonZeroBytes:                ArgUint64(e.BreakEven.NonZeroBytes),
+		BytesCost:                   ArgUint64(e.BreakEven.BytesCost),
+		ExecutionCost:               ArgUint64(e.BreakEven.ExecutionCost),
+		BreakEvenGasPrice:           ArgBigPtr(e.BreakEven.BreakEvenGasPrice),
+		BreakEvenGasPriceWithFactor: ArgBigPtr(e.BreakEvenGasPriceWithFactor),
+		EffectiveGasPrice:           ArgBigPtr(e.EffectiveGasPrice),
+		EffectivePercentage:         ArgUint64(e.EffectivePercentage),
+		Sponsored:                   e.Sponsored,
+		Accepted:                    e.Accepted,
+		RejectReason:                e.RejectReason,
+	}
+}
+
+// EffectiveGasPriceLog structure
+type EffectiveGasPriceLog struct {
+	Enabled        bool      `json:"enabled"`
+	ValueFinal     *ArgBig   `json:"valueFinal"`
+	ValueFirst     *ArgBig   `json:"valueFirst"`
+	ValueSecond    *ArgBig   `json:"valueSecond"`
+	FinalDeviation *ArgBig   `json:"finalDeviation"`
+	MaxDeviation   *ArgBig   `json:"maxDeviation"`
+	GasUsedFirst   ArgUint64 `json:"gasUsedFirst"`
+	GasUsedSecond  ArgUint64 `json:"gasUsedSecond"`
+	GasPrice       *ArgBig   `json:"gasPrice"`
+	Percentage     ArgUint64 `json:"percentage"`
+	Reprocess      bool      `json:"reprocess"`
+	GasPriceOC     bool      `json:"gasPriceOC"`
+	BalanceOC      bool      `json:"balanceOC"`
+	L1GasPrice     ArgUint64 `json:"l1GasPrice"`
+	L2GasPrice     ArgUint64 `json:"l2GasPrice"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// NewEffectiveGasPriceLog creates a new instance of EffectiveGasPriceLog
+func NewEffectiveGasPriceLog(l state.EffectiveGasPriceLog) EffectiveGasPriceLog {
+	return EffectiveGasPriceLog{
+		Enabled:        l.Enabled,
+		ValueFinal:     ArgBigPtr(l.ValueFinal),
+		ValueFirst:     ArgBigPtr(l.ValueFirst),
+		ValueSecond:    ArgBigPtr(l.ValueSecond),
+		FinalDeviation: ArgBigPtr(l.FinalDeviation),
+		MaxDeviation:   ArgBigPtr(l.MaxDeviation),
+		GasUsedFirst:   ArgUint64(l.GasUsedFirst),
+		GasUsedSecond:  ArgUint64(l.GasUsedSecond),
+		GasPrice:       ArgBigPtr(l.GasPrice),
+		Percentage:     ArgUint64(l.Percentage),
+		Reprocess:      l.Reprocess,
+		GasPriceOC:     l.GasPriceOC,
+		BalanceOC:      l.BalanceOC,
+		L1GasPrice:     ArgUint64(l.L1GasPrice),
+		L2GasPrice:     ArgUint64(l.L2GasPrice),
+		Error:          l.Error,
+	}
+}
+
+// PruneInfo structure
+type PruneInfo struct {
+	LastPrunedBlock ArgUint64 `json:"lastPrunedBlock"`
+	LastPrunedBatch ArgUint64 `json:"lastPrunedBatch"`
+	PrunedAt        time.Time `json:"prunedAt"`
+}
+
+// NewPruneInfo creates a new instance of PruneInfo
+func NewPruneInfo(p state.PruneInfo) PruneInfo {
+	return PruneInfo{
+		LastPrunedBlock: ArgUint64(p.L2BlockNumber),
+		LastPrunedBatch: ArgUint64(p.BatchNumber),
+		PrunedAt:        p.PrunedAt,
+	}
+}
+
+// Reputation structure
+type Reputation struct {
+	Subject     string     `json:"subject"`
+	Offenses    ArgUint64  `json:"offenses"`
+	Invalid     ArgUint64  `json:"invalid"`
+	Reverted    ArgUint64  `json:"reverted"`
+	OOC         ArgUint64  `json:"ooc"`
+	Underpriced ArgUint64  `json:"underpriced"`
+	WindowStart time.Time  `json:"windowStart"`
+	Bans        ArgUint64  `json:"bans"`
+	Banned      bool       `json:"banned"`
+	BannedUntil *time.Time `json:"bannedUntil"`
+}
+
+// NewReputation creates a new instance of Reputation
+func NewReputation(r pool.Reputation) Reputation {
+	return Reputation{
+		Subject:     r.Subject,
+		Offenses:    ArgUint64(r.Offenses()),
+		Invalid:     ArgUint64(r.Invalid),
+		Reverted:    ArgUint64(r.Reverted),
+		OOC:         ArgUint64(r.OOC),
+		Underpriced: ArgUint64(r.Underpriced),
+		WindowStart: r.WindowStart,
+		Bans:        ArgUint64(r.Bans),
+		Banned:      r.IsBanned(time.Now()),
+		BannedUntil: r.BannedUntil,
+	}
+}
+
 // ToBatchNumArg converts a big.Int into a batch number rpc parameter
 func ToBatchNumArg(number *big.Int) string {
 	if number == nil {
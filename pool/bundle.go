@@ -0,0 +1,75 @@
+package pool
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Bundle is an ordered list of pool txs that must be sequenced together and
+// contiguously in the same batch, or not sequenced at all
+type Bundle struct {
+	Hash common.Hash
+	Txs  []Transaction
+}
+
+// BundleHash returns the hash that identifies the bundle of the provided txs,
+// it's the keccak256 of the concatenation of the tx hashes
+func BundleHash(txs []types.Transaction) common.Hash {
+	hashes := make([]byte, 0, len(txs)*common.HashLength)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// AddBundle validates the txs of a bundle and adds them to the pool with the pending state.
+// The txs are not pre-executed because they depend on each other, the zkCounters of the
+// bundle are computed by the sequencer when the bundle is executed as a whole
+func (p *Pool) AddBundle(ctx context.Context, txs []types.Transaction, ip string) (common.Hash, error) {
+	return p.addBundle(ctx, txs, ip, time.Now(), true)
+}
+
+// addBundle validates and adds the txs of a bundle to the pool with the pending state, keeping receivedAt as
+// the time the bundle was received. The rejections are only recorded as offenses when recordOffenses is set
+func (p *Pool) addBundle(ctx context.Context, txs []types.Transaction, ip string, receivedAt time.Time, recordOffenses bool) (common.Hash, error) {
+	if len(txs) == 0 {
+		return common.Hash{}, ErrEmptyBundle
+	}
+	if p.cfg.MaxTxsPerBundle > 0 && uint64(len(txs)) > p.cfg.MaxTxsPerBundle {
+		return common.Hash{}, ErrBundleTooLarge
+	}
+
+	seen := make(map[common.Hash]bool, len(txs))
+	poolTxs := make([]Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if seen[tx.Hash()] {
+			return common.Hash{}, ErrDuplicatedBundleTx
+		}
+		seen[tx.Hash()] = true
+
+		poolTx := NewTransaction(tx, ip, false)
+		poolTx.ReceivedAt = receivedAt
+		if err := p.validateTx(ctx, *poolTx); err != nil {
+			if recordOffenses {
+				p.recordRejection(ctx, tx, ip, err)
+			}
+			return common.Hash{}, err
+		}
+		poolTxs = append(poolTxs, *poolTx)
+	}
+
+	bundleHash := BundleHash(txs)
+	if err := p.storage.AddBundle(ctx, bundleHash, poolTxs); err != nil {
+		return common.Hash{}, err
+	}
+	return bundleHash, nil
+}
+
+// GetNonWIPPendingBundles returns the pending bundles that are not being processed by the sequencer
+func (p *Pool) GetNonWIPPendingBundles(ctx context.Context) ([]Bundle, error) {
+	return p.storage.GetNonWIPPendingBundles(ctx)
+}
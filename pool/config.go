@@ -5,6 +5,14 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/db"
 )
 
+const (
+	// StoragePostgres is the pool storage that keeps the pool in the postgres database configured in DB
+	StoragePostgres = "postgres"
+	// StorageMemory is the pool storage that keeps the pool in memory, it's lost when the node stops and can
+	// only be shared by the components running in the same process
+	StorageMemory = "memory"
+)
+
 // Config is the pool configuration
 type Config struct {
 	// IntervalToRefreshBlockedAddresses is the time it takes to sync the
@@ -20,6 +28,9 @@ type Config struct {
 	// MaxTxDataBytesSize is the max size of the data field of a transaction in bytes
 	MaxTxDataBytesSize int `mapstructure:"MaxTxDataBytesSize"`
 
+	// Storage is where the pool is kept: "postgres" (default) or "memory"
+	Storage string `mapstructure:"Storage"`
+
 	// DB is the database configuration
 	DB db.Config `mapstructure:"DB"`
 
@@ -38,8 +49,75 @@ type Config struct {
 	// GlobalQueue represents the maximum number of non-executable transaction slots for all accounts
 	GlobalQueue uint64 `mapstructure:"GlobalQueue"`
 
+	// PriceBump is the min percentage that the gas price of a tx must be increased over the gas price of
+	// the tx with the same sender and nonce in the pool to replace it
+	PriceBump uint64 `mapstructure:"PriceBump"`
+
 	// EffectiveGasPrice is the config for the effective gas price calculation
 	EffectiveGasPrice EffectiveGasPriceCfg `mapstructure:"EffectiveGasPrice"`
+
+	// MaxTxsPerBundle is the maximum number of transactions allowed in a bundle
+	MaxTxsPerBundle uint64 `mapstructure:"MaxTxsPerBundle"`
+
+	// Sponsorship is the config for the gas sponsorship of the txs allowed by the sponsor policy
+	Sponsorship SponsorshipCfg `mapstructure:"Sponsorship"`
+
+	// Reputation is the config for the spam reputation of the source IPs and senders of the txs
+	Reputation ReputationCfg `mapstructure:"Reputation"`
+
+	// Eviction is the config for the eviction of the queued txs that stay too long in the pool
+	Eviction EvictionCfg `mapstructure:"Eviction"`
+}
+
+// EvictionCfg contains the configuration properties for the eviction of the queued txs
+type EvictionCfg struct {
+	// Enabled is a flag to periodically evict the queued txs (the pending txs with a nonce gap) received more
+	// than TTL ago and, when the queued txs exceed the GlobalQueue, the lowest priced and most nonce gapped ones
+	Enabled bool `mapstructure:"Enabled"`
+
+	// Interval is the time to wait between evictions
+	Interval types.Duration `mapstructure:"Interval"`
+
+	// TTL is the time a queued tx can stay in the pool before being evicted
+	TTL types.Duration `mapstructure:"TTL"`
+}
+
+// ReputationCfg contains the configuration properties for the spam reputation of the source IPs and senders
+type ReputationCfg struct {
+	// Enabled is a flag to count the invalid, reverted, out of counters and underpriced txs of each source IP
+	// and sender, and to limit or ban the ones with too many of them
+	Enabled bool `mapstructure:"Enabled"`
+
+	// Window is the time after which the offenses of a source IP or sender start to be counted again
+	Window types.Duration `mapstructure:"Window"`
+
+	// LimitThreshold is the number of offenses in the window after which the txs of a source IP or sender
+	// are limited to LimitedAccountQueue, 0 means no limit
+	LimitThreshold uint64 `mapstructure:"LimitThreshold"`
+
+	// LimitedAccountQueue is the AccountQueue applied to the txs of the limited source IPs and senders
+	LimitedAccountQueue uint64 `mapstructure:"LimitedAccountQueue"`
+
+	// BanThreshold is the number of offenses in the window after which a source IP or sender is banned,
+	// 0 means no bans
+	BanThreshold uint64 `mapstructure:"BanThreshold"`
+
+	// BanDuration is the duration of the first ban of a source IP or sender, each following ban lasts
+	// twice the previous one
+	BanDuration types.Duration `mapstructure:"BanDuration"`
+
+	// MaxBanDuration is the max duration of a ban
+	MaxBanDuration types.Duration `mapstructure:"MaxBanDuration"`
+}
+
+// SponsorshipCfg contains the configuration properties for the gas sponsorship
+type SponsorshipCfg struct {
+	// Enabled is a flag to sponsor the txs to the contracts or from the senders allowed by the sponsor policy,
+	// sponsored txs skip the min gas price and break even gas price checks
+	Enabled bool `mapstructure:"Enabled"`
+
+	// Period is the time after which the gas used of the sponsor budgets is reset
+	Period types.Duration `mapstructure:"Period"`
 }
 
 // EffectiveGasPriceCfg contains the configuration properties for the effective gas price
@@ -52,8 +52,35 @@ func (e *EffectiveGasPrice) GetTxAndL2GasPrice(txGasPrice *big.Int, l1GasPrice u
 	}
 }
 
+// BreakEvenGasPriceBreakdown contains the inputs and the intermediate values of the break even gas
+// price calculation of a tx
+type BreakEvenGasPriceBreakdown struct {
+	// L2MinGasPrice is the L1 gas price times the L1GasPriceFactor, limited to the min gas price allowed
+	L2MinGasPrice uint64
+	// ZeroBytes is the number of zero bytes of the tx
+	ZeroBytes uint64
+	// NonZeroBytes is the number of non zero bytes of the tx, including the signature and the effective percentage
+	NonZeroBytes uint64
+	// BytesCost is the cost in wei of posting the bytes of the tx to L1
+	BytesCost uint64
+	// ExecutionCost is the cost in wei of the gas used by the tx at the L2 min gas price
+	ExecutionCost uint64
+	// BreakEvenGasPrice is the total cost divided by the gas used and multiplied by the NetProfit
+	BreakEvenGasPrice *big.Int
+}
+
 // CalculateBreakEvenGasPrice calculates the break even gas price for a transaction
 func (e *EffectiveGasPrice) CalculateBreakEvenGasPrice(rawTx []byte, txGasPrice *big.Int, txGasUsed uint64, l1GasPrice uint64) (*big.Int, error) {
+	breakdown, err := e.CalculateBreakEvenGasPriceBreakdown(rawTx, txGasPrice, txGasUsed, l1GasPrice)
+	if err != nil {
+		return nil, err
+	}
+	return breakdown.BreakEvenGasPrice, nil
+}
+
+// CalculateBreakEvenGasPriceBreakdown calculates the break even gas price for a transaction returning
+// the values used in the calculation
+func (e *EffectiveGasPrice) CalculateBreakEvenGasPriceBreakdown(rawTx []byte, txGasPrice *big.Int, txGasUsed uint64, l1GasPrice uint64) (BreakEvenGasPriceBreakdown, error) {
 	const (
 		// constants used in calculation of BreakEvenGasPrice
 		signatureBytesLength           = 65
@@ -62,12 +89,12 @@ func (e *EffectiveGasPrice) CalculateBreakEvenGasPrice(rawTx []byte, txGasPrice
 	)
 
 	if l1GasPrice == 0 {
-		return nil, ErrZeroL1GasPrice
+		return BreakEvenGasPriceBreakdown{}, ErrZeroL1GasPrice
 	}
 
 	if txGasUsed == 0 {
 		// Returns tx.GasPrice as the breakEvenGasPrice
-		return txGasPrice, nil
+		return BreakEvenGasPriceBreakdown{BreakEvenGasPrice: txGasPrice}, nil
 	}
 
 	// Get L2 Min Gas Price
@@ -80,11 +107,19 @@ func (e *EffectiveGasPrice) CalculateBreakEvenGasPrice(rawTx []byte, txGasPrice
 	txNonZeroBytes := uint64(len(rawTx)) - txZeroBytes
 
 	// Calculate BreakEvenGasPrice
-	totalTxPrice := (txGasUsed * l2MinGasPrice) +
-		((constBytesTx+txNonZeroBytes)*e.cfg.ByteGasCost+txZeroBytes*e.cfg.ZeroByteGasCost)*l1GasPrice
+	executionCost := txGasUsed * l2MinGasPrice
+	bytesCost := ((constBytesTx+txNonZeroBytes)*e.cfg.ByteGasCost + txZeroBytes*e.cfg.ZeroByteGasCost) * l1GasPrice
+	totalTxPrice := executionCost + bytesCost
 	breakEvenGasPrice := new(big.Int).SetUint64(uint64(float64(totalTxPrice/txGasUsed) * e.cfg.NetProfit))
 
-	return breakEvenGasPrice, nil
+	return BreakEvenGasPriceBreakdown{
+		L2MinGasPrice:     l2MinGasPrice,
+		ZeroBytes:         txZeroBytes,
+		NonZeroBytes:      constBytesTx + txNonZeroBytes,
+		BytesCost:         bytesCost,
+		ExecutionCost:     executionCost,
+		BreakEvenGasPrice: breakEvenGasPrice,
+	}, nil
 }
 
 // CalculateEffectiveGasPrice calculates the final effective gas price for a tx
@@ -204,6 +204,27 @@ func TestCalculateBreakEvenGasPrice(t *testing.T) {
 	}
 }
 
+func TestCalculateBreakEvenGasPriceBreakdown(t *testing.T) {
+	egp := NewEffectiveGasPrice(egpCfg, minGasPriceAllowed)
+
+	breakdown, err := egp.CalculateBreakEvenGasPriceBreakdown([]byte{1, 0, 2, 0, 3, 0, 4, 0, 5, 0}, new(big.Int).SetUint64(1000), 200, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakEvenGasPriceBreakdown{
+		L2MinGasPrice:     25,
+		ZeroBytes:         5,
+		NonZeroBytes:      71,
+		BytesCost:         115600,
+		ExecutionCost:     5000,
+		BreakEvenGasPrice: new(big.Int).SetUint64(603),
+	}, breakdown)
+
+	// the L2 min gas price is limited to the min gas price allowed
+	breakdown, err = egp.CalculateBreakEvenGasPriceBreakdown([]byte{}, new(big.Int).SetUint64(1000), 200, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(minGasPriceAllowed), breakdown.L2MinGasPrice)
+	assert.Equal(t, uint64(2000), breakdown.ExecutionCost)
+}
+
 func TestCalculateEffectiveGasPrice(t *testing.T) {
 	egp := NewEffectiveGasPrice(egpCfg, minGasPriceAllowed)
 
@@ -85,4 +85,31 @@ var (
 
 	// ErrSenderDisallowedDeploy is returned when deploy transactions are disallowed by policy
 	ErrSenderDisallowedDeploy = errors.New("sender disallowed deploy by policy")
+
+	// ErrCallDisallowed is returned when the function selector of a contract call is not allowed by the call policy
+	ErrCallDisallowed = errors.New("function selector disallowed call by policy")
+
+	// ErrValueDisallowed is returned when the tx value is over the max value per tx and the sender is not allowed
+	// by the value policy
+	ErrValueDisallowed = errors.New("sender disallowed value over max value by policy")
+
+	// ErrIPDisallowedSendTx is returned when the source IP of the tx is not allowed by the ip policy
+	ErrIPDisallowedSendTx = errors.New("ip disallowed send_tx by policy")
+
+	// ErrUnknownIPDisallowedSendTx is returned when the source IP of the tx is unknown and the ip policy
+	// disallows some IPs
+	ErrUnknownIPDisallowedSendTx = errors.New("unknown ip disallowed send_tx by policy")
+
+	// ErrBannedByReputation is returned when the source IP or the sender of the tx is temporarily banned for
+	// submitting too many invalid, reverted, out of counters or underpriced txs
+	ErrBannedByReputation = errors.New("ip or sender temporarily banned by reputation")
+
+	// ErrEmptyBundle is returned if a bundle has no transactions.
+	ErrEmptyBundle = errors.New("empty bundle")
+
+	// ErrBundleTooLarge is returned if a bundle has more transactions than the allowed by MaxTxsPerBundle.
+	ErrBundleTooLarge = errors.New("bundle has too many transactions")
+
+	// ErrDuplicatedBundleTx is returned if the same transaction is included more than once in a bundle.
+	ErrDuplicatedBundleTx = errors.New("duplicated transaction in bundle")
 )
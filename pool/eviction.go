@@ -0,0 +1,169 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StartEvictingTxs starts evicting periodically the queued txs that stay too long in the pool
+// and the ones that exceed the GlobalQueue, if the eviction is enabled
+func (p *Pool) StartEvictingTxs(ctx context.Context) {
+	if !p.cfg.Eviction.Enabled {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.cfg.Eviction.Interval.Duration):
+				if err := p.evictTxs(ctx); err != nil {
+					log.Errorf("failed to evict pool txs: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// evictTxs evicts the queued txs, the pending txs that can't be executed because there is a gap between their
+// nonce and the nonce of their sender, received more than TTL ago and then, if the queued txs still exceed the
+// GlobalQueue, the lowest priced and most nonce gapped ones until they don't. The queued txs are evicted even
+// if the sequencer has loaded them as WIP, it's notified to drop them, see SubscribeEvictedTxs. The executable
+// txs and the txs of the bundles are never evicted
+func (p *Pool) evictTxs(ctx context.Context) error {
+	txs, err := p.storage.GetNonBundlePendingTxs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+
+	nonceGaps, err := p.getNonceGaps(ctx, txs)
+	if err != nil {
+		return err
+	}
+	queued := make([]Transaction, 0, len(nonceGaps))
+	for _, tx := range txs {
+		if _, found := nonceGaps[tx.Hash()]; found {
+			queued = append(queued, tx)
+		}
+	}
+
+	if p.cfg.Eviction.TTL.Duration > 0 {
+		expiredBefore := time.Now().Add(-p.cfg.Eviction.TTL.Duration)
+		expired := make([]Transaction, 0, len(queued))
+		remaining := make([]Transaction, 0, len(queued))
+		for _, tx := range queued {
+			if tx.ReceivedAt.Before(expiredBefore) {
+				expired = append(expired, tx)
+			} else {
+				remaining = append(remaining, tx)
+			}
+		}
+		if err := p.evict(ctx, expired, metrics.EvictionReasonTTL); err != nil {
+			return err
+		}
+		queued = remaining
+	}
+
+	if p.cfg.GlobalQueue == 0 || uint64(len(queued)) <= p.cfg.GlobalQueue {
+		return nil
+	}
+	sortForTrimming(queued, nonceGaps)
+	return p.evict(ctx, queued[:uint64(len(queued))-p.cfg.GlobalQueue], metrics.EvictionReasonGlobalQueue)
+}
+
+// getNonceGaps returns, by tx hash, the nonce gap of the queued txs: how far the nonce of each of them is from
+// the next nonce of its sender, that is the nonce of the sender in the state increased by its pending txs with
+// consecutive nonces. The txs that are not in the returned map are executable
+func (p *Pool) getNonceGaps(ctx context.Context, txs []Transaction) (map[common.Hash]uint64, error) {
+	lastL2Block, err := p.state.GetLastL2Block(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txsBySender := map[common.Address][]Transaction{}
+	for _, tx := range txs {
+		from, err := state.GetSender(tx.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		txsBySender[from] = append(txsBySender[from], tx)
+	}
+
+	nonceGaps := map[common.Hash]uint64{}
+	for from, senderTxs := range txsBySender {
+		nextNonce, err := p.state.GetNonce(ctx, from, lastL2Block.Root())
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(senderTxs, func(i, j int) bool { return senderTxs[i].Nonce() < senderTxs[j].Nonce() })
+		for _, tx := range senderTxs {
+			switch {
+			case tx.Nonce() == nextNonce:
+				nextNonce++
+			case tx.Nonce() > nextNonce:
+				nonceGaps[tx.Hash()] = tx.Nonce() - nextNonce
+			}
+		}
+	}
+	return nonceGaps, nil
+}
+
+// sortForTrimming sorts the txs in the order they are evicted to trim the pool down to the GlobalQueue:
+// the lowest gas price first, then the biggest nonce gap and then the oldest
+func sortForTrimming(txs []Transaction, nonceGaps map[common.Hash]uint64) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		if gasPriceCmp := txs[i].GasPrice().Cmp(txs[j].GasPrice()); gasPriceCmp != 0 {
+			return gasPriceCmp < 0
+		}
+		if gapI, gapJ := nonceGaps[txs[i].Hash()], nonceGaps[txs[j].Hash()]; gapI != gapJ {
+			return gapI > gapJ
+		}
+		return txs[i].ReceivedAt.Before(txs[j].ReceivedAt)
+	})
+}
+
+// evict deletes the txs from the pool, notifying them as evicted, and adds an event to the event log for each of them
+func (p *Pool) evict(ctx context.Context, txs []Transaction, reason metrics.EvictionReasonLabel) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	hashes := make([]common.Hash, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash())
+	}
+	if err := p.storage.DeleteEvictedTxs(ctx, hashes); err != nil {
+		return err
+	}
+	metrics.TxsEvicted(reason, len(txs))
+	log.Infof("%d pool txs evicted by %s", len(txs), reason)
+
+	for _, tx := range txs {
+		event := &event.Event{
+			ReceivedAt:  time.Now(),
+			IPAddress:   tx.IP,
+			Source:      event.Source_Node,
+			Component:   event.Component_Pool,
+			Level:       event.Level_Info,
+			EventID:     event.EventID_PoolTxEvicted,
+			Description: fmt.Sprintf("tx %s evicted by %s, received at %s", tx.Hash().String(), reason, tx.ReceivedAt.String()),
+		}
+
+		err := p.eventLog.LogEvent(ctx, event)
+		if err != nil {
+			log.Errorf("error adding event: %v", err)
+		}
+	}
+	return nil
+}
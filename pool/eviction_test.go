@@ -0,0 +1,182 @@
+package pool_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/event/nileventstorage"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/test/operations"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nonceState is a state that only knows the nonces of the accounts, enough to find the queued txs
+type nonceState struct {
+	nonces map[common.Address]uint64
+}
+
+func (s *nonceState) GetBalance(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (s *nonceState) GetLastL2Block(ctx context.Context, dbTx pgx.Tx) (*ethTypes.Block, error) {
+	return ethTypes.NewBlockWithHeader(&ethTypes.Header{Number: big.NewInt(0)}), nil
+}
+
+func (s *nonceState) GetNonce(ctx context.Context, address common.Address, root common.Hash) (uint64, error) {
+	return s.nonces[address], nil
+}
+
+func (s *nonceState) GetTransactionByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*ethTypes.Transaction, error) {
+	return nil, state.ErrNotFound
+}
+
+func (s *nonceState) PreProcessTransaction(ctx context.Context, tx *ethTypes.Transaction, dbTx pgx.Tx) (*state.ProcessBatchResponse, error) {
+	return nil, state.ErrExecutorNil
+}
+
+// evictionTx is a tx added to the pool to be evicted or kept
+type evictionTx struct {
+	nonce      uint64
+	gasPrice   int64
+	receivedAt time.Time
+	isWIP      bool
+	evicted    bool
+}
+
+// testEviction adds the txs to the pool, evicts them and checks the evicted ones are deleted
+// from the pool and notified, and the others are kept
+func testEviction(t *testing.T, evictionCfg pool.Config, stateNonce uint64, txs []evictionTx) {
+	if storageBackend == pool.StoragePostgres {
+		initOrResetDB(t)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	require.NoError(t, err)
+	auth, err := operations.GetAuth(senderPrivateKey, chainID.Uint64())
+	require.NoError(t, err)
+	st := &nonceState{nonces: map[common.Address]uint64{auth.From: stateNonce}}
+	p := pool.NewPool(evictionCfg, bc, s, st, chainID.Uint64(), event.NewEventLog(event.Config{}, eventStorage))
+
+	evicted := map[common.Hash]bool{}
+	for _, evictionTx := range txs {
+		tx, err := auth.Signer(auth.From, ethTypes.NewTransaction(evictionTx.nonce, common.Address{}, big.NewInt(0), gasLimit, big.NewInt(evictionTx.gasPrice), nil))
+		require.NoError(t, err)
+		poolTx := pool.NewTransaction(*tx, ip, evictionTx.isWIP)
+		poolTx.ReceivedAt = evictionTx.receivedAt
+		require.NoError(t, s.AddTx(ctx, *poolTx))
+		evicted[tx.Hash()] = evictionTx.evicted
+	}
+
+	evictedTxs, err := p.SubscribeEvictedTxs(ctx)
+	require.NoError(t, err)
+	require.NoError(t, p.EvictTxs(ctx))
+
+	notified := map[common.Hash]bool{}
+	for hash, isEvicted := range evicted {
+		_, err := p.GetTxByHash(ctx, hash)
+		if !isEvicted {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.ErrorIs(t, err, pool.ErrNotFound)
+		select {
+		case evictedHash := <-evictedTxs:
+			notified[evictedHash] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("evicted tx not notified")
+		}
+	}
+	for hash := range notified {
+		assert.True(t, evicted[hash], "tx %s notified as evicted", hash.String())
+	}
+}
+
+func Test_EvictTxsByTTL(t *testing.T) {
+	evictionCfg := cfg
+	evictionCfg.GlobalQueue = 0
+	evictionCfg.Eviction = pool.EvictionCfg{
+		Enabled:  true,
+		Interval: cfgTypes.NewDuration(time.Minute),
+		TTL:      cfgTypes.NewDuration(time.Hour),
+	}
+
+	now := time.Now()
+	testEviction(t, evictionCfg, 1, []evictionTx{
+		// the executable txs are never evicted
+		{nonce: 1, gasPrice: gasPrice.Int64(), receivedAt: now.Add(-2 * time.Hour)},
+		{nonce: 2, gasPrice: gasPrice.Int64(), receivedAt: now.Add(-2 * time.Hour), isWIP: true},
+		// the queued txs are evicted when expired, even if the sequencer has loaded them
+		{nonce: 4, gasPrice: gasPrice.Int64(), receivedAt: now.Add(-2 * time.Hour), isWIP: true, evicted: true},
+		{nonce: 5, gasPrice: gasPrice.Int64(), receivedAt: now.Add(-time.Minute)},
+		{nonce: 6, gasPrice: gasPrice.Int64(), receivedAt: now.Add(-61 * time.Minute), evicted: true},
+	})
+}
+
+func Test_EvictTxsByGlobalQueue(t *testing.T) {
+	evictionCfg := cfg
+	evictionCfg.GlobalQueue = 2
+	evictionCfg.Eviction = pool.EvictionCfg{
+		Enabled:  true,
+		Interval: cfgTypes.NewDuration(time.Minute),
+	}
+
+	now := time.Now()
+	testEviction(t, evictionCfg, 0, []evictionTx{
+		// the executable txs don't count against the GlobalQueue, even if they are the cheapest
+		{nonce: 0, gasPrice: 1000000000, receivedAt: now, isWIP: true},
+		{nonce: 1, gasPrice: 1000000000, receivedAt: now},
+		{nonce: 3, gasPrice: 3000000000, receivedAt: now, isWIP: true},
+		{nonce: 4, gasPrice: 2000000000, receivedAt: now, evicted: true},
+		{nonce: 5, gasPrice: 3000000000, receivedAt: now},
+		{nonce: 6, gasPrice: 2000000000, receivedAt: now, isWIP: true, evicted: true},
+	})
+}
+
+func Test_SortForTrimming(t *testing.T) {
+	auth, err := operations.GetAuth(senderPrivateKey, chainID.Uint64())
+	require.NoError(t, err)
+
+	newTx := func(nonce uint64, gasPrice int64, receivedAt time.Time) pool.Transaction {
+		tx, err := auth.Signer(auth.From, ethTypes.NewTransaction(nonce, common.Address{}, big.NewInt(0), gasLimit, big.NewInt(gasPrice), nil))
+		require.NoError(t, err)
+		poolTx := pool.NewTransaction(*tx, ip, false)
+		poolTx.ReceivedAt = receivedAt
+		return *poolTx
+	}
+
+	now := time.Now()
+	expensive := newTx(0, 3000000000, now.Add(-time.Hour))
+	cheapNoGap := newTx(1, 1000000000, now.Add(-time.Hour))
+	cheapBigGap := newTx(2, 1000000000, now)
+	cheapBigGapOlder := newTx(3, 1000000000, now.Add(-time.Minute))
+	medium := newTx(4, 2000000000, now)
+
+	txs := []pool.Transaction{expensive, cheapNoGap, medium, cheapBigGap, cheapBigGapOlder}
+	nonceGaps := map[common.Hash]uint64{
+		cheapBigGap.Hash():      5,
+		cheapBigGapOlder.Hash(): 5,
+		medium.Hash():           10,
+	}
+	pool.SortForTrimming(txs, nonceGaps)
+
+	expected := []common.Hash{cheapBigGapOlder.Hash(), cheapBigGap.Hash(), cheapNoGap.Hash(), medium.Hash(), expensive.Hash()}
+	actual := make([]common.Hash, 0, len(txs))
+	for _, tx := range txs {
+		actual = append(actual, tx.Hash())
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,32 @@
+package pool
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Storage exposes the storage interface to the tests of the pool_test package,
+// so they can run against every storage implementation
+type Storage = storage
+
+// CheckReputation exposes checkReputation to the tests of the pool_test package
+func (p *Pool) CheckReputation(ctx context.Context, ip string, from common.Address) (bool, error) {
+	return p.checkReputation(ctx, ip, from)
+}
+
+// RecordRejection exposes recordRejection to the tests of the pool_test package
+func (p *Pool) RecordRejection(ctx context.Context, tx types.Transaction, ip string, err error) {
+	p.recordRejection(ctx, tx, ip, err)
+}
+
+// EvictTxs exposes evictTxs to the tests of the pool_test package
+func (p *Pool) EvictTxs(ctx context.Context) error {
+	return p.evictTxs(ctx)
+}
+
+// SortForTrimming exposes sortForTrimming to the tests of the pool_test package
+func SortForTrimming(txs []Transaction, nonceGaps map[common.Hash]uint64) {
+	sortForTrimming(txs, nonceGaps)
+}
@@ -3,6 +3,7 @@ package pool
 import (
 	"context"
 	"math/big"
+	"net"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/state"
@@ -13,15 +14,20 @@ import (
 
 type storage interface {
 	AddTx(ctx context.Context, tx Transaction) error
+	AddBundle(ctx context.Context, bundleHash common.Hash, txs []Transaction) error
 	CountTransactionsByStatus(ctx context.Context, status ...TxStatus) (uint64, error)
 	CountTransactionsByFromAndStatus(ctx context.Context, from common.Address, status ...TxStatus) (uint64, error)
 	DeleteTransactionsByHashes(ctx context.Context, hashes []common.Hash) error
+	DeleteEvictedTxs(ctx context.Context, hashes []common.Hash) error
 	GetGasPrices(ctx context.Context) (uint64, uint64, error)
 	GetNonce(ctx context.Context, address common.Address) (uint64, error)
 	GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error)
 	GetTxsByFromAndNonce(ctx context.Context, from common.Address, nonce uint64) ([]Transaction, error)
 	GetTxsByStatus(ctx context.Context, state TxStatus, limit uint64) ([]Transaction, error)
 	GetNonWIPPendingTxs(ctx context.Context) ([]Transaction, error)
+	GetNonBundlePendingTxs(ctx context.Context) ([]Transaction, error)
+	GetNonWIPPendingBundles(ctx context.Context) ([]Bundle, error)
+	GetPendingBundles(ctx context.Context) ([]Bundle, error)
 	IsTxPending(ctx context.Context, hash common.Hash) (bool, error)
 	SetGasPrices(ctx context.Context, l2GasPrice uint64, l1GasPrice uint64) error
 	DeleteGasPricesHistoryOlderThan(ctx context.Context, date time.Time) error
@@ -37,7 +43,10 @@ type storage interface {
 	MarkWIPTxsAsPending(ctx context.Context) error
 	GetAllAddressesBlocked(ctx context.Context) ([]common.Address, error)
 	MinL2GasPriceSince(ctx context.Context, timestamp time.Time) (uint64, error)
+	SubscribePendingTxs(ctx context.Context) (<-chan struct{}, error)
+	SubscribeEvictedTxs(ctx context.Context) (<-chan common.Hash, error)
 	policy
+	reputation
 }
 
 type stateInterface interface {
@@ -49,10 +58,23 @@ type stateInterface interface {
 }
 type policy interface {
 	CheckPolicy(ctx context.Context, policy PolicyName, address common.Address) (bool, error)
-	AddAddressesToPolicy(ctx context.Context, policy PolicyName, addresses []common.Address) error
+	CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error)
+	CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error)
+	GetMaxTxValue(ctx context.Context) (*big.Int, error)
+	AddAddressesToPolicy(ctx context.Context, policy PolicyName, addresses []common.Address, expiresAt *time.Time) error
 	RemoveAddressesFromPolicy(ctx context.Context, policy PolicyName, addresses []common.Address) error
 	ClearPolicy(ctx context.Context, policy PolicyName) error
 	DescribePolicies(ctx context.Context) ([]Policy, error)
 	DescribePolicy(ctx context.Context, name PolicyName) (Policy, error)
 	ListAcl(ctx context.Context, policy PolicyName, query []common.Address) ([]common.Address, error)
+	CheckSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error)
+	ChargeSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error)
+}
+
+type reputation interface {
+	AddOffense(ctx context.Context, subject string, offense Offense, windowStart time.Time) (Reputation, error)
+	BanSubject(ctx context.Context, subject string, until time.Time) error
+	GetReputation(ctx context.Context, subject string) (*Reputation, error)
+	ListReputations(ctx context.Context) ([]Reputation, error)
+	DeleteReputation(ctx context.Context, subject string) error
 }
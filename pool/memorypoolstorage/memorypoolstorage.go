@@ -0,0 +1,646 @@
+package memorypoolstorage
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// memoryTx is a tx stored in the memory pool storage with the fields that are columns in the postgres storage
+type memoryTx struct {
+	pool.Transaction
+	from        common.Address
+	bundleHash  *common.Hash
+	bundleIndex int
+}
+
+// memoryGasPrice is an entry of the gas prices history
+type memoryGasPrice struct {
+	l2GasPrice uint64
+	l1GasPrice uint64
+	timestamp  time.Time
+}
+
+// MemoryPoolStorage is an implementation of the pool storage that keeps the data in memory,
+// the data is lost on restart and can only be shared by the components running in the same process
+type MemoryPoolStorage struct {
+	mutex                sync.RWMutex
+	txs                  map[common.Hash]*memoryTx
+	txsBySender          map[common.Address]map[uint64][]common.Hash
+	gasPrices            []memoryGasPrice
+	blockedAddresses     map[common.Address]struct{}
+	policies             map[pool.PolicyName]bool
+	maxTxValue           *big.Int
+	acl                  map[pool.PolicyName]map[common.Address]*time.Time
+	callPolicies         map[common.Address]bool
+	callRules            map[callRuleKey]*time.Time
+	ipRules              map[string]pool.IPRule
+	sponsorBudgets       map[common.Address]*pool.SponsorBudget
+	reputations          map[string]*pool.Reputation
+	subscriptions        map[chan struct{}]struct{}
+	evictedSubscriptions map[chan common.Hash]struct{}
+}
+
+// NewMemoryPoolStorage creates and initializes an instance of MemoryPoolStorage,
+// the policies are initialized with the same rules set by the pool migrations
+func NewMemoryPoolStorage() *MemoryPoolStorage {
+	return &MemoryPoolStorage{
+		txs:              make(map[common.Hash]*memoryTx),
+		txsBySender:      make(map[common.Address]map[uint64][]common.Hash),
+		blockedAddresses: make(map[common.Address]struct{}),
+		policies: map[pool.PolicyName]bool{
+			pool.SendTx:  false,
+			pool.Deploy:  false,
+			pool.Sponsor: true,
+			pool.Value:   true,
+			pool.IP:      false,
+		},
+		acl:                  make(map[pool.PolicyName]map[common.Address]*time.Time),
+		callPolicies:         make(map[common.Address]bool),
+		callRules:            make(map[callRuleKey]*time.Time),
+		ipRules:              make(map[string]pool.IPRule),
+		sponsorBudgets:       make(map[common.Address]*pool.SponsorBudget),
+		reputations:          make(map[string]*pool.Reputation),
+		subscriptions:        make(map[chan struct{}]struct{}),
+		evictedSubscriptions: make(map[chan common.Hash]struct{}),
+	}
+}
+
+// AddTx adds a transaction to the pool with the provided status
+func (m *MemoryPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) error {
+	from, err := state.GetSender(tx.Transaction)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.addTx(tx, from, nil, 0)
+	return nil
+}
+
+// AddBundle adds all the transactions of a bundle to the pool at once,
+// keeping the position of each transaction inside the bundle
+func (m *MemoryPoolStorage) AddBundle(ctx context.Context, bundleHash common.Hash, txs []pool.Transaction) error {
+	senders := make([]common.Address, 0, len(txs))
+	for _, tx := range txs {
+		from, err := state.GetSender(tx.Transaction)
+		if err != nil {
+			return err
+		}
+		senders = append(senders, from)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, tx := range txs {
+		hash := bundleHash
+		m.addTx(tx, senders[i], &hash, i)
+	}
+	return nil
+}
+
+// addTx adds the tx replacing the one with the same hash, the caller must hold the write lock
+func (m *MemoryPoolStorage) addTx(tx pool.Transaction, from common.Address, bundleHash *common.Hash, bundleIndex int) {
+	hash := tx.Hash()
+	m.deleteTx(hash)
+
+	tx.FailedReason = nil
+	if tx.Sponsor != nil {
+		sponsor := *tx.Sponsor
+		tx.Sponsor = &sponsor
+	}
+	m.txs[hash] = &memoryTx{Transaction: tx, from: from, bundleHash: bundleHash, bundleIndex: bundleIndex}
+
+	nonces, found := m.txsBySender[from]
+	if !found {
+		nonces = make(map[uint64][]common.Hash)
+		m.txsBySender[from] = nonces
+	}
+	nonces[tx.Nonce()] = append(nonces[tx.Nonce()], hash)
+
+	m.notify(tx.Status)
+}
+
+// deleteTx deletes the tx with the provided hash if it exists, the caller must hold the write lock
+func (m *MemoryPoolStorage) deleteTx(hash common.Hash) {
+	tx, found := m.txs[hash]
+	if !found {
+		return
+	}
+	delete(m.txs, hash)
+
+	nonces := m.txsBySender[tx.from]
+	hashes := nonces[tx.Nonce()]
+	for i, h := range hashes {
+		if h == hash {
+			hashes = append(hashes[:i], hashes[i+1:]...)
+			break
+		}
+	}
+	if len(hashes) > 0 {
+		nonces[tx.Nonce()] = hashes
+	} else {
+		delete(nonces, tx.Nonce())
+	}
+	if len(nonces) == 0 {
+		delete(m.txsBySender, tx.from)
+	}
+}
+
+// copyTx returns a copy of the stored tx that can be modified by the caller
+func copyTx(tx *memoryTx) pool.Transaction {
+	poolTx := tx.Transaction
+	if tx.FailedReason != nil {
+		failedReason := *tx.FailedReason
+		poolTx.FailedReason = &failedReason
+	}
+	if tx.Sponsor != nil {
+		sponsor := *tx.Sponsor
+		poolTx.Sponsor = &sponsor
+	}
+	return poolTx
+}
+
+// filterTxs returns the stored txs that match the filter sorted by nonce and gas price, the txs with the same
+// nonce and gas price are sorted by the time they were received. The caller must hold the read lock
+func (m *MemoryPoolStorage) filterTxs(filter func(tx *memoryTx) bool) []*memoryTx {
+	txs := make([]*memoryTx, 0)
+	for _, tx := range m.txs {
+		if filter(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Nonce() != txs[j].Nonce() {
+			return txs[i].Nonce() < txs[j].Nonce()
+		}
+		if cmp := txs[i].GasPrice().Cmp(txs[j].GasPrice()); cmp != 0 {
+			return cmp > 0
+		}
+		return txs[i].ReceivedAt.Before(txs[j].ReceivedAt)
+	})
+	return txs
+}
+
+// hasStatus returns true if the status is one of the provided statuses
+func hasStatus(status pool.TxStatus, statuses []pool.TxStatus) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTxsByStatus returns an array of transactions filtered by status sorted by gas price,
+// if limit = 0, then there is no limit
+func (m *MemoryPoolStorage) GetTxsByStatus(ctx context.Context, status pool.TxStatus, limit uint64) ([]pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool { return tx.Status == status })
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].GasPrice().Cmp(filtered[j].GasPrice()) > 0
+	})
+	if limit > 0 && uint64(len(filtered)) > limit {
+		filtered = filtered[:limit]
+	}
+
+	txs := make([]pool.Transaction, 0, len(filtered))
+	for _, tx := range filtered {
+		txs = append(txs, copyTx(tx))
+	}
+	return txs, nil
+}
+
+// GetNonWIPPendingTxs returns the pending txs that are not WIP and don't belong to a bundle
+func (m *MemoryPoolStorage) GetNonWIPPendingTxs(ctx context.Context) ([]pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool {
+		return !tx.IsWIP && tx.Status == pool.TxStatusPending && tx.bundleHash == nil
+	})
+
+	txs := make([]pool.Transaction, 0, len(filtered))
+	for _, tx := range filtered {
+		txs = append(txs, copyTx(tx))
+	}
+	return txs, nil
+}
+
+// GetNonBundlePendingTxs returns the pending txs that don't belong to a bundle, WIP or not
+func (m *MemoryPoolStorage) GetNonBundlePendingTxs(ctx context.Context) ([]pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool {
+		return tx.Status == pool.TxStatusPending && tx.bundleHash == nil
+	})
+
+	txs := make([]pool.Transaction, 0, len(filtered))
+	for _, tx := range filtered {
+		txs = append(txs, copyTx(tx))
+	}
+	return txs, nil
+}
+
+// GetNonWIPPendingBundles returns the bundles whose transactions are pending and not WIP,
+// the transactions of each bundle are returned in the bundle order
+func (m *MemoryPoolStorage) GetNonWIPPendingBundles(ctx context.Context) ([]pool.Bundle, error) {
+	return m.getPendingBundles(false), nil
+}
+
+// GetPendingBundles returns the bundles whose transactions are pending, WIP or not,
+// the transactions of each bundle are returned in the bundle order
+func (m *MemoryPoolStorage) GetPendingBundles(ctx context.Context) ([]pool.Bundle, error) {
+	return m.getPendingBundles(true), nil
+}
+
+// getPendingBundles returns the bundles whose transactions are pending, the WIP ones only if includeWIP is set
+func (m *MemoryPoolStorage) getPendingBundles(includeWIP bool) []pool.Bundle {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool {
+		return (includeWIP || !tx.IsWIP) && tx.Status == pool.TxStatusPending && tx.bundleHash != nil
+	})
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if !filtered[i].ReceivedAt.Equal(filtered[j].ReceivedAt) {
+			return filtered[i].ReceivedAt.Before(filtered[j].ReceivedAt)
+		}
+		if *filtered[i].bundleHash != *filtered[j].bundleHash {
+			return filtered[i].bundleHash.Hex() < filtered[j].bundleHash.Hex()
+		}
+		return filtered[i].bundleIndex < filtered[j].bundleIndex
+	})
+
+	bundles := []pool.Bundle{}
+	for _, tx := range filtered {
+		if len(bundles) == 0 || bundles[len(bundles)-1].Hash != *tx.bundleHash {
+			bundles = append(bundles, pool.Bundle{Hash: *tx.bundleHash})
+		}
+		bundles[len(bundles)-1].Txs = append(bundles[len(bundles)-1].Txs, copyTx(tx))
+	}
+	return bundles
+}
+
+// GetPendingTxHashesSince returns the pending tx since the given time.
+func (m *MemoryPoolStorage) GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool {
+		return tx.Status == pool.TxStatusPending && !tx.ReceivedAt.Before(since)
+	})
+
+	hashes := make([]common.Hash, 0, len(filtered))
+	for _, tx := range filtered {
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes, nil
+}
+
+// GetTxs gets txs with the lowest nonce
+func (m *MemoryPoolStorage) GetTxs(ctx context.Context, filterStatus pool.TxStatus, minGasPrice, limit uint64) ([]*pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := m.filterTxs(func(tx *memoryTx) bool {
+		return tx.Status == filterStatus && tx.GasPrice().Uint64() >= minGasPrice
+	})
+	if uint64(len(filtered)) > limit {
+		filtered = filtered[:limit]
+	}
+
+	txs := make([]*pool.Transaction, 0, len(filtered))
+	for _, tx := range filtered {
+		poolTx := copyTx(tx)
+		txs = append(txs, &poolTx)
+	}
+	return txs, nil
+}
+
+// CountTransactionsByStatus get number of transactions
+// accordingly to the provided statuses
+func (m *MemoryPoolStorage) CountTransactionsByStatus(ctx context.Context, status ...pool.TxStatus) (uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	counter := uint64(0)
+	for _, tx := range m.txs {
+		if hasStatus(tx.Status, status) {
+			counter++
+		}
+	}
+	return counter, nil
+}
+
+// CountTransactionsByFromAndStatus get number of transactions
+// accordingly to the from address and provided statuses
+func (m *MemoryPoolStorage) CountTransactionsByFromAndStatus(ctx context.Context, from common.Address, status ...pool.TxStatus) (uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	counter := uint64(0)
+	for _, hashes := range m.txsBySender[from] {
+		for _, hash := range hashes {
+			if hasStatus(m.txs[hash].Status, status) {
+				counter++
+			}
+		}
+	}
+	return counter, nil
+}
+
+// UpdateTxStatus updates a transaction status accordingly to the
+// provided status and hash
+func (m *MemoryPoolStorage) UpdateTxStatus(ctx context.Context, updateInfo pool.TxStatusUpdateInfo) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.updateTxStatus(updateInfo)
+	return nil
+}
+
+// UpdateTxsStatus updates transactions status accordingly to the provided status and hashes
+func (m *MemoryPoolStorage) UpdateTxsStatus(ctx context.Context, updateInfos []pool.TxStatusUpdateInfo) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, updateInfo := range updateInfos {
+		m.updateTxStatus(updateInfo)
+	}
+	return nil
+}
+
+// updateTxStatus updates the status of the tx if it exists, the caller must hold the write lock
+func (m *MemoryPoolStorage) updateTxStatus(updateInfo pool.TxStatusUpdateInfo) {
+	tx, found := m.txs[updateInfo.Hash]
+	if !found {
+		return
+	}
+	tx.Status = updateInfo.NewStatus
+	tx.IsWIP = updateInfo.IsWIP
+	if updateInfo.FailedReason != nil {
+		failedReason := *updateInfo.FailedReason
+		tx.FailedReason = &failedReason
+	}
+	m.notify(tx.Status)
+}
+
+// UpdateTxWIPStatus updates a transaction wip status accordingly to the
+// provided WIP status and hash
+func (m *MemoryPoolStorage) UpdateTxWIPStatus(ctx context.Context, hash common.Hash, isWIP bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if tx, found := m.txs[hash]; found {
+		tx.IsWIP = isWIP
+	}
+	return nil
+}
+
+// MarkWIPTxsAsPending updates WIP status to non WIP
+func (m *MemoryPoolStorage) MarkWIPTxsAsPending(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, tx := range m.txs {
+		tx.IsWIP = false
+	}
+	return nil
+}
+
+// DeleteTransactionsByHashes deletes txs by their hashes
+func (m *MemoryPoolStorage) DeleteTransactionsByHashes(ctx context.Context, hashes []common.Hash) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, hash := range hashes {
+		m.deleteTx(hash)
+	}
+	return nil
+}
+
+// DeleteEvictedTxs deletes the txs evicted from the pool and notifies them to the subscriptions
+func (m *MemoryPoolStorage) DeleteEvictedTxs(ctx context.Context, hashes []common.Hash) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, hash := range hashes {
+		if _, found := m.txs[hash]; !found {
+			continue
+		}
+		m.deleteTx(hash)
+		m.notifyEvicted(hash)
+	}
+	return nil
+}
+
+// DeleteTransactionByHash deletes tx by its hash
+func (m *MemoryPoolStorage) DeleteTransactionByHash(ctx context.Context, hash common.Hash) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.deleteTx(hash)
+	return nil
+}
+
+// DeleteFailedTransactionsOlderThan deletes all failed transactions older than the given date
+func (m *MemoryPoolStorage) DeleteFailedTransactionsOlderThan(ctx context.Context, date time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for hash, tx := range m.txs {
+		if tx.Status == pool.TxStatusFailed && tx.ReceivedAt.Before(date) {
+			m.deleteTx(hash)
+		}
+	}
+	return nil
+}
+
+// SetGasPrices sets the latest l2 and l1 gas prices
+func (m *MemoryPoolStorage) SetGasPrices(ctx context.Context, l2GasPrice, l1GasPrice uint64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.gasPrices = append(m.gasPrices, memoryGasPrice{l2GasPrice: l2GasPrice, l1GasPrice: l1GasPrice, timestamp: time.Now().UTC()})
+	return nil
+}
+
+// GetGasPrices returns the latest l2 and l1 gas prices
+func (m *MemoryPoolStorage) GetGasPrices(ctx context.Context) (uint64, uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if len(m.gasPrices) == 0 {
+		return 0, 0, nil
+	}
+	last := m.gasPrices[len(m.gasPrices)-1]
+	return last.l2GasPrice, last.l1GasPrice, nil
+}
+
+// DeleteGasPricesHistoryOlderThan deletes all gas prices older than the given date except the last one
+func (m *MemoryPoolStorage) DeleteGasPricesHistoryOlderThan(ctx context.Context, date time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.gasPrices) == 0 {
+		return nil
+	}
+	gasPrices := make([]memoryGasPrice, 0, len(m.gasPrices))
+	for i, gasPrice := range m.gasPrices {
+		if !gasPrice.timestamp.Before(date) || i == len(m.gasPrices)-1 {
+			gasPrices = append(gasPrices, gasPrice)
+		}
+	}
+	m.gasPrices = gasPrices
+	return nil
+}
+
+// MinL2GasPriceSince returns the min L2 gas price after given timestamp
+func (m *MemoryPoolStorage) MinL2GasPriceSince(ctx context.Context, timestamp time.Time) (uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	gasPrice := uint64(0)
+	for _, gp := range m.gasPrices {
+		if !gp.timestamp.Before(timestamp) && (gasPrice == 0 || gp.l2GasPrice < gasPrice) {
+			gasPrice = gp.l2GasPrice
+		}
+	}
+	if gasPrice == 0 {
+		return 0, state.ErrNotFound
+	}
+	return gasPrice, nil
+}
+
+// IsTxPending determines if the tx associated to the given hash is pending or
+// not.
+func (m *MemoryPoolStorage) IsTxPending(ctx context.Context, hash common.Hash) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tx, found := m.txs[hash]
+	return found && tx.Status == pool.TxStatusPending, nil
+}
+
+// GetTxsByFromAndNonce get all the transactions from the pool with the same from and nonce
+func (m *MemoryPoolStorage) GetTxsByFromAndNonce(ctx context.Context, from common.Address, nonce uint64) ([]pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	hashes := m.txsBySender[from][nonce]
+	txs := make([]pool.Transaction, 0, len(hashes))
+	for _, hash := range hashes {
+		txs = append(txs, copyTx(m.txs[hash]))
+	}
+	return txs, nil
+}
+
+// GetTxFromAddressFromByHash gets tx from address by hash
+func (m *MemoryPoolStorage) GetTxFromAddressFromByHash(ctx context.Context, hash common.Hash) (common.Address, uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tx, found := m.txs[hash]
+	if !found {
+		return common.Address{}, 0, pool.ErrNotFound
+	}
+	return tx.from, tx.Nonce(), nil
+}
+
+// GetNonce gets the nonce to the provided address accordingly to the txs in the pool
+func (m *MemoryPoolStorage) GetNonce(ctx context.Context, address common.Address) (uint64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var nonce *uint64
+	for n, hashes := range m.txsBySender[address] {
+		for _, hash := range hashes {
+			status := m.txs[hash].Status
+			if status != pool.TxStatusPending && status != pool.TxStatusSelected {
+				continue
+			}
+			if nonce == nil || n > *nonce {
+				txNonce := n
+				nonce = &txNonce
+			}
+		}
+	}
+
+	if nonce == nil {
+		return 0, nil
+	}
+	return *nonce + 1, nil
+}
+
+// GetTxByHash gets a transaction in the pool by its hash
+func (m *MemoryPoolStorage) GetTxByHash(ctx context.Context, hash common.Hash) (*pool.Transaction, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tx, found := m.txs[hash]
+	if !found {
+		return nil, pool.ErrNotFound
+	}
+	poolTx := copyTx(tx)
+	return &poolTx, nil
+}
+
+// GetTxZkCountersByHash gets a transaction zkcounters by its hash
+func (m *MemoryPoolStorage) GetTxZkCountersByHash(ctx context.Context, hash common.Hash) (*state.ZKCounters, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tx, found := m.txs[hash]
+	if !found {
+		return nil, pool.ErrNotFound
+	}
+	zkCounters := tx.ZKCounters
+	return &zkCounters, nil
+}
+
+// AddBlockedAddresses blocks the provided addresses
+func (m *MemoryPoolStorage) AddBlockedAddresses(ctx context.Context, addresses []common.Address) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, address := range addresses {
+		m.blockedAddresses[address] = struct{}{}
+	}
+	return nil
+}
+
+// RemoveBlockedAddresses unblocks the provided addresses
+func (m *MemoryPoolStorage) RemoveBlockedAddresses(ctx context.Context, addresses []common.Address) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, address := range addresses {
+		delete(m.blockedAddresses, address)
+	}
+	return nil
+}
+
+// GetAllAddressesBlocked get all addresses blocked
+func (m *MemoryPoolStorage) GetAllAddressesBlocked(ctx context.Context) ([]common.Address, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var addrs []common.Address
+	for address := range m.blockedAddresses {
+		addrs = append(addrs, address)
+	}
+	return addrs, nil
+}
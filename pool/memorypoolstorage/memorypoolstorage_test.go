@@ -0,0 +1,324 @@
+package memorypoolstorage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var chainID = big.NewInt(1000)
+
+func newTestTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, gasPrice int64) pool.Transaction {
+	tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(gasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), key)
+	require.NoError(t, err)
+	return *pool.NewTransaction(*signedTx, "127.0.0.1", false)
+}
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestTxs(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+	key, from := newTestKey(t)
+
+	tx0 := newTestTx(t, key, 0, 10)
+	tx1 := newTestTx(t, key, 1, 10)
+	tx1Replacement := newTestTx(t, key, 1, 20)
+	for _, tx := range []pool.Transaction{tx1, tx0, tx1Replacement} {
+		require.NoError(t, s.AddTx(ctx, tx))
+	}
+
+	// pending txs are sorted by nonce and gas price
+	txs, err := s.GetNonWIPPendingTxs(ctx)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+	assert.Equal(t, []common.Hash{tx0.Hash(), tx1Replacement.Hash(), tx1.Hash()}, []common.Hash{txs[0].Hash(), txs[1].Hash(), txs[2].Hash()})
+
+	sameNonceTxs, err := s.GetTxsByFromAndNonce(ctx, from, 1)
+	require.NoError(t, err)
+	assert.Len(t, sameNonceTxs, 2)
+
+	nonce, err := s.GetNonce(ctx, from)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce)
+
+	// WIP txs aren't pending to be loaded
+	require.NoError(t, s.UpdateTxWIPStatus(ctx, tx0.Hash(), true))
+	txs, err = s.GetNonWIPPendingTxs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+	require.NoError(t, s.MarkWIPTxsAsPending(ctx))
+	txs, err = s.GetNonWIPPendingTxs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, txs, 3)
+
+	// status updates
+	failedReason := "replaced transaction"
+	require.NoError(t, s.UpdateTxsStatus(ctx, []pool.TxStatusUpdateInfo{
+		{Hash: tx0.Hash(), NewStatus: pool.TxStatusSelected},
+		{Hash: tx1.Hash(), NewStatus: pool.TxStatusFailed, FailedReason: &failedReason},
+	}))
+	tx, err := s.GetTxByHash(ctx, tx1.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, pool.TxStatusFailed, tx.Status)
+	assert.Equal(t, failedReason, *tx.FailedReason)
+	pending, err := s.IsTxPending(ctx, tx1.Hash())
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	count, err := s.CountTransactionsByStatus(ctx, pool.TxStatusPending, pool.TxStatusSelected)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+	count, err = s.CountTransactionsByFromAndStatus(ctx, from, pool.TxStatusFailed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	// deletions
+	require.NoError(t, s.DeleteFailedTransactionsOlderThan(ctx, time.Now().Add(time.Second)))
+	_, err = s.GetTxByHash(ctx, tx1.Hash())
+	assert.ErrorIs(t, err, pool.ErrNotFound)
+	require.NoError(t, s.DeleteTransactionsByHashes(ctx, []common.Hash{tx0.Hash(), tx1Replacement.Hash()}))
+	count, err = s.CountTransactionsByStatus(ctx, pool.TxStatusPending, pool.TxStatusSelected, pool.TxStatusFailed)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	nonce, err = s.GetNonce(ctx, from)
+	require.NoError(t, err)
+	assert.Zero(t, nonce)
+}
+
+func TestBundles(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+	key, _ := newTestKey(t)
+
+	txs := []pool.Transaction{newTestTx(t, key, 1, 10), newTestTx(t, key, 0, 10)}
+	bundleHash := common.HexToHash("0x1")
+	require.NoError(t, s.AddBundle(ctx, bundleHash, txs))
+
+	// the bundle txs aren't loaded as regular txs and keep the bundle order
+	pendingTxs, err := s.GetNonWIPPendingTxs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pendingTxs)
+	bundles, err := s.GetNonWIPPendingBundles(ctx)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, bundleHash, bundles[0].Hash)
+	require.Len(t, bundles[0].Txs, 2)
+	assert.Equal(t, txs[0].Hash(), bundles[0].Txs[0].Hash())
+	assert.Equal(t, txs[1].Hash(), bundles[0].Txs[1].Hash())
+
+	// the bundles being processed by the sequencer are still returned as pending bundles
+	for _, tx := range txs {
+		require.NoError(t, s.UpdateTxWIPStatus(ctx, tx.Hash(), true))
+	}
+	bundles, err = s.GetNonWIPPendingBundles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, bundles)
+	bundles, err = s.GetPendingBundles(ctx)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Len(t, bundles[0].Txs, 2)
+}
+
+func TestGasPrices(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+
+	l2GasPrice, l1GasPrice, err := s.GetGasPrices(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, l2GasPrice)
+	assert.Zero(t, l1GasPrice)
+	_, err = s.MinL2GasPriceSince(ctx, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, err, state.ErrNotFound)
+
+	require.NoError(t, s.SetGasPrices(ctx, 1, 10))
+	require.NoError(t, s.SetGasPrices(ctx, 2, 20))
+	l2GasPrice, l1GasPrice, err = s.GetGasPrices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), l2GasPrice)
+	assert.Equal(t, uint64(20), l1GasPrice)
+	min, err := s.MinL2GasPriceSince(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), min)
+
+	// the last gas price is always kept
+	require.NoError(t, s.DeleteGasPricesHistoryOlderThan(ctx, time.Now().Add(time.Minute)))
+	min, err = s.MinL2GasPriceSince(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), min)
+}
+
+func TestPolicies(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+	_, addr := newTestKey(t)
+	_, otherAddr := newTestKey(t)
+
+	// deny lists start empty
+	allow, err := s.CheckPolicy(ctx, pool.SendTx, addr)
+	require.NoError(t, err)
+	assert.True(t, allow)
+
+	require.NoError(t, s.AddAddressesToPolicy(ctx, pool.SendTx, []common.Address{addr}, nil))
+	allow, err = s.CheckPolicy(ctx, pool.SendTx, addr)
+	require.NoError(t, err)
+	assert.False(t, allow)
+
+	// allow lists only allow the addresses in the acl
+	require.NoError(t, s.UpdatePolicy(ctx, pool.SendTx, true))
+	allow, err = s.CheckPolicy(ctx, pool.SendTx, addr)
+	require.NoError(t, err)
+	assert.True(t, allow)
+	allow, err = s.CheckPolicy(ctx, pool.SendTx, otherAddr)
+	require.NoError(t, err)
+	assert.False(t, allow)
+
+	acl, err := s.ListAcl(ctx, pool.SendTx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{addr}, acl)
+	require.NoError(t, s.ClearPolicy(ctx, pool.SendTx))
+	acl, err = s.ListAcl(ctx, pool.SendTx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, acl)
+
+	// sponsor budgets
+	ok, err := s.CheckSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, s.SetSponsorBudget(ctx, []common.Address{addr}, 150))
+	ok, err = s.CheckSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	// the budget is only used when charged
+	ok, err = s.CheckSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.ChargeSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.CheckSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	// the charges can't exceed the budget
+	ok, err = s.ChargeSponsorBudget(ctx, addr, 100, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	budgets, err := s.ListSponsorBudgets(ctx)
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, uint64(100), budgets[0].GasUsed)
+	// the gas used is reset when the period is over
+	ok, err = s.CheckSponsorBudget(ctx, addr, 100, 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.ChargeSponsorBudget(ctx, addr, 100, 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	budgets, err = s.ListSponsorBudgets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), budgets[0].GasUsed)
+}
+
+func TestBlockedAddresses(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+	_, addr := newTestKey(t)
+
+	require.NoError(t, s.AddBlockedAddresses(ctx, []common.Address{addr}))
+	blocked, err := s.GetAllAddressesBlocked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{addr}, blocked)
+
+	require.NoError(t, s.RemoveBlockedAddresses(ctx, []common.Address{addr}))
+	blocked, err = s.GetAllAddressesBlocked(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+}
+
+func TestSubscribePendingTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewMemoryPoolStorage()
+	key, _ := newTestKey(t)
+
+	signals, err := s.SubscribePendingTxs(ctx)
+	require.NoError(t, err)
+
+	tx := newTestTx(t, key, 0, 10)
+	require.NoError(t, s.AddTx(ctx, tx))
+	require.NoError(t, s.UpdateTxStatus(ctx, pool.TxStatusUpdateInfo{Hash: tx.Hash(), NewStatus: pool.TxStatusPending}))
+	// the signals not consumed are coalesced
+	_, ok := <-signals
+	assert.True(t, ok)
+	select {
+	case <-signals:
+		t.Fatal("unexpected signal")
+	default:
+	}
+
+	// non pending statuses aren't signaled
+	require.NoError(t, s.UpdateTxStatus(ctx, pool.TxStatusUpdateInfo{Hash: tx.Hash(), NewStatus: pool.TxStatusSelected}))
+	select {
+	case <-signals:
+		t.Fatal("unexpected signal")
+	default:
+	}
+
+	cancel()
+	for range signals {
+	}
+}
+
+func TestReputations(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryPoolStorage()
+	_, addr := newTestKey(t)
+	subject := addr.String()
+
+	_, err := s.GetReputation(ctx, subject)
+	assert.ErrorIs(t, err, pool.ErrNotFound)
+
+	windowStart := time.Now().Add(-time.Hour)
+	reputation, err := s.AddOffense(ctx, subject, pool.OffenseOOC, windowStart)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), reputation.OOC)
+	reputation, err = s.AddOffense(ctx, subject, pool.OffenseInvalid, windowStart)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), reputation.Offenses())
+
+	// the offenses before the window start are forgotten
+	reputation, err = s.AddOffense(ctx, subject, pool.OffenseReverted, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, pool.Reputation{Subject: subject, Reverted: 1, WindowStart: reputation.WindowStart}, reputation)
+
+	// banning forgets the offenses
+	bannedUntil := time.Now().Add(time.Minute)
+	require.NoError(t, s.BanSubject(ctx, subject, bannedUntil))
+	_, err = s.AddOffense(ctx, "127.0.0.1", pool.OffenseUnderpriced, windowStart)
+	require.NoError(t, err)
+	reputations, err := s.ListReputations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reputations, 2)
+	assert.Equal(t, subject, reputations[0].Subject)
+	assert.True(t, reputations[0].IsBanned(time.Now()))
+	assert.Equal(t, uint64(1), reputations[0].Bans)
+	assert.Zero(t, reputations[0].Offenses())
+
+	require.NoError(t, s.DeleteReputation(ctx, subject))
+	_, err = s.GetReputation(ctx, subject)
+	assert.ErrorIs(t, err, pool.ErrNotFound)
+}
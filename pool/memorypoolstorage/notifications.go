@@ -0,0 +1,77 @@
+package memorypoolstorage
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// evictedTxsBufferSize is the size of the channels the evicted txs are sent to the subscriptions through
+const evictedTxsBufferSize = 100
+
+// SubscribePendingTxs signals the returned channel every time a tx is added as pending or its status changes
+// to pending. The signals are sent without blocking, so all the changes made while the previous signal hasn't
+// been consumed are coalesced in a single signal. The returned channel is closed when the context is done
+func (m *MemoryPoolStorage) SubscribePendingTxs(ctx context.Context) (<-chan struct{}, error) {
+	signals := make(chan struct{}, 1)
+
+	m.mutex.Lock()
+	m.subscriptions[signals] = struct{}{}
+	m.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mutex.Lock()
+		delete(m.subscriptions, signals)
+		m.mutex.Unlock()
+		close(signals)
+	}()
+
+	return signals, nil
+}
+
+// notify signals the subscriptions when the status is pending, the caller must hold the write lock
+func (m *MemoryPoolStorage) notify(status pool.TxStatus) {
+	if status != pool.TxStatusPending {
+		return
+	}
+	for signals := range m.subscriptions {
+		select {
+		case signals <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SubscribeEvictedTxs sends the hash of every tx evicted from the pool to the returned channel.
+// The returned channel is closed when the context is done
+func (m *MemoryPoolStorage) SubscribeEvictedTxs(ctx context.Context) (<-chan common.Hash, error) {
+	hashes := make(chan common.Hash, evictedTxsBufferSize)
+
+	m.mutex.Lock()
+	m.evictedSubscriptions[hashes] = struct{}{}
+	m.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mutex.Lock()
+		delete(m.evictedSubscriptions, hashes)
+		m.mutex.Unlock()
+		close(hashes)
+	}()
+
+	return hashes, nil
+}
+
+// notifyEvicted sends the evicted tx to the subscriptions without blocking, the caller must hold the write lock
+func (m *MemoryPoolStorage) notifyEvicted(hash common.Hash) {
+	for hashes := range m.evictedSubscriptions {
+		select {
+		case hashes <- hash:
+		default:
+			log.Warnf("evicted tx %s not notified, the subscription is full", hash.String())
+		}
+	}
+}
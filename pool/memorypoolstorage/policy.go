@@ -0,0 +1,405 @@
+package memorypoolstorage
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// callRuleKey identifies a rule of the call policy
+type callRuleKey struct {
+	target   common.Address
+	selector pool.Selector
+}
+
+// notExpired returns true if the expiry time of an association with a policy hasn't passed
+func notExpired(expiresAt *time.Time) bool {
+	return expiresAt == nil || expiresAt.After(time.Now())
+}
+
+// CheckPolicy returns the rule for the named policy and address. If the address is associated with the policy, the rule
+// will be the setting for the policy. If the address is no associated with the policy, the rule will be the opposite of
+// the policy setting. The expired associations are ignored.
+func (m *MemoryPoolStorage) CheckPolicy(ctx context.Context, policy pool.PolicyName, address common.Address) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	allow, found := m.policies[policy]
+	if !found {
+		return false, nil
+	}
+	if expiresAt, found := m.acl[policy][address]; found && notExpired(expiresAt) {
+		return allow, nil
+	}
+	return !allow, nil
+}
+
+// CheckCallPolicy returns the rule of the call policy for a call with the calldata to the target contract,
+// see pool.IsCallAllowed. The expired associations are ignored.
+func (m *MemoryPoolStorage) CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	selector, fullSelector := pool.CallSelector(data)
+	allow, listed := m.callPolicies[target]
+	var selectorListed bool
+	for key, expiresAt := range m.callRules {
+		if key.target == target && notExpired(expiresAt) {
+			listed = true
+			selectorListed = selectorListed || key.selector == selector
+		}
+	}
+	return pool.IsCallAllowed(listed, allow, selectorListed, fullSelector), nil
+}
+
+// CheckIPPolicy returns the rule of the ip policy for the source IP. If the IP is in a range associated with the
+// policy, the rule will be the setting for the policy. If not, the rule will be the opposite of the policy setting.
+// The expired associations are ignored. A nil IP is unknown, so it could be in any range: it's only allowed when the
+// policy is deny and there are no ranges associated with it.
+func (m *MemoryPoolStorage) CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	allow := m.policies[pool.IP]
+	for _, rule := range m.ipRules {
+		if notExpired(rule.ExpiresAt) && (ip == nil || rule.Range.Contains(ip)) {
+			if ip == nil {
+				return false, nil
+			}
+			return allow, nil
+		}
+	}
+	return !allow, nil
+}
+
+// GetMaxTxValue returns the max value per tx of the senders not allowed by the value policy, nil if there is no limit
+func (m *MemoryPoolStorage) GetMaxTxValue(ctx context.Context) (*big.Int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.maxTxValue == nil {
+		return nil, nil
+	}
+	return new(big.Int).Set(m.maxTxValue), nil
+}
+
+// SetMaxTxValue sets the max value per tx of the senders not allowed by the value policy, nil removes the limit
+func (m *MemoryPoolStorage) SetMaxTxValue(ctx context.Context, maxValue *big.Int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.maxTxValue = nil
+	if maxValue != nil {
+		m.maxTxValue = new(big.Int).Set(maxValue)
+	}
+	return nil
+}
+
+// UpdatePolicy sets the allow/deny rule for the named policy
+func (m *MemoryPoolStorage) UpdatePolicy(ctx context.Context, policy pool.PolicyName, allow bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, found := m.policies[policy]; found {
+		m.policies[policy] = allow
+	}
+	return nil
+}
+
+// AddAddressesToPolicy adds addresses to the named policy until expiresAt, or with no expiry if expiresAt is nil.
+// The expiry of the addresses already associated with the policy is updated
+func (m *MemoryPoolStorage) AddAddressesToPolicy(ctx context.Context, policy pool.PolicyName, addresses []common.Address, expiresAt *time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	acl, found := m.acl[policy]
+	if !found {
+		acl = make(map[common.Address]*time.Time)
+		m.acl[policy] = acl
+	}
+	for _, address := range addresses {
+		acl[address] = expiresAt
+	}
+	return nil
+}
+
+// RemoveAddressesFromPolicy removes addresses from the named policy
+func (m *MemoryPoolStorage) RemoveAddressesFromPolicy(ctx context.Context, policy pool.PolicyName, addresses []common.Address) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, address := range addresses {
+		delete(m.acl[policy], address)
+	}
+	return nil
+}
+
+// ClearPolicy removes _all_ addresses from the named policy, the call and ip policies also remove
+// all their call and ip rules
+func (m *MemoryPoolStorage) ClearPolicy(ctx context.Context, policy pool.PolicyName) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.acl, policy)
+	switch policy {
+	case pool.Call:
+		m.callPolicies = make(map[common.Address]bool)
+		m.callRules = make(map[callRuleKey]*time.Time)
+	case pool.IP:
+		m.ipRules = make(map[string]pool.IPRule)
+	}
+	return nil
+}
+
+// DescribePolicies return all the policies
+func (m *MemoryPoolStorage) DescribePolicies(ctx context.Context) ([]pool.Policy, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var list []pool.Policy
+	for name := range m.policies {
+		list = append(list, m.describePolicy(name))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// DescribePolicy returns the named policy
+func (m *MemoryPoolStorage) DescribePolicy(ctx context.Context, name pool.PolicyName) (pool.Policy, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, found := m.policies[name]; !found {
+		return pool.Policy{}, pool.ErrNotFound
+	}
+	return m.describePolicy(name), nil
+}
+
+// describePolicy returns the named policy, the caller must hold the mutex
+func (m *MemoryPoolStorage) describePolicy(name pool.PolicyName) pool.Policy {
+	policy := pool.Policy{Name: name, Allow: m.policies[name]}
+	if name == pool.Value && m.maxTxValue != nil {
+		policy.MaxValue = new(big.Int).Set(m.maxTxValue)
+	}
+	return policy
+}
+
+// ListAcl returns a list of the addresses associated with the policy that haven't expired
+func (m *MemoryPoolStorage) ListAcl(ctx context.Context, policy pool.PolicyName, query []common.Address) ([]common.Address, error) {
+	entries, err := m.ListAclEntries(ctx, policy, query)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []common.Address
+	for _, entry := range entries {
+		addresses = append(addresses, entry.Address)
+	}
+	return addresses, nil
+}
+
+// ListAclEntries returns the associations of addresses with the policy that haven't expired,
+// filtered by the query addresses if any
+func (m *MemoryPoolStorage) ListAclEntries(ctx context.Context, policy pool.PolicyName, query []common.Address) ([]pool.Acl, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var entries []pool.Acl
+	addEntry := func(address common.Address) {
+		if expiresAt, found := m.acl[policy][address]; found && notExpired(expiresAt) {
+			entries = append(entries, pool.Acl{PolicyName: policy, Address: address, ExpiresAt: expiresAt})
+		}
+	}
+	if len(query) > 0 {
+		for _, address := range query {
+			addEntry(address)
+		}
+	} else {
+		for address := range m.acl[policy] {
+			addEntry(address)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address.Hex() < entries[j].Address.Hex() })
+	return entries, nil
+}
+
+// UpdateCallPolicy sets the mode of the call policy for the target contracts
+func (m *MemoryPoolStorage) UpdateCallPolicy(ctx context.Context, targets []common.Address, allow bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, target := range targets {
+		m.callPolicies[target] = allow
+	}
+	return nil
+}
+
+// ListCallPolicies returns the modes of the call policy set for the target contracts
+func (m *MemoryPoolStorage) ListCallPolicies(ctx context.Context) ([]pool.CallPolicy, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var policies []pool.CallPolicy
+	for target, allow := range m.callPolicies {
+		policies = append(policies, pool.CallPolicy{Target: target, Allow: allow})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Target.Hex() < policies[j].Target.Hex() })
+	return policies, nil
+}
+
+// AddCallRules adds the function selectors of the target contracts to the call policy,
+// the expiry of the rules that already exist is updated
+func (m *MemoryPoolStorage) AddCallRules(ctx context.Context, rules []pool.CallRule) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, rule := range rules {
+		m.callRules[callRuleKey{rule.Target, rule.Selector}] = rule.ExpiresAt
+	}
+	return nil
+}
+
+// RemoveCallRules removes the function selectors of the target contracts from the call policy
+func (m *MemoryPoolStorage) RemoveCallRules(ctx context.Context, rules []pool.CallRule) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, rule := range rules {
+		delete(m.callRules, callRuleKey{rule.Target, rule.Selector})
+	}
+	return nil
+}
+
+// ListCallRules returns the rules of the call policy that haven't expired
+func (m *MemoryPoolStorage) ListCallRules(ctx context.Context) ([]pool.CallRule, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var rules []pool.CallRule
+	for key, expiresAt := range m.callRules {
+		if notExpired(expiresAt) {
+			rules = append(rules, pool.CallRule{Target: key.target, Selector: key.selector, ExpiresAt: expiresAt})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Target != rules[j].Target {
+			return rules[i].Target.Hex() < rules[j].Target.Hex()
+		}
+		return rules[i].Selector.Hex() < rules[j].Selector.Hex()
+	})
+	return rules, nil
+}
+
+// AddIPRules adds the source IP ranges to the ip policy, the expiry of the rules that already exist is updated
+func (m *MemoryPoolStorage) AddIPRules(ctx context.Context, rules []pool.IPRule) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, rule := range rules {
+		m.ipRules[rule.Range.String()] = rule
+	}
+	return nil
+}
+
+// RemoveIPRules removes the source IP ranges from the ip policy
+func (m *MemoryPoolStorage) RemoveIPRules(ctx context.Context, rules []pool.IPRule) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, rule := range rules {
+		delete(m.ipRules, rule.Range.String())
+	}
+	return nil
+}
+
+// ListIPRules returns the rules of the ip policy that haven't expired
+func (m *MemoryPoolStorage) ListIPRules(ctx context.Context) ([]pool.IPRule, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var rules []pool.IPRule
+	for _, rule := range m.ipRules {
+		if notExpired(rule.ExpiresAt) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Range.String() < rules[j].Range.String() })
+	return rules, nil
+}
+
+// SetSponsorBudget sets the gas budget per period of the addresses allowed by the sponsor policy,
+// the gas used in the current period of the addresses that already have a budget is kept
+func (m *MemoryPoolStorage) SetSponsorBudget(ctx context.Context, addresses []common.Address, gasBudget uint64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, address := range addresses {
+		if budget, found := m.sponsorBudgets[address]; found {
+			budget.GasBudget = gasBudget
+			continue
+		}
+		m.sponsorBudgets[address] = &pool.SponsorBudget{Address: address, GasBudget: gasBudget, PeriodStart: time.Now()}
+	}
+	return nil
+}
+
+// ListSponsorBudgets returns the gas budgets of the addresses allowed by the sponsor policy
+func (m *MemoryPoolStorage) ListSponsorBudgets(ctx context.Context) ([]pool.SponsorBudget, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var budgets []pool.SponsorBudget
+	for _, budget := range m.sponsorBudgets {
+		budgets = append(budgets, *budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Address.Hex() < budgets[j].Address.Hex() })
+	return budgets, nil
+}
+
+// CheckSponsorBudget returns true if the budget left to the sponsor in the current period covers the gas,
+// the gas used is reset when the period is over. It returns false if the sponsor has no budget
+func (m *MemoryPoolStorage) CheckSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	budget, found := m.sponsorBudgets[address]
+	if !found {
+		return false, nil
+	}
+	return sponsorGasUsed(budget, period, time.Now())+gas <= budget.GasBudget, nil
+}
+
+// ChargeSponsorBudget adds the gas to the gas used by the sponsor in the current period if the budget left covers it,
+// the gas used is reset when the period is over. It returns false, without charging the gas, if the budget is exhausted
+func (m *MemoryPoolStorage) ChargeSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	budget, found := m.sponsorBudgets[address]
+	if !found {
+		return false, nil
+	}
+
+	now := time.Now()
+	if sponsorGasUsed(budget, period, now)+gas > budget.GasBudget {
+		return false, nil
+	}
+	if !budget.PeriodStart.Add(period).After(now) {
+		budget.PeriodStart = now
+		budget.GasUsed = 0
+	}
+	budget.GasUsed += gas
+	return true, nil
+}
+
+// sponsorGasUsed returns the gas used by the sponsor in the current period
+func sponsorGasUsed(budget *pool.SponsorBudget, period time.Duration, now time.Time) uint64 {
+	if !budget.PeriodStart.Add(period).After(now) {
+		return 0
+	}
+	return budget.GasUsed
+}
@@ -0,0 +1,105 @@
+package memorypoolstorage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+)
+
+// AddOffense adds the offense to the reputation of the subject and returns the updated reputation.
+// The offenses counted before windowStart are forgotten and a new window is started
+func (m *MemoryPoolStorage) AddOffense(ctx context.Context, subject string, offense pool.Offense, windowStart time.Time) (pool.Reputation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	reputation, found := m.reputations[subject]
+	if !found {
+		reputation = &pool.Reputation{Subject: subject, WindowStart: now}
+		m.reputations[subject] = reputation
+	} else if reputation.WindowStart.Before(windowStart) {
+		resetOffenses(reputation, now)
+	}
+
+	switch offense {
+	case pool.OffenseInvalid:
+		reputation.Invalid++
+	case pool.OffenseReverted:
+		reputation.Reverted++
+	case pool.OffenseOOC:
+		reputation.OOC++
+	case pool.OffenseUnderpriced:
+		reputation.Underpriced++
+	}
+	return *reputation, nil
+}
+
+// BanSubject bans the subject until the provided time, the offenses of the subject are forgotten
+// and a new window is started
+func (m *MemoryPoolStorage) BanSubject(ctx context.Context, subject string, until time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	reputation, found := m.reputations[subject]
+	if !found {
+		return nil
+	}
+	reputation.Bans++
+	reputation.BannedUntil = &until
+	resetOffenses(reputation, time.Now())
+	return nil
+}
+
+// GetReputation returns the reputation of the subject, ErrNotFound if the subject has no offenses
+func (m *MemoryPoolStorage) GetReputation(ctx context.Context, subject string) (*pool.Reputation, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	reputation, found := m.reputations[subject]
+	if !found {
+		return nil, pool.ErrNotFound
+	}
+	r := *reputation
+	return &r, nil
+}
+
+// ListReputations returns the reputations of all the subjects, the banned ones first
+func (m *MemoryPoolStorage) ListReputations(ctx context.Context) ([]pool.Reputation, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	reputations := make([]pool.Reputation, 0, len(m.reputations))
+	for _, reputation := range m.reputations {
+		reputations = append(reputations, *reputation)
+	}
+	sort.Slice(reputations, func(i, j int) bool {
+		bi, bj := reputations[i].BannedUntil, reputations[j].BannedUntil
+		if bi != nil && bj != nil && !bi.Equal(*bj) {
+			return bi.After(*bj)
+		}
+		if (bi == nil) != (bj == nil) {
+			return bi != nil
+		}
+		return reputations[i].Subject < reputations[j].Subject
+	})
+	return reputations, nil
+}
+
+// DeleteReputation forgets the offenses and the bans of the subject
+func (m *MemoryPoolStorage) DeleteReputation(ctx context.Context, subject string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.reputations, subject)
+	return nil
+}
+
+func resetOffenses(reputation *pool.Reputation, windowStart time.Time) {
+	reputation.Invalid = 0
+	reputation.Reverted = 0
+	reputation.OOC = 0
+	reputation.Underpriced = 0
+	reputation.WindowStart = windowStart
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/0xPolygonHermez/zkevm-node/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	prefix          = "pool_"
+	txsEvictedName  = prefix + "txs_evicted"
+	reasonLabelName = "reason"
+)
+
+// EvictionReasonLabel represents the possible values for the
+// `pool_txs_evicted` metric `reason` label.
+type EvictionReasonLabel string
+
+const (
+	// EvictionReasonTTL represents a tx evicted because it stayed in the pool longer than the TTL
+	EvictionReasonTTL EvictionReasonLabel = "ttl"
+	// EvictionReasonGlobalQueue represents a tx evicted to trim the pool down to the GlobalQueue
+	EvictionReasonGlobalQueue EvictionReasonLabel = "global_queue"
+)
+
+// Register the metrics for the pool package.
+func Register() {
+	counterVecs := []metrics.CounterVecOpts{
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: txsEvictedName,
+				Help: "[POOL] number of pending txs evicted from the pool by reason",
+			},
+			Labels: []string{reasonLabelName},
+		},
+	}
+
+	metrics.RegisterCounterVecs(counterVecs...)
+}
+
+// TxsEvicted increments the evicted txs counter vector by the provided count
+// for the given reason.
+func TxsEvicted(reason EvictionReasonLabel, count int) {
+	metrics.CounterVecAdd(txsEvictedName, string(reason), float64(count))
+}
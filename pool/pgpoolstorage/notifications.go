@@ -0,0 +1,88 @@
+package pgpoolstorage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-node/db"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// txsChannel is the postgres channel notified when a tx is added to the pool or its status changes,
+	// the payload of the notification is the status and the hash of the tx separated by a colon
+	txsChannel = "pool_txs"
+	// evictedTxsChannel is the postgres channel notified when a tx is evicted from the pool,
+	// the payload of the notification is the hash of the tx
+	evictedTxsChannel = "pool_evicted_txs"
+	// evictedTxsBufferSize is the size of the channel the evicted txs are sent to the subscriber through
+	evictedTxsBufferSize = 100
+)
+
+// notifyTx notifies the tx status through the txs channel, when e is a db transaction
+// the notification is only delivered if the db transaction is committed
+func notifyTx(ctx context.Context, e execer, hash string, status pool.TxStatus) error {
+	_, err := e.Exec(ctx, "SELECT pg_notify($1, $2)", txsChannel, string(status)+":"+hash)
+	return err
+}
+
+// SubscribePendingTxs listens to the txs channel in a dedicated connection and signals the returned channel every
+// time a tx is added as pending or its status changes to pending. The signals are sent without blocking, so all the
+// notifications received while the previous signal hasn't been consumed are coalesced in a single signal. The
+// returned channel is closed when the connection drops or the context is done
+func (p *PostgresPoolStorage) SubscribePendingTxs(ctx context.Context) (<-chan struct{}, error) {
+	notifications, err := db.Listen(ctx, p.db, txsChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	signals := make(chan struct{}, 1)
+	go func() {
+		defer close(signals)
+
+		for notification := range notifications {
+			status, _, _ := strings.Cut(notification.Payload, ":")
+			if pool.TxStatus(status) != pool.TxStatusPending {
+				continue
+			}
+			select {
+			case signals <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return signals, nil
+}
+
+// notifyEvictedTx notifies the evicted tx through the evicted txs channel
+func notifyEvictedTx(ctx context.Context, e execer, hash string) error {
+	_, err := e.Exec(ctx, "SELECT pg_notify($1, $2)", evictedTxsChannel, hash)
+	return err
+}
+
+// SubscribeEvictedTxs listens to the evicted txs channel in a dedicated connection and sends the hash of
+// every tx evicted from the pool to the returned channel. The returned channel is closed when the connection
+// drops or the context is done
+func (p *PostgresPoolStorage) SubscribeEvictedTxs(ctx context.Context) (<-chan common.Hash, error) {
+	notifications, err := db.Listen(ctx, p.db, evictedTxsChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(chan common.Hash, evictedTxsBufferSize)
+	go func() {
+		defer close(hashes)
+
+		for notification := range notifications {
+			select {
+			case hashes <- common.HexToHash(notification.Payload):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return hashes, nil
+}
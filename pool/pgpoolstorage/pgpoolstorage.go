@@ -8,14 +8,21 @@ import (
 
 	"github.com/0xPolygonHermez/zkevm-node/db"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// execer is implemented by both the db pool and a db transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
 // PostgresPoolStorage is an implementation of the Pool interface
 // that uses a postgres database to store the data
 type PostgresPoolStorage struct {
@@ -36,6 +43,32 @@ func NewPostgresPoolStorage(cfg db.Config) (*PostgresPoolStorage, error) {
 
 // AddTx adds a transaction to the pool table with the provided status
 func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) error {
+	return p.addTx(ctx, p.db, tx, nil, nil)
+}
+
+// AddBundle adds all the transactions of a bundle to the pool table in a single db
+// transaction, keeping the position of each transaction inside the bundle
+func (p *PostgresPoolStorage) AddBundle(ctx context.Context, bundleHash common.Hash, txs []pool.Transaction) error {
+	dbTx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	hash := bundleHash.Hex()
+	for i, tx := range txs {
+		index := i
+		if err := p.addTx(ctx, dbTx, tx, &hash, &index); err != nil {
+			if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+				log.Errorf("failed to rollback dbTx when adding bundle %s that gave err: %v. Rollback err: %v", hash, err, rollbackErr)
+			}
+			return err
+		}
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+func (p *PostgresPoolStorage) addTx(ctx context.Context, e execer, tx pool.Transaction, bundleHash *string, bundleIndex *int) error {
 	hash := tx.Hash().Hex()
 
 	b, err := tx.MarshalBinary()
@@ -74,10 +107,13 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 			from_address,
 			is_wip,
 			ip,
-			failed_reason
+			failed_reason,
+			bundle_hash,
+			bundle_index,
+			sponsor
 		) 
 		VALUES 
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NULL)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NULL, $19, $20, $21)
 			ON CONFLICT (hash) DO UPDATE SET 
 			encoded = $2,
 			decoded = $3,
@@ -96,7 +132,10 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 			from_address = $16,
 			is_wip = $17,
 			ip = $18,
-			failed_reason = NULL
+			failed_reason = NULL,
+			bundle_hash = $19,
+			bundle_index = $20,
+			sponsor = $21
 	`
 
 	// Get FromAddress from the JSON data
@@ -106,7 +145,13 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 	}
 	fromAddress := data.String()
 
-	if _, err := p.db.Exec(ctx, sql,
+	var sponsor *string
+	if tx.Sponsor != nil {
+		sponsorAddress := tx.Sponsor.Hex()
+		sponsor = &sponsorAddress
+	}
+
+	if _, err := e.Exec(ctx, sql,
 		hash,
 		encoded,
 		decoded,
@@ -124,10 +169,13 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 		tx.ReceivedAt,
 		fromAddress,
 		tx.IsWIP,
-		tx.IP); err != nil {
+		tx.IP,
+		bundleHash,
+		bundleIndex,
+		sponsor); err != nil {
 		return err
 	}
-	return nil
+	return notifyTx(ctx, e, hash, tx.Status)
 }
 
 // GetTxsByStatus returns an array of transactions filtered by status
@@ -141,11 +189,11 @@ func (p *PostgresPoolStorage) GetTxsByStatus(ctx context.Context, status pool.Tx
 	)
 	if limit == 0 {
 		sql = `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, used_poseidon_paddings, used_mem_aligns,
-				used_arithmetics, used_binaries, used_steps, failed_reason FROM pool.transaction WHERE status = $1 ORDER BY gas_price DESC`
+				used_arithmetics, used_binaries, used_steps, failed_reason, sponsor FROM pool.transaction WHERE status = $1 ORDER BY gas_price DESC`
 		rows, err = p.db.Query(ctx, sql, status.String())
 	} else {
 		sql = `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, used_poseidon_paddings, used_mem_aligns,
-				used_arithmetics, used_binaries, used_steps, failed_reason FROM pool.transaction WHERE status = $1 ORDER BY gas_price DESC LIMIT $2`
+				used_arithmetics, used_binaries, used_steps, failed_reason, sponsor FROM pool.transaction WHERE status = $1 ORDER BY gas_price DESC LIMIT $2`
 		rows, err = p.db.Query(ctx, sql, status.String(), limit)
 	}
 	if err != nil {
@@ -174,7 +222,7 @@ func (p *PostgresPoolStorage) GetNonWIPPendingTxs(ctx context.Context) ([]pool.T
 	)
 
 	sql = `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, used_poseidon_paddings, used_mem_aligns,
-		used_arithmetics, used_binaries, used_steps, failed_reason FROM pool.transaction WHERE is_wip IS FALSE and status = $1`
+		used_arithmetics, used_binaries, used_steps, failed_reason, sponsor FROM pool.transaction WHERE is_wip IS FALSE and status = $1 and bundle_hash IS NULL`
 	rows, err = p.db.Query(ctx, sql, pool.TxStatusPending)
 
 	if err != nil {
@@ -194,6 +242,68 @@ func (p *PostgresPoolStorage) GetNonWIPPendingTxs(ctx context.Context) ([]pool.T
 	return txs, nil
 }
 
+// GetNonBundlePendingTxs returns the pending txs that don't belong to a bundle, WIP or not
+func (p *PostgresPoolStorage) GetNonBundlePendingTxs(ctx context.Context) ([]pool.Transaction, error) {
+	sql := `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, used_poseidon_paddings, used_mem_aligns,
+		used_arithmetics, used_binaries, used_steps, failed_reason, sponsor FROM pool.transaction WHERE status = $1 and bundle_hash IS NULL`
+	rows, err := p.db.Query(ctx, sql, pool.TxStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]pool.Transaction, 0, len(rows.RawValues()))
+	for rows.Next() {
+		tx, err := scanTx(rows)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, *tx)
+	}
+
+	return txs, nil
+}
+
+// GetNonWIPPendingBundles returns the bundles whose transactions are pending and not WIP,
+// the transactions of each bundle are returned in the bundle order
+func (p *PostgresPoolStorage) GetNonWIPPendingBundles(ctx context.Context) ([]pool.Bundle, error) {
+	return p.getPendingBundles(ctx, false)
+}
+
+// GetPendingBundles returns the bundles whose transactions are pending, WIP or not,
+// the transactions of each bundle are returned in the bundle order
+func (p *PostgresPoolStorage) GetPendingBundles(ctx context.Context) ([]pool.Bundle, error) {
+	return p.getPendingBundles(ctx, true)
+}
+
+// getPendingBundles returns the bundles whose transactions are pending, the WIP ones only if includeWIP is set
+func (p *PostgresPoolStorage) getPendingBundles(ctx context.Context, includeWIP bool) ([]pool.Bundle, error) {
+	const sql = `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, used_poseidon_paddings, used_mem_aligns,
+		used_arithmetics, used_binaries, used_steps, failed_reason, sponsor, bundle_hash FROM pool.transaction
+		WHERE ($2 OR is_wip IS FALSE) and status = $1 and bundle_hash IS NOT NULL ORDER BY received_at, bundle_hash, bundle_index`
+	rows, err := p.db.Query(ctx, sql, pool.TxStatusPending, includeWIP)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bundles := []pool.Bundle{}
+	for rows.Next() {
+		var bundleHash string
+		tx, err := scanTx(rows, &bundleHash)
+		if err != nil {
+			return nil, err
+		}
+		hash := common.HexToHash(bundleHash)
+		if len(bundles) == 0 || bundles[len(bundles)-1].Hash != hash {
+			bundles = append(bundles, pool.Bundle{Hash: hash})
+		}
+		bundles[len(bundles)-1].Txs = append(bundles[len(bundles)-1].Txs, *tx)
+	}
+
+	return bundles, nil
+}
+
 // GetPendingTxHashesSince returns the pending tx since the given time.
 func (p *PostgresPoolStorage) GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error) {
 	sql := "SELECT hash FROM pool.transaction WHERE status = $1 AND received_at >= $2"
@@ -388,7 +498,7 @@ func (p *PostgresPoolStorage) UpdateTxStatus(ctx context.Context, updateInfo poo
 		return err
 	}
 
-	return nil
+	return notifyTx(ctx, p.db, updateInfo.Hash.Hex(), updateInfo.NewStatus)
 }
 
 // UpdateTxsStatus updates transactions status accordingly to the provided status and hashes
@@ -415,6 +525,39 @@ func (p *PostgresPoolStorage) DeleteTransactionsByHashes(ctx context.Context, ha
 	return nil
 }
 
+// DeleteEvictedTxs deletes the txs evicted from the pool and notifies them through the evicted txs channel,
+// the notifications are only delivered if the txs are deleted
+func (p *PostgresPoolStorage) DeleteEvictedTxs(ctx context.Context, hashes []common.Hash) error {
+	dbTx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	hh := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		hh = append(hh, h.Hex())
+	}
+	err = func() error {
+		if _, err := dbTx.Exec(ctx, "DELETE FROM pool.transaction WHERE hash = ANY ($1)", hh); err != nil {
+			return err
+		}
+		for _, h := range hh {
+			if err := notifyEvictedTx(ctx, dbTx, h); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+			log.Errorf("failed to rollback dbTx when deleting evicted txs that gave err: %v. Rollback err: %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	return dbTx.Commit(ctx)
+}
+
 // DeleteFailedTransactionsOlderThan deletes all failed transactions older than the given date
 func (p *PostgresPoolStorage) DeleteFailedTransactionsOlderThan(ctx context.Context, date time.Time) error {
 	sql := `DELETE FROM pool.transaction WHERE status = 'failed' and received_at < $1`
@@ -504,7 +647,7 @@ func (p *PostgresPoolStorage) IsTxPending(ctx context.Context, hash common.Hash)
 // GetTxsByFromAndNonce get all the transactions from the pool with the same from and nonce
 func (p *PostgresPoolStorage) GetTxsByFromAndNonce(ctx context.Context, from common.Address, nonce uint64) ([]pool.Transaction, error) {
 	sql := `SELECT encoded, status, received_at, is_wip, ip, cumulative_gas_used, used_keccak_hashes, used_poseidon_hashes, 
-				   used_poseidon_paddings, used_mem_aligns,	used_arithmetics, used_binaries, used_steps, failed_reason
+				   used_poseidon_paddings, used_mem_aligns,	used_arithmetics, used_binaries, used_steps, failed_reason, sponsor
 	          FROM pool.transaction
 			 WHERE from_address = $1
 			   AND nonce = $2`
@@ -621,7 +764,7 @@ func (p *PostgresPoolStorage) GetTxByHash(ctx context.Context, hash common.Hash)
 	return poolTx, nil
 }
 
-func scanTx(rows pgx.Rows) (*pool.Transaction, error) {
+func scanTx(rows pgx.Rows, extraDest ...interface{}) (*pool.Transaction, error) {
 	var (
 		encoded, status, ip  string
 		receivedAt           time.Time
@@ -635,10 +778,12 @@ func scanTx(rows pgx.Rows) (*pool.Transaction, error) {
 		usedBinaries         uint32
 		usedSteps            uint32
 		failedReason         *string
+		sponsor              *string
 	)
 
-	if err := rows.Scan(&encoded, &status, &receivedAt, &isWIP, &ip, &cumulativeGasUsed, &usedKeccakHashes, &usedPoseidonHashes,
-		&usedPoseidonPaddings, &usedMemAligns, &usedArithmetics, &usedBinaries, &usedSteps, &failedReason); err != nil {
+	dest := []interface{}{&encoded, &status, &receivedAt, &isWIP, &ip, &cumulativeGasUsed, &usedKeccakHashes, &usedPoseidonHashes,
+		&usedPoseidonPaddings, &usedMemAligns, &usedArithmetics, &usedBinaries, &usedSteps, &failedReason, &sponsor}
+	if err := rows.Scan(append(dest, extraDest...)...); err != nil {
 		return nil, err
 	}
 
@@ -666,6 +811,10 @@ func scanTx(rows pgx.Rows) (*pool.Transaction, error) {
 	tx.ZKCounters.UsedBinaries = usedBinaries
 	tx.ZKCounters.UsedSteps = usedSteps
 	tx.FailedReason = failedReason
+	if sponsor != nil {
+		sponsorAddress := common.HexToAddress(*sponsor)
+		tx.Sponsor = &sponsorAddress
+	}
 
 	return tx, nil
 }
@@ -716,6 +865,28 @@ func (p *PostgresPoolStorage) UpdateTxWIPStatus(ctx context.Context, hash common
 	return nil
 }
 
+// AddBlockedAddresses blocks the provided addresses
+func (p *PostgresPoolStorage) AddBlockedAddresses(ctx context.Context, addresses []common.Address) error {
+	sql := "INSERT INTO pool.blocked(addr) VALUES ($1) ON CONFLICT DO NOTHING"
+	for _, address := range addresses {
+		if _, err := p.db.Exec(ctx, sql, address.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveBlockedAddresses unblocks the provided addresses
+func (p *PostgresPoolStorage) RemoveBlockedAddresses(ctx context.Context, addresses []common.Address) error {
+	sql := "DELETE FROM pool.blocked WHERE addr = $1"
+	for _, address := range addresses {
+		if _, err := p.db.Exec(ctx, sql, address.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetAllAddressesBlocked get all addresses blocked
 func (p *PostgresPoolStorage) GetAllAddressesBlocked(ctx context.Context) ([]common.Address, error) {
 	sql := `SELECT addr FROM pool.blocked`
@@ -3,8 +3,9 @@ package pgpoolstorage
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strings"
+	"math/big"
+	"net"
+	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/ethereum/go-ethereum/common"
@@ -13,7 +14,7 @@ import (
 
 // CheckPolicy returns the rule for the named policy and address. If the address is associated with the policy, the rule
 // will be the setting for the policy. If the address is no associated with the policy, the rule will be the opposite of
-// the policy setting.
+// the policy setting. The expired associations are ignored.
 func (p *PostgresPoolStorage) CheckPolicy(ctx context.Context, policy pool.PolicyName, address common.Address) (bool, error) {
 	sql := `SELECT 
 				CASE WHEN a.address is null THEN 
@@ -25,6 +26,7 @@ func (p *PostgresPoolStorage) CheckPolicy(ctx context.Context, policy pool.Polic
 				LEFT JOIN pool.acl a 
 					ON p.name = a.policy 
 					AND a.address = $1 
+					AND (a.expires_at IS NULL OR a.expires_at > NOW())
 			WHERE p.name = $2`
 
 	rows, err := p.db.Query(ctx, sql, address.Hex(), policy)
@@ -58,9 +60,10 @@ func (p *PostgresPoolStorage) UpdatePolicy(ctx context.Context, policy pool.Poli
 	return nil
 }
 
-// AddAddressesToPolicy adds addresses to the named policy
-func (p *PostgresPoolStorage) AddAddressesToPolicy(ctx context.Context, policy pool.PolicyName, addresses []common.Address) error {
-	sql := "INSERT INTO pool.acl (policy, address) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+// AddAddressesToPolicy adds addresses to the named policy until expiresAt, or with no expiry if expiresAt is nil.
+// The expiry of the addresses already associated with the policy is updated
+func (p *PostgresPoolStorage) AddAddressesToPolicy(ctx context.Context, policy pool.PolicyName, addresses []common.Address, expiresAt *time.Time) error {
+	sql := "INSERT INTO pool.acl (policy, address, expires_at) VALUES ($1, $2, $3) ON CONFLICT (address, policy) DO UPDATE SET expires_at = $3"
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return err
@@ -70,7 +73,7 @@ func (p *PostgresPoolStorage) AddAddressesToPolicy(ctx context.Context, policy p
 	}(tx, ctx)
 
 	for _, a := range addresses {
-		_, err = tx.Exec(ctx, sql, policy, a.Hex())
+		_, err = tx.Exec(ctx, sql, policy, a.Hex(), expiresAt)
 		if err != nil {
 			return err
 		}
@@ -106,19 +109,29 @@ func (p *PostgresPoolStorage) RemoveAddressesFromPolicy(ctx context.Context, pol
 	return nil
 }
 
-// ClearPolicy removes _all_ addresses from the named policy
+// ClearPolicy removes _all_ addresses from the named policy, the call and ip policies also remove
+// all their call and ip rules
 func (p *PostgresPoolStorage) ClearPolicy(ctx context.Context, policy pool.PolicyName) error {
 	sql := "DELETE FROM pool.acl WHERE policy = $1"
 	_, err := p.db.Exec(ctx, sql, policy)
 	if err != nil {
 		return err
 	}
-	return nil
+	switch policy {
+	case pool.Call:
+		_, err = p.db.Exec(ctx, "DELETE FROM pool.call_acl")
+		if err == nil {
+			_, err = p.db.Exec(ctx, "DELETE FROM pool.call_policy")
+		}
+	case pool.IP:
+		_, err = p.db.Exec(ctx, "DELETE FROM pool.ip_acl")
+	}
+	return err
 }
 
 // DescribePolicies return all the policies
 func (p *PostgresPoolStorage) DescribePolicies(ctx context.Context) ([]pool.Policy, error) {
-	sql := "SELECT name, allow FROM pool.policy"
+	sql := "SELECT name, allow, max_value::TEXT FROM pool.policy"
 	rows, err := p.db.Query(ctx, sql)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -133,14 +146,16 @@ func (p *PostgresPoolStorage) DescribePolicies(ctx context.Context) ([]pool.Poli
 	for rows.Next() {
 		var name string
 		var allow bool
-		err = rows.Scan(&name, &allow)
+		var maxValue *string
+		err = rows.Scan(&name, &allow, &maxValue)
 		if err != nil {
 			return nil, err
 		}
 		if pool.IsPolicy(name) { // skip unknown
 			p := pool.Policy{
-				Name:  pool.PolicyName(name),
-				Allow: allow,
+				Name:     pool.PolicyName(name),
+				Allow:    allow,
+				MaxValue: parseMaxValue(maxValue),
 			}
 			list = append(list, p)
 		}
@@ -150,36 +165,55 @@ func (p *PostgresPoolStorage) DescribePolicies(ctx context.Context) ([]pool.Poli
 
 // DescribePolicy returns the named policy
 func (p *PostgresPoolStorage) DescribePolicy(ctx context.Context, name pool.PolicyName) (pool.Policy, error) {
-	sql := "SELECT name, allow FROM pool.policy WHERE name = $1 LIMIT 1"
+	sql := "SELECT name, allow, max_value::TEXT FROM pool.policy WHERE name = $1 LIMIT 1"
 	row := p.db.QueryRow(ctx, sql, name)
 	var (
-		pName string
-		allow bool
+		pName    string
+		allow    bool
+		maxValue *string
 	)
-	err := row.Scan(&pName, &allow)
+	err := row.Scan(&pName, &allow, &maxValue)
 	if err != nil {
 		return pool.Policy{}, err
 	}
 	return pool.Policy{
-		Name:  pool.PolicyName(pName),
-		Allow: allow,
+		Name:     pool.PolicyName(pName),
+		Allow:    allow,
+		MaxValue: parseMaxValue(maxValue),
 	}, nil
 }
 
-// ListAcl returns a list of the addresses associated with the policy
+// ListAcl returns a list of the addresses associated with the policy that haven't expired
 func (p *PostgresPoolStorage) ListAcl(
 	ctx context.Context, policy pool.PolicyName, query []common.Address) ([]common.Address, error) {
-	sql := "SELECT address FROM pool.acl WHERE policy = $1"
+	entries, err := p.ListAclEntries(ctx, policy, query)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []common.Address
+	for _, entry := range entries {
+		addresses = append(addresses, entry.Address)
+	}
+	return addresses, nil
+}
+
+// ListAclEntries returns the associations of addresses with the policy that haven't expired,
+// filtered by the query addresses if any
+func (p *PostgresPoolStorage) ListAclEntries(ctx context.Context, policy pool.PolicyName, query []common.Address) ([]pool.Acl, error) {
+	sql := "SELECT address, expires_at FROM pool.acl WHERE policy = $1 AND (expires_at IS NULL OR expires_at > NOW())"
+	args := []interface{}{string(policy)}
 
 	if len(query) > 0 {
 		var addrs []string
 		for _, a := range query {
 			addrs = append(addrs, a.Hex())
 		}
-		sql = sql + fmt.Sprintf(" IN (%v)", strings.Join(addrs, ","))
+		sql += " AND address = ANY($2)"
+		args = append(args, addrs)
 	}
+	sql += " ORDER BY address"
 
-	rows, err := p.db.Query(ctx, sql, string(policy))
+	rows, err := p.db.Query(ctx, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -189,14 +223,378 @@ func (p *PostgresPoolStorage) ListAcl(
 	}
 	defer rows.Close()
 
-	var addresses []common.Address
+	var entries []pool.Acl
 	for rows.Next() {
 		var addr string
-		err = rows.Scan(&addr)
+		entry := pool.Acl{PolicyName: policy}
+		err = rows.Scan(&addr, &entry.ExpiresAt)
 		if err != nil {
 			return nil, err
 		}
-		addresses = append(addresses, common.HexToAddress(addr))
+		entry.Address = common.HexToAddress(addr)
+		entries = append(entries, entry)
 	}
-	return addresses, nil
+	return entries, nil
+}
+
+// SetSponsorBudget sets the gas budget per period of the addresses allowed by the sponsor policy,
+// the gas used in the current period of the addresses that already have a budget is kept
+func (p *PostgresPoolStorage) SetSponsorBudget(ctx context.Context, addresses []common.Address, gasBudget uint64) error {
+	sql := `INSERT INTO pool.sponsor_budget (address, gas_budget, gas_used, period_start) VALUES ($1, $2, 0, NOW())
+			ON CONFLICT (address) DO UPDATE SET gas_budget = $2`
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, a := range addresses {
+		_, err = tx.Exec(ctx, sql, a.Hex(), gasBudget)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListSponsorBudgets returns the gas budgets of the addresses allowed by the sponsor policy
+func (p *PostgresPoolStorage) ListSponsorBudgets(ctx context.Context) ([]pool.SponsorBudget, error) {
+	sql := "SELECT address, gas_budget, gas_used, period_start FROM pool.sponsor_budget ORDER BY address"
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+	defer rows.Close()
+
+	var budgets []pool.SponsorBudget
+	for rows.Next() {
+		var (
+			address string
+			budget  pool.SponsorBudget
+		)
+		err = rows.Scan(&address, &budget.GasBudget, &budget.GasUsed, &budget.PeriodStart)
+		if err != nil {
+			return nil, err
+		}
+		budget.Address = common.HexToAddress(address)
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+// CheckSponsorBudget returns true if the budget left to the sponsor in the current period covers the gas,
+// the gas used is reset when the period is over. It returns false if the sponsor has no budget
+func (p *PostgresPoolStorage) CheckSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error) {
+	sql := `SELECT EXISTS (
+				SELECT 1 FROM pool.sponsor_budget
+				WHERE address = $1
+				  AND (CASE WHEN period_start + make_interval(secs => $3) <= NOW() THEN 0 ELSE gas_used END) + $2 <= gas_budget
+			)`
+	var covered bool
+	err := p.db.QueryRow(ctx, sql, address.Hex(), gas, period.Seconds()).Scan(&covered)
+	if err != nil {
+		return false, err
+	}
+	return covered, nil
+}
+
+// ChargeSponsorBudget adds the gas to the gas used by the sponsor in the current period if the budget left covers it,
+// the gas used is reset when the period is over. The check and the charge are done in a single statement, so the
+// concurrent charges can't exceed the budget. It returns false, without charging the gas, if the budget is exhausted
+func (p *PostgresPoolStorage) ChargeSponsorBudget(ctx context.Context, address common.Address, gas uint64, period time.Duration) (bool, error) {
+	sql := `UPDATE pool.sponsor_budget SET
+				gas_used = CASE WHEN period_start + make_interval(secs => $3) <= NOW() THEN $2 ELSE gas_used + $2 END,
+				period_start = CASE WHEN period_start + make_interval(secs => $3) <= NOW() THEN NOW() ELSE period_start END
+			WHERE address = $1
+			  AND (CASE WHEN period_start + make_interval(secs => $3) <= NOW() THEN 0 ELSE gas_used END) + $2 <= gas_budget`
+	cmdTag, err := p.db.Exec(ctx, sql, address.Hex(), gas, period.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// CheckCallPolicy returns the rule of the call policy for a call with the calldata to the target contract,
+// see pool.IsCallAllowed. The expired associations are ignored.
+func (p *PostgresPoolStorage) CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error) {
+	sql := `SELECT
+				(SELECT allow FROM pool.call_policy WHERE target = $1),
+				EXISTS (
+					SELECT 1 FROM pool.call_acl
+					WHERE target = $1 AND (expires_at IS NULL OR expires_at > NOW())
+				),
+				EXISTS (
+					SELECT 1 FROM pool.call_acl
+					WHERE target = $1 AND selector = $2 AND (expires_at IS NULL OR expires_at > NOW())
+				)`
+
+	selector, fullSelector := pool.CallSelector(data)
+	var (
+		allow          *bool
+		hasRules       bool
+		selectorListed bool
+	)
+	err := p.db.QueryRow(ctx, sql, target.Hex(), selector.Hex()).Scan(&allow, &hasRules, &selectorListed)
+	if err != nil {
+		return false, err
+	}
+	listed := allow != nil || hasRules
+	return pool.IsCallAllowed(listed, allow != nil && *allow, selectorListed, fullSelector), nil
+}
+
+// CheckIPPolicy returns the rule of the ip policy for the source IP. If the IP is in a range associated with the
+// policy, the rule will be the setting for the policy. If not, the rule will be the opposite of the policy setting.
+// The expired associations are ignored. A nil IP is unknown, so it could be in any range: it's only allowed when the
+// policy is deny and there are no ranges associated with it.
+func (p *PostgresPoolStorage) CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error) {
+	sql := `SELECT 
+				CASE WHEN EXISTS (
+					SELECT 1 FROM pool.ip_acl 
+					WHERE ($1::INET IS NULL OR ip_range >>= $1::INET) AND (expires_at IS NULL OR expires_at > NOW())
+				) THEN p.allow AND $1::INET IS NOT NULL ELSE NOT p.allow END
+			FROM pool.policy p
+			WHERE p.name = $2`
+
+	var ipArg *string
+	if ip != nil {
+		s := ip.String()
+		ipArg = &s
+	}
+	var allow bool
+	err := p.db.QueryRow(ctx, sql, ipArg, pool.IP).Scan(&allow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return allow, nil
+}
+
+// GetMaxTxValue returns the max value per tx of the senders not allowed by the value policy, nil if there is no limit
+func (p *PostgresPoolStorage) GetMaxTxValue(ctx context.Context) (*big.Int, error) {
+	sql := "SELECT max_value::TEXT FROM pool.policy WHERE name = $1"
+	var maxValue *string
+	err := p.db.QueryRow(ctx, sql, pool.Value).Scan(&maxValue)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return parseMaxValue(maxValue), nil
+}
+
+// SetMaxTxValue sets the max value per tx of the senders not allowed by the value policy, nil removes the limit
+func (p *PostgresPoolStorage) SetMaxTxValue(ctx context.Context, maxValue *big.Int) error {
+	var value *string
+	if maxValue != nil {
+		v := maxValue.String()
+		value = &v
+	}
+	sql := "UPDATE pool.policy SET max_value = $1::NUMERIC WHERE name = $2"
+	_, err := p.db.Exec(ctx, sql, value, pool.Value)
+	return err
+}
+
+// UpdateCallPolicy sets the mode of the call policy for the target contracts
+func (p *PostgresPoolStorage) UpdateCallPolicy(ctx context.Context, targets []common.Address, allow bool) error {
+	sql := `INSERT INTO pool.call_policy (target, allow) VALUES ($1, $2)
+			ON CONFLICT (target) DO UPDATE SET allow = $2`
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, target := range targets {
+		_, err = tx.Exec(ctx, sql, target.Hex(), allow)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListCallPolicies returns the modes of the call policy set for the target contracts
+func (p *PostgresPoolStorage) ListCallPolicies(ctx context.Context) ([]pool.CallPolicy, error) {
+	rows, err := p.db.Query(ctx, "SELECT target, allow FROM pool.call_policy ORDER BY target")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []pool.CallPolicy
+	for rows.Next() {
+		var (
+			target string
+			allow  bool
+		)
+		if err := rows.Scan(&target, &allow); err != nil {
+			return nil, err
+		}
+		policies = append(policies, pool.CallPolicy{Target: common.HexToAddress(target), Allow: allow})
+	}
+	return policies, rows.Err()
+}
+
+// AddCallRules adds the function selectors of the target contracts to the call policy,
+// the expiry of the rules that already exist is updated
+func (p *PostgresPoolStorage) AddCallRules(ctx context.Context, rules []pool.CallRule) error {
+	sql := `INSERT INTO pool.call_acl (target, selector, expires_at) VALUES ($1, $2, $3)
+			ON CONFLICT (target, selector) DO UPDATE SET expires_at = $3`
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, r := range rules {
+		_, err = tx.Exec(ctx, sql, r.Target.Hex(), r.Selector.Hex(), r.ExpiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveCallRules removes the function selectors of the target contracts from the call policy
+func (p *PostgresPoolStorage) RemoveCallRules(ctx context.Context, rules []pool.CallRule) error {
+	sql := "DELETE FROM pool.call_acl WHERE target = $1 AND selector = $2"
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, r := range rules {
+		_, err = tx.Exec(ctx, sql, r.Target.Hex(), r.Selector.Hex())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListCallRules returns the rules of the call policy that haven't expired
+func (p *PostgresPoolStorage) ListCallRules(ctx context.Context) ([]pool.CallRule, error) {
+	sql := `SELECT target, selector, expires_at FROM pool.call_acl
+			WHERE expires_at IS NULL OR expires_at > NOW() ORDER BY target, selector`
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []pool.CallRule
+	for rows.Next() {
+		var (
+			target, selector string
+			rule             pool.CallRule
+		)
+		err = rows.Scan(&target, &selector, &rule.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		rule.Target = common.HexToAddress(target)
+		rule.Selector, err = pool.HexToSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// AddIPRules adds the source IP ranges to the ip policy, the expiry of the rules that already exist is updated
+func (p *PostgresPoolStorage) AddIPRules(ctx context.Context, rules []pool.IPRule) error {
+	sql := `INSERT INTO pool.ip_acl (ip_range, expires_at) VALUES ($1::CIDR, $2)
+			ON CONFLICT (ip_range) DO UPDATE SET expires_at = $2`
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, r := range rules {
+		_, err = tx.Exec(ctx, sql, r.Range.String(), r.ExpiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveIPRules removes the source IP ranges from the ip policy
+func (p *PostgresPoolStorage) RemoveIPRules(ctx context.Context, rules []pool.IPRule) error {
+	sql := "DELETE FROM pool.ip_acl WHERE ip_range = $1::CIDR"
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for _, r := range rules {
+		_, err = tx.Exec(ctx, sql, r.Range.String())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListIPRules returns the rules of the ip policy that haven't expired
+func (p *PostgresPoolStorage) ListIPRules(ctx context.Context) ([]pool.IPRule, error) {
+	sql := `SELECT ip_range::TEXT, expires_at FROM pool.ip_acl
+			WHERE expires_at IS NULL OR expires_at > NOW() ORDER BY ip_range`
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []pool.IPRule
+	for rows.Next() {
+		var (
+			ipRange string
+			rule    pool.IPRule
+		)
+		err = rows.Scan(&ipRange, &rule.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		_, network, err := net.ParseCIDR(ipRange)
+		if err != nil {
+			return nil, err
+		}
+		rule.Range = *network
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseMaxValue parses the text of a max value column, nil if there is no limit
+func parseMaxValue(value *string) *big.Int {
+	if value == nil {
+		return nil
+	}
+	maxValue, ok := new(big.Int).SetString(*value, 10)
+	if !ok {
+		return nil
+	}
+	return maxValue
 }
@@ -0,0 +1,98 @@
+package pgpoolstorage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/jackc/pgx/v4"
+)
+
+const reputationColumns = "subject, invalid, reverted, ooc, underpriced, window_start, bans, banned_until"
+
+// AddOffense adds the offense to the reputation of the subject and returns the updated reputation.
+// The offenses counted before windowStart are forgotten and a new window is started
+func (p *PostgresPoolStorage) AddOffense(ctx context.Context, subject string, offense pool.Offense, windowStart time.Time) (pool.Reputation, error) {
+	sql := `INSERT INTO pool.reputation (subject, invalid, reverted, ooc, underpriced, window_start)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+			ON CONFLICT (subject) DO UPDATE SET
+				invalid = CASE WHEN reputation.window_start < $6 THEN 0 ELSE reputation.invalid END + EXCLUDED.invalid,
+				reverted = CASE WHEN reputation.window_start < $6 THEN 0 ELSE reputation.reverted END + EXCLUDED.reverted,
+				ooc = CASE WHEN reputation.window_start < $6 THEN 0 ELSE reputation.ooc END + EXCLUDED.ooc,
+				underpriced = CASE WHEN reputation.window_start < $6 THEN 0 ELSE reputation.underpriced END + EXCLUDED.underpriced,
+				window_start = CASE WHEN reputation.window_start < $6 THEN NOW() ELSE reputation.window_start END
+			RETURNING ` + reputationColumns
+
+	var invalid, reverted, ooc, underpriced uint64
+	switch offense {
+	case pool.OffenseInvalid:
+		invalid = 1
+	case pool.OffenseReverted:
+		reverted = 1
+	case pool.OffenseOOC:
+		ooc = 1
+	case pool.OffenseUnderpriced:
+		underpriced = 1
+	}
+
+	row := p.db.QueryRow(ctx, sql, subject, invalid, reverted, ooc, underpriced, windowStart)
+	return scanReputation(row)
+}
+
+// BanSubject bans the subject until the provided time, the offenses of the subject are forgotten
+// and a new window is started
+func (p *PostgresPoolStorage) BanSubject(ctx context.Context, subject string, until time.Time) error {
+	sql := `UPDATE pool.reputation SET
+				bans = bans + 1, banned_until = $2,
+				invalid = 0, reverted = 0, ooc = 0, underpriced = 0, window_start = NOW()
+			WHERE subject = $1`
+	_, err := p.db.Exec(ctx, sql, subject, until)
+	return err
+}
+
+// GetReputation returns the reputation of the subject, ErrNotFound if the subject has no offenses
+func (p *PostgresPoolStorage) GetReputation(ctx context.Context, subject string) (*pool.Reputation, error) {
+	sql := "SELECT " + reputationColumns + " FROM pool.reputation WHERE subject = $1"
+	reputation, err := scanReputation(p.db.QueryRow(ctx, sql, subject))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, pool.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &reputation, nil
+}
+
+// ListReputations returns the reputations of all the subjects, the banned ones first
+func (p *PostgresPoolStorage) ListReputations(ctx context.Context) ([]pool.Reputation, error) {
+	sql := "SELECT " + reputationColumns + " FROM pool.reputation ORDER BY banned_until DESC NULLS LAST, subject"
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reputations := []pool.Reputation{}
+	for rows.Next() {
+		reputation, err := scanReputation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reputations = append(reputations, reputation)
+	}
+	return reputations, rows.Err()
+}
+
+// DeleteReputation forgets the offenses and the bans of the subject
+func (p *PostgresPoolStorage) DeleteReputation(ctx context.Context, subject string) error {
+	sql := "DELETE FROM pool.reputation WHERE subject = $1"
+	_, err := p.db.Exec(ctx, sql, subject)
+	return err
+}
+
+func scanReputation(row pgx.Row) (pool.Reputation, error) {
+	var reputation pool.Reputation
+	err := row.Scan(&reputation.Subject, &reputation.Invalid, &reputation.Reverted, &reputation.OOC, &reputation.Underpriced,
+		&reputation.WindowStart, &reputation.Bans, &reputation.BannedUntil)
+	return reputation, err
+}
@@ -1,6 +1,16 @@
 package pool
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
 
 // PolicyName is a named policy
 type PolicyName string
@@ -10,12 +20,24 @@ const (
 	SendTx PolicyName = "send_tx"
 	// Deploy is the name of the policy that governs that an address may deploy a contract
 	Deploy PolicyName = "deploy"
+	// Sponsor is the name of the policy that governs that the txs to a contract or from a sender are sponsored,
+	// so they skip the gas price checks while the gas budget of the address is not exhausted
+	Sponsor PolicyName = "sponsor"
+	// Call is the name of the policy that governs the function selectors that may be called on a contract,
+	// its mode is set per contract, see CallPolicy
+	Call PolicyName = "call"
+	// Value is the name of the policy that governs that an address may send txs with a value over the max value per tx
+	Value PolicyName = "value"
+	// IP is the name of the policy that governs the source IP ranges that may send transactions to the pool
+	IP PolicyName = "ip"
 )
 
 // Policy describes state of a named policy
 type Policy struct {
 	Name  PolicyName
 	Allow bool
+	// MaxValue is the max value per tx of the senders not allowed by the Value policy, nil if there is no limit
+	MaxValue *big.Int
 }
 
 // Desc returns the string representation of a policy rule
@@ -30,14 +52,159 @@ func (p *Policy) Desc() string {
 type Acl struct {
 	PolicyName PolicyName
 	Address    common.Address
+	// ExpiresAt is the time when the exception stops applying, nil if it never expires
+	ExpiresAt *time.Time
+}
+
+// Selector is the function selector of a contract call, the first 4 bytes of the tx data
+type Selector [4]byte
+
+// HexToSelector parses a 4 bytes hex encoded function selector
+func HexToSelector(s string) (Selector, error) {
+	var selector Selector
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return selector, err
+	}
+	if len(b) != len(selector) {
+		return selector, fmt.Errorf("invalid selector length %d", len(b))
+	}
+	copy(selector[:], b)
+	return selector, nil
+}
+
+// Hex returns the hex encoding of the selector
+func (s Selector) Hex() string {
+	return hexutil.Encode(s[:])
+}
+
+// CallPolicy describes the mode of the call policy for a target contract
+type CallPolicy struct {
+	Target common.Address
+	// Allow is true if only the selectors listed for the target may be called, false if they may not be called
+	Allow bool
+}
+
+// CallSelector returns the function selector of the calldata of a contract call and whether the calldata
+// has a full selector, the calldata shorter than a selector is padded with zeros
+func CallSelector(data []byte) (Selector, bool) {
+	var selector Selector
+	copy(selector[:], data)
+	return selector, len(data) >= len(selector)
+}
+
+// IsCallAllowed returns the rule of the call policy for a call to a target contract. The calls to the contracts
+// listed by the policy, the ones with a mode or with selectors, are allowed if the mode is allow and the selector
+// is listed, or if the mode is deny and it isn't; the mode of the contracts with selectors but no mode is deny.
+// The calldata shorter than a selector is only allowed if the mode is allow and its zero padded selector is
+// listed. The calls to the contracts not listed are allowed
+func IsCallAllowed(listed, allow, selectorListed, fullSelector bool) bool {
+	switch {
+	case !listed:
+		return true
+	case !fullSelector:
+		return allow && selectorListed
+	default:
+		return allow == selectorListed
+	}
+}
+
+// CallRule describes exception to the Call policy by target contract and function selector
+type CallRule struct {
+	Target   common.Address
+	Selector Selector
+	// ExpiresAt is the time when the exception stops applying, nil if it never expires
+	ExpiresAt *time.Time
+}
+
+// IPRule describes exception to the IP policy by source IP range
+type IPRule struct {
+	Range net.IPNet
+	// ExpiresAt is the time when the exception stops applying, nil if it never expires
+	ExpiresAt *time.Time
 }
 
 // IsPolicy tests if a string represents a known named Policy
 func IsPolicy(name string) bool {
-	for _, p := range []PolicyName{SendTx, Deploy} {
+	for _, p := range []PolicyName{SendTx, Deploy, Sponsor, Call, Value, IP} {
 		if name == string(p) {
 			return true
 		}
 	}
 	return false
 }
+
+// SponsorBudget describes the gas an address allowed by the Sponsor policy can sponsor per period
+type SponsorBudget struct {
+	Address     common.Address
+	GasBudget   uint64
+	GasUsed     uint64
+	PeriodStart time.Time
+}
+
+// PolicyChecker contains the methods needed to check the policies of a tx
+type PolicyChecker interface {
+	CheckPolicy(ctx context.Context, policy PolicyName, address common.Address) (bool, error)
+	CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error)
+	CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error)
+	GetMaxTxValue(ctx context.Context) (*big.Int, error)
+}
+
+// CheckTxPolicies returns the error of the first policy that disallows the tx sent by from through the ip.
+// When the ip is unknown the tx is only allowed if the IP policy doesn't disallow any IP
+func CheckTxPolicies(ctx context.Context, p PolicyChecker, tx types.Transaction, from common.Address, ip string) error {
+	var parsedIP net.IP
+	if ip != "" {
+		parsedIP = net.ParseIP(ip)
+		if parsedIP == nil {
+			return ErrInvalidIP
+		}
+	}
+	if allow, err := p.CheckIPPolicy(ctx, parsedIP); err != nil {
+		return err
+	} else if !allow && parsedIP == nil {
+		return ErrUnknownIPDisallowedSendTx
+	} else if !allow {
+		return ErrIPDisallowedSendTx
+	}
+
+	if tx.To() == nil || *tx.To() == (common.Address{}) {
+		// check that sender may deploy contracts
+		if allow, err := p.CheckPolicy(ctx, Deploy, from); err != nil {
+			return err
+		} else if !allow {
+			return ErrSenderDisallowedDeploy
+		}
+	} else {
+		if allow, err := p.CheckPolicy(ctx, SendTx, *tx.To()); err != nil {
+			return err
+		} else if !allow {
+			return ErrContractDisallowedSendTx
+		}
+		if allow, err := p.CheckPolicy(ctx, SendTx, from); err != nil {
+			return err
+		} else if !allow {
+			return ErrSenderDisallowedSendTx
+		}
+		if allow, err := p.CheckCallPolicy(ctx, *tx.To(), tx.Data()); err != nil {
+			return err
+		} else if !allow {
+			return ErrCallDisallowed
+		}
+	}
+
+	if tx.Value().Sign() > 0 {
+		maxValue, err := p.GetMaxTxValue(ctx)
+		if err != nil {
+			return err
+		}
+		if maxValue != nil && tx.Value().Cmp(maxValue) > 0 {
+			if allow, err := p.CheckPolicy(ctx, Value, from); err != nil {
+				return err
+			} else if !allow {
+				return ErrValueDisallowed
+			}
+		}
+	}
+	return nil
+}
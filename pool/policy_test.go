@@ -0,0 +1,235 @@
+package pool_test
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/memorypoolstorage"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckTxPolicies(t *testing.T) {
+	ctx := context.Background()
+	from := common.HexToAddress("0x1")
+	contract := common.HexToAddress("0x2")
+	transfer, err := pool.HexToSelector("0xa9059cbb")
+	require.NoError(t, err)
+	approve, err := pool.HexToSelector("0x095ea7b3")
+	require.NoError(t, err)
+	_, privateRange, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	expired := time.Now().Add(-time.Minute)
+
+	newTx := func(to *common.Address, value int64, data []byte) ethTypes.Transaction {
+		return *ethTypes.NewTx(&ethTypes.LegacyTx{To: to, Value: big.NewInt(value), Gas: gasLimit, GasPrice: gasPrice, Data: data})
+	}
+
+	testCases := []struct {
+		name        string
+		setup       func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage)
+		tx          ethTypes.Transaction
+		ip          string
+		expectedErr error
+	}{
+		{
+			name: "No rules, allowed",
+			tx:   newTx(&contract, 1, transfer[:]),
+			ip:   "10.0.0.1",
+		},
+		{
+			name:        "Invalid IP, rejected",
+			tx:          newTx(&contract, 0, nil),
+			ip:          "not an ip",
+			expectedErr: pool.ErrInvalidIP,
+		},
+		{
+			name: "IP range denied, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange}}))
+			},
+			tx:          newTx(&contract, 0, nil),
+			ip:          "10.1.2.3",
+			expectedErr: pool.ErrIPDisallowedSendTx,
+		},
+		{
+			name: "IP range denied with unknown IP, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange}}))
+			},
+			tx:          newTx(&contract, 0, nil),
+			expectedErr: pool.ErrUnknownIPDisallowedSendTx,
+		},
+		{
+			name: "IP range allowed with unknown IP, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdatePolicy(ctx, pool.IP, true))
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange}}))
+			},
+			tx:          newTx(&contract, 0, nil),
+			expectedErr: pool.ErrUnknownIPDisallowedSendTx,
+		},
+		{
+			name: "Expired IP range denied with unknown IP, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange, ExpiresAt: &expired}}))
+			},
+			tx: newTx(&contract, 0, nil),
+		},
+		{
+			name: "IP outside allowed range, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdatePolicy(ctx, pool.IP, true))
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange}}))
+			},
+			tx:          newTx(&contract, 0, nil),
+			ip:          "192.168.0.1",
+			expectedErr: pool.ErrIPDisallowedSendTx,
+		},
+		{
+			name: "Expired IP range denied, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *privateRange, ExpiresAt: &expired}}))
+			},
+			tx: newTx(&contract, 0, nil),
+			ip: "10.1.2.3",
+		},
+		{
+			name: "Contract denied, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddAddressesToPolicy(ctx, pool.SendTx, []common.Address{contract}, nil))
+			},
+			tx:          newTx(&contract, 0, nil),
+			expectedErr: pool.ErrContractDisallowedSendTx,
+		},
+		{
+			name: "Sender denied until expired, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddAddressesToPolicy(ctx, pool.SendTx, []common.Address{from}, &expired))
+			},
+			tx: newTx(&contract, 0, nil),
+		},
+		{
+			name: "Sender not allowed to deploy, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdatePolicy(ctx, pool.Deploy, true))
+			},
+			tx:          newTx(nil, 0, nil),
+			expectedErr: pool.ErrSenderDisallowedDeploy,
+		},
+		{
+			name: "Selector denied, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: approve}}))
+			},
+			tx:          newTx(&contract, 0, append(approve[:], 1, 2, 3)),
+			expectedErr: pool.ErrCallDisallowed,
+		},
+		{
+			name: "Selector not denied, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: approve}}))
+			},
+			tx: newTx(&contract, 0, transfer[:]),
+		},
+		{
+			name: "Selector not allowed, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{contract}, true))
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: transfer}}))
+			},
+			tx:          newTx(&contract, 0, approve[:]),
+			expectedErr: pool.ErrCallDisallowed,
+		},
+		{
+			name: "Selector allowed, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{contract}, true))
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: transfer}}))
+			},
+			tx: newTx(&contract, 0, transfer[:]),
+		},
+		{
+			name: "Mode of another contract, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{from}, true))
+			},
+			tx: newTx(&contract, 0, approve[:]),
+		},
+		{
+			name: "Contract on call allow list without selectors, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{contract}, true))
+			},
+			tx:          newTx(&contract, 0, transfer[:]),
+			expectedErr: pool.ErrCallDisallowed,
+		},
+		{
+			name: "Transfer without data to contract on call allow list, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{contract}, true))
+			},
+			tx:          newTx(&contract, 1, nil),
+			expectedErr: pool.ErrCallDisallowed,
+		},
+		{
+			name: "Short calldata to contract on call deny list, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: approve}}))
+			},
+			tx:          newTx(&contract, 0, transfer[:3]),
+			expectedErr: pool.ErrCallDisallowed,
+		},
+		{
+			name: "Short calldata to contract not listed, allowed",
+			tx:   newTx(&contract, 1, transfer[:3]),
+		},
+		{
+			name: "Expired selectors, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: contract, Selector: approve, ExpiresAt: &expired}}))
+			},
+			tx: newTx(&contract, 0, approve[:]),
+		},
+		{
+			name: "Value over max value, rejected",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.SetMaxTxValue(ctx, big.NewInt(100)))
+			},
+			tx:          newTx(&contract, 101, nil),
+			expectedErr: pool.ErrValueDisallowed,
+		},
+		{
+			name: "Value equal to max value, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.SetMaxTxValue(ctx, big.NewInt(100)))
+			},
+			tx: newTx(&contract, 100, nil),
+		},
+		{
+			name: "Value over max value by allowed sender, allowed",
+			setup: func(t *testing.T, s *memorypoolstorage.MemoryPoolStorage) {
+				require.NoError(t, s.SetMaxTxValue(ctx, big.NewInt(100)))
+				require.NoError(t, s.AddAddressesToPolicy(ctx, pool.Value, []common.Address{from}, nil))
+			},
+			tx: newTx(&contract, 101, nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := memorypoolstorage.NewMemoryPoolStorage()
+			if tc.setup != nil {
+				tc.setup(t, s)
+			}
+			err := pool.CheckTxPolicies(ctx, s, tc.tx, from, tc.ip)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/event"
 	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
@@ -69,6 +71,8 @@ type GasPrices struct {
 
 // NewPool creates and initializes an instance of Pool
 func NewPool(cfg Config, batchConstraintsCfg state.BatchConstraintsCfg, s storage, st stateInterface, chainID uint64, eventLog *event.EventLog) *Pool {
+	metrics.Register()
+
 	startTimestamp := time.Now()
 	p := &Pool{
 		cfg:                     cfg,
@@ -177,16 +181,40 @@ func (p *Pool) StartPollingMinSuggestedGasPrice(ctx context.Context) {
 
 // AddTx adds a transaction to the pool with the pending state
 func (p *Pool) AddTx(ctx context.Context, tx types.Transaction, ip string) error {
+	return p.addTx(ctx, tx, ip, time.Now(), true)
+}
+
+// addTx validates and adds a transaction to the pool with the pending state, keeping receivedAt
+// as the time the tx was received. The rejections are only recorded as offenses of the source IP
+// and the sender when recordOffenses is set, the txs imported by the operators weren't sent by them
+func (p *Pool) addTx(ctx context.Context, tx types.Transaction, ip string, receivedAt time.Time, recordOffenses bool) error {
 	poolTx := NewTransaction(tx, ip, false)
-	if err := p.validateTx(ctx, *poolTx); err != nil {
+	poolTx.ReceivedAt = receivedAt
+
+	sponsor, err := p.getSponsor(ctx, tx)
+	if err != nil {
 		return err
 	}
+	poolTx.Sponsor = sponsor
 
-	return p.StoreTx(ctx, tx, ip, false)
+	err = p.validateTx(ctx, *poolTx)
+	if err == nil {
+		err = p.storeTx(ctx, tx, ip, false, sponsor, receivedAt)
+	}
+	if recordOffenses {
+		p.recordRejection(ctx, tx, ip, err)
+	}
+	return err
 }
 
 // StoreTx adds a transaction to the pool with the pending state
 func (p *Pool) StoreTx(ctx context.Context, tx types.Transaction, ip string, isWIP bool) error {
+	return p.storeTx(ctx, tx, ip, isWIP, nil, time.Now())
+}
+
+// storeTx pre-executes the tx and adds it to the pool with the pending state, a sponsored tx stays
+// sponsored if the budget left to the sponsor covers the gas used by the tx
+func (p *Pool) storeTx(ctx context.Context, tx types.Transaction, ip string, isWIP bool, sponsor *common.Address, receivedAt time.Time) error {
 	// Execute transaction to calculate its zkCounters
 	preExecutionResponse, err := p.preExecuteTx(ctx, tx)
 	if errors.Is(err, runtime.ErrIntrinsicInvalidBatchGasLimit) {
@@ -233,28 +261,43 @@ func (p *Pool) StoreTx(ctx context.Context, tx types.Transaction, ip string, isW
 		}
 	}
 
-	gasPrices, err := p.GetGasPrices(ctx)
-	if err != nil {
-		return err
+	// The reverted txs are accepted, but they worsen the reputation of their source IP and sender. The WIP
+	// txs are stored by the synchronizer from the trusted state, so they weren't submitted to this pool
+	if preExecutionResponse.isReverted && !isWIP {
+		p.recordOffense(ctx, tx, ip, OffenseReverted)
 	}
 
-	err = p.ValidateBreakEvenGasPrice(ctx, tx, preExecutionResponse.txResponse.GasUsed, gasPrices)
-	if err != nil {
-		return err
+	if sponsor != nil {
+		sponsor, err = p.checkSponsorBudget(ctx, tx, *sponsor, preExecutionResponse.txResponse.GasUsed)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sponsored txs skip the break even gas price check
+	if sponsor == nil {
+		gasPrices, err := p.GetGasPrices(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = p.ValidateBreakEvenGasPrice(ctx, tx, preExecutionResponse.txResponse.GasUsed, gasPrices)
+		if err != nil {
+			return err
+		}
 	}
 
 	poolTx := NewTransaction(tx, ip, isWIP)
+	poolTx.ReceivedAt = receivedAt
 	poolTx.ZKCounters = preExecutionResponse.usedZkCounters
+	poolTx.Sponsor = sponsor
 
 	return p.storage.AddTx(ctx, *poolTx)
 }
 
 // ValidateBreakEvenGasPrice validates the effective gas price
 func (p *Pool) ValidateBreakEvenGasPrice(ctx context.Context, tx types.Transaction, preExecutionGasUsed uint64, gasPrices GasPrices) error {
-	// Get the tx gas price we will use in the egp calculation. If egp is disabled we will use a "simulated" tx gas price
-	txGasPrice, _ := p.effectiveGasPrice.GetTxAndL2GasPrice(tx.GasPrice(), gasPrices.L1GasPrice, gasPrices.L2GasPrice)
-
-	breakEvenGasPrice, err := p.effectiveGasPrice.CalculateBreakEvenGasPrice(tx.Data(), txGasPrice, preExecutionGasUsed, gasPrices.L1GasPrice)
+	check, err := p.checkBreakEvenGasPrice(tx, preExecutionGasUsed, gasPrices)
 	if err != nil {
 		if p.cfg.EffectiveGasPrice.Enabled {
 			log.Errorf("error calculating BreakEvenGasPrice: %v", err)
@@ -265,35 +308,172 @@ func (p *Pool) ValidateBreakEvenGasPrice(ctx context.Context, tx types.Transacti
 		}
 	}
 
-	reject := false
-	loss := new(big.Int).SetUint64(0)
+	log.Infof("egp-log: txGasPrice(): %v, breakEvenGasPrice: %v, breakEvenGasPriceWithFactor: %v, gasUsed: %v, reject: %t, loss: %v, L1GasPrice: %d, L2GasPrice: %d, Enabled: %t, tx: %s",
+		check.txGasPrice, check.breakEvenGasPrice, check.breakEvenGasPriceWithFactor, preExecutionGasUsed, check.reject, check.loss, gasPrices.L1GasPrice, gasPrices.L2GasPrice, p.cfg.EffectiveGasPrice.Enabled, tx.Hash().String())
+
+	// Reject transaction if EffectiveGasPrice is enabled
+	if p.cfg.EffectiveGasPrice.Enabled && check.reject {
+		log.Infof("reject tx with gasPrice lower than L2GasPrice, tx: %s", tx.Hash().String())
+		return ErrEffectiveGasPriceGasPriceTooLow
+	}
+
+	return nil
+}
+
+// breakEvenGasPriceCheck is the result of comparing the gas price of a tx with its break even gas price
+type breakEvenGasPriceCheck struct {
+	txGasPrice                  *big.Int
+	breakEvenGasPrice           *big.Int
+	breakEvenGasPriceWithFactor *big.Int
+	loss                        *big.Int
+	reject                      bool
+}
+
+// checkBreakEvenGasPrice compares the gas price of a tx with its break even gas price, the tx is
+// rejected when its gas price is below the break even gas price and the L2 gas price
+func (p *Pool) checkBreakEvenGasPrice(tx types.Transaction, preExecutionGasUsed uint64, gasPrices GasPrices) (breakEvenGasPriceCheck, error) {
+	// Get the tx gas price we will use in the egp calculation. If egp is disabled we will use a "simulated" tx gas price
+	txGasPrice, _ := p.effectiveGasPrice.GetTxAndL2GasPrice(tx.GasPrice(), gasPrices.L1GasPrice, gasPrices.L2GasPrice)
+
+	breakEvenGasPrice, err := p.effectiveGasPrice.CalculateBreakEvenGasPrice(tx.Data(), txGasPrice, preExecutionGasUsed, gasPrices.L1GasPrice)
+	if err != nil {
+		return breakEvenGasPriceCheck{}, err
+	}
+
+	return p.compareBreakEvenGasPrice(txGasPrice, breakEvenGasPrice, gasPrices.L2GasPrice), nil
+}
+
+// compareBreakEvenGasPrice applies the BreakEvenFactor to the break even gas price and compares it with the tx gas price
+func (p *Pool) compareBreakEvenGasPrice(txGasPrice, breakEvenGasPrice *big.Int, l2GasPrice uint64) breakEvenGasPriceCheck {
+	check := breakEvenGasPriceCheck{
+		txGasPrice:        txGasPrice,
+		breakEvenGasPrice: breakEvenGasPrice,
+		loss:              new(big.Int).SetUint64(0),
+	}
 
 	tmpFactor := new(big.Float).Mul(new(big.Float).SetInt(breakEvenGasPrice), new(big.Float).SetFloat64(p.cfg.EffectiveGasPrice.BreakEvenFactor))
-	breakEvenGasPriceWithFactor := new(big.Int)
-	tmpFactor.Int(breakEvenGasPriceWithFactor)
+	check.breakEvenGasPriceWithFactor = new(big.Int)
+	tmpFactor.Int(check.breakEvenGasPriceWithFactor)
 
-	if breakEvenGasPriceWithFactor.Cmp(txGasPrice) == 1 { // breakEvenGasPriceWithMargin > txGasPrice
+	if check.breakEvenGasPriceWithFactor.Cmp(txGasPrice) == 1 { // breakEvenGasPriceWithMargin > txGasPrice
 		// check against L2GasPrice now
-		L2GasPrice := big.NewInt(0).SetUint64(gasPrices.L2GasPrice)
+		L2GasPrice := big.NewInt(0).SetUint64(l2GasPrice)
 		if txGasPrice.Cmp(L2GasPrice) == -1 { // txGasPrice < gasPrices.L2GasPrice
 			// reject tx
-			reject = true
+			check.reject = true
 		} else {
 			// accept loss
-			loss = loss.Sub(breakEvenGasPriceWithFactor, txGasPrice)
+			check.loss = check.loss.Sub(check.breakEvenGasPriceWithFactor, txGasPrice)
 		}
 	}
 
-	log.Infof("egp-log: txGasPrice(): %v, breakEvenGasPrice: %v, breakEvenGasPriceWithFactor: %v, gasUsed: %v, reject: %t, loss: %v, L1GasPrice: %d, L2GasPrice: %d, Enabled: %t, tx: %s",
-		txGasPrice, breakEvenGasPrice, breakEvenGasPriceWithFactor, preExecutionGasUsed, reject, loss, gasPrices.L1GasPrice, gasPrices.L2GasPrice, p.cfg.EffectiveGasPrice.Enabled, tx.Hash().String())
+	return check
+}
 
-	// Reject transaction if EffectiveGasPrice is enabled
-	if p.cfg.EffectiveGasPrice.Enabled && reject {
-		log.Infof("reject tx with gasPrice lower than L2GasPrice, tx: %s", tx.Hash().String())
-		return ErrEffectiveGasPriceGasPriceTooLow
+// EffectiveGasPriceEstimation is the effective gas price that would be applied to a tx if it was
+// sent now, together with the values used to calculate it
+type EffectiveGasPriceEstimation struct {
+	// Enabled indicates if the effective gas price is applied, when it's disabled the txs pay their gas price
+	Enabled bool
+	// GasPrices are the current L1 and L2 gas prices
+	GasPrices GasPrices
+	// GasUsed is the gas used by the tx in the pre execution
+	GasUsed uint64
+	// TxGasPrice is the gas price used in the calculations, simulated from the L1 gas price when disabled
+	TxGasPrice *big.Int
+	// BreakEven contains the break even gas price of the tx and the values used to calculate it
+	BreakEven BreakEvenGasPriceBreakdown
+	// BreakEvenGasPriceWithFactor is the break even gas price checked by the pool when the tx is added
+	BreakEvenGasPriceWithFactor *big.Int
+	// EffectiveGasPrice is the gas price that would be charged to the tx
+	EffectiveGasPrice *big.Int
+	// EffectivePercentage is the effective percentage of the gas price that would be added to the batch
+	EffectivePercentage uint8
+	// Sponsored indicates if the gas used by the tx would be covered by a sponsor
+	Sponsored bool
+	// Accepted indicates if the tx would be accepted by the pool
+	Accepted bool
+	// RejectReason is the reason why the tx would be rejected, empty when it's accepted
+	RejectReason string
+}
+
+// EstimateEffectiveGasPrice pre-executes a tx and calculates the effective gas price that would be
+// applied to it with the current gas prices, the same way the sequencer does, without adding it to the pool.
+// Every value is calculated from the tx encoded as the sequencer adds it to the batch. The sponsored txs skip
+// the break even check and are processed with their gas price, so they are accepted at their gas price, usually 0
+func (p *Pool) EstimateEffectiveGasPrice(ctx context.Context, tx types.Transaction) (EffectiveGasPriceEstimation, error) {
+	estimation := EffectiveGasPriceEstimation{Enabled: p.cfg.EffectiveGasPrice.Enabled, Accepted: true}
+
+	preExecutionResponse, err := p.preExecuteTx(ctx, tx)
+	if errors.Is(err, runtime.ErrIntrinsicInvalidBatchGasLimit) {
+		return estimation, ErrGasLimit
+	} else if err != nil {
+		return estimation, err
+	}
+	if preExecutionResponse.txResponse != nil {
+		estimation.GasUsed = preExecutionResponse.txResponse.GasUsed
+	}
+	if preExecutionResponse.isOOC {
+		estimation.Accepted = false
+		estimation.RejectReason = ErrOutOfCounters.Error()
 	}
 
-	return nil
+	estimation.GasPrices, err = p.GetGasPrices(ctx)
+	if err != nil {
+		return estimation, err
+	}
+
+	// the sequencer calculates the effective gas price from the tx encoded without the effective percentage
+	rawTx, err := state.EncodeTransactionWithoutEffectivePercentage(tx)
+	if err != nil {
+		return estimation, err
+	}
+	txGasPrice, txL2GasPrice := p.effectiveGasPrice.GetTxAndL2GasPrice(tx.GasPrice(), estimation.GasPrices.L1GasPrice, estimation.GasPrices.L2GasPrice)
+	estimation.TxGasPrice = txGasPrice
+	estimation.BreakEven, err = p.effectiveGasPrice.CalculateBreakEvenGasPriceBreakdown(rawTx, txGasPrice, estimation.GasUsed, estimation.GasPrices.L1GasPrice)
+	if err != nil {
+		return estimation, err
+	}
+	check := p.compareBreakEvenGasPrice(txGasPrice, estimation.BreakEven.BreakEvenGasPrice, estimation.GasPrices.L2GasPrice)
+	estimation.BreakEvenGasPriceWithFactor = check.breakEvenGasPriceWithFactor
+
+	estimation.Sponsored, err = p.isSponsored(ctx, tx, estimation.GasUsed)
+	if err != nil {
+		return estimation, err
+	}
+	if estimation.Sponsored {
+		estimation.TxGasPrice = tx.GasPrice()
+		estimation.EffectiveGasPrice = new(big.Int).Set(tx.GasPrice())
+		estimation.EffectivePercentage = state.MaxEffectivePercentage
+		return estimation, nil
+	}
+
+	if estimation.Enabled && check.reject && estimation.Accepted {
+		estimation.Accepted = false
+		estimation.RejectReason = ErrEffectiveGasPriceGasPriceTooLow.Error()
+	}
+
+	effectiveGasPrice, err := p.effectiveGasPrice.CalculateEffectiveGasPrice(rawTx, txGasPrice, estimation.GasUsed, estimation.GasPrices.L1GasPrice, txL2GasPrice)
+	if err != nil {
+		return estimation, err
+	}
+	// If EffectiveGasPrice >= txGasPrice, the tx is processed with its gas price
+	if effectiveGasPrice.Cmp(txGasPrice) >= 0 {
+		effectiveGasPrice.Set(txGasPrice)
+	}
+	estimation.EffectiveGasPrice = effectiveGasPrice
+	estimation.EffectivePercentage, err = p.effectiveGasPrice.CalculateEffectiveGasPricePercentage(txGasPrice, effectiveGasPrice)
+	if err != nil {
+		return estimation, err
+	}
+
+	// If EGP is disabled the tx is processed with its gas price (MaxEffectivePercentage=255)
+	if !estimation.Enabled {
+		estimation.EffectiveGasPrice = tx.GasPrice()
+		estimation.EffectivePercentage = state.MaxEffectivePercentage
+	}
+
+	return estimation, nil
 }
 
 // preExecuteTx executes a transaction to calculate its zkCounters
@@ -351,6 +531,18 @@ func (p *Pool) GetNonWIPPendingTxs(ctx context.Context) ([]Transaction, error) {
 	return p.storage.GetNonWIPPendingTxs(ctx)
 }
 
+// SubscribeEvictedTxs returns a channel the hashes of the txs evicted from the pool are sent to, so the txs
+// can be dropped by the components that keep a copy of them. The channel is closed when the subscription stops
+func (p *Pool) SubscribeEvictedTxs(ctx context.Context) (<-chan common.Hash, error) {
+	return p.storage.SubscribeEvictedTxs(ctx)
+}
+
+// SubscribePendingTxs returns a channel that is signaled when there are new pending txs in the pool, the channel
+// is closed when the pool can't notify them anymore
+func (p *Pool) SubscribePendingTxs(ctx context.Context) (<-chan struct{}, error) {
+	return p.storage.SubscribePendingTxs(ctx)
+}
+
 // GetSelectedTxs gets selected txs from the pool db
 func (p *Pool) GetSelectedTxs(ctx context.Context, limit uint64) ([]Transaction, error) {
 	return p.storage.GetTxsByStatus(ctx, TxStatusSelected, limit)
@@ -432,6 +624,12 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 		return ErrInvalidSender
 	}
 
+	// reject the txs from banned source IPs or senders, the txs of the ones with a bad reputation are limited
+	limited, err := p.checkReputation(ctx, poolTx.IP, from)
+	if err != nil {
+		return err
+	}
+
 	// Reject transactions over defined size to prevent DOS attacks
 	if poolTx.Size() > p.cfg.MaxTxBytesSize {
 		log.Infof("%v: %v", ErrOversizedData.Error(), from.String())
@@ -451,6 +649,12 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 		return ErrBlockedSender
 	}
 
+	// check if the tx is allowed by the policies
+	if err := CheckTxPolicies(ctx, p.storage, poolTx.Transaction, from, poolTx.IP); err != nil {
+		log.Infof("%v: %v", err.Error(), poolTx.Hash().String())
+		return err
+	}
+
 	lastL2Block, err := p.state.GetLastL2Block(ctx, nil)
 	if err != nil {
 		log.Errorf("failed to load last l2 block while adding tx to the pool", err)
@@ -468,7 +672,11 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 	}
 
 	// check if sender has reached the limit of transactions in the pool
-	if p.cfg.AccountQueue > 0 {
+	accountQueue := p.cfg.AccountQueue
+	if limited && (accountQueue == 0 || p.cfg.Reputation.LimitedAccountQueue < accountQueue) {
+		accountQueue = p.cfg.Reputation.LimitedAccountQueue
+	}
+	if accountQueue > 0 {
 		// txCount, err := p.storage.CountTransactionsByFromAndStatus(ctx, from, TxStatusPending)
 		// if err != nil {
 		// 	return err
@@ -478,7 +686,7 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 		// }
 
 		// Ensure the transaction does not jump out of the expected AccountQueue
-		if poolTx.Nonce() > currentNonce+p.cfg.AccountQueue-1 {
+		if poolTx.Nonce() > currentNonce+accountQueue-1 {
 			log.Infof("%v: %v", ErrNonceTooHigh.Error(), from.String())
 			return ErrNonceTooHigh
 		}
@@ -496,15 +704,11 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 		}
 	}
 
-	// Reject transactions with a gas price lower than the minimum gas price
-	p.minSuggestedGasPriceMux.RLock()
-	gasPriceCmp := poolTx.GasPrice().Cmp(p.minSuggestedGasPrice)
-	if gasPriceCmp == -1 {
-		log.Debugf("low gas price: minSuggestedGasPrice %v got %v", p.minSuggestedGasPrice, poolTx.GasPrice())
-	}
-	p.minSuggestedGasPriceMux.RUnlock()
-	if gasPriceCmp == -1 {
-		return ErrGasPrice
+	// Reject transactions with a gas price lower than the minimum gas price, unless they are sponsored
+	if poolTx.Sponsor == nil {
+		if err := p.checkMinGasPrice(poolTx.Transaction); err != nil {
+			return err
+		}
 	}
 
 	// Transactor should have enough funds to cover the costs
@@ -536,25 +740,22 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 		return err
 	}
 
-	// check if the new transaction has more gas than all the other txs in the pool
-	// with the same from and nonce to be able to replace the current txs by the new
-	// when being selected
+	// check if the new transaction has a gas price bumped enough over all the other txs in the pool
+	// with the same from and nonce to be able to replace the current txs by the new when being selected
 	for _, oldTx := range oldTxs {
 		// discard invalid txs
 		if oldTx.Status == TxStatusInvalid || oldTx.Status == TxStatusFailed {
 			continue
 		}
 
-		oldTxPrice := new(big.Int).Mul(oldTx.GasPrice(), new(big.Int).SetUint64(oldTx.Gas()))
-		txPrice := new(big.Int).Mul(poolTx.GasPrice(), new(big.Int).SetUint64(poolTx.Gas()))
-
 		if oldTx.Hash() == poolTx.Hash() {
 			return ErrAlreadyKnown
 		}
 
-		// if old Tx Price is higher than the new poolTx price, it returns an error
-		if oldTxPrice.Cmp(txPrice) > 0 {
-			return ErrReplaceUnderpriced
+		sponsored := poolTx.Sponsor != nil && oldTx.Sponsor != nil
+		if err := checkReplacementGasPrice(oldTx.GasPrice(), poolTx.GasPrice(), p.cfg.PriceBump, sponsored); err != nil {
+			p.logReplacementRejected(ctx, poolTx, oldTx, err)
+			return err
 		}
 	}
 
@@ -566,6 +767,38 @@ func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
 	return nil
 }
 
+// logReplacementRejected adds an event to the event log with the reason why a tx was rejected as replacement of a tx in the pool
+func (p *Pool) logReplacementRejected(ctx context.Context, poolTx Transaction, oldTx Transaction, reason error) {
+	event := &event.Event{
+		ReceivedAt:  time.Now(),
+		IPAddress:   poolTx.IP,
+		Source:      event.Source_Node,
+		Component:   event.Component_Pool,
+		Level:       event.Level_Info,
+		EventID:     event.EventID_TxReplacementRejected,
+		Description: fmt.Sprintf("tx %s replacing tx %s: %v", poolTx.Hash().String(), oldTx.Hash().String(), reason),
+	}
+
+	err := p.eventLog.LogEvent(ctx, event)
+	if err != nil {
+		log.Errorf("error adding event: %v", err)
+	}
+}
+
+// checkMinGasPrice returns ErrGasPrice if the gas price of the tx is lower than the minimum gas price
+func (p *Pool) checkMinGasPrice(tx types.Transaction) error {
+	p.minSuggestedGasPriceMux.RLock()
+	gasPriceCmp := tx.GasPrice().Cmp(p.minSuggestedGasPrice)
+	if gasPriceCmp == -1 {
+		log.Debugf("low gas price: minSuggestedGasPrice %v got %v", p.minSuggestedGasPrice, tx.GasPrice())
+	}
+	p.minSuggestedGasPriceMux.RUnlock()
+	if gasPriceCmp == -1 {
+		return ErrGasPrice
+	}
+	return nil
+}
+
 func (p *Pool) pollMinSuggestedGasPrice(ctx context.Context) {
 	fromTimestamp := time.Now().UTC().Add(-p.cfg.MinAllowedGasPriceInterval.Duration)
 	// Ensuring we don't use a timestamp before the pool start as it may be using older L1 gas price factor
@@ -702,3 +935,18 @@ func IntrinsicGas(tx types.Transaction) (uint64, error) {
 func (p *Pool) CheckPolicy(ctx context.Context, policy PolicyName, address common.Address) (bool, error) {
 	return p.storage.CheckPolicy(ctx, policy, address)
 }
+
+// CheckCallPolicy checks if a call with the calldata to a target contract is allowed by the call policy
+func (p *Pool) CheckCallPolicy(ctx context.Context, target common.Address, data []byte) (bool, error) {
+	return p.storage.CheckCallPolicy(ctx, target, data)
+}
+
+// CheckIPPolicy checks if a source IP is allowed by the ip policy
+func (p *Pool) CheckIPPolicy(ctx context.Context, ip net.IP) (bool, error) {
+	return p.storage.CheckIPPolicy(ctx, ip)
+}
+
+// GetMaxTxValue returns the max value per tx of the senders not allowed by the value policy, nil if there is no limit
+func (p *Pool) GetMaxTxValue(ctx context.Context) (*big.Int, error) {
+	return p.storage.GetMaxTxValue(ctx)
+}
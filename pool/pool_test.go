@@ -1,12 +1,14 @@
 package pool_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"fmt"
 	"math"
 	"math/big"
+	"net"
 	"os"
 	"strings"
 	"testing"
@@ -21,9 +23,11 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/memorypoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakeexecutor"
 	"github.com/0xPolygonHermez/zkevm-node/test/contracts/bin/Revert"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
 	"github.com/0xPolygonHermez/zkevm-node/test/operations"
@@ -65,6 +69,7 @@ var (
 		IntervalToRefreshGasPrices:        cfgTypes.NewDuration(5 * time.Second),
 		AccountQueue:                      15,
 		GlobalQueue:                       20,
+		MaxTxsPerBundle:                   3,
 		EffectiveGasPrice: pool.EffectiveGasPriceCfg{
 			Enabled:                   true,
 			L1GasPriceFactor:          0.25,
@@ -93,6 +98,9 @@ var (
 		MaxSteps:             7570538,
 	}
 	ip = "101.1.50.20"
+
+	// storageBackend is the pool storage the tests are running against
+	storageBackend string
 )
 
 func TestMain(m *testing.M) {
@@ -101,8 +109,40 @@ func TestMain(m *testing.M) {
 		Outputs: []string{"stderr"},
 	})
 
-	code := m.Run()
-	os.Exit(code)
+	// all the tests run against every pool storage
+	for _, backend := range []string{pool.StoragePostgres, pool.StorageMemory} {
+		storageBackend = backend
+		log.Infof("running pool tests against the %s storage", backend)
+		if code := m.Run(); code != 0 {
+			os.Exit(code)
+		}
+	}
+	os.Exit(0)
+}
+
+// testStorage is the pool storage plus the methods the tests use to block addresses,
+// configure the policies and check the sponsor budgets
+type testStorage interface {
+	pool.Storage
+	AddBlockedAddresses(ctx context.Context, addresses []common.Address) error
+	RemoveBlockedAddresses(ctx context.Context, addresses []common.Address) error
+	UpdatePolicy(ctx context.Context, policy pool.PolicyName, allow bool) error
+	SetMaxTxValue(ctx context.Context, maxValue *big.Int) error
+	UpdateCallPolicy(ctx context.Context, targets []common.Address, allow bool) error
+	ListCallPolicies(ctx context.Context) ([]pool.CallPolicy, error)
+	AddCallRules(ctx context.Context, rules []pool.CallRule) error
+	AddIPRules(ctx context.Context, rules []pool.IPRule) error
+	ListIPRules(ctx context.Context) ([]pool.IPRule, error)
+	SetSponsorBudget(ctx context.Context, addresses []common.Address, gasBudget uint64) error
+	ListSponsorBudgets(ctx context.Context) ([]pool.SponsorBudget, error)
+}
+
+// newPoolStorage creates the pool storage the tests are running against
+func newPoolStorage() (testStorage, error) {
+	if storageBackend == pool.StorageMemory {
+		return memorypoolstorage.NewMemoryPoolStorage(), nil
+	}
+	return pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
 }
 
 type testData struct {
@@ -189,11 +229,6 @@ func Test_AddTx(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -215,7 +250,7 @@ func Test_AddTx(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	const chainID = 2576980377
@@ -230,27 +265,23 @@ func Test_AddTx(t *testing.T) {
 	err = p.AddTx(ctx, *tx, ip)
 	require.NoError(t, err)
 
-	rows, err := poolSqlDB.Query(ctx, "SELECT hash, encoded, decoded, status, used_steps FROM pool.transaction")
+	poolTx, err := s.GetTxByHash(ctx, tx.Hash())
 	require.NoError(t, err)
-	defer rows.Close() // nolint:staticcheck
-
-	c := 0
-	for rows.Next() {
-		var hash, encoded, decoded, status string
-		var usedSteps int
-		err := rows.Scan(&hash, &encoded, &decoded, &status, &usedSteps)
-		require.NoError(t, err)
-		b, _ := tx.MarshalJSON()
+	encoded, err := poolTx.MarshalBinary()
+	require.NoError(t, err)
+	decoded, err := poolTx.MarshalJSON()
+	require.NoError(t, err)
+	b, _ = tx.MarshalJSON()
 
-		assert.Equal(t, "0x3c499a6308dbf4e67bd4e949b0b609e3a0a5a7fd6a497acb23e37ae7f0a923cc", hash, "invalid hash")
-		assert.Equal(t, expectedTxEncoded, encoded, "invalid encoded")
-		assert.JSONEq(t, string(b), decoded, "invalid decoded")
-		assert.Equal(t, string(pool.TxStatusPending), status, "invalid tx status")
-		assert.Greater(t, usedSteps, 0, "invalid used steps")
-		c++
-	}
+	assert.Equal(t, "0x3c499a6308dbf4e67bd4e949b0b609e3a0a5a7fd6a497acb23e37ae7f0a923cc", poolTx.Hash().String(), "invalid hash")
+	assert.Equal(t, expectedTxEncoded, hex.EncodeToHex(encoded), "invalid encoded")
+	assert.JSONEq(t, string(b), string(decoded), "invalid decoded")
+	assert.Equal(t, pool.TxStatusPending, poolTx.Status, "invalid tx status")
+	assert.Greater(t, poolTx.UsedSteps, uint32(0), "invalid used steps")
 
-	assert.Equal(t, 1, c, "invalid number of txs in the pool")
+	c, err := s.CountTransactionsByStatus(ctx, pool.TxStatusPending)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), c, "invalid number of txs in the pool")
 }
 
 func Test_AddTx_OversizedData(t *testing.T) {
@@ -296,7 +327,7 @@ func Test_AddTx_OversizedData(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	const chainID = 2576980377
@@ -323,10 +354,6 @@ func Test_AddPreEIP155Tx(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -362,7 +389,7 @@ func Test_AddPreEIP155Tx(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	const chainID = 2576980377
@@ -379,30 +406,26 @@ func Test_AddPreEIP155Tx(t *testing.T) {
 	err = p.AddTx(ctx, tx, ip)
 	require.NoError(t, err)
 
-	rows, err := poolSqlDB.Query(ctx, "SELECT hash, encoded, decoded, status FROM pool.transaction")
+	poolTx, err := s.GetTxByHash(ctx, tx.Hash())
+	require.NoError(t, err)
+	encoded, err := poolTx.MarshalBinary()
+	require.NoError(t, err)
+	decoded, err := poolTx.MarshalJSON()
 	require.NoError(t, err)
-	defer rows.Close() // nolint:staticcheck
-
-	c := 0
-	for rows.Next() {
-		var hash, encoded, decoded, status string
-		err := rows.Scan(&hash, &encoded, &decoded, &status)
-		require.NoError(t, err)
-
-		b, err := tx.MarshalBinary()
-		require.NoError(t, err)
 
-		bJSON, err := tx.MarshalJSON()
-		require.NoError(t, err)
+	b, err = tx.MarshalBinary()
+	require.NoError(t, err)
+	bJSON, err := tx.MarshalJSON()
+	require.NoError(t, err)
 
-		assert.Equal(t, tx.Hash().String(), hash, "invalid hash")
-		assert.Equal(t, hex.EncodeToHex(b), encoded, "invalid encoded")
-		assert.JSONEq(t, string(bJSON), decoded, "invalid decoded")
-		assert.Equal(t, string(pool.TxStatusPending), status, "invalid tx status")
-		c++
-	}
+	assert.Equal(t, tx.Hash().String(), poolTx.Hash().String(), "invalid hash")
+	assert.Equal(t, hex.EncodeToHex(b), hex.EncodeToHex(encoded), "invalid encoded")
+	assert.JSONEq(t, string(bJSON), string(decoded), "invalid decoded")
+	assert.Equal(t, pool.TxStatusPending, poolTx.Status, "invalid tx status")
 
-	assert.Equal(t, 1, c, "invalid number of txs in the pool")
+	c, err := s.CountTransactionsByStatus(ctx, pool.TxStatusPending)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), c, "invalid number of txs in the pool")
 }
 
 func Test_GetPendingTxs(t *testing.T) {
@@ -433,7 +456,7 @@ func Test_GetPendingTxs(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -465,6 +488,172 @@ func Test_GetPendingTxs(t *testing.T) {
 	}
 }
 
+func Test_ExportImportTxs(t *testing.T) {
+	initOrResetDB(t)
+
+	stateSqlDB, err := db.NewSQLDB(stateDBCfg)
+	require.NoError(t, err)
+	defer stateSqlDB.Close() //nolint:gosec,errcheck
+
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	st := newState(stateSqlDB, eventLog)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	ctx := context.Background()
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	_, err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	require.NoError(t, err)
+
+	const txsCount = 3
+	hashes := make([]common.Hash, 0, txsCount)
+	for i := 0; i < txsCount; i++ {
+		tx := ethTypes.NewTransaction(uint64(i), common.Address{}, big.NewInt(10), gasLimit, gasPrice, []byte{})
+		signedTx, err := auth.Signer(auth.From, tx)
+		require.NoError(t, err)
+		require.NoError(t, p.AddTx(ctx, *signedTx, ip))
+		hashes = append(hashes, signedTx.Hash())
+	}
+	const bundleTxsCount = 2
+	bundleTxs := make([]ethTypes.Transaction, 0, bundleTxsCount)
+	for i := txsCount; i < txsCount+bundleTxsCount; i++ {
+		tx := ethTypes.NewTransaction(uint64(i), common.Address{}, big.NewInt(10), gasLimit, gasPrice, []byte{})
+		signedTx, err := auth.Signer(auth.From, tx)
+		require.NoError(t, err)
+		bundleTxs = append(bundleTxs, *signedTx)
+		hashes = append(hashes, signedTx.Hash())
+	}
+	bundleHash, err := p.AddBundle(ctx, bundleTxs, ip)
+	require.NoError(t, err)
+	exportedTx, err := s.GetTxByHash(ctx, hashes[0])
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	count, err := p.ExportTxs(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(txsCount+bundleTxsCount), count)
+	exported := buf.Bytes()
+
+	// the txs are added back keeping the time they were received, and the bundles as bundles
+	require.NoError(t, s.DeleteTransactionsByHashes(ctx, hashes))
+	result, err := p.ImportTxs(ctx, bytes.NewReader(exported))
+	require.NoError(t, err)
+	assert.Equal(t, pool.ImportResult{Read: txsCount + bundleTxsCount, Imported: txsCount + bundleTxsCount}, result)
+	importedTx, err := s.GetTxByHash(ctx, hashes[0])
+	require.NoError(t, err)
+	assert.Equal(t, pool.TxStatusPending, importedTx.Status)
+	assert.Equal(t, ip, importedTx.IP)
+	assert.Equal(t, exportedTx.ReceivedAt.Unix(), importedTx.ReceivedAt.Unix())
+	bundles, err := s.GetNonWIPPendingBundles(ctx)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, bundleHash, bundles[0].Hash)
+	require.Len(t, bundles[0].Txs, bundleTxsCount)
+	for i, tx := range bundles[0].Txs {
+		assert.Equal(t, bundleTxs[i].Hash(), tx.Hash())
+	}
+
+	// the txs already in the pool are skipped
+	result, err = p.ImportTxs(ctx, bytes.NewReader(exported))
+	require.NoError(t, err)
+	assert.Equal(t, pool.ImportResult{Read: txsCount + bundleTxsCount, Known: txsCount + bundleTxsCount}, result)
+}
+
+func Test_AddBundle(t *testing.T) {
+	initOrResetDB(t)
+
+	stateSqlDB, err := db.NewSQLDB(stateDBCfg)
+	require.NoError(t, err)
+	defer stateSqlDB.Close() //nolint:gosec,errcheck
+
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	st := newState(stateSqlDB, eventLog)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	ctx := context.Background()
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	_, err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	require.NoError(t, err)
+
+	txs := []ethTypes.Transaction{}
+	for i := 0; i < 4; i++ {
+		tx := ethTypes.NewTransaction(uint64(i), common.Address{}, big.NewInt(10), gasLimit, gasPrice, []byte{})
+		signedTx, err := auth.Signer(auth.From, tx)
+		require.NoError(t, err)
+		txs = append(txs, *signedTx)
+	}
+
+	_, err = p.AddBundle(ctx, []ethTypes.Transaction{}, ip)
+	assert.ErrorIs(t, err, pool.ErrEmptyBundle)
+
+	_, err = p.AddBundle(ctx, txs, ip)
+	assert.ErrorIs(t, err, pool.ErrBundleTooLarge)
+
+	_, err = p.AddBundle(ctx, []ethTypes.Transaction{txs[0], txs[0]}, ip)
+	assert.ErrorIs(t, err, pool.ErrDuplicatedBundleTx)
+
+	bundleHash, err := p.AddBundle(ctx, txs[:2], ip)
+	require.NoError(t, err)
+	assert.Equal(t, pool.BundleHash(txs[:2]), bundleHash)
+
+	// bundle txs are not returned as regular pending txs
+	pendingTxs, err := p.GetNonWIPPendingTxs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pendingTxs)
+
+	bundles, err := p.GetNonWIPPendingBundles(ctx)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, bundleHash, bundles[0].Hash)
+	require.Len(t, bundles[0].Txs, 2)
+	assert.Equal(t, txs[0].Hash(), bundles[0].Txs[0].Hash())
+	assert.Equal(t, txs[1].Hash(), bundles[0].Txs[1].Hash())
+	assert.Equal(t, pool.TxStatusPending, bundles[0].Txs[0].Status)
+}
+
 func Test_GetPendingTxsZeroPassed(t *testing.T) {
 	initOrResetDB(t)
 
@@ -493,7 +682,7 @@ func Test_GetPendingTxsZeroPassed(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -553,7 +742,7 @@ func Test_GetTopPendingTxByProfitabilityAndZkCounters(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -589,10 +778,6 @@ func Test_UpdateTxsStatus(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -613,7 +798,7 @@ func Test_UpdateTxsStatus(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -655,20 +840,13 @@ func Test_UpdateTxsStatus(t *testing.T) {
 		t.Error(err)
 	}
 
-	var count int
-	rows, err := poolSqlDB.Query(ctx, "SELECT status, failed_reason FROM pool.transaction WHERE hash = ANY($1)", []string{signedTx1.Hash().String(), signedTx2.Hash().String()})
-	if err != nil {
-		t.Error(err)
-	}
-	defer rows.Close() // nolint:staticcheck
-	var state, failedReason string
-	for rows.Next() {
-		count++
-		if err := rows.Scan(&state, &failedReason); err != nil {
-			t.Error(err)
-		}
+	for _, hash := range []common.Hash{signedTx1.Hash(), signedTx2.Hash()} {
+		poolTx, err := s.GetTxByHash(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, newStatus, poolTx.Status)
+		require.NotNil(t, poolTx.FailedReason)
+		assert.Equal(t, expectedFailedReason, *poolTx.FailedReason)
 	}
-	assert.Equal(t, 2, count)
 }
 
 func Test_UpdateTxStatus(t *testing.T) {
@@ -680,10 +858,6 @@ func Test_UpdateTxStatus(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -704,7 +878,7 @@ func Test_UpdateTxStatus(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -725,24 +899,89 @@ func Test_UpdateTxStatus(t *testing.T) {
 		t.Error(err)
 	}
 
-	rows, err := poolSqlDB.Query(ctx, "SELECT status, failed_reason FROM pool.transaction WHERE hash = $1", signedTx.Hash().Hex())
+	poolTx, err := s.GetTxByHash(ctx, signedTx.Hash())
 	require.NoError(t, err)
 
-	defer rows.Close() // nolint:staticcheck
-	var state, failedReason string
-	rows.Next()
-	if err := rows.Scan(&state, &failedReason); err != nil {
-		t.Error(err)
+	assert.Equal(t, pool.TxStatusInvalid, poolTx.Status)
+	require.NotNil(t, poolTx.FailedReason)
+	assert.Equal(t, expectedFailedReason, *poolTx.FailedReason)
+}
+
+func Test_SubscribePendingTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	initOrResetDB(t)
+
+	stateSqlDB, err := db.NewSQLDB(stateDBCfg)
+	require.NoError(t, err)
+	defer stateSqlDB.Close() //nolint:gosec,errcheck
+
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		log.Fatal(err)
 	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	st := newState(stateSqlDB, eventLog)
 
-	assert.Equal(t, pool.TxStatusInvalid, pool.TxStatus(state))
-	assert.Equal(t, expectedFailedReason, failedReason)
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	_, err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
+
+	notifications, err := p.SubscribePendingTxs(ctx)
+	require.NoError(t, err)
+	requireNotified := func(expected bool) {
+		select {
+		case <-notifications:
+			require.True(t, expected, "unexpected notification")
+		case <-time.After(time.Second):
+			require.False(t, expected, "notification not received")
+		}
+	}
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	require.NoError(t, err)
+	tx := ethTypes.NewTransaction(uint64(0), common.Address{}, big.NewInt(10), gasLimit, gasPrice, []byte{})
+	signedTx, err := auth.Signer(auth.From, tx)
+	require.NoError(t, err)
+
+	// a new pending tx is notified
+	require.NoError(t, p.AddTx(ctx, *signedTx, ip))
+	requireNotified(true)
+
+	// a tx leaving the pending status isn't notified
+	require.NoError(t, p.UpdateTxStatus(ctx, signedTx.Hash(), pool.TxStatusSelected, false, nil))
+	requireNotified(false)
+
+	// a tx back to pending is notified
+	require.NoError(t, p.UpdateTxStatus(ctx, signedTx.Hash(), pool.TxStatusPending, false, nil))
+	requireNotified(true)
+
+	// the channel is closed when the subscription ends
+	cancel()
+	_, ok := <-notifications
+	require.False(t, ok)
 }
 
 func Test_SetAndGetGasPrice(t *testing.T) {
 	initOrResetDB(t)
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	eventStorage, err := nileventstorage.NewNilEventStorage()
@@ -767,7 +1006,7 @@ func Test_SetAndGetGasPrice(t *testing.T) {
 func TestDeleteGasPricesHistoryOlderThan(t *testing.T) {
 	initOrResetDB(t)
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	eventStorage, err := nileventstorage.NewNilEventStorage()
@@ -841,7 +1080,7 @@ func TestGetPendingTxSince(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -908,10 +1147,6 @@ func Test_DeleteTransactionsByHashes(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -933,7 +1168,7 @@ func Test_DeleteTransactionsByHashes(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -958,10 +1193,13 @@ func Test_DeleteTransactionsByHashes(t *testing.T) {
 	err = p.DeleteTransactionsByHashes(ctx, []common.Hash{signedTx1.Hash(), signedTx2.Hash()})
 	require.NoError(t, err)
 
-	var count int
-	err = poolSqlDB.QueryRow(ctx, "SELECT COUNT(*) FROM pool.transaction").Scan(&count)
+	for _, hash := range []common.Hash{signedTx1.Hash(), signedTx2.Hash()} {
+		_, err := s.GetTxByHash(ctx, hash)
+		require.ErrorIs(t, err, pool.ErrNotFound)
+	}
+	count, err := s.CountTransactionsByStatus(ctx, pool.TxStatusPending)
 	require.NoError(t, err)
-	assert.Equal(t, 0, count)
+	assert.Equal(t, uint64(0), count)
 }
 
 func Test_TryAddIncompatibleTxs(t *testing.T) {
@@ -1008,7 +1246,7 @@ func Test_TryAddIncompatibleTxs(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	type testCase struct {
@@ -1095,12 +1333,15 @@ func Test_TryAddIncompatibleTxs(t *testing.T) {
 func newState(sqlDB *pgxpool.Pool, eventLog *event.EventLog) *state.State {
 	ctx := context.Background()
 	stateDb := state.NewPostgresStorage(state.Config{}, sqlDB)
-	zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
+	stateDBClient, executorClient, fake := fakeexecutor.NewFromEnv()
+	if !fake {
+		zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
 
-	executorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
-	mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
-	executorClient, _, _ := executor.NewExecutorClient(ctx, executorServerConfig)
-	stateDBClient, _, _ := merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
+		executorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
+		mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
+		executorClient, _, _ = executor.NewExecutorClient(ctx, executorServerConfig)
+		stateDBClient, _, _ = merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
+	}
 	stateTree := merkletree.NewStateTree(stateDBClient)
 
 	st := state.NewState(state.Config{MaxCumulativeGasUsed: 800000, ChainID: chainID.Uint64(), ForkIDIntervals: []state.ForkIDInterval{{
@@ -1148,7 +1389,7 @@ func Test_AddTxWithIntrinsicGasTooLow(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
 
@@ -1327,7 +1568,7 @@ func Test_AddTx_GasPriceErr(t *testing.T) {
 			require.NoError(t, err)
 			require.NoError(t, dbTx.Commit(ctx))
 
-			s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+			s, err := newPoolStorage()
 			require.NoError(t, err)
 
 			p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1358,6 +1599,210 @@ func Test_AddTx_GasPriceErr(t *testing.T) {
 	}
 }
 
+func Test_AddTx_Sponsored(t *testing.T) {
+	testCases := []struct {
+		name          string
+		gasBudget     uint64
+		expectedError error
+	}{
+		{
+			name:      "sponsored tx with budget",
+			gasBudget: 1000000,
+		},
+		{
+			name:          "sponsored tx with exhausted budget",
+			gasBudget:     1,
+			expectedError: pool.ErrGasPrice,
+		},
+	}
+
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	sponsorshipCfg := cfg
+	sponsorshipCfg.Sponsorship = pool.SponsorshipCfg{Enabled: true, Period: cfgTypes.NewDuration(time.Hour)}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			initOrResetDB(t)
+
+			stateSqlDB, err := db.NewSQLDB(stateDBCfg)
+			require.NoError(t, err)
+			defer stateSqlDB.Close() //nolint:gosec,errcheck
+
+			st := newState(stateSqlDB, eventLog)
+
+			genesisBlock := state.Block{
+				BlockNumber: 0,
+				BlockHash:   state.ZeroHash,
+				ParentHash:  state.ZeroHash,
+				ReceivedAt:  time.Now(),
+			}
+			genesis := state.Genesis{
+				GenesisActions: []*state.GenesisAction{
+					{
+						Address: senderAddress,
+						Type:    int(merkletree.LeafTypeBalance),
+						Value:   "1000000000000000000000",
+					},
+				},
+			}
+			ctx := context.Background()
+			dbTx, err := st.BeginStateTransaction(ctx)
+			require.NoError(t, err)
+			_, err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+			require.NoError(t, err)
+			require.NoError(t, dbTx.Commit(ctx))
+
+			s, err := newPoolStorage()
+			require.NoError(t, err)
+
+			sender := common.HexToAddress(senderAddress)
+			require.NoError(t, s.AddAddressesToPolicy(ctx, pool.Sponsor, []common.Address{sender}, nil))
+			require.NoError(t, s.SetSponsorBudget(ctx, []common.Address{sender}, tc.gasBudget))
+
+			p := setupPool(t, sponsorshipCfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
+			to := common.HexToAddress("0x1")
+			tx := ethTypes.NewTx(&ethTypes.LegacyTx{
+				Nonce:    0,
+				To:       &to,
+				Value:    big.NewInt(0),
+				Gas:      gasLimit,
+				GasPrice: big.NewInt(0),
+			})
+			privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+			require.NoError(t, err)
+
+			auth, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(int64(chainID.Uint64())))
+			require.NoError(t, err)
+
+			signedTx, err := auth.Signer(auth.From, tx)
+			require.NoError(t, err)
+
+			err = p.AddTx(ctx, *signedTx, ip)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			poolTx, err := s.GetTxByHash(ctx, signedTx.Hash())
+			require.NoError(t, err)
+			require.NotNil(t, poolTx.Sponsor)
+			assert.Equal(t, sender, *poolTx.Sponsor)
+
+			// a sponsored tx replaces another sponsored tx without a gas price bump
+			replacementTx := ethTypes.NewTx(&ethTypes.LegacyTx{
+				Nonce:    0,
+				To:       &to,
+				Value:    big.NewInt(1),
+				Gas:      gasLimit,
+				GasPrice: big.NewInt(0),
+			})
+			signedReplacementTx, err := auth.Signer(auth.From, replacementTx)
+			require.NoError(t, err)
+			require.NoError(t, p.AddTx(ctx, *signedReplacementTx, ip))
+
+			// the sponsor is only charged when the tx is processed by the sequencer
+			budgets, err := s.ListSponsorBudgets(ctx)
+			require.NoError(t, err)
+			require.Len(t, budgets, 1)
+			assert.Equal(t, uint64(0), budgets[0].GasUsed)
+			charged, err := p.ChargeSponsor(ctx, signedReplacementTx.Hash(), 21000)
+			require.NoError(t, err)
+			assert.True(t, charged)
+			budgets, err = s.ListSponsorBudgets(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(21000), budgets[0].GasUsed)
+
+			// the budget can't be overspent
+			charged, err = p.ChargeSponsor(ctx, signedReplacementTx.Hash(), tc.gasBudget)
+			require.NoError(t, err)
+			assert.False(t, charged)
+			budgets, err = s.ListSponsorBudgets(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(21000), budgets[0].GasUsed)
+		})
+	}
+}
+
+func Test_EstimateEffectiveGasPrice(t *testing.T) {
+	initOrResetDB(t)
+
+	stateSqlDB, err := db.NewSQLDB(stateDBCfg)
+	require.NoError(t, err)
+	defer stateSqlDB.Close() //nolint:gosec,errcheck
+
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	st := newState(stateSqlDB, eventLog)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	ctx := context.Background()
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	_, err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+
+	sponsorshipCfg := cfg
+	sponsorshipCfg.EffectiveGasPrice.Enabled = true
+	sponsorshipCfg.Sponsorship = pool.SponsorshipCfg{Enabled: true, Period: cfgTypes.NewDuration(time.Hour)}
+	p := setupPool(t, sponsorshipCfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	require.NoError(t, err)
+	to := common.HexToAddress("0x1")
+	tx := ethTypes.NewTx(&ethTypes.LegacyTx{
+		Nonce:    0,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Gas:      gasLimit,
+		GasPrice: big.NewInt(0),
+		Data:     []byte{0x01, 0x02},
+	})
+	signedTx, err := auth.Signer(auth.From, tx)
+	require.NoError(t, err)
+
+	// the values are calculated from the same encoded tx
+	estimation, err := p.EstimateEffectiveGasPrice(ctx, *signedTx)
+	require.NoError(t, err)
+	assert.False(t, estimation.Sponsored)
+	assert.False(t, estimation.Accepted)
+	assert.Equal(t, pool.ErrEffectiveGasPriceGasPriceTooLow.Error(), estimation.RejectReason)
+	breakEvenGasPriceWithFactor := new(big.Int)
+	new(big.Float).Mul(new(big.Float).SetInt(estimation.BreakEven.BreakEvenGasPrice), big.NewFloat(sponsorshipCfg.EffectiveGasPrice.BreakEvenFactor)).Int(breakEvenGasPriceWithFactor)
+	assert.Equal(t, breakEvenGasPriceWithFactor, estimation.BreakEvenGasPriceWithFactor)
+
+	// the sponsored txs are accepted at their gas price
+	require.NoError(t, s.AddAddressesToPolicy(ctx, pool.Sponsor, []common.Address{auth.From}, nil))
+	require.NoError(t, s.SetSponsorBudget(ctx, []common.Address{auth.From}, 1000000))
+	estimation, err = p.EstimateEffectiveGasPrice(ctx, *signedTx)
+	require.NoError(t, err)
+	assert.True(t, estimation.Sponsored)
+	assert.True(t, estimation.Accepted)
+	assert.Empty(t, estimation.RejectReason)
+	assert.Equal(t, big.NewInt(0), estimation.EffectiveGasPrice)
+	assert.Equal(t, state.MaxEffectivePercentage, estimation.EffectivePercentage)
+}
+
 func Test_AddRevertedTx(t *testing.T) {
 	initOrResetDB(t)
 
@@ -1386,7 +1831,7 @@ func Test_AddRevertedTx(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1428,10 +1873,6 @@ func Test_BlockedAddress(t *testing.T) {
 	require.NoError(t, err)
 	defer stateSqlDB.Close() //nolint:gosec,errcheck
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
 		log.Fatal(err)
@@ -1466,8 +1907,7 @@ func Test_BlockedAddress(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
-
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	cfg := pool.Config{
@@ -1503,8 +1943,7 @@ func Test_BlockedAddress(t *testing.T) {
 	require.NoError(t, err)
 
 	// block address
-	_, err = poolSqlDB.Exec(ctx, "INSERT INTO pool.blocked(addr) VALUES($1)", auth.From.String())
-	require.NoError(t, err)
+	require.NoError(t, s.AddBlockedAddresses(ctx, []common.Address{auth.From}))
 
 	// wait it to refresh
 	time.Sleep(cfg.IntervalToRefreshBlockedAddresses.Duration)
@@ -1524,8 +1963,7 @@ func Test_BlockedAddress(t *testing.T) {
 	require.Equal(t, pool.ErrBlockedSender, err)
 
 	// remove block
-	_, err = poolSqlDB.Exec(ctx, "DELETE FROM pool.blocked WHERE addr = $1", auth.From.String())
-	require.NoError(t, err)
+	require.NoError(t, s.RemoveBlockedAddresses(ctx, []common.Address{auth.From}))
 
 	// wait it to refresh
 	time.Sleep(cfg.IntervalToRefreshBlockedAddresses.Duration)
@@ -1602,7 +2040,7 @@ func Test_AddTx_GasOverBatchLimit(t *testing.T) {
 			require.NoError(t, err)
 			require.NoError(t, dbTx.Commit(ctx))
 
-			s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+			s, err := newPoolStorage()
 			require.NoError(t, err)
 
 			p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1676,7 +2114,7 @@ func Test_AddTx_AccountQueueLimit(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1777,7 +2215,7 @@ func Test_AddTx_GlobalQueueLimit(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1863,7 +2301,7 @@ func Test_AddTx_NonceTooHigh(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := setupPool(t, cfg, bc, s, st, chainID.Uint64(), ctx, eventLog)
@@ -1933,7 +2371,7 @@ func Test_AddTx_IPValidation(t *testing.T) {
 			require.NoError(t, err)
 			require.NoError(t, dbTx.Commit(ctx))
 
-			s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+			s, err := newPoolStorage()
 			require.NoError(t, err)
 
 			const chainID = 2576980377
@@ -1956,7 +2394,7 @@ func Test_AddTx_IPValidation(t *testing.T) {
 	}
 }
 
-func setupPool(t *testing.T, cfg pool.Config, constraintsCfg state.BatchConstraintsCfg, s *pgpoolstorage.PostgresPoolStorage, st *state.State, chainID uint64, ctx context.Context, eventLog *event.EventLog) *pool.Pool {
+func setupPool(t *testing.T, cfg pool.Config, constraintsCfg state.BatchConstraintsCfg, s pool.Storage, st *state.State, chainID uint64, ctx context.Context, eventLog *event.EventLog) *pool.Pool {
 	err := s.SetGasPrices(ctx, gasPrice.Uint64(), l1GasPrice.Uint64())
 	require.NoError(t, err)
 	p := pool.NewPool(cfg, constraintsCfg, s, st, chainID, eventLog)
@@ -1999,7 +2437,7 @@ func prepareToExecuteTx(t *testing.T, chainIDToCreate uint64) testData {
 	require.NoError(t, err)
 	require.NoError(t, dbTx.Commit(ctx))
 
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := setupPool(t, cfg, bc, s, st, chainIDToCreate, ctx, eventLog)
@@ -2027,12 +2465,8 @@ func createSignedTx(t *testing.T, dataLen int, gasPrice *big.Int, gasLimit uint6
 func Test_PolicyAcl(t *testing.T) {
 	initOrResetDB(t)
 
-	poolSqlDB, err := db.NewSQLDB(poolDBCfg)
-	require.NoError(t, err)
-	defer poolSqlDB.Close() //nolint:gosec,errcheck
-
 	ctx := context.Background()
-	s, err := pgpoolstorage.NewPostgresPoolStorage(poolDBCfg)
+	s, err := newPoolStorage()
 	require.NoError(t, err)
 
 	p := pool.NewPool(cfg, bc, s, nil, uint64(1), nil)
@@ -2056,9 +2490,7 @@ func Test_PolicyAcl(t *testing.T) {
 
 	// put addr on lists
 	for _, policy := range []pool.PolicyName{pool.SendTx, pool.Deploy} {
-		ctag, err := poolSqlDB.Exec(ctx, "INSERT INTO pool.acl (policy, address) VALUES ($1,$2)", policy, addr.Hex())
-		require.NoError(t, err)
-		require.Equal(t, int64(1), ctag.RowsAffected())
+		require.NoError(t, s.AddAddressesToPolicy(ctx, policy, []common.Address{addr}, nil))
 	}
 
 	// addr should not be denied by policy
@@ -2069,9 +2501,9 @@ func Test_PolicyAcl(t *testing.T) {
 	}
 
 	// change policies to allow by acl
-	ctag, err := poolSqlDB.Exec(ctx, "UPDATE pool.policy SET allow = true")
-	require.NoError(t, err)
-	require.Equal(t, int64(2), ctag.RowsAffected())
+	for _, policy := range []pool.PolicyName{pool.SendTx, pool.Deploy} {
+		require.NoError(t, s.UpdatePolicy(ctx, policy, true))
+	}
 
 	// addr is now allowed
 	for _, policy := range []pool.PolicyName{pool.SendTx, pool.Deploy} {
@@ -2088,4 +2520,60 @@ func Test_PolicyAcl(t *testing.T) {
 			require.False(t, allow)
 		}
 	}
+
+	// expired addrs are ignored
+	expired := time.Now().Add(-time.Minute)
+	expiredAddr := randAddr()
+	require.NoError(t, s.AddAddressesToPolicy(ctx, pool.SendTx, []common.Address{expiredAddr}, &expired))
+	allow, err := s.CheckPolicy(ctx, pool.SendTx, expiredAddr)
+	require.NoError(t, err)
+	require.False(t, allow)
+	acl, err := s.ListAcl(ctx, pool.SendTx, []common.Address{addr, expiredAddr})
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{addr}, acl)
+
+	// call rules are a deny list of function selectors per target unless the target is in allow mode
+	target := randAddr()
+	selector := pool.Selector{0xa9, 0x05, 0x9c, 0xbb}
+	allow, err = p.CheckCallPolicy(ctx, target, selector[:])
+	require.NoError(t, err)
+	require.True(t, allow)
+	require.NoError(t, s.AddCallRules(ctx, []pool.CallRule{{Target: target, Selector: selector}}))
+	allow, err = p.CheckCallPolicy(ctx, target, selector[:])
+	require.NoError(t, err)
+	require.False(t, allow)
+	require.NoError(t, s.UpdateCallPolicy(ctx, []common.Address{target}, true))
+	allow, err = p.CheckCallPolicy(ctx, target, selector[:])
+	require.NoError(t, err)
+	require.True(t, allow)
+	allow, err = p.CheckCallPolicy(ctx, target, nil)
+	require.NoError(t, err)
+	require.False(t, allow)
+	policies, err := s.ListCallPolicies(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []pool.CallPolicy{{Target: target, Allow: true}}, policies)
+
+	// ip rules are a deny list of ip ranges
+	_, ipRange, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	require.NoError(t, s.AddIPRules(ctx, []pool.IPRule{{Range: *ipRange}}))
+	allow, err = p.CheckIPPolicy(ctx, net.ParseIP("10.1.2.3"))
+	require.NoError(t, err)
+	require.False(t, allow)
+	allow, err = p.CheckIPPolicy(ctx, net.ParseIP("192.168.1.1"))
+	require.NoError(t, err)
+	require.True(t, allow)
+	rules, err := s.ListIPRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, ipRange.String(), rules[0].Range.String())
+
+	// the max value per tx starts without limit
+	maxValue, err := p.GetMaxTxValue(ctx)
+	require.NoError(t, err)
+	require.Nil(t, maxValue)
+	require.NoError(t, s.SetMaxTxValue(ctx, big.NewInt(1000)))
+	maxValue, err = p.GetMaxTxValue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), maxValue)
 }
@@ -0,0 +1,181 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Offense is a kind of submission that worsens the reputation of the source IP and the sender of a tx
+type Offense string
+
+const (
+	// OffenseInvalid is a tx rejected by the pool validations, e.g. with a wrong nonce or without funds
+	OffenseInvalid Offense = "invalid"
+	// OffenseReverted is a tx reverted in the pre execution
+	OffenseReverted Offense = "reverted"
+	// OffenseOOC is a tx rejected because it's out of counters in the pre execution
+	OffenseOOC Offense = "ooc"
+	// OffenseUnderpriced is a tx rejected because of its gas price
+	OffenseUnderpriced Offense = "underpriced"
+)
+
+// Reputation is the record of the offenses of a source IP or a sender in the current window
+// and of the times it has been banned
+type Reputation struct {
+	// Subject is the source IP or the checksummed address of the sender
+	Subject     string
+	Invalid     uint64
+	Reverted    uint64
+	OOC         uint64
+	Underpriced uint64
+	// WindowStart is the time the offenses started to be counted
+	WindowStart time.Time
+	// Bans is the number of times the subject has been banned
+	Bans uint64
+	// BannedUntil is the end of the current or the last ban, nil if the subject has never been banned
+	BannedUntil *time.Time
+}
+
+// Offenses returns the number of offenses of the subject in the current window
+func (r Reputation) Offenses() uint64 {
+	return r.Invalid + r.Reverted + r.OOC + r.Underpriced
+}
+
+// IsBanned returns if the subject is banned at the provided time
+func (r Reputation) IsBanned(now time.Time) bool {
+	return r.BannedUntil != nil && r.BannedUntil.After(now)
+}
+
+// ReputationSubject returns the subject used to track the reputation of a source IP or an address,
+// the IPs are normalized and the addresses checksummed. It returns an empty string if the input is
+// neither an IP nor an address
+func ReputationSubject(input string) string {
+	if common.IsHexAddress(input) {
+		return common.HexToAddress(input).String()
+	}
+	if ip := net.ParseIP(input); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// offenseOf returns the offense committed by a tx rejected with err, the txs rejected because of the
+// pool limits, the policies or internal errors are not offenses
+func offenseOf(err error) (Offense, bool) {
+	switch {
+	case errors.Is(err, ErrOutOfCounters):
+		return OffenseOOC, true
+	case errors.Is(err, ErrGasPrice),
+		errors.Is(err, ErrEffectiveGasPriceGasPriceTooLow),
+		errors.Is(err, ErrReplaceUnderpriced):
+		return OffenseUnderpriced, true
+	case errors.Is(err, ErrInvalidSender),
+		errors.Is(err, ErrInvalidChainID),
+		errors.Is(err, ErrTxTypeNotSupported),
+		errors.Is(err, ErrOversizedData),
+		errors.Is(err, ErrNegativeValue),
+		errors.Is(err, ErrNonceTooLow),
+		errors.Is(err, ErrNonceTooHigh),
+		errors.Is(err, ErrInsufficientFunds),
+		errors.Is(err, ErrIntrinsicGas),
+		errors.Is(err, ErrGasUintOverflow),
+		errors.Is(err, ErrGasLimit):
+		return OffenseInvalid, true
+	}
+	return "", false
+}
+
+// reputationSubjects returns the subjects whose reputation is affected by a tx: its source IP, when known,
+// and its sender, when the signature is valid
+func reputationSubjects(tx types.Transaction, ip string) []string {
+	subjects := make([]string, 0, 2) //nolint:gomnd
+	if subject := ReputationSubject(ip); subject != "" {
+		subjects = append(subjects, subject)
+	}
+	if from, err := state.GetSender(tx); err == nil {
+		subjects = append(subjects, from.String())
+	}
+	return subjects
+}
+
+// checkReputation rejects the txs from banned source IPs or senders. It returns true if the source IP
+// or the sender has reached the LimitThreshold in the current window, so its txs must be limited
+func (p *Pool) checkReputation(ctx context.Context, ip string, from common.Address) (bool, error) {
+	if !p.cfg.Reputation.Enabled {
+		return false, nil
+	}
+
+	now := time.Now()
+	limited := false
+	for _, subject := range []string{ReputationSubject(ip), from.String()} {
+		if subject == "" {
+			continue
+		}
+		reputation, err := p.storage.GetReputation(ctx, subject)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			log.Errorf("failed to get reputation of %s: %v", subject, err)
+			return false, err
+		}
+		if reputation.IsBanned(now) {
+			log.Infof("%v: %s until %s", ErrBannedByReputation.Error(), subject, reputation.BannedUntil.String())
+			return false, ErrBannedByReputation
+		}
+		windowExpired := reputation.WindowStart.Add(p.cfg.Reputation.Window.Duration).Before(now)
+		if !windowExpired && p.cfg.Reputation.LimitThreshold > 0 && reputation.Offenses() >= p.cfg.Reputation.LimitThreshold {
+			limited = true
+		}
+	}
+	return limited, nil
+}
+
+// recordRejection records the offense committed by a tx rejected with err, if any
+func (p *Pool) recordRejection(ctx context.Context, tx types.Transaction, ip string, err error) {
+	if offense, ok := offenseOf(err); ok {
+		p.recordOffense(ctx, tx, ip, offense)
+	}
+}
+
+// recordOffense worsens the reputation of the source IP and the sender of a tx and bans them when they
+// reach the BanThreshold in the current window. Each ban lasts twice the previous one, up to MaxBanDuration.
+// The failures to record the offense are logged, they don't affect the tx
+func (p *Pool) recordOffense(ctx context.Context, tx types.Transaction, ip string, offense Offense) {
+	if !p.cfg.Reputation.Enabled {
+		return
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-p.cfg.Reputation.Window.Duration)
+	for _, subject := range reputationSubjects(tx, ip) {
+		reputation, err := p.storage.AddOffense(ctx, subject, offense, windowStart)
+		if err != nil {
+			log.Errorf("failed to add %s offense of tx %s to the reputation of %s: %v", offense, tx.Hash().String(), subject, err)
+			continue
+		}
+		if p.cfg.Reputation.BanThreshold == 0 || reputation.Offenses() < p.cfg.Reputation.BanThreshold {
+			continue
+		}
+
+		banDuration := p.cfg.Reputation.BanDuration.Duration
+		for i := uint64(0); i < reputation.Bans && banDuration < p.cfg.Reputation.MaxBanDuration.Duration; i++ {
+			banDuration *= 2 //nolint:gomnd
+		}
+		if p.cfg.Reputation.MaxBanDuration.Duration > 0 && banDuration > p.cfg.Reputation.MaxBanDuration.Duration {
+			banDuration = p.cfg.Reputation.MaxBanDuration.Duration
+		}
+		bannedUntil := now.Add(banDuration)
+		if err := p.storage.BanSubject(ctx, subject, bannedUntil); err != nil {
+			log.Errorf("failed to ban %s: %v", subject, err)
+			continue
+		}
+		log.Warnf("%s banned until %s after %d offenses, ban number %d", subject, bannedUntil.String(), reputation.Offenses(), reputation.Bans+1)
+	}
+}
@@ -0,0 +1,136 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/test/operations"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Reputation(t *testing.T) {
+	if storageBackend == pool.StoragePostgres {
+		initOrResetDB(t)
+	}
+	ctx := context.Background()
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	reputationCfg := cfg
+	reputationCfg.Reputation = pool.ReputationCfg{
+		Enabled:             true,
+		Window:              cfgTypes.NewDuration(time.Hour),
+		LimitThreshold:      2,
+		LimitedAccountQueue: 1,
+		BanThreshold:        3,
+		BanDuration:         cfgTypes.NewDuration(time.Minute),
+		MaxBanDuration:      cfgTypes.NewDuration(90 * time.Second),
+	}
+	// the reputation is only tracked in the pool storage, so the pool doesn't need the state
+	p := pool.NewPool(reputationCfg, bc, s, nil, chainID.Uint64(), nil)
+
+	auth, err := operations.GetAuth(senderPrivateKey, chainID.Uint64())
+	require.NoError(t, err)
+	tx, err := auth.Signer(auth.From, ethTypes.NewTransaction(0, common.Address{}, big.NewInt(0), gasLimit, gasPrice, nil))
+	require.NoError(t, err)
+	from := common.HexToAddress(senderAddress)
+
+	// the rejections that aren't offenses don't affect the reputation
+	p.RecordRejection(ctx, *tx, ip, pool.ErrTxPoolOverflow)
+	_, err = p.GetReputation(ctx, ip)
+	require.ErrorIs(t, err, pool.ErrNotFound)
+
+	p.RecordRejection(ctx, *tx, ip, pool.ErrOutOfCounters)
+	limited, err := p.CheckReputation(ctx, ip, from)
+	require.NoError(t, err)
+	assert.False(t, limited)
+
+	// the txs are limited after LimitThreshold offenses
+	p.RecordRejection(ctx, *tx, ip, pool.ErrGasPrice)
+	limited, err = p.CheckReputation(ctx, ip, from)
+	require.NoError(t, err)
+	assert.True(t, limited)
+
+	// the txs are rejected after BanThreshold offenses, both the IP and the sender are banned
+	p.RecordRejection(ctx, *tx, ip, pool.ErrNonceTooLow)
+	_, err = p.CheckReputation(ctx, ip, from)
+	assert.ErrorIs(t, err, pool.ErrBannedByReputation)
+	_, err = p.CheckReputation(ctx, "", from)
+	assert.ErrorIs(t, err, pool.ErrBannedByReputation)
+
+	reputation, err := p.GetReputation(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), reputation.Bans)
+	assert.Zero(t, reputation.Offenses())
+	require.NotNil(t, reputation.BannedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *reputation.BannedUntil, 5*time.Second)
+
+	// the next ban lasts twice the previous one, up to MaxBanDuration
+	for i := 0; i < 3; i++ {
+		p.RecordRejection(ctx, *tx, ip, pool.ErrInsufficientFunds)
+	}
+	reputation, err = p.GetReputation(ctx, from.String())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), reputation.Bans)
+	assert.WithinDuration(t, time.Now().Add(90*time.Second), *reputation.BannedUntil, 5*time.Second)
+
+	reputations, err := p.ListReputations(ctx)
+	require.NoError(t, err)
+	assert.Len(t, reputations, 2)
+
+	// clearing the reputation lifts the ban
+	require.NoError(t, p.DeleteReputation(ctx, ip))
+	require.NoError(t, p.DeleteReputation(ctx, from.String()))
+	limited, err = p.CheckReputation(ctx, ip, from)
+	require.NoError(t, err)
+	assert.False(t, limited)
+}
+
+func Test_ImportTxsDoesNotRecordOffenses(t *testing.T) {
+	if storageBackend == pool.StoragePostgres {
+		initOrResetDB(t)
+	}
+	ctx := context.Background()
+
+	s, err := newPoolStorage()
+	require.NoError(t, err)
+	reputationCfg := cfg
+	reputationCfg.Reputation = pool.ReputationCfg{
+		Enabled:        true,
+		Window:         cfgTypes.NewDuration(time.Hour),
+		BanThreshold:   1,
+		BanDuration:    cfgTypes.NewDuration(time.Minute),
+		MaxBanDuration: cfgTypes.NewDuration(time.Minute),
+	}
+	p := pool.NewPool(reputationCfg, bc, s, nil, chainID.Uint64(), nil)
+
+	// the tx is signed for another chain, so it's rejected before it's pre-executed
+	auth, err := operations.GetAuth(senderPrivateKey, chainID.Uint64()+1)
+	require.NoError(t, err)
+	tx, err := auth.Signer(auth.From, ethTypes.NewTransaction(0, common.Address{}, big.NewInt(0), gasLimit, gasPrice, nil))
+	require.NoError(t, err)
+	rawTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	exported, err := json.Marshal(pool.ExportedTx{RawTx: rawTx, IP: ip, ReceivedAt: time.Now(), Status: pool.TxStatusPending})
+	require.NoError(t, err)
+
+	result, err := p.ImportTxs(ctx, bytes.NewReader(exported))
+	require.NoError(t, err)
+	assert.Equal(t, pool.ImportResult{Read: 1, Rejected: 1}, result)
+	_, err = p.GetReputation(ctx, ip)
+	require.ErrorIs(t, err, pool.ErrNotFound)
+
+	// the same tx sent to the RPC is an offense
+	require.ErrorIs(t, p.AddTx(ctx, *tx, ip), pool.ErrInvalidChainID)
+	_, err = p.GetReputation(ctx, ip)
+	require.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package pool
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// getSponsor returns the address allowed by the sponsor policy that sponsors the tx, the tx receiver
+// is checked first and then the tx sender. It returns nil if the tx is not sponsored
+func (p *Pool) getSponsor(ctx context.Context, tx types.Transaction) (*common.Address, error) {
+	if !p.cfg.Sponsorship.Enabled {
+		return nil, nil
+	}
+
+	candidates := make([]common.Address, 0, 2) //nolint:gomnd
+	if tx.To() != nil {
+		candidates = append(candidates, *tx.To())
+	}
+	// an invalid sender is rejected later by the tx validation
+	if from, err := state.GetSender(tx); err == nil {
+		candidates = append(candidates, from)
+	}
+
+	for _, candidate := range candidates {
+		sponsored, err := p.storage.CheckPolicy(ctx, Sponsor, candidate)
+		if err != nil {
+			log.Errorf("failed to check sponsor policy for address %s: %v", candidate.String(), err)
+			return nil, err
+		}
+		if sponsored {
+			sponsor := candidate
+			return &sponsor, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkSponsorBudget returns the sponsor if the budget left to it for the current period covers the gas used by
+// the tx. If the budget is exhausted the tx is not sponsored, so it must pass the min gas price check. The budget
+// isn't charged here, the sequencer charges it before processing the tx, see ChargeSponsor
+func (p *Pool) checkSponsorBudget(ctx context.Context, tx types.Transaction, sponsor common.Address, gasUsed uint64) (*common.Address, error) {
+	covered, err := p.storage.CheckSponsorBudget(ctx, sponsor, gasUsed, p.cfg.Sponsorship.Period.Duration)
+	if err != nil {
+		log.Errorf("failed to check budget of sponsor %s for tx %s: %v", sponsor.String(), tx.Hash().String(), err)
+		return nil, err
+	}
+	if covered {
+		log.Infof("tx %s sponsored by %s, gas used: %d", tx.Hash().String(), sponsor.String(), gasUsed)
+		return &sponsor, nil
+	}
+
+	log.Infof("budget of sponsor %s exhausted, tx %s is not sponsored", sponsor.String(), tx.Hash().String())
+	if err := p.checkMinGasPrice(tx); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// isSponsored returns if the tx has a sponsor whose budget left covers the gas used by the tx
+func (p *Pool) isSponsored(ctx context.Context, tx types.Transaction, gasUsed uint64) (bool, error) {
+	sponsor, err := p.getSponsor(ctx, tx)
+	if err != nil || sponsor == nil {
+		return false, err
+	}
+	return p.storage.CheckSponsorBudget(ctx, *sponsor, gasUsed, p.cfg.Sponsorship.Period.Duration)
+}
+
+// ChargeSponsor charges the gas used by a tx to the budget of its sponsor for the current period if the budget left
+// covers it. It returns false, without charging the budget, if the tx is not sponsored or the budget is exhausted,
+// so the tx must be processed as a not sponsored one
+func (p *Pool) ChargeSponsor(ctx context.Context, hash common.Hash, gasUsed uint64) (bool, error) {
+	poolTx, err := p.storage.GetTxByHash(ctx, hash)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if poolTx.Sponsor == nil {
+		return false, nil
+	}
+
+	charged, err := p.storage.ChargeSponsorBudget(ctx, *poolTx.Sponsor, gasUsed, p.cfg.Sponsorship.Period.Duration)
+	if err != nil {
+		log.Errorf("failed to charge budget of sponsor %s for tx %s: %v", poolTx.Sponsor.String(), hash.String(), err)
+		return false, err
+	}
+	if !charged {
+		log.Infof("budget of sponsor %s exhausted, tx %s is not sponsored", poolTx.Sponsor.String(), hash.String())
+		return false, nil
+	}
+	log.Infof("sponsor %s charged for tx %s, gas used: %d", poolTx.Sponsor.String(), hash.String(), gasUsed)
+	return true, nil
+}
@@ -45,6 +45,8 @@ type Transaction struct {
 	IsWIP                 bool
 	IP                    string
 	FailedReason          *string
+	// Sponsor is the address allowed by the sponsor policy whose budget pays for the tx gas, nil if not sponsored
+	Sponsor *common.Address
 }
 
 // NewTransaction creates a new transaction
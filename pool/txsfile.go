@@ -0,0 +1,193 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ExportedTx is the portable representation of a pool tx, written by ExportTxs
+// and read by ImportTxs as one JSON object per line
+type ExportedTx struct {
+	RawTx      hexutil.Bytes `json:"rawTx"`
+	IP         string        `json:"ip"`
+	ReceivedAt time.Time     `json:"receivedAt"`
+	Status     TxStatus      `json:"status"`
+	// BundleHash is the hash of the bundle of the tx, nil if the tx doesn't belong to a bundle
+	BundleHash *common.Hash `json:"bundleHash,omitempty"`
+	// BundleIndex is the position of the tx in its bundle
+	BundleIndex int `json:"bundleIndex,omitempty"`
+}
+
+// ImportResult summarizes the txs read by ImportTxs
+type ImportResult struct {
+	// Read is the number of txs read from the file
+	Read uint64
+	// Imported is the number of txs added to the pool
+	Imported uint64
+	// Known is the number of txs skipped because they were already in the pool
+	Known uint64
+	// Rejected is the number of txs skipped because they are no longer valid
+	Rejected uint64
+}
+
+// ExportTxs writes the pending txs of the pool to w sorted by the time they were received, so
+// ImportTxs adds them back in the same order. The txs of a bundle are written together in the
+// bundle order. It returns the number of txs written
+func (p *Pool) ExportTxs(ctx context.Context, w io.Writer) (uint64, error) {
+	txs, err := p.storage.GetNonBundlePendingTxs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	bundles, err := p.storage.GetPendingBundles(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// every group is either a tx or the txs of a bundle
+	groups := make([][]ExportedTx, 0, len(txs)+len(bundles))
+	for _, tx := range txs {
+		exportedTx, err := newExportedTx(tx)
+		if err != nil {
+			return 0, err
+		}
+		groups = append(groups, []ExportedTx{exportedTx})
+	}
+	for _, bundle := range bundles {
+		group := make([]ExportedTx, 0, len(bundle.Txs))
+		for i, tx := range bundle.Txs {
+			exportedTx, err := newExportedTx(tx)
+			if err != nil {
+				return 0, err
+			}
+			bundleHash := bundle.Hash
+			exportedTx.BundleHash = &bundleHash
+			exportedTx.BundleIndex = i
+			group = append(group, exportedTx)
+		}
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i][0].ReceivedAt.Before(groups[j][0].ReceivedAt)
+	})
+
+	var count uint64
+	encoder := json.NewEncoder(w)
+	for _, group := range groups {
+		for _, exportedTx := range group {
+			if err := encoder.Encode(exportedTx); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// newExportedTx returns the portable representation of a pool tx
+func newExportedTx(tx Transaction) (ExportedTx, error) {
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return ExportedTx{}, fmt.Errorf("failed to encode tx %s: %w", tx.Hash().String(), err)
+	}
+	return ExportedTx{
+		RawTx:      rawTx,
+		IP:         tx.IP,
+		ReceivedAt: tx.ReceivedAt,
+		Status:     tx.Status,
+	}, nil
+}
+
+// ImportTxs reads the txs written by ExportTxs from r and adds them to the pool keeping the time
+// they were received, the txs of a bundle are added back as a bundle. The txs go through the same
+// validations as the txs sent to the RPC against the current min gas price, so the txs that are no
+// longer valid, e.g. because they were already mined, are logged and skipped without affecting the
+// reputation of their source IP and sender
+func (p *Pool) ImportTxs(ctx context.Context, r io.Reader) (ImportResult, error) {
+	// the min gas price is only polled by the pools started with the node
+	p.pollMinSuggestedGasPrice(ctx)
+
+	var (
+		result        ImportResult
+		bundleHash    *common.Hash
+		bundleTxs     []types.Transaction
+		bundleFirstTx ExportedTx
+	)
+	decoder := json.NewDecoder(r)
+	for {
+		var exportedTx ExportedTx
+		if err := decoder.Decode(&exportedTx); errors.Is(err, io.EOF) {
+			p.importBundle(ctx, bundleHash, bundleTxs, bundleFirstTx, &result)
+			return result, nil
+		} else if err != nil {
+			return result, fmt.Errorf("failed to decode tx %d: %w", result.Read+1, err)
+		}
+		result.Read++
+
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(exportedTx.RawTx); err != nil {
+			return result, fmt.Errorf("failed to decode raw tx %d: %w", result.Read, err)
+		}
+
+		// the bundle read so far is complete when the next tx doesn't belong to it
+		if bundleHash != nil && (exportedTx.BundleHash == nil || *exportedTx.BundleHash != *bundleHash) {
+			p.importBundle(ctx, bundleHash, bundleTxs, bundleFirstTx, &result)
+			bundleHash, bundleTxs = nil, nil
+		}
+
+		if exportedTx.Status != TxStatusPending {
+			log.Infof("skipping tx %s with status %s", tx.Hash().String(), exportedTx.Status)
+			result.Rejected++
+			continue
+		}
+
+		if exportedTx.BundleHash != nil {
+			if bundleHash == nil {
+				bundleHash = exportedTx.BundleHash
+				bundleFirstTx = exportedTx
+			}
+			bundleTxs = append(bundleTxs, tx)
+			continue
+		}
+
+		err := p.addTx(ctx, tx, exportedTx.IP, exportedTx.ReceivedAt, false)
+		countImported(&result, 1, err, "tx "+tx.Hash().String())
+	}
+}
+
+// importBundle adds back to the pool the txs read of the bundle with the provided hash, the bundle is
+// skipped if any of its txs is missing. It does nothing when bundleHash is nil
+func (p *Pool) importBundle(ctx context.Context, bundleHash *common.Hash, txs []types.Transaction, firstTx ExportedTx, result *ImportResult) {
+	if bundleHash == nil {
+		return
+	}
+	name := "bundle " + bundleHash.String()
+	if BundleHash(txs) != *bundleHash {
+		log.Infof("skipping %s: some of its txs are missing", name)
+		result.Rejected += uint64(len(txs))
+		return
+	}
+	_, err := p.addBundle(ctx, txs, firstTx.IP, firstTx.ReceivedAt, false)
+	countImported(result, uint64(len(txs)), err, name)
+}
+
+// countImported adds to the result the count txs imported as a tx or a bundle with the provided name
+func countImported(result *ImportResult, count uint64, err error, name string) {
+	if errors.Is(err, ErrAlreadyKnown) {
+		result.Known += count
+	} else if err != nil {
+		log.Infof("skipping %s: %v", name, err)
+		result.Rejected += count
+	} else {
+		result.Imported += count
+	}
+}
@@ -1,8 +1,30 @@
 package pool
 
-import "net"
+import (
+	"fmt"
+	"math/big"
+	"net"
+)
 
 // IsValidIP returns true if the given string is a valid IP address
 func IsValidIP(ip string) bool {
 	return ip != "" && net.ParseIP(ip) != nil
 }
+
+// checkReplacementGasPrice returns ErrReplaceUnderpriced if the gas price of a tx replacing another with the same
+// sender and nonce isn't higher than the gas price of the replaced tx bumped by the priceBump percentage. The
+// sponsored txs don't pay for their gas, so a sponsored tx replacing another only needs a gas price as high
+func checkReplacementGasPrice(oldGasPrice, newGasPrice *big.Int, priceBump uint64, sponsored bool) error {
+	if sponsored {
+		if newGasPrice.Cmp(oldGasPrice) < 0 {
+			return fmt.Errorf("%w: gas price %v of sponsored tx must be at least %v", ErrReplaceUnderpriced, newGasPrice, oldGasPrice)
+		}
+		return nil
+	}
+	minGasPrice := new(big.Int).Mul(oldGasPrice, new(big.Int).SetUint64(100+priceBump)) //nolint:gomnd
+	minGasPrice.Div(minGasPrice, big.NewInt(100))                                       //nolint:gomnd
+	if newGasPrice.Cmp(oldGasPrice) <= 0 || newGasPrice.Cmp(minGasPrice) < 0 {
+		return fmt.Errorf("%w: gas price %v must be at least %v (%d%% bump over %v)", ErrReplaceUnderpriced, newGasPrice, minGasPrice, priceBump, oldGasPrice)
+	}
+	return nil
+}
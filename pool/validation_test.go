@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,37 @@ func Test_IsValidIP(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkReplacementGasPrice(t *testing.T) {
+	var tests = []struct {
+		name        string
+		oldGasPrice int64
+		newGasPrice int64
+		priceBump   uint64
+		sponsored   bool
+		expectedErr error
+	}{
+		{"Bumped enough", 100, 110, 10, false, nil},
+		{"Bumped more than enough", 100, 200, 10, false, nil},
+		{"Not bumped enough", 100, 109, 10, false, ErrReplaceUnderpriced},
+		{"Same gas price", 100, 100, 10, false, ErrReplaceUnderpriced},
+		{"Lower gas price", 100, 50, 10, false, ErrReplaceUnderpriced},
+		{"Higher gas price without bump", 100, 101, 0, false, nil},
+		{"Same gas price without bump", 100, 100, 0, false, ErrReplaceUnderpriced},
+		{"Zero gas price replaced", 0, 1, 10, false, nil},
+		{"Zero gas price replaced by zero", 0, 0, 10, false, ErrReplaceUnderpriced},
+		{"Sponsored zero gas price replaced by zero", 0, 0, 10, true, nil},
+		{"Sponsored same gas price", 100, 100, 10, true, nil},
+		{"Sponsored lower gas price", 100, 50, 10, true, ErrReplaceUnderpriced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReplacementGasPrice(big.NewInt(tt.oldGasPrice), big.NewInt(tt.newGasPrice), tt.priceBump, tt.sponsored)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
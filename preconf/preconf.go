@@ -0,0 +1,82 @@
+package preconf
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// domainSeparator is prepended to the signed payload so a preconfirmation
+// signature can't be reused as the signature of any other kind of message
+var domainSeparator = []byte("zkevm-preconfirmation-v1")
+
+var (
+	// ErrInvalidSignature is returned when the signature of a preconfirmation is malformed
+	ErrInvalidSignature = errors.New("invalid preconfirmation signature")
+	// ErrUnexpectedSigner is returned when the preconfirmation was not signed by the expected sequencer
+	ErrUnexpectedSigner = errors.New("preconfirmation not signed by the expected sequencer")
+)
+
+// Preconfirmation is the promise of the trusted sequencer to include a tx
+// in the provided position of a batch. If the batch that reaches L1 doesn't
+// match the preconfirmation, the signed preconfirmation proves the sequencer
+// broke its promise
+type Preconfirmation struct {
+	TxHash        common.Hash    `json:"txHash"`
+	BatchNumber   hexutil.Uint64 `json:"batchNumber"`
+	L2BlockNumber hexutil.Uint64 `json:"l2BlockNumber"`
+	Position      hexutil.Uint64 `json:"position"`
+	StateRoot     common.Hash    `json:"stateRoot"`
+	Signature     hexutil.Bytes  `json:"signature"`
+}
+
+// Hash returns the digest signed by the sequencer, it covers all the
+// fields of the preconfirmation except the signature
+func (p *Preconfirmation) Hash() common.Hash {
+	data := make([]byte, 0, len(domainSeparator)+2*common.HashLength+3*8) //nolint:gomnd
+	data = append(data, domainSeparator...)
+	data = append(data, p.TxHash.Bytes()...)
+	data = binary.BigEndian.AppendUint64(data, uint64(p.BatchNumber))
+	data = binary.BigEndian.AppendUint64(data, uint64(p.L2BlockNumber))
+	data = binary.BigEndian.AppendUint64(data, uint64(p.Position))
+	data = append(data, p.StateRoot.Bytes()...)
+	return crypto.Keccak256Hash(data)
+}
+
+// Sign signs the preconfirmation with the provided sequencer key
+func (p *Preconfirmation) Sign(privateKey *ecdsa.PrivateKey) error {
+	signature, err := crypto.Sign(p.Hash().Bytes(), privateKey)
+	if err != nil {
+		return err
+	}
+	p.Signature = signature
+	return nil
+}
+
+// Signer recovers the address that signed the preconfirmation
+func (p *Preconfirmation) Signer() (common.Address, error) {
+	if len(p.Signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+	pubKey, err := crypto.SigToPub(p.Hash().Bytes(), p.Signature)
+	if err != nil {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// Verify checks the preconfirmation was signed by the provided sequencer address
+func (p *Preconfirmation) Verify(sequencer common.Address) error {
+	signer, err := p.Signer()
+	if err != nil {
+		return err
+	}
+	if signer != sequencer {
+		return ErrUnexpectedSigner
+	}
+	return nil
+}
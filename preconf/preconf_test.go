@@ -0,0 +1,49 @@
+package preconf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreconfirmationSignAndVerify(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sequencer := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	p := &Preconfirmation{
+		TxHash:        common.HexToHash("0x1"),
+		BatchNumber:   2,
+		L2BlockNumber: 3,
+		Position:      4,
+		StateRoot:     common.HexToHash("0x5"),
+	}
+	require.NoError(t, p.Sign(privateKey))
+	require.NoError(t, p.Verify(sequencer))
+
+	signer, err := p.Signer()
+	require.NoError(t, err)
+	assert.Equal(t, sequencer, signer)
+
+	// the preconfirmation survives a round trip through its json representation
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	var decoded Preconfirmation
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.NoError(t, decoded.Verify(sequencer))
+
+	// any change in the promised inclusion invalidates the signature
+	tampered := decoded
+	tampered.Position = 5
+	assert.ErrorIs(t, tampered.Verify(sequencer), ErrUnexpectedSigner)
+
+	assert.ErrorIs(t, p.Verify(common.HexToAddress("0x1")), ErrUnexpectedSigner)
+
+	tampered = decoded
+	tampered.Signature = tampered.Signature[:10]
+	assert.ErrorIs(t, tampered.Verify(sequencer), ErrInvalidSignature)
+}
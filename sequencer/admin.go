@@ -0,0 +1,182 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+)
+
+const (
+	// AdminAPIName is the namespace of the sequencer admin endpoints
+	AdminAPIName = "sequencer"
+
+	// adminMaxRequestsPerIPAndSecond is the rate limit of the admin server, it's intended for operators only
+	adminMaxRequestsPerIPAndSecond = 10
+)
+
+// AdminStatus is the finalizer state returned by sequencer_status
+type AdminStatus struct {
+	BatchNumber             types.ArgUint64      `json:"batchNumber"`
+	CountOfTxs              int                  `json:"countOfTxs"`
+	RemainingResources      state.BatchResources `json:"remainingResources"`
+	UsedResources           state.BatchResources `json:"usedResources"`
+	Paused                  bool                 `json:"paused"`
+	StopSequencerOnBatchNum types.ArgUint64      `json:"stopSequencerOnBatchNum"`
+	NextGERDeadline         int64                `json:"nextGERDeadline"`
+	NextForcedBatchDeadline int64                `json:"nextForcedBatchDeadline"`
+	PendingForcedBatches    int                  `json:"pendingForcedBatches"`
+}
+
+// AdminEndpoints contains the implementations of the "sequencer" RPC endpoints,
+// they allow operators to steer the running finalizer. All the requests must be
+// authenticated with the "Authorization: Bearer <APIKey>" header
+type AdminEndpoints struct {
+	apiKey    string
+	finalizer *finalizer
+	worker    *Worker
+	dbManager dbManagerInterface
+	eventLog  *event.EventLog
+}
+
+// newAdminEndpoints returns AdminEndpoints
+func newAdminEndpoints(apiKey string, finalizer *finalizer, worker *Worker, dbManager dbManagerInterface, eventLog *event.EventLog) *AdminEndpoints {
+	return &AdminEndpoints{
+		apiKey:    apiKey,
+		finalizer: finalizer,
+		worker:    worker,
+		dbManager: dbManager,
+		eventLog:  eventLog,
+	}
+}
+
+// PauseFinalizer stops the finalizer from processing new txs and closing batches
+func (a *AdminEndpoints) PauseFinalizer(httpRequest *http.Request) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+	a.finalizer.pause()
+	a.logAction(httpRequest, "finalizer paused")
+	return true, nil
+}
+
+// ResumeFinalizer resumes the processing of txs of a paused finalizer
+func (a *AdminEndpoints) ResumeFinalizer(httpRequest *http.Request) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+	a.finalizer.resume()
+	a.logAction(httpRequest, "finalizer resumed")
+	return true, nil
+}
+
+// CloseBatch requests the finalizer to close the WIP batch as soon as possible
+func (a *AdminEndpoints) CloseBatch(httpRequest *http.Request) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+	a.finalizer.requestCloseBatch()
+	a.logAction(httpRequest, "WIP batch close requested")
+	return true, nil
+}
+
+// SetStopBatch sets the batch number where the finalizer will halt, 0 disables it
+func (a *AdminEndpoints) SetStopBatch(httpRequest *http.Request, batchNumber types.ArgUint64) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+	a.finalizer.setStopSequencerOnBatchNum(uint64(batchNumber))
+	a.logAction(httpRequest, fmt.Sprintf("stop sequencer on batch number set to %d", uint64(batchNumber)))
+	return true, nil
+}
+
+// DropTx removes a tx from the worker and marks it as failed in the pool
+func (a *AdminEndpoints) DropTx(httpRequest *http.Request, hash types.ArgHash) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+
+	txHash := hash.Hash()
+	txTracker, err := a.worker.DropTx(txHash)
+	if errors.Is(err, ErrDropInProgress) {
+		return nil, types.NewRPCError(types.DefaultErrorCode, "tx %s is being processed by the sequencer, try again later", txHash.String())
+	} else if txTracker == nil {
+		return nil, types.NewRPCError(types.DefaultErrorCode, "tx %s not found in the worker", txHash.String())
+	}
+
+	failedReason := ErrDroppedByAdmin.Error()
+	err = a.dbManager.UpdateTxStatus(httpRequest.Context(), txHash, pool.TxStatusFailed, false, &failedReason)
+	if err != nil {
+		log.Errorf("failed to update status of tx %s dropped by the admin, err: %v", txHash.String(), err)
+		return nil, types.NewRPCError(types.DefaultErrorCode, "tx %s dropped from the worker but failed to update it in the pool", txHash.String())
+	}
+	a.logAction(httpRequest, fmt.Sprintf("tx %s dropped", txHash.String()))
+	return true, nil
+}
+
+// Status returns the current state of the finalizer
+func (a *AdminEndpoints) Status(httpRequest *http.Request) (interface{}, types.Error) {
+	if err := a.authorize(httpRequest); err != nil {
+		return nil, err
+	}
+
+	f := a.finalizer
+	status := AdminStatus{
+		Paused:                  f.paused.Load(),
+		StopSequencerOnBatchNum: types.ArgUint64(f.stopSequencerOnBatchNum.Load()),
+	}
+
+	f.sharedResourcesMux.RLock()
+	if f.batch != nil {
+		status.BatchNumber = types.ArgUint64(f.batch.batchNumber)
+		status.CountOfTxs = f.batch.countOfTxs
+		status.RemainingResources = f.batch.remainingResources
+		status.UsedResources = getUsedBatchResources(f.batchConstraints, f.batch.remainingResources)
+	}
+	f.sharedResourcesMux.RUnlock()
+
+	f.nextGERMux.RLock()
+	status.NextGERDeadline = f.nextGERDeadline
+	f.nextGERMux.RUnlock()
+
+	f.nextForcedBatchesMux.RLock()
+	status.NextForcedBatchDeadline = f.nextForcedBatchDeadline
+	status.PendingForcedBatches = len(f.nextForcedBatches)
+	f.nextForcedBatchesMux.RUnlock()
+
+	return status, nil
+}
+
+// authorize checks the request carries the configured API key
+func (a *AdminEndpoints) authorize(httpRequest *http.Request) types.Error {
+	return jsonrpc.AuthorizeAPIKey(httpRequest, a.apiKey)
+}
+
+// logAction stores the admin action in the event log
+func (a *AdminEndpoints) logAction(httpRequest *http.Request, description string) {
+	log.Infof("sequencer admin action: %s", description)
+	if a.eventLog == nil {
+		return
+	}
+
+	event := &event.Event{
+		ReceivedAt:  time.Now(),
+		IPAddress:   httpRequest.RemoteAddr,
+		Source:      event.Source_Node,
+		Component:   event.Component_Sequencer,
+		Level:       event.Level_Notice,
+		EventID:     event.EventID_SequencerAdminAction,
+		Description: description,
+	}
+	if err := a.eventLog.LogEvent(context.Background(), event); err != nil {
+		log.Errorf("error storing sequencer admin action event: %v", err)
+	}
+}
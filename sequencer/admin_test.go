@@ -0,0 +1,130 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminAPIKey = "secret"
+
+func TestAdminEndpointsAuthorization(t *testing.T) {
+	f := setupFinalizer(true)
+	admin := newAdminEndpoints(testAdminAPIKey, f, nil, dbManagerMock, f.eventLog)
+
+	testCases := []struct {
+		name          string
+		authorization string
+		expectedErr   bool
+	}{
+		{name: "missing header", authorization: "", expectedErr: true},
+		{name: "wrong scheme", authorization: "Basic " + testAdminAPIKey, expectedErr: true},
+		{name: "wrong key", authorization: "Bearer other", expectedErr: true},
+		{name: "valid key", authorization: "Bearer " + testAdminAPIKey, expectedErr: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			_, err := admin.PauseFinalizer(req)
+			if tc.expectedErr {
+				require.NotNil(t, err)
+				assert.Equal(t, types.AccessDeniedCode, err.ErrorCode())
+				assert.False(t, f.paused.Load())
+			} else {
+				require.Nil(t, err)
+				assert.True(t, f.paused.Load())
+			}
+		})
+	}
+}
+
+func TestAdminEndpointsFinalizerControls(t *testing.T) {
+	f := setupFinalizer(true)
+	admin := newAdminEndpoints(testAdminAPIKey, f, nil, dbManagerMock, f.eventLog)
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminAPIKey)
+
+	_, err := admin.PauseFinalizer(req)
+	require.Nil(t, err)
+	_, err = admin.SetStopBatch(req, types.ArgUint64(10))
+	require.Nil(t, err)
+	_, err = admin.CloseBatch(req)
+	require.Nil(t, err)
+	assert.True(t, f.closeBatchRequested.Load())
+
+	res, err := admin.Status(req)
+	require.Nil(t, err)
+	status := res.(AdminStatus)
+	assert.True(t, status.Paused)
+	assert.Equal(t, types.ArgUint64(10), status.StopSequencerOnBatchNum)
+	assert.Equal(t, types.ArgUint64(1), status.BatchNumber)
+	assert.Equal(t, getMaxRemainingResources(bc), status.RemainingResources)
+	assert.Equal(t, state.BatchResources{}, status.UsedResources)
+
+	_, err = admin.ResumeFinalizer(req)
+	require.Nil(t, err)
+	assert.False(t, f.paused.Load())
+}
+
+func TestAdminEndpointsDropTx(t *testing.T) {
+	f := setupFinalizer(true)
+	stateMock := NewStateMock(t)
+	worker := initWorker(stateMock, rcMax)
+	admin := newAdminEndpoints(testAdminAPIKey, f, worker, dbManagerMock, f.eventLog)
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminAPIKey)
+
+	ctx := context.Background()
+	from := common.Address{1}
+	stateMock.On("GetLastStateRoot", ctx, nil).Return(common.Hash{0}, nil)
+	stateMock.On("GetNonceByStateRoot", ctx, from, common.Hash{0}).Return(big.NewInt(1), nil)
+	stateMock.On("GetBalanceByStateRoot", ctx, from, common.Hash{0}).Return(big.NewInt(10), nil)
+
+	tx := &TxTracker{
+		Hash:     common.Hash{1},
+		HashStr:  common.Hash{1}.String(),
+		From:     from,
+		FromStr:  from.String(),
+		Nonce:    1,
+		Cost:     big.NewInt(5),
+		GasPrice: big.NewInt(1),
+		IP:       validIP,
+	}
+	_, err := worker.AddTxTracker(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, 1, worker.txSortedList.len())
+
+	// the tx can't be dropped while it's being processed
+	worker.processingTx = tx.Hash
+	_, rpcErr := admin.DropTx(req, types.ArgHash(tx.Hash))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, 1, worker.txSortedList.len())
+	worker.processingTx = common.Hash{}
+
+	failedReason := ErrDroppedByAdmin.Error()
+	dbManagerMock.On("UpdateTxStatus", mock.Anything, tx.Hash, pool.TxStatusFailed, false, &failedReason).Return(nil).Once()
+
+	_, rpcErr = admin.DropTx(req, types.ArgHash(tx.Hash))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, 0, worker.txSortedList.len())
+	assert.Empty(t, worker.pool)
+	dbManagerMock.AssertExpectations(t)
+
+	// the tx is not in the worker anymore
+	_, rpcErr = admin.DropTx(req, types.ArgHash(tx.Hash))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, types.DefaultErrorCode, rpcErr.ErrorCode())
+}
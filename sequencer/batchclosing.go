@@ -0,0 +1,178 @@
+package sequencer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+)
+
+const (
+	// BatchClosingResourceThreshold closes the batch when any of its remaining resources is under
+	// the ResourcePercentageToCloseBatch of the batch constraints
+	BatchClosingResourceThreshold = "resourcethreshold"
+	// BatchClosingTargetBatchTime closes the batch when TargetBatchTime has elapsed since it was opened
+	BatchClosingTargetBatchTime = "targetbatchtime"
+	// BatchClosingTargetL1DataSize closes the batch when its L2 data reaches TargetL1DataSize bytes
+	BatchClosingTargetL1DataSize = "targetl1datasize"
+	// BatchClosingTargetGas closes the batch when its cumulative gas used reaches TargetGas
+	BatchClosingTargetGas = "targetgas"
+)
+
+// batchClosingStrategy decides when the WIP batch must be closed before it's full
+type batchClosingStrategy interface {
+	// shouldClose returns true and the closing reason if the batch must be closed at the given time
+	shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason)
+}
+
+// newBatchClosingStrategy creates the batch closing strategy set in the config. When several strategies
+// are set, the batch is closed as soon as any of them is met
+func newBatchClosingStrategy(cfg FinalizerCfg, constraints state.BatchConstraintsCfg) (batchClosingStrategy, error) {
+	names := cfg.BatchClosing.Strategies
+	if len(names) == 0 {
+		names = []string{BatchClosingResourceThreshold}
+	}
+
+	strategies := make(anyBatchClosingStrategy, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case BatchClosingResourceThreshold:
+			strategies = append(strategies, newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, constraints))
+		case BatchClosingTargetBatchTime:
+			if cfg.BatchClosing.TargetBatchTime.Duration <= 0 {
+				return nil, fmt.Errorf("invalid TargetBatchTime for batch closing strategy %s: must be greater than 0", name)
+			}
+			strategies = append(strategies, &targetBatchTimeClosing{target: cfg.BatchClosing.TargetBatchTime.Duration})
+		case BatchClosingTargetL1DataSize:
+			if cfg.BatchClosing.TargetL1DataSize == 0 || cfg.BatchClosing.TargetL1DataSize > constraints.MaxBatchBytesSize {
+				return nil, fmt.Errorf("invalid TargetL1DataSize for batch closing strategy %s: must be greater than 0 and not greater than MaxBatchBytesSize (%d)", name, constraints.MaxBatchBytesSize)
+			}
+			strategies = append(strategies, &targetL1DataSizeClosing{target: cfg.BatchClosing.TargetL1DataSize, constraints: constraints})
+		case BatchClosingTargetGas:
+			if cfg.BatchClosing.TargetGas == 0 || cfg.BatchClosing.TargetGas > constraints.MaxCumulativeGasUsed {
+				return nil, fmt.Errorf("invalid TargetGas for batch closing strategy %s: must be greater than 0 and not greater than MaxCumulativeGasUsed (%d)", name, constraints.MaxCumulativeGasUsed)
+			}
+			strategies = append(strategies, &targetGasClosing{target: cfg.BatchClosing.TargetGas, constraints: constraints})
+		default:
+			return nil, fmt.Errorf("unknown batch closing strategy: %s", name)
+		}
+	}
+
+	return strategies, nil
+}
+
+// anyBatchClosingStrategy closes the batch when any of its strategies is met,
+// the strategies are checked in the order they are set in the config
+type anyBatchClosingStrategy []batchClosingStrategy
+
+func (s anyBatchClosingStrategy) shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason) {
+	for _, strategy := range s {
+		if closeBatch, reason := strategy.shouldClose(batch, now); closeBatch {
+			return true, reason
+		}
+	}
+	return false, state.EmptyClosingReason
+}
+
+// resourceThresholdClosing closes the batch when its remaining resources are under the threshold,
+// which is the most efficient moment to close the batch from the prover point of view
+type resourceThresholdClosing struct {
+	percentage  uint32
+	constraints state.BatchConstraintsCfg
+}
+
+func newResourceThresholdClosing(percentage uint32, constraints state.BatchConstraintsCfg) *resourceThresholdClosing {
+	return &resourceThresholdClosing{percentage: percentage, constraints: constraints}
+}
+
+func (s *resourceThresholdClosing) shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason) {
+	resources := batch.remainingResources
+	zkCounters := resources.ZKCounters
+	result := false
+	resourceDesc := ""
+	if resources.Bytes <= s.getConstraintThresholdUint64(s.constraints.MaxBatchBytesSize) {
+		resourceDesc = "MaxBatchBytesSize"
+		result = true
+	} else if zkCounters.UsedSteps <= s.getConstraintThresholdUint32(s.constraints.MaxSteps) {
+		resourceDesc = "MaxSteps"
+		result = true
+	} else if zkCounters.UsedPoseidonPaddings <= s.getConstraintThresholdUint32(s.constraints.MaxPoseidonPaddings) {
+		resourceDesc = "MaxPoseidonPaddings"
+		result = true
+	} else if zkCounters.UsedBinaries <= s.getConstraintThresholdUint32(s.constraints.MaxBinaries) {
+		resourceDesc = "MaxBinaries"
+		result = true
+	} else if zkCounters.UsedKeccakHashes <= s.getConstraintThresholdUint32(s.constraints.MaxKeccakHashes) {
+		resourceDesc = "MaxKeccakHashes"
+		result = true
+	} else if zkCounters.UsedArithmetics <= s.getConstraintThresholdUint32(s.constraints.MaxArithmetics) {
+		resourceDesc = "MaxArithmetics"
+		result = true
+	} else if zkCounters.UsedMemAligns <= s.getConstraintThresholdUint32(s.constraints.MaxMemAligns) {
+		resourceDesc = "MaxMemAligns"
+		result = true
+	} else if zkCounters.CumulativeGasUsed <= s.getConstraintThresholdUint64(s.constraints.MaxCumulativeGasUsed) {
+		resourceDesc = "MaxCumulativeGasUsed"
+		result = true
+	}
+
+	if !result {
+		return false, state.EmptyClosingReason
+	}
+	log.Infof("Closing batch: %d, because it reached %s threshold limit", batch.batchNumber, resourceDesc)
+	return true, state.BatchAlmostFullClosingReason
+}
+
+// getConstraintThresholdUint64 returns the threshold for the given input
+func (s *resourceThresholdClosing) getConstraintThresholdUint64(input uint64) uint64 {
+	return input * uint64(s.percentage) / oneHundred
+}
+
+// getConstraintThresholdUint32 returns the threshold for the given input
+func (s *resourceThresholdClosing) getConstraintThresholdUint32(input uint32) uint32 {
+	return uint32(input*s.percentage) / oneHundred
+}
+
+// targetBatchTimeClosing closes a non empty batch when the target time has elapsed since it was opened,
+// lower targets reduce the latency of the txs at the cost of sending more batches to L1
+type targetBatchTimeClosing struct {
+	target time.Duration
+}
+
+func (s *targetBatchTimeClosing) shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason) {
+	if batch.isEmpty() || now.Sub(batch.timestamp) < s.target {
+		return false, state.EmptyClosingReason
+	}
+	log.Infof("Closing batch: %d, because it reached the target batch time %s", batch.batchNumber, s.target)
+	return true, state.TargetBatchTimeClosingReason
+}
+
+// targetL1DataSizeClosing closes the batch when its L2 data reaches the target size, so the
+// batches can be lined up with the size of the sequences sent to L1
+type targetL1DataSizeClosing struct {
+	target      uint64
+	constraints state.BatchConstraintsCfg
+}
+
+func (s *targetL1DataSizeClosing) shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason) {
+	if s.constraints.MaxBatchBytesSize-batch.remainingResources.Bytes < s.target {
+		return false, state.EmptyClosingReason
+	}
+	log.Infof("Closing batch: %d, because it reached the target L1 data size of %d bytes", batch.batchNumber, s.target)
+	return true, state.TargetL1DataSizeClosingReason
+}
+
+// targetGasClosing closes the batch when its cumulative gas used reaches the target gas
+type targetGasClosing struct {
+	target      uint64
+	constraints state.BatchConstraintsCfg
+}
+
+func (s *targetGasClosing) shouldClose(batch *WipBatch, now time.Time) (bool, state.ClosingReason) {
+	if s.constraints.MaxCumulativeGasUsed-batch.remainingResources.ZKCounters.CumulativeGasUsed < s.target {
+		return false, state.EmptyClosingReason
+	}
+	log.Infof("Closing batch: %d, because it reached the target gas %d", batch.batchNumber, s.target)
+	return true, state.TargetGasClosingReason
+}
@@ -0,0 +1,162 @@
+package sequencer
+
+import (
+	"testing"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchClosingStrategy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         BatchClosingCfg
+		expectedErr bool
+	}{
+		{name: "default", cfg: BatchClosingCfg{}},
+		{name: "resource threshold", cfg: BatchClosingCfg{Strategies: []string{BatchClosingResourceThreshold}}},
+		{
+			name: "all strategies combined",
+			cfg: BatchClosingCfg{
+				Strategies:       []string{BatchClosingResourceThreshold, BatchClosingTargetBatchTime, BatchClosingTargetL1DataSize, BatchClosingTargetGas},
+				TargetBatchTime:  cfgTypes.NewDuration(time.Second),
+				TargetL1DataSize: 1000,
+				TargetGas:        1000,
+			},
+		},
+		{name: "target batch time not set", cfg: BatchClosingCfg{Strategies: []string{BatchClosingTargetBatchTime}}, expectedErr: true},
+		{name: "target L1 data size not set", cfg: BatchClosingCfg{Strategies: []string{BatchClosingTargetL1DataSize}}, expectedErr: true},
+		{name: "target L1 data size over the batch limit", cfg: BatchClosingCfg{Strategies: []string{BatchClosingTargetL1DataSize}, TargetL1DataSize: bc.MaxBatchBytesSize + 1}, expectedErr: true},
+		{name: "target gas not set", cfg: BatchClosingCfg{Strategies: []string{BatchClosingTargetGas}}, expectedErr: true},
+		{name: "target gas over the batch limit", cfg: BatchClosingCfg{Strategies: []string{BatchClosingTargetGas}, TargetGas: bc.MaxCumulativeGasUsed + 1}, expectedErr: true},
+		{name: "unknown strategy", cfg: BatchClosingCfg{Strategies: []string{"unknown"}}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			finalizerCfg := cfg
+			finalizerCfg.BatchClosing = tc.cfg
+			strategy, err := newBatchClosingStrategy(finalizerCfg, bc)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, strategy)
+		})
+	}
+}
+
+func TestBatchClosingStrategies(t *testing.T) {
+	now = testNow
+	defer func() {
+		now = time.Now
+	}()
+
+	finalizerCfg := cfg
+	finalizerCfg.BatchClosing = BatchClosingCfg{
+		Strategies:       []string{BatchClosingTargetBatchTime, BatchClosingTargetL1DataSize, BatchClosingTargetGas},
+		TargetBatchTime:  cfgTypes.NewDuration(2 * time.Second),
+		TargetL1DataSize: 1000,
+		TargetGas:        100000,
+	}
+	strategy, err := newBatchClosingStrategy(finalizerCfg, bc)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		modifyBatch    func(batch *WipBatch)
+		expectedClose  bool
+		expectedReason state.ClosingReason
+	}{
+		{
+			name:        "no target reached",
+			modifyBatch: func(batch *WipBatch) {},
+		},
+		{
+			name: "target batch time reached by an empty batch",
+			modifyBatch: func(batch *WipBatch) {
+				batch.countOfTxs = 0
+				batch.timestamp = now().Add(-time.Minute)
+			},
+		},
+		{
+			name: "target batch time reached",
+			modifyBatch: func(batch *WipBatch) {
+				batch.timestamp = now().Add(-2 * time.Second)
+			},
+			expectedClose:  true,
+			expectedReason: state.TargetBatchTimeClosingReason,
+		},
+		{
+			name: "target L1 data size reached",
+			modifyBatch: func(batch *WipBatch) {
+				batch.remainingResources.Bytes -= 1000
+			},
+			expectedClose:  true,
+			expectedReason: state.TargetL1DataSizeClosingReason,
+		},
+		{
+			name: "target gas reached",
+			modifyBatch: func(batch *WipBatch) {
+				batch.remainingResources.ZKCounters.CumulativeGasUsed -= 100000
+			},
+			expectedClose:  true,
+			expectedReason: state.TargetGasClosingReason,
+		},
+		{
+			name: "first strategy in the config order is reported",
+			modifyBatch: func(batch *WipBatch) {
+				batch.timestamp = now().Add(-time.Minute)
+				batch.remainingResources.ZKCounters.CumulativeGasUsed = 0
+			},
+			expectedClose:  true,
+			expectedReason: state.TargetBatchTimeClosingReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			batch := &WipBatch{
+				batchNumber:        1,
+				timestamp:          now(),
+				countOfTxs:         1,
+				remainingResources: getMaxRemainingResources(bc),
+			}
+			tc.modifyBatch(batch)
+
+			closeBatch, reason := strategy.shouldClose(batch, now())
+			assert.Equal(t, tc.expectedClose, closeBatch)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestResourceThresholdClosing_getConstraintThresholdUint64(t *testing.T) {
+	// arrange
+	threshold := newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, bc)
+	input := uint64(100)
+	expect := input * uint64(cfg.ResourcePercentageToCloseBatch) / 100
+
+	// act
+	result := threshold.getConstraintThresholdUint64(input)
+
+	// assert
+	assert.Equal(t, result, expect)
+}
+
+func TestResourceThresholdClosing_getConstraintThresholdUint32(t *testing.T) {
+	// arrange
+	threshold := newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, bc)
+	input := uint32(100)
+	expect := uint32(input * cfg.ResourcePercentageToCloseBatch / 100)
+
+	// act
+	result := threshold.getConstraintThresholdUint32(input)
+
+	// assert
+	assert.Equal(t, result, expect)
+}
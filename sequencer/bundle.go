@@ -0,0 +1,21 @@
+package sequencer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BundleTracker is the worker representation of a pool bundle, its txs must be
+// sequenced together and contiguously in the same batch, or not sequenced at all
+type BundleTracker struct {
+	Hash common.Hash
+	Txs  []*TxTracker
+}
+
+// rawTxs returns the concatenation of the raw txs of the bundle
+func (b *BundleTracker) rawTxs() []byte {
+	raw := []byte{}
+	for _, tx := range b.Txs {
+		raw = append(raw, tx.RawTx...)
+	}
+	return raw
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/merkletree/hashdb"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakeexecutor"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
 	"github.com/0xPolygonHermez/zkevm-node/test/testutils"
 	"github.com/ethereum/go-ethereum/common"
@@ -53,17 +54,23 @@ func setupTest(t *testing.T) {
 		panic(err)
 	}
 
-	zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
-	localMtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
-	localExecutorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
-
-	localExecutorClient, localExecutorClientConn, localExecutorCancel = executor.NewExecutorClient(localCtx, localExecutorServerConfig)
-	s := localExecutorClientConn.GetState()
-	log.Infof("executorClientConn state: %s", s.String())
-
-	localMtDBServiceClient, localMtDBClientConn, localMtDBCancel = merkletree.NewMTDBServiceClient(localCtx, localMtDBServerConfig)
-	s = localMtDBClientConn.GetState()
-	log.Infof("localStateDbClientConn state: %s", s.String())
+	var fake bool
+	if localMtDBServiceClient, localExecutorClient, fake = fakeexecutor.NewFromEnv(); fake {
+		localMtDBClientConn, localExecutorClientConn = nil, nil
+		localMtDBCancel, localExecutorCancel = func() {}, func() {}
+	} else {
+		zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
+		localMtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
+		localExecutorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
+
+		localExecutorClient, localExecutorClientConn, localExecutorCancel = executor.NewExecutorClient(localCtx, localExecutorServerConfig)
+		s := localExecutorClientConn.GetState()
+		log.Infof("executorClientConn state: %s", s.String())
+
+		localMtDBServiceClient, localMtDBClientConn, localMtDBCancel = merkletree.NewMTDBServiceClient(localCtx, localMtDBServerConfig)
+		s = localMtDBClientConn.GetState()
+		log.Infof("localStateDbClientConn state: %s", s.String())
+	}
 
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
@@ -99,9 +106,13 @@ func setupTest(t *testing.T) {
 
 func cleanup(t *testing.T) {
 	localMtDBCancel()
-	localMtDBClientConn.Close()
 	localExecutorCancel()
-	localExecutorClientConn.Close()
+	if localMtDBClientConn != nil {
+		localMtDBClientConn.Close()
+	}
+	if localExecutorClientConn != nil {
+		localExecutorClientConn.Close()
+	}
 }
 
 func prepareForcedBatches(t *testing.T) {
@@ -24,6 +24,12 @@ type Config struct {
 	// MaxTxLifetime is the time a tx can be in the sequencer/worker memory
 	MaxTxLifetime types.Duration `mapstructure:"MaxTxLifetime"`
 
+	// TxOrdering is the policy used by the worker to choose the order of the txs to be sequenced
+	TxOrdering TxOrderingCfg `mapstructure:"TxOrdering"`
+
+	// Quotas limits the txs sequenced per sender and per IP to keep a single spammer from filling whole batches
+	Quotas QuotasCfg `mapstructure:"Quotas"`
+
 	// Finalizer's specific config properties
 	Finalizer FinalizerCfg `mapstructure:"Finalizer"`
 
@@ -35,6 +41,84 @@ type Config struct {
 
 	// L2Coinbase is the address receives the L2 fee
 	L2Coinbase common.Address `mapstructure:"L2Coinbase"`
+
+	// Admin is the config of the JSON-RPC server used by the operators to steer the sequencer
+	Admin AdminCfg `mapstructure:"Admin"`
+
+	// Preconfirmations is the config of the signed inclusion preconfirmations
+	Preconfirmations PreconfirmationsCfg `mapstructure:"Preconfirmations"`
+
+	// LeaderElection is the config of the active/passive mode of the sequencer instances
+	LeaderElection LeaderElectionCfg `mapstructure:"LeaderElection"`
+}
+
+// LeaderElectionCfg contains the sequencer leader election configuration properties
+type LeaderElectionCfg struct {
+	// Enabled is a flag to run several sequencer instances in active/passive mode, only the
+	// instance that holds the leadership lease sequences and the others wait as hot standby
+	Enabled bool `mapstructure:"Enabled"`
+	// InstanceID identifies the instance in the lease, it must be unique across the sequencer
+	// instances. If empty, the hostname and the process id are used
+	InstanceID string `mapstructure:"InstanceID"`
+	// LeaseDuration is the time the lease is valid without being renewed, a standby instance
+	// takes over the sequencing when the lease of the leader expires
+	LeaseDuration types.Duration `mapstructure:"LeaseDuration"`
+	// RenewInterval is the frequency the leader renews the lease and the standby instances try to acquire it
+	RenewInterval types.Duration `mapstructure:"RenewInterval"`
+}
+
+// PreconfirmationsCfg contains the signed inclusion preconfirmations configuration properties
+type PreconfirmationsCfg struct {
+	// Enabled is a flag to sign a preconfirmation for each tx executed in a WIP batch
+	Enabled bool `mapstructure:"Enabled"`
+	// PrivateKey is the key used to sign the preconfirmations. Use the same key as SequenceSender.PrivateKey
+	// to allow the users to verify the preconfirmations against the trusted sequencer address of the L1 contract
+	PrivateKey types.KeystoreFileConfig `mapstructure:"PrivateKey"`
+}
+
+// AdminCfg contains the sequencer admin server's configuration properties
+type AdminCfg struct {
+	// Enabled is a flag to enable/disable the admin server
+	Enabled bool `mapstructure:"Enabled"`
+	// Host to bind the admin server, keep it private
+	Host string `mapstructure:"Host"`
+	// Port to listen on
+	Port int `mapstructure:"Port"`
+	// APIKey must be sent in the "Authorization: Bearer <APIKey>" header of every admin request
+	APIKey string `mapstructure:"APIKey"`
+}
+
+// TxOrderingCfg contains the worker's tx ordering configuration properties
+type TxOrderingCfg struct {
+	// Policy is the tx ordering policy: "gasprice" (default), "fifo", "fairshare" or "priorityfee"
+	Policy string `mapstructure:"Policy"`
+
+	// FairShareWindowSize is the number of last sequenced txs considered by the "fairshare" policy
+	// to compute the share of each sender
+	FairShareWindowSize uint64 `mapstructure:"FairShareWindowSize"`
+}
+
+// QuotasCfg contains the worker's sender and IP quotas configuration properties
+type QuotasCfg struct {
+	// Enabled is a flag to defer the txs of the senders and IPs that are over their quotas
+	Enabled bool `mapstructure:"Enabled"`
+
+	// WindowSize is the number of last batches, including the WIP batch, the quotas are computed over
+	WindowSize uint64 `mapstructure:"WindowSize"`
+
+	// MaxTxsPerSender is the max number of txs of a sender in the window, 0 means no limit
+	MaxTxsPerSender uint64 `mapstructure:"MaxTxsPerSender"`
+
+	// MaxTxsPerIP is the max number of txs received from an IP in the window, 0 means no limit
+	MaxTxsPerIP uint64 `mapstructure:"MaxTxsPerIP"`
+
+	// MaxZKCountersPercentagePerSender is the max percentage of each ZK counter of the window
+	// (WindowSize batches) a sender can use, 0 means no limit
+	MaxZKCountersPercentagePerSender uint32 `mapstructure:"MaxZKCountersPercentagePerSender"`
+
+	// MaxZKCountersPercentagePerIP is the max percentage of each ZK counter of the window
+	// (WindowSize batches) the txs received from an IP can use, 0 means no limit
+	MaxZKCountersPercentagePerIP uint32 `mapstructure:"MaxZKCountersPercentagePerIP"`
 }
 
 // StreamServerCfg contains the data streamer's configuration properties
@@ -87,10 +171,32 @@ type FinalizerCfg struct {
 	// SequentialReprocessFullBatch indicates if the reprocess of a closed batch (sanity check) must be done in a
 	// sequential way (instead than in parallel)
 	SequentialReprocessFullBatch bool `mapstructure:"SequentialReprocessFullBatch"`
+
+	// BatchClosing is the config of the strategies used to close the WIP batch before it's full
+	BatchClosing BatchClosingCfg `mapstructure:"BatchClosing"`
+}
+
+// BatchClosingCfg contains the finalizer's batch closing strategies configuration properties
+type BatchClosingCfg struct {
+	// Strategies is the list of strategies used to close the WIP batch before it's full, the batch is closed
+	// as soon as any of them is met: "resourcethreshold" (default), "targetbatchtime", "targetl1datasize" and "targetgas"
+	Strategies []string `mapstructure:"Strategies"`
+
+	// TargetBatchTime is the time since a non empty batch was opened to close it ("targetbatchtime")
+	TargetBatchTime types.Duration `mapstructure:"TargetBatchTime"`
+
+	// TargetL1DataSize is the size in bytes of the L2 data of a batch to close it ("targetl1datasize"),
+	// set it to line up the batches with the size of the sequences sent to L1
+	TargetL1DataSize uint64 `mapstructure:"TargetL1DataSize"`
+
+	// TargetGas is the cumulative gas used of a batch to close it ("targetgas")
+	TargetGas uint64 `mapstructure:"TargetGas"`
 }
 
 // DBManagerCfg contains the DBManager's configuration properties
 type DBManagerCfg struct {
+	// PoolRetrievalInterval is the interval to poll the pool when it can't notify the new pending txs,
+	// or when it hasn't notified any for this long
 	PoolRetrievalInterval    types.Duration `mapstructure:"PoolRetrievalInterval"`
 	L2ReorgRetrievalInterval types.Duration `mapstructure:"L2ReorgRetrievalInterval"`
 }
@@ -2,13 +2,19 @@ package sequencer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	"github.com/0xPolygonHermez/zkevm-node/event"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
+	stateMetrics "github.com/0xPolygonHermez/zkevm-node/state/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jackc/pgx/v4"
@@ -30,6 +36,7 @@ type dbManager struct {
 	numberOfStateInconsistencies uint64
 	streamServer                 *datastreamer.StreamServer
 	dataToStream                 chan state.DSL2FullBlock
+	eventLog                     *event.EventLog
 }
 
 func (d *dbManager) GetBatchByNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, error) {
@@ -63,6 +70,7 @@ func newDBManager(ctx context.Context, config DBManagerCfg, txPool txPool, state
 // Start stars the dbManager routines
 func (d *dbManager) Start() {
 	go d.loadFromPool()
+	go d.dropEvictedTxs()
 	go func() {
 		for {
 			time.Sleep(d.cfg.L2ReorgRetrievalInterval.Duration)
@@ -129,22 +137,97 @@ func (d *dbManager) checkStateInconsistency() {
 	}
 }
 
-// loadFromPool keeps loading transactions from the pool
+// loadFromPool keeps loading transactions from the pool. While the pool notifies the new pending txs they are
+// loaded as soon as they are notified, the pool is also polled when nothing has been loaded for PoolRetrievalInterval
+// so the txs are not missed if a notification is lost
 func (d *dbManager) loadFromPool() {
-	for {
-		time.Sleep(d.cfg.PoolRetrievalInterval.Duration)
+	ticker := time.NewTicker(d.cfg.PoolRetrievalInterval.Duration)
+	defer ticker.Stop()
+
+	var notifications <-chan struct{}
+	for d.ctx.Err() == nil {
+		d.loadPendingFromPool()
+		ticker.Reset(d.cfg.PoolRetrievalInterval.Duration)
+
+		if notifications == nil {
+			notifications = d.subscribeToPool()
+			if notifications != nil {
+				// load the txs added to the pool before the subscription
+				continue
+			}
+		}
 
-		poolTransactions, err := d.txPool.GetNonWIPPendingTxs(d.ctx)
-		if err != nil && err != pool.ErrNotFound {
-			log.Errorf("load tx from pool: %v", err)
+		// notifications is nil when the pool can't notify, so only the ticker and the ctx are awaited
+		select {
+		case <-d.ctx.Done():
+		case <-ticker.C:
+		case _, ok := <-notifications:
+			if !ok {
+				log.Warnf("pool notifications stopped, polling the pool every %s", d.cfg.PoolRetrievalInterval.Duration)
+				notifications = nil
+			}
 		}
+	}
+}
 
-		for _, tx := range poolTransactions {
-			err := d.addTxToWorker(tx)
-			if err != nil {
-				log.Errorf("error adding transaction to worker: %v", err)
+// subscribeToPool subscribes to the new pending txs of the pool, it returns nil if the pool can't notify them
+func (d *dbManager) subscribeToPool() <-chan struct{} {
+	notifications, err := d.txPool.SubscribePendingTxs(d.ctx)
+	if err != nil {
+		log.Errorf("failed to subscribe to pool notifications, polling the pool every %s: %v", d.cfg.PoolRetrievalInterval.Duration, err)
+		return nil
+	}
+	log.Info("subscribed to pool notifications")
+	return notifications
+}
+
+// dropEvictedTxs drops from the worker the txs evicted from the pool, the WIP txs are evicted too when they
+// are queued. When the pool stops notifying the evicted txs it's subscribed again after PoolRetrievalInterval
+func (d *dbManager) dropEvictedTxs() {
+	for d.ctx.Err() == nil {
+		evictedTxs, err := d.txPool.SubscribeEvictedTxs(d.ctx)
+		if err != nil {
+			log.Errorf("failed to subscribe to pool evicted txs: %v", err)
+		} else {
+			for txHash := range evictedTxs {
+				txTracker, err := d.worker.DropTx(txHash)
+				if err != nil {
+					log.Infof("tx %s evicted from the pool not dropped from the worker: %v", txHash.String(), err)
+				} else if txTracker != nil {
+					log.Infof("tx %s evicted from the pool dropped from the worker", txHash.String())
+				}
 			}
 		}
+		if d.ctx.Err() == nil {
+			time.Sleep(d.cfg.PoolRetrievalInterval.Duration)
+		}
+	}
+}
+
+// loadPendingFromPool adds the non WIP pending txs and bundles of the pool to the worker
+func (d *dbManager) loadPendingFromPool() {
+	poolTransactions, err := d.txPool.GetNonWIPPendingTxs(d.ctx)
+	if err != nil && err != pool.ErrNotFound {
+		log.Errorf("load tx from pool: %v", err)
+	}
+
+	for _, tx := range poolTransactions {
+		err := d.addTxToWorker(tx)
+		if err != nil {
+			log.Errorf("error adding transaction to worker: %v", err)
+		}
+	}
+
+	poolBundles, err := d.txPool.GetNonWIPPendingBundles(d.ctx)
+	if err != nil && err != pool.ErrNotFound {
+		log.Errorf("load bundles from pool: %v", err)
+	}
+
+	for _, bundle := range poolBundles {
+		err := d.addBundleToWorker(bundle)
+		if err != nil {
+			log.Errorf("error adding bundle %s to worker: %v", bundle.Hash.String(), err)
+		}
 	}
 }
 
@@ -234,8 +317,16 @@ func (d *dbManager) addTxToWorker(tx pool.Transaction) error {
 	if err != nil {
 		return err
 	}
+	txTracker.Sponsored = tx.Sponsor != nil
+	// the txs are ordered by the time they were received by the pool, not by the time they were loaded
+	if !tx.ReceivedAt.IsZero() {
+		txTracker.ReceivedAt = tx.ReceivedAt
+	}
 	replacedTx, dropReason := d.worker.AddTxTracker(d.ctx, txTracker)
 	if dropReason != nil {
+		if errors.Is(dropReason, ErrDuplicatedNonce) || errors.Is(dropReason, ErrReplaceInProgress) {
+			d.logReplacementRejected(tx, dropReason)
+		}
 		failedReason := dropReason.Error()
 		return d.txPool.UpdateTxStatus(d.ctx, txTracker.Hash, pool.TxStatusFailed, false, &failedReason)
 	} else {
@@ -250,6 +341,52 @@ func (d *dbManager) addTxToWorker(tx pool.Transaction) error {
 	}
 }
 
+// logReplacementRejected adds an event to the event log with the reason why the worker rejected a tx
+// as replacement of a tx with the same sender and nonce
+func (d *dbManager) logReplacementRejected(tx pool.Transaction, reason error) {
+	if d.eventLog == nil {
+		return
+	}
+	event := &event.Event{
+		ReceivedAt:  time.Now(),
+		IPAddress:   tx.IP,
+		Source:      event.Source_Node,
+		Component:   event.Component_Sequencer,
+		Level:       event.Level_Info,
+		EventID:     event.EventID_TxReplacementRejected,
+		Description: fmt.Sprintf("tx %s: %v", tx.Hash().String(), reason),
+	}
+	if err := d.eventLog.LogEvent(d.ctx, event); err != nil {
+		log.Errorf("error adding event: %v", err)
+	}
+}
+
+func (d *dbManager) addBundleToWorker(bundle pool.Bundle) error {
+	bundleTracker := &BundleTracker{Hash: bundle.Hash, Txs: make([]*TxTracker, 0, len(bundle.Txs))}
+	for _, tx := range bundle.Txs {
+		txTracker, err := d.worker.NewTxTracker(tx.Transaction, tx.ZKCounters, tx.IP)
+		if err != nil {
+			failedReason := fmt.Sprintf("%s: %s", ErrBundleFailed.Error(), err.Error())
+			for _, bundleTx := range bundle.Txs {
+				if updateErr := d.txPool.UpdateTxStatus(d.ctx, bundleTx.Hash(), pool.TxStatusFailed, false, &failedReason); updateErr != nil {
+					log.Errorf("failed to update status to failed in the pool for bundle tx: %s, err: %v", bundleTx.Hash().String(), updateErr)
+				}
+			}
+			return err
+		}
+		bundleTracker.Txs = append(bundleTracker.Txs, txTracker)
+	}
+
+	d.worker.AddBundle(bundleTracker)
+
+	for _, tx := range bundle.Txs {
+		if err := d.txPool.UpdateTxWIPStatus(d.ctx, tx.Hash(), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BeginStateTransaction starts a db transaction in the state
 func (d *dbManager) BeginStateTransaction(ctx context.Context) (pgx.Tx, error) {
 	return d.state.BeginStateTransaction(ctx)
@@ -260,19 +397,20 @@ func (d *dbManager) DeleteTransactionFromPool(ctx context.Context, txHash common
 	return d.txPool.DeleteTransactionByHash(ctx, txHash)
 }
 
-// StoreProcessedTxAndDeleteFromPool stores a tx into the state and changes it status in the pool
-func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx transactionToStore) error {
+// StoreProcessedTxAndDeleteFromPool stores a tx into the state and changes it status in the pool,
+// it returns the number of the l2 block the tx is stored in
+func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx transactionToStore) (uint64, error) {
 	d.checkStateInconsistency()
 
 	log.Debugf("Storing tx %v", tx.response.TxHash)
 	dbTx, err := d.BeginStateTransaction(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	l2BlockHeader, err := d.state.StoreTransaction(ctx, tx.batchNumber, tx.response, tx.coinbase, uint64(tx.timestamp.Unix()), tx.egpLog, dbTx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Update batch l2 data
@@ -282,13 +420,13 @@ func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx tr
 		if err2 != nil {
 			log.Errorf("failed to rollback dbTx when getting batch that gave err: %v. Rollback err: %v", err2, err)
 		}
-		return err
+		return 0, err
 	}
 
 	forkID := d.state.GetForkIDByBatchNumber(tx.batchNumber)
 	txData, err := state.EncodeTransaction(tx.response.Tx, uint8(tx.response.EffectivePercentage), forkID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	batch.BatchL2Data = append(batch.BatchL2Data, txData...)
 
@@ -299,20 +437,20 @@ func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx tr
 			if err2 != nil {
 				log.Errorf("failed to rollback dbTx when updating batch l2 data that gave err: %v. Rollback err: %v", err2, err)
 			}
-			return err
+			return 0, err
 		}
 	}
 
 	err = dbTx.Commit(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if !tx.isForcedBatch {
 		// Change Tx status to selected
 		err = d.txPool.UpdateTxStatus(ctx, tx.response.TxHash, pool.TxStatusSelected, false, nil)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
@@ -335,7 +473,7 @@ func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx tr
 
 		binaryTxData, err := tx.response.Tx.MarshalBinary()
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		l2Transaction := state.DSL2Transaction{
@@ -352,7 +490,13 @@ func (d *dbManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx tr
 		}
 	}
 
-	return nil
+	return l2BlockHeader.Number.Uint64(), nil
+}
+
+// AddPreconfirmation stores and publishes the preconfirmation right away, it must not wait for the
+// tx to be stored, the preconfirmation must reach the users before the receipt
+func (d *dbManager) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation) error {
+	return d.state.AddPreconfirmation(ctx, preconfirmation, nil)
 }
 
 // GetWIPBatch returns ready WIP batch
@@ -683,6 +827,11 @@ func (d *dbManager) UpdateTxStatus(ctx context.Context, hash common.Hash, newSta
 	return d.txPool.UpdateTxStatus(ctx, hash, newStatus, isWIP, failedReason)
 }
 
+// ChargeSponsor charges the gas to the budget of the sponsor of the tx, it returns false if the budget doesn't cover it
+func (d *dbManager) ChargeSponsor(ctx context.Context, hash common.Hash, gasUsed uint64) (bool, error) {
+	return d.txPool.ChargeSponsor(ctx, hash, gasUsed)
+}
+
 // GetLatestVirtualBatchTimestamp gets last virtual batch timestamp
 func (d *dbManager) GetLatestVirtualBatchTimestamp(ctx context.Context, dbTx pgx.Tx) (time.Time, error) {
 	return d.state.GetLatestVirtualBatchTimestamp(ctx, dbTx)
@@ -714,7 +863,7 @@ func (d *dbManager) GetL1AndL2GasPrice() (uint64, uint64) {
 
 // GetStoredFlushID returns the stored flush ID and prover ID
 func (d *dbManager) GetStoredFlushID(ctx context.Context) (uint64, string, error) {
-	return d.state.GetStoredFlushID(ctx)
+	return d.state.GetStoredFlushID(executor.WithCaller(ctx, stateMetrics.SequencerCallerLabel))
 }
 
 // GetForcedBatch gets a forced batch by number
@@ -2,7 +2,9 @@ package sequencer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,12 +15,15 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree/hashdb"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakeexecutor"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
 	"github.com/0xPolygonHermez/zkevm-node/test/testutils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 )
@@ -51,12 +56,18 @@ func setupDBManager() {
 		panic(err)
 	}
 
-	zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
-	mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
-
-	mtDBServiceClient, mtDBClientConn, mtDBCancel = merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
-	s := mtDBClientConn.GetState()
-	log.Infof("stateDbClientConn state: %s", s.String())
+	var fake bool
+	if mtDBServiceClient, executorClient, fake = fakeexecutor.NewFromEnv(); fake {
+		mtDBClientConn = nil
+		mtDBCancel = func() {}
+	} else {
+		zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
+		mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
+
+		mtDBServiceClient, mtDBClientConn, mtDBCancel = merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
+		s := mtDBClientConn.GetState()
+		log.Infof("stateDbClientConn state: %s", s.String())
+	}
 
 	eventStorage, err := nileventstorage.NewNilEventStorage()
 	if err != nil {
@@ -97,7 +108,9 @@ func initOrResetDB() {
 
 func cleanupDBManager() {
 	mtDBCancel()
-	mtDBClientConn.Close()
+	if mtDBClientConn != nil {
+		mtDBClientConn.Close()
+	}
 }
 
 func TestOpenBatch(t *testing.T) {
@@ -167,3 +180,35 @@ func TestCreateFirstBatch(t *testing.T) {
 	require.Equal(t, uint64(1), processingContext.BatchNumber)
 	cleanupDBManager()
 }
+
+func TestLoadFromPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	poolMock := NewPoolMock(t)
+	dbManager := &dbManager{ctx: ctx, cfg: DBManagerCfg{PoolRetrievalInterval: types.NewDuration(200 * time.Millisecond)}, txPool: poolMock}
+
+	var loads atomic.Int32
+	poolMock.On("GetNonWIPPendingTxs", ctx).Run(func(args mock.Arguments) { loads.Add(1) }).Return(nil, pool.ErrNotFound)
+	poolMock.On("GetNonWIPPendingBundles", ctx).Return(nil, pool.ErrNotFound)
+	notifications := make(chan struct{}, 1)
+	poolMock.On("SubscribePendingTxs", ctx).Return((<-chan struct{})(notifications), nil).Once()
+	poolMock.On("SubscribePendingTxs", ctx).Return(nil, errors.New("connection refused"))
+
+	go dbManager.loadFromPool()
+
+	// the pool is loaded at start and right after the subscription
+	require.Eventually(t, func() bool { return loads.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(2), loads.Load())
+
+	// the pool is loaded when it notifies new pending txs
+	notifications <- struct{}{}
+	require.Eventually(t, func() bool { return loads.Load() == 3 }, 100*time.Millisecond, time.Millisecond)
+
+	// the pool is polled while subscribed when nothing is notified for PoolRetrievalInterval
+	require.Eventually(t, func() bool { return loads.Load() == 4 }, time.Second, time.Millisecond)
+
+	// the pool is polled when the notifications stop and the subscription fails
+	close(notifications)
+	require.Eventually(t, func() bool { return loads.Load() >= 7 }, 2*time.Second, time.Millisecond)
+}
@@ -12,6 +12,10 @@ var (
 	ErrDuplicatedNonce = errors.New("duplicated nonce")
 	// ErrReplacedTransaction is returned when an existing tx is replaced by a new tx with the same nonce and higher gasPrice
 	ErrReplacedTransaction = errors.New("replaced transaction")
+	// ErrReplaceInProgress is returned when a new tx tries to replace a tx with the same nonce that is being processed
+	ErrReplaceInProgress = errors.New("replaced transaction is already being processed")
+	// ErrDropInProgress is returned when dropping a tx that is being processed
+	ErrDropInProgress = errors.New("dropped transaction is being processed")
 	// ErrGetBatchByNumber happens when we get an error trying to get a batch by number (GetBatchByNumber)
 	ErrGetBatchByNumber = errors.New("get batch by number error")
 	// ErrDecodeBatchL2Data happens when we get an error trying to decode BatchL2Data (DecodeTxs)
@@ -25,4 +29,12 @@ var (
 	ErrStateRootNoMatch = errors.New("state root no match")
 	// ErrExecutorError happens when we got an executor error when processing a batch
 	ErrExecutorError = errors.New("executor error")
+	// ErrBundleFailed is the failed reason of the txs of a bundle that could not be sequenced
+	ErrBundleFailed = errors.New("bundle failed")
+	// ErrBundleDoesNotFit happens when a bundle doesn't fit in the remaining resources of the WIP batch
+	ErrBundleDoesNotFit = errors.New("bundle does not fit in the batch")
+	// ErrBundleOverQuota happens when a bundle exceeds the quota of any of its senders or IPs
+	ErrBundleOverQuota = errors.New("bundle over quota")
+	// ErrDroppedByAdmin is the failed reason of the txs dropped through the sequencer admin API
+	ErrDroppedByAdmin = errors.New("dropped by the sequencer admin")
 )
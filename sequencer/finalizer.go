@@ -2,6 +2,7 @@ package sequencer
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,12 +16,14 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	stateMetrics "github.com/0xPolygonHermez/zkevm-node/state/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -28,10 +31,7 @@ const (
 	oneHundred                            = 100
 	pendingTxsBufferSizeMultiplier        = 10
 	forkId5                        uint64 = 5
-)
-
-var (
-	now = time.Now
+	pausedFinalizerSleepDuration          = time.Second
 )
 
 // finalizer represents the finalizer component of the sequencer.
@@ -45,6 +45,8 @@ type finalizer struct {
 	executor           stateInterface
 	batch              *WipBatch
 	batchConstraints   state.BatchConstraintsCfg
+	batchClosing       batchClosingStrategy
+	now                func() time.Time // clock used for the batch timestamps and the closing deadlines
 	processRequest     state.ProcessRequest
 	sharedResourcesMux *sync.RWMutex
 	// GER of the current WIP batch
@@ -73,6 +75,17 @@ type finalizer struct {
 	lastPendingFlushID           uint64
 	pendingFlushIDCond           *sync.Cond
 	streamServer                 *datastreamer.StreamServer
+	// runtime controls set through the admin API
+	paused                  atomic.Bool
+	closeBatchRequested     atomic.Bool
+	stopSequencerOnBatchNum atomic.Uint64
+	// preconfirmations
+	preconfKey *ecdsa.PrivateKey
+	// each tx added to be stored is stored in its own l2 block in the same order, so its l2 block number is
+	// l2BlockNumberBase plus its storeSeq. The base is corrected when a tx isn't stored in the expected l2 block
+	l2BlockNumberBase uint64
+	storeSeq          uint64
+	l2BlockNumberMux  sync.Mutex
 }
 
 type transactionToStore struct {
@@ -87,6 +100,9 @@ type transactionToStore struct {
 	isForcedBatch bool
 	flushId       uint64
 	egpLog        *state.EffectiveGasPriceLog
+	position      uint64
+	storeSeq      uint64
+	l2BlockNumber uint64
 }
 
 // WipBatch represents a work-in-progress batch.
@@ -118,6 +134,8 @@ func newFinalizer(
 	isSynced func(ctx context.Context) bool,
 	closingSignalCh ClosingSignalCh,
 	batchConstraints state.BatchConstraintsCfg,
+	batchClosing batchClosingStrategy,
+	clock func() time.Time,
 	eventLog *event.EventLog,
 	streamServer *datastreamer.StreamServer,
 ) *finalizer {
@@ -131,6 +149,8 @@ func newFinalizer(
 		executor:           executor,
 		batch:              new(WipBatch),
 		batchConstraints:   batchConstraints,
+		batchClosing:       batchClosing,
+		now:                clock,
 		processRequest:     state.ProcessRequest{},
 		sharedResourcesMux: new(sync.RWMutex),
 		currentGERHash:     state.ZeroHash,
@@ -159,6 +179,7 @@ func newFinalizer(
 	}
 
 	f.reprocessFullBatchError.Store(false)
+	f.stopSequencerOnBatchNum.Store(cfg.StopSequencerOnBatchNum)
 
 	return &f
 }
@@ -181,6 +202,14 @@ func (f *finalizer) Start(ctx context.Context, batch *WipBatch, processingReq *s
 		f.processRequest = *processingReq
 	}
 
+	if f.preconfKey != nil {
+		lastL2BlockHeader, err := f.dbManager.GetLastL2BlockHeader(ctx, nil)
+		if err != nil {
+			log.Fatalf("failed to get last l2 block header, Err: %s", err)
+		}
+		f.l2BlockNumberBase = lastL2BlockHeader.Number.Uint64()
+	}
+
 	// Closing signals receiver
 	go f.listenForClosingSignals(ctx)
 
@@ -273,23 +302,10 @@ func (f *finalizer) listenForClosingSignals(ctx context.Context) {
 			return
 		// ForcedBatch ch
 		case fb := <-f.closingSignalCh.ForcedBatchCh:
-			log.Debugf("finalizer received forced batch at block number: %v", fb.BlockNumber)
-
-			f.nextForcedBatchesMux.Lock()
-			f.nextForcedBatches = f.sortForcedBatches(append(f.nextForcedBatches, fb))
-			if f.nextForcedBatchDeadline == 0 {
-				f.setNextForcedBatchDeadline()
-			}
-			f.nextForcedBatchesMux.Unlock()
+			f.handleForcedBatchSignal(fb)
 		// GlobalExitRoot ch
 		case ger := <-f.closingSignalCh.GERCh:
-			log.Debugf("finalizer received global exit root: %s", ger.String())
-			f.nextGERMux.Lock()
-			f.nextGER = ger
-			if f.nextGERDeadline == 0 {
-				f.setNextGERDeadline()
-			}
-			f.nextGERMux.Unlock()
+			f.handleGERSignal(ger)
 		// L2Reorg ch
 		case <-f.closingSignalCh.L2ReorgCh:
 			log.Debug("finalizer received L2 reorg event")
@@ -300,6 +316,29 @@ func (f *finalizer) listenForClosingSignals(ctx context.Context) {
 	}
 }
 
+// handleForcedBatchSignal queues the forced batch to be processed after the WIP batch is closed
+func (f *finalizer) handleForcedBatchSignal(fb state.ForcedBatch) {
+	log.Debugf("finalizer received forced batch at block number: %v", fb.BlockNumber)
+
+	f.nextForcedBatchesMux.Lock()
+	f.nextForcedBatches = f.sortForcedBatches(append(f.nextForcedBatches, fb))
+	if f.nextForcedBatchDeadline == 0 {
+		f.setNextForcedBatchDeadline()
+	}
+	f.nextForcedBatchesMux.Unlock()
+}
+
+// handleGERSignal sets the GER to be used in the next batch
+func (f *finalizer) handleGERSignal(ger common.Hash) {
+	log.Debugf("finalizer received global exit root: %s", ger.String())
+	f.nextGERMux.Lock()
+	f.nextGER = ger
+	if f.nextGERDeadline == 0 {
+		f.setNextGERDeadline()
+	}
+	f.nextGERMux.Unlock()
+}
+
 // updateLastPendingFLushID updates f.lastPendingFLushID with newFlushID value (it it has changed) and sends
 // the signal condition f.pendingFlushIDCond to notify other go funcs that the f.lastPendingFlushID value has changed
 func (f *finalizer) updateLastPendingFlushID(newFlushID uint64) {
@@ -311,6 +350,17 @@ func (f *finalizer) updateLastPendingFlushID(newFlushID uint64) {
 
 // addPendingTxToStore adds a pending tx that is ready to be stored in the state DB once its flushid has been stored by the executor
 func (f *finalizer) addPendingTxToStore(ctx context.Context, txToStore transactionToStore) {
+	if f.preconfKey != nil {
+		f.l2BlockNumberMux.Lock()
+		f.storeSeq++
+		txToStore.storeSeq = f.storeSeq
+		txToStore.l2BlockNumber = f.l2BlockNumberBase + f.storeSeq
+		f.l2BlockNumberMux.Unlock()
+		if !txToStore.isForcedBatch {
+			f.publishPreconfirmation(ctx, txToStore)
+		}
+	}
+
 	f.pendingTransactionsToStoreWG.Add(1)
 
 	f.worker.AddPendingTxToStore(txToStore.hash, txToStore.from)
@@ -322,6 +372,45 @@ func (f *finalizer) addPendingTxToStore(ctx context.Context, txToStore transacti
 		// delete the pending TxToStore added in the worker
 		f.pendingTransactionsToStoreWG.Done()
 		f.worker.DeletePendingTxToStore(txToStore.hash, txToStore.from)
+		if f.preconfKey != nil {
+			f.l2BlockNumberMux.Lock()
+			f.storeSeq--
+			f.l2BlockNumberMux.Unlock()
+		}
+	}
+}
+
+// syncL2BlockNumber corrects the l2 block number of the next txs to be stored when the tx has been stored
+// in a l2 block other than the expected one, the preconfirmations already published can't be corrected
+func (f *finalizer) syncL2BlockNumber(txToStore transactionToStore, storedL2BlockNumber uint64) {
+	if f.preconfKey == nil {
+		return
+	}
+	f.l2BlockNumberMux.Lock()
+	defer f.l2BlockNumberMux.Unlock()
+	if expected := f.l2BlockNumberBase + txToStore.storeSeq; expected != storedL2BlockNumber {
+		log.Warnf("tx %s stored in l2 block %d instead of the expected %d, correcting the l2 block number of the next preconfirmations",
+			txToStore.hash.String(), storedL2BlockNumber, expected)
+		f.l2BlockNumberBase = storedL2BlockNumber - txToStore.storeSeq
+	}
+}
+
+// publishPreconfirmation signs the promise to include the tx in the WIP batch and publishes it. It's done as soon as
+// the tx is executed, so the users get the preconfirmation before the tx is stored and its receipt is available
+func (f *finalizer) publishPreconfirmation(ctx context.Context, txToStore transactionToStore) {
+	preconfirmation := &preconf.Preconfirmation{
+		TxHash:        txToStore.response.TxHash,
+		BatchNumber:   hexutil.Uint64(txToStore.batchNumber),
+		L2BlockNumber: hexutil.Uint64(txToStore.l2BlockNumber),
+		Position:      hexutil.Uint64(txToStore.position),
+		StateRoot:     txToStore.response.StateRoot,
+	}
+	if err := preconfirmation.Sign(f.preconfKey); err != nil {
+		log.Errorf("failed to sign preconfirmation for tx %s, err: %v", txToStore.hash.String(), err)
+		return
+	}
+	if err := f.dbManager.AddPreconfirmation(ctx, preconfirmation); err != nil {
+		log.Errorf("failed to publish preconfirmation for tx %s, err: %v", txToStore.hash.String(), err)
 	}
 }
 
@@ -330,65 +419,106 @@ func (f *finalizer) finalizeBatches(ctx context.Context) {
 	log.Debug("finalizer init loop")
 	showNotFoundTxLog := true // used to log debug only the first message when there is no txs to process
 	for {
-		start := now()
-		if f.batch.batchNumber == f.cfg.StopSequencerOnBatchNum {
-			f.halt(ctx, fmt.Errorf("finalizer reached stop sequencer batch number: %v", f.cfg.StopSequencerOnBatchNum))
-		}
+		f.finalizeBatchesStep(ctx, &showNotFoundTxLog)
 
-		tx := f.worker.GetBestFittingTx(f.batch.remainingResources)
-		metrics.WorkerProcessingTime(time.Since(start))
-		if tx != nil {
-			log.Debugf("processing tx: %s", tx.Hash.Hex())
-			showNotFoundTxLog = true
+		if err := ctx.Err(); err != nil {
+			log.Infof("stopping finalizer because of context, err: %s", err)
+			return
+		}
+	}
+}
 
-			firstTxProcess := true
+// finalizeBatchesStep runs a single iteration of the finalizeBatches loop: it processes the next bundle or tx
+// available in the worker and then closes the WIP batch if any of the closing conditions is met
+func (f *finalizer) finalizeBatchesStep(ctx context.Context, showNotFoundTxLog *bool) {
+	start := time.Now()
+	if stopBatchNum := f.stopSequencerOnBatchNum.Load(); f.batch.batchNumber == stopBatchNum {
+		f.halt(ctx, fmt.Errorf("finalizer reached stop sequencer batch number: %v", stopBatchNum))
+	}
 
-			f.sharedResourcesMux.Lock()
-			for {
-				_, err := f.processTransaction(ctx, tx, firstTxProcess)
-				if err != nil {
-					if err == ErrEffectiveGasPriceReprocess {
-						firstTxProcess = false
-						log.Info("reprocessing tx because of effective gas price calculation: %s", tx.Hash.Hex())
-						continue
-					} else {
-						log.Errorf("failed to process transaction in finalizeBatches, Err: %v", err)
-						break
-					}
-				}
-				break
-			}
-			f.sharedResourcesMux.Unlock()
-		} else {
-			// wait for new txs
-			if showNotFoundTxLog {
-				log.Debug("no transactions to be processed. Waiting...")
-				showNotFoundTxLog = false
-			}
-			if f.cfg.SleepDuration.Duration > 0 {
-				time.Sleep(f.cfg.SleepDuration.Duration)
-			}
-		}
+	if f.closeBatchRequested.CompareAndSwap(true, false) {
+		log.Infof("closing batch %d because it was requested through the admin API.", f.batch.batchNumber)
+		f.batch.closingReason = state.AdminClosingReason
+		f.finalizeBatch(ctx)
+	}
 
-		if !f.cfg.SequentialReprocessFullBatch && f.reprocessFullBatchError.Load() {
-			// There is an error reprocessing previous batch closed (parallel sanity check)
-			// We halt the execution of the Sequencer at this point
-			f.halt(ctx, fmt.Errorf("halting Sequencer because of error reprocessing full batch (sanity check). Check previous errors in logs to know which was the cause"))
+	if f.paused.Load() {
+		if ctx.Err() == nil {
+			time.Sleep(pausedFinalizerSleepDuration)
 		}
+		return
+	}
 
-		if f.isDeadlineEncountered() {
-			log.Infof("closing batch %d because deadline was encountered.", f.batch.batchNumber)
-			f.finalizeBatch(ctx)
-		} else if f.isBatchFull() || f.isBatchAlmostFull() {
-			log.Infof("closing batch %d because it's almost full.", f.batch.batchNumber)
+	// bundles are processed before the regular txs
+	var tx *TxTracker
+	bundle := f.worker.PopBundle()
+	if bundle == nil {
+		tx = f.worker.GetBestFittingTx(f.batch.remainingResources)
+	}
+	metrics.WorkerProcessingTime(time.Since(start))
+	if bundle != nil {
+		log.Debugf("processing bundle: %s", bundle.Hash.String())
+		*showNotFoundTxLog = true
+
+		f.sharedResourcesMux.Lock()
+		err := f.processBundle(ctx, bundle)
+		f.sharedResourcesMux.Unlock()
+		if errors.Is(err, ErrBundleDoesNotFit) {
+			log.Infof("closing batch %d because bundle %s does not fit in it.", f.batch.batchNumber, bundle.Hash.String())
+			f.batch.closingReason = state.BatchAlmostFullClosingReason
 			f.finalizeBatch(ctx)
+		} else if err != nil {
+			log.Errorf("failed to process bundle in finalizeBatches, Err: %v", err)
 		}
+	} else if tx != nil {
+		log.Debugf("processing tx: %s", tx.Hash.Hex())
+		*showNotFoundTxLog = true
 
-		if err := ctx.Err(); err != nil {
-			log.Infof("stopping finalizer because of context, err: %s", err)
-			return
+		firstTxProcess := true
+
+		f.sharedResourcesMux.Lock()
+		for {
+			_, err := f.processTransaction(ctx, tx, firstTxProcess)
+			if err != nil {
+				if err == ErrEffectiveGasPriceReprocess {
+					firstTxProcess = false
+					log.Info("reprocessing tx because of effective gas price calculation: %s", tx.Hash.Hex())
+					continue
+				} else {
+					log.Errorf("failed to process transaction in finalizeBatches, Err: %v", err)
+					break
+				}
+			}
+			break
+		}
+		f.sharedResourcesMux.Unlock()
+	} else {
+		// wait for new txs
+		if *showNotFoundTxLog {
+			log.Debug("no transactions to be processed. Waiting...")
+			*showNotFoundTxLog = false
+		}
+		if f.cfg.SleepDuration.Duration > 0 {
+			time.Sleep(f.cfg.SleepDuration.Duration)
 		}
 	}
+
+	if !f.cfg.SequentialReprocessFullBatch && f.reprocessFullBatchError.Load() {
+		// There is an error reprocessing previous batch closed (parallel sanity check)
+		// We halt the execution of the Sequencer at this point
+		f.halt(ctx, fmt.Errorf("halting Sequencer because of error reprocessing full batch (sanity check). Check previous errors in logs to know which was the cause"))
+	}
+
+	if f.isDeadlineEncountered() {
+		log.Infof("closing batch %d because deadline was encountered.", f.batch.batchNumber)
+		f.finalizeBatch(ctx)
+	} else if f.isBatchFull() {
+		log.Infof("closing batch %d because it's full.", f.batch.batchNumber)
+		f.finalizeBatch(ctx)
+	} else if f.isClosingStrategyMet() {
+		log.Infof("closing batch %d because of the batch closing strategy, reason: %s.", f.batch.batchNumber, f.batch.closingReason)
+		f.finalizeBatch(ctx)
+	}
 }
 
 // sortForcedBatches sorts the forced batches by ForcedBatchNumber
@@ -433,6 +563,26 @@ func (f *finalizer) finalizeBatch(ctx context.Context) {
 	}
 }
 
+// pause stops the finalizer from processing new txs until it's resumed
+func (f *finalizer) pause() {
+	f.paused.Store(true)
+}
+
+// resume resumes the processing of txs
+func (f *finalizer) resume() {
+	f.paused.Store(false)
+}
+
+// requestCloseBatch requests the finalizer to close the WIP batch in the next iteration
+func (f *finalizer) requestCloseBatch() {
+	f.closeBatchRequested.Store(true)
+}
+
+// setStopSequencerOnBatchNum sets the batch number where the finalizer will halt, 0 means never
+func (f *finalizer) setStopSequencerOnBatchNum(batchNum uint64) {
+	f.stopSequencerOnBatchNum.Store(batchNum)
+}
+
 // halt halts the finalizer
 func (f *finalizer) halt(ctx context.Context, err error) {
 	event := &event.Event{
@@ -575,6 +725,7 @@ func (f *finalizer) newWIPBatch(ctx context.Context) (*WipBatch, error) {
 
 	batch, err := f.openWIPBatch(ctx, lastBatchNumber+1, f.currentGERHash, stateRoot)
 	if err == nil {
+		f.worker.SlideQuotasWindow()
 		f.processRequest.Timestamp = batch.timestamp
 		f.processRequest.BatchNumber = batch.batchNumber
 		f.processRequest.OldStateRoot = stateRoot
@@ -610,8 +761,27 @@ func (f *finalizer) processTransaction(ctx context.Context, tx *TxTracker, first
 
 		txGasPrice := tx.GasPrice
 
+		// The sponsor is charged before processing the tx, so the pending sponsored txs can't overspend its budget.
+		// If the budget left doesn't cover the tx it's processed as a not sponsored tx
+		if firstTxProcess && tx.Sponsored && !tx.sponsorCharged {
+			charged, chargeErr := f.dbManager.ChargeSponsor(ctx, tx.Hash, tx.BatchResources.ZKCounters.CumulativeGasUsed)
+			if chargeErr != nil {
+				log.Errorf("failed to charge the sponsor, processing the tx as not sponsored: %v", chargeErr)
+			}
+			tx.Sponsored, tx.sponsorCharged = charged, charged
+		}
+
+		// Sponsored txs bypass the EffectiveGasPrice, they are processed with the tx gas price
+		if firstTxProcess && tx.Sponsored {
+			tx.L1GasPrice, tx.L2GasPrice = f.dbManager.GetL1AndL2GasPrice()
+			tx.EffectiveGasPrice.Set(txGasPrice)
+			tx.EGPLog.GasPrice.Set(txGasPrice)
+			tx.EGPLog.ValueFirst.Set(txGasPrice)
+			tx.IsLastExecution = true
+		}
+
 		// If it is the first time we process this tx then we calculate the EffectiveGasPrice
-		if firstTxProcess {
+		if firstTxProcess && !tx.Sponsored {
 			// Get L1 gas price and store in txTracker to make it consistent during the lifespan of the transaction
 			tx.L1GasPrice, tx.L2GasPrice = f.dbManager.GetL1AndL2GasPrice()
 			// Get the tx and l2 gas price we will use in the egp calculation. If egp is disabled we will use a "simulated" tx gas price
@@ -653,22 +823,28 @@ func (f *finalizer) processTransaction(ctx context.Context, tx *TxTracker, first
 			}
 		}
 
-		effectivePercentage, err := f.effectiveGasPrice.CalculateEffectiveGasPricePercentage(txGasPrice, tx.EffectiveGasPrice)
-		if err != nil {
-			if f.effectiveGasPrice.IsEnabled() {
-				return nil, err
+		var effectivePercentage uint8
+		if tx.Sponsored {
+			effectivePercentage = state.MaxEffectivePercentage
+			tx.EGPLog.Percentage = effectivePercentage
+		} else {
+			effectivePercentage, err = f.effectiveGasPrice.CalculateEffectiveGasPricePercentage(txGasPrice, tx.EffectiveGasPrice)
+			if err != nil {
+				if f.effectiveGasPrice.IsEnabled() {
+					return nil, err
+				} else {
+					log.Warnf("EffectiveGasPrice is disabled, but failed to to CalculateEffectiveGasPricePercentage#1: %s", err)
+					tx.EGPLog.Error = fmt.Sprintf("%s; CalculateEffectiveGasPricePercentage#1: %s", tx.EGPLog.Error, err)
+				}
 			} else {
-				log.Warnf("EffectiveGasPrice is disabled, but failed to to CalculateEffectiveGasPricePercentage#1: %s", err)
-				tx.EGPLog.Error = fmt.Sprintf("%s; CalculateEffectiveGasPricePercentage#1: %s", tx.EGPLog.Error, err)
+				// Save percentage for later logging
+				tx.EGPLog.Percentage = effectivePercentage
 			}
-		} else {
-			// Save percentage for later logging
-			tx.EGPLog.Percentage = effectivePercentage
-		}
 
-		// If EGP is disabled we use tx GasPrice (MaxEffectivePercentage=255)
-		if !f.effectiveGasPrice.IsEnabled() {
-			effectivePercentage = state.MaxEffectivePercentage
+			// If EGP is disabled we use tx GasPrice (MaxEffectivePercentage=255)
+			if !f.effectiveGasPrice.IsEnabled() {
+				effectivePercentage = state.MaxEffectivePercentage
+			}
 		}
 
 		effectivePercentageAsDecodedHex, err := hex.DecodeHex(fmt.Sprintf("%x", effectivePercentage))
@@ -724,6 +900,154 @@ func (f *finalizer) processTransaction(ctx context.Context, tx *TxTracker, first
 	return nil, nil
 }
 
+// processBundle executes all the txs of a bundle in a single executor call on top of the WIP batch.
+// The txs are added contiguously to the batch only if all of them succeed and fit in the remaining
+// batch resources and in the quotas of their senders and IPs, otherwise the WIP batch state root is
+// left untouched. Bundle txs are processed
+// with their full gas price (no effective gas price reprocessing). ErrBundleDoesNotFit is returned
+// when the bundle has been requeued to be retried in a new batch
+func (f *finalizer) processBundle(ctx context.Context, bundle *BundleTracker) error {
+	log := log.WithFields("bundleHash", bundle.Hash.String(), "batchNumber", f.processRequest.BatchNumber)
+	start := time.Now()
+	defer func() {
+		metrics.ProcessingTime(time.Since(start))
+	}()
+
+	if f.batch.countOfTxs+len(bundle.Txs) > int(f.batchConstraints.MaxTxsPerBatch) {
+		return f.handleBundleDoesNotFit(ctx, bundle)
+	}
+
+	processRequest := f.processRequest
+	if f.batch.isEmpty() {
+		processRequest.GlobalExitRoot = f.batch.globalExitRoot
+	} else {
+		processRequest.GlobalExitRoot = state.ZeroHash
+	}
+
+	forkId := f.dbManager.GetForkIDByBatchNumber(processRequest.BatchNumber)
+	processRequest.Transactions = []byte{}
+	for _, tx := range bundle.Txs {
+		processRequest.Transactions = append(processRequest.Transactions, tx.RawTx...)
+		if forkId >= forkId5 {
+			processRequest.Transactions = append(processRequest.Transactions, state.MaxEffectivePercentage)
+		}
+	}
+
+	log.Infof("processBundle: %d txs. Batch.BatchNumber: %d, OldStateRoot: %s, GER: %s", len(bundle.Txs), f.batch.batchNumber, processRequest.OldStateRoot, processRequest.GlobalExitRoot.String())
+	result, err := f.executor.ProcessBatch(ctx, processRequest, true)
+	if err != nil && errors.Is(err, runtime.ErrExecutorDBError) {
+		// retry the bundle later
+		f.worker.RequeueBundle(bundle)
+		return err
+	} else if err != nil {
+		f.failBundle(ctx, bundle, pool.TxStatusFailed, err.Error())
+		return err
+	} else if result.IsExecutorLevelError {
+		f.failBundle(ctx, bundle, pool.TxStatusInvalid, result.ExecutorError.Error())
+		return result.ExecutorError
+	} else if result.IsRomOOCError {
+		return f.handleBundleDoesNotFit(ctx, bundle)
+	} else if len(result.Responses) != len(bundle.Txs) {
+		err = fmt.Errorf("executor returned %d responses for the %d txs of bundle %s", len(result.Responses), len(bundle.Txs), bundle.Hash.String())
+		f.failBundle(ctx, bundle, pool.TxStatusFailed, err.Error())
+		return err
+	}
+
+	for i, txResponse := range result.Responses {
+		if txResponse.RomError != nil {
+			reason := fmt.Sprintf("tx %s failed: %s", bundle.Txs[i].HashStr, txResponse.RomError)
+			f.failBundle(ctx, bundle, pool.TxStatusFailed, reason)
+			return nil
+		}
+	}
+
+	remainingResources := f.batch.remainingResources
+	err = remainingResources.Sub(state.BatchResources{ZKCounters: result.UsedZkCounters, Bytes: uint64(len(bundle.rawTxs()))})
+	if err != nil {
+		return f.handleBundleDoesNotFit(ctx, bundle)
+	}
+
+	// the bundle skips the quota checks of GetBestFittingTx, so it's checked and charged as a whole
+	// with the counters it has really used
+	err = f.worker.AddBundleToQuotas(bundle, result.UsedZkCounters)
+	if err != nil {
+		f.failBundle(ctx, bundle, pool.TxStatusFailed, err.Error())
+		return nil
+	}
+	f.batch.remainingResources = remainingResources
+
+	oldStateRoot := f.batch.stateRoot
+	for i, txResponse := range result.Responses {
+		tx := bundle.Txs[i]
+		tx.EffectiveGasPrice.Set(tx.GasPrice)
+		tx.EGPLog.ValueFinal.Set(tx.GasPrice)
+		tx.EGPLog.Percentage = state.MaxEffectivePercentage
+
+		txToStore := transactionToStore{
+			hash:          tx.Hash,
+			from:          tx.From,
+			response:      txResponse,
+			batchResponse: result,
+			batchNumber:   f.batch.batchNumber,
+			timestamp:     f.batch.timestamp,
+			coinbase:      f.batch.coinbase,
+			oldStateRoot:  oldStateRoot,
+			isForcedBatch: false,
+			flushId:       result.FlushID,
+			egpLog:        &tx.EGPLog,
+			position:      uint64(f.batch.countOfTxs),
+		}
+		oldStateRoot = txResponse.StateRoot
+
+		f.updateLastPendingFlushID(result.FlushID)
+
+		f.addPendingTxToStore(ctx, txToStore)
+
+		f.batch.countOfTxs++
+
+		f.worker.ChargeTxOrdering(tx)
+
+		f.updateWorkerAfterSuccessfulProcessing(ctx, tx.Hash, tx.From, false, result)
+	}
+
+	// Update in-memory batch and processRequest
+	f.processRequest.OldStateRoot = result.NewStateRoot
+	f.batch.stateRoot = result.NewStateRoot
+	f.batch.localExitRoot = result.NewLocalExitRoot
+	log.Infof("processBundle: data loaded in memory. batch.batchNumber: %d, result.NewStateRoot: %s, result.NewLocalExitRoot: %s", f.batch.batchNumber, result.NewStateRoot.String(), result.NewLocalExitRoot.String())
+
+	return nil
+}
+
+// handleBundleDoesNotFit requeues the bundle to be retried in a new batch. If the WIP batch is
+// empty the bundle will never fit in a batch, so it's discarded instead
+func (f *finalizer) handleBundleDoesNotFit(ctx context.Context, bundle *BundleTracker) error {
+	if f.batch.isEmpty() {
+		f.failBundle(ctx, bundle, pool.TxStatusInvalid, ErrBundleDoesNotFit.Error())
+		return nil
+	}
+	f.worker.RequeueBundle(bundle)
+	return ErrBundleDoesNotFit
+}
+
+// failBundle updates the status of all the txs of the bundle in the pool
+func (f *finalizer) failBundle(ctx context.Context, bundle *BundleTracker, status pool.TxStatus, reason string) {
+	log.Infof("bundle %s discarded, reason: %s", bundle.Hash.String(), reason)
+	failedReason := fmt.Sprintf("%s: %s", ErrBundleFailed.Error(), reason)
+	for _, tx := range bundle.Txs {
+		err := f.dbManager.UpdateTxStatus(ctx, tx.Hash, status, false, &failedReason)
+		if err != nil {
+			log.Errorf("failed to update status to %s in the pool for bundle tx: %s, err: %s", status, tx.Hash.String(), err)
+			continue
+		}
+		if status == pool.TxStatusInvalid {
+			metrics.TxProcessed(metrics.TxProcessedLabelInvalid, 1)
+		} else {
+			metrics.TxProcessed(metrics.TxProcessedLabelFailed, 1)
+		}
+	}
+}
+
 // handleProcessTransactionResponse handles the response of transaction processing.
 func (f *finalizer) handleProcessTransactionResponse(ctx context.Context, tx *TxTracker, result *state.ProcessBatchResponse, oldStateRoot common.Hash) (errWg *sync.WaitGroup, err error) {
 	// Handle Transaction Error
@@ -805,6 +1129,7 @@ func (f *finalizer) handleProcessTransactionResponse(ctx context.Context, tx *Tx
 		isForcedBatch: false,
 		flushId:       result.FlushID,
 		egpLog:        &tx.EGPLog,
+		position:      uint64(f.batch.countOfTxs),
 	}
 
 	f.updateLastPendingFlushID(result.FlushID)
@@ -813,6 +1138,9 @@ func (f *finalizer) handleProcessTransactionResponse(ctx context.Context, tx *Tx
 
 	f.batch.countOfTxs++
 
+	f.worker.AddTxToQuotas(tx, result.UsedZkCounters)
+	f.worker.ChargeTxOrdering(tx)
+
 	f.updateWorkerAfterSuccessfulProcessing(ctx, tx.Hash, tx.From, false, result)
 
 	return nil, nil
@@ -914,11 +1242,12 @@ func (f *finalizer) storeProcessedTx(ctx context.Context, txToStore transactionT
 	} else {
 		log.Info("storeProcessedTx: storing processed txToStore")
 	}
-	err := f.dbManager.StoreProcessedTxAndDeleteFromPool(ctx, txToStore)
+	l2BlockNumber, err := f.dbManager.StoreProcessedTxAndDeleteFromPool(ctx, txToStore)
 	if err != nil {
 		log.Info("halting the finalizer because of a database error on storing processed transaction")
 		f.halt(ctx, err)
 	}
+	f.syncL2BlockNumber(txToStore, l2BlockNumber)
 	metrics.TxProcessed(metrics.TxProcessedLabelSuccessful, 1)
 }
 
@@ -1128,7 +1457,7 @@ func (f *finalizer) processForcedBatch(ctx context.Context, lastBatchNumberInSta
 		GlobalExitRoot: forcedBatch.GlobalExitRoot,
 		Transactions:   forcedBatch.RawTxsData,
 		Coinbase:       f.sequencerAddress,
-		Timestamp:      now(),
+		Timestamp:      f.now(),
 		Caller:         stateMetrics.SequencerCallerLabel,
 	}
 
@@ -1258,7 +1587,7 @@ func (f *finalizer) openBatch(ctx context.Context, num uint64, ger common.Hash,
 	processingCtx := state.ProcessingContext{
 		BatchNumber:    num,
 		Coinbase:       f.sequencerAddress,
-		Timestamp:      now(),
+		Timestamp:      f.now(),
 		GlobalExitRoot: ger,
 	}
 	err := f.dbManager.OpenBatch(ctx, processingCtx, dbTx)
@@ -1382,18 +1711,19 @@ func (f *finalizer) getOldStateRootFromBatches(batches []*state.Batch) common.Ha
 // isDeadlineEncountered returns true if any closing signal deadline is encountered
 func (f *finalizer) isDeadlineEncountered() bool {
 	// Forced batch deadline
-	if f.nextForcedBatchDeadline != 0 && now().Unix() >= f.nextForcedBatchDeadline {
+	if f.nextForcedBatchDeadline != 0 && f.now().Unix() >= f.nextForcedBatchDeadline {
 		log.Infof("Closing batch: %d, forced batch deadline encountered.", f.batch.batchNumber)
+		f.batch.closingReason = state.ForcedBatchDeadlineClosingReason
 		return true
 	}
 	// Global Exit Root deadline
-	if f.nextGERDeadline != 0 && now().Unix() >= f.nextGERDeadline {
+	if f.nextGERDeadline != 0 && f.now().Unix() >= f.nextGERDeadline {
 		log.Infof("Closing batch: %d, Global Exit Root deadline encountered.", f.batch.batchNumber)
 		f.batch.closingReason = state.GlobalExitRootDeadlineClosingReason
 		return true
 	}
 	// Timestamp resolution deadline
-	if !f.batch.isEmpty() && f.batch.timestamp.Add(f.cfg.TimestampResolution.Duration).Before(time.Now()) {
+	if !f.batch.isEmpty() && f.batch.timestamp.Add(f.cfg.TimestampResolution.Duration).Before(f.now()) {
 		log.Infof("Closing batch: %d, because of timestamp resolution.", f.batch.batchNumber)
 		f.batch.closingReason = state.TimeoutResolutionDeadlineClosingReason
 		return true
@@ -1420,64 +1750,23 @@ func (f *finalizer) checkRemainingResources(result *state.ProcessBatchResponse,
 	return nil
 }
 
-// isBatchAlmostFull checks if the current batch remaining resources are under the Constraints threshold for most efficient moment to close a batch
-func (f *finalizer) isBatchAlmostFull() bool {
-	resources := f.batch.remainingResources
-	zkCounters := resources.ZKCounters
-	result := false
-	resourceDesc := ""
-	if resources.Bytes <= f.getConstraintThresholdUint64(f.batchConstraints.MaxBatchBytesSize) {
-		resourceDesc = "MaxBatchBytesSize"
-		result = true
-	} else if zkCounters.UsedSteps <= f.getConstraintThresholdUint32(f.batchConstraints.MaxSteps) {
-		resourceDesc = "MaxSteps"
-		result = true
-	} else if zkCounters.UsedPoseidonPaddings <= f.getConstraintThresholdUint32(f.batchConstraints.MaxPoseidonPaddings) {
-		resourceDesc = "MaxPoseidonPaddings"
-		result = true
-	} else if zkCounters.UsedBinaries <= f.getConstraintThresholdUint32(f.batchConstraints.MaxBinaries) {
-		resourceDesc = "MaxBinaries"
-		result = true
-	} else if zkCounters.UsedKeccakHashes <= f.getConstraintThresholdUint32(f.batchConstraints.MaxKeccakHashes) {
-		resourceDesc = "MaxKeccakHashes"
-		result = true
-	} else if zkCounters.UsedArithmetics <= f.getConstraintThresholdUint32(f.batchConstraints.MaxArithmetics) {
-		resourceDesc = "MaxArithmetics"
-		result = true
-	} else if zkCounters.UsedMemAligns <= f.getConstraintThresholdUint32(f.batchConstraints.MaxMemAligns) {
-		resourceDesc = "MaxMemAligns"
-		result = true
-	} else if zkCounters.CumulativeGasUsed <= f.getConstraintThresholdUint64(f.batchConstraints.MaxCumulativeGasUsed) {
-		resourceDesc = "MaxCumulativeGasUsed"
-		result = true
-	}
-
-	if result {
-		log.Infof("Closing batch: %d, because it reached %s threshold limit", f.batch.batchNumber, resourceDesc)
-		f.batch.closingReason = state.BatchAlmostFullClosingReason
-	}
-
-	return result
+// isClosingStrategyMet checks if the batch closing strategy decides to close the current batch before it's full
+func (f *finalizer) isClosingStrategyMet() bool {
+	closeBatch, reason := f.batchClosing.shouldClose(f.batch, f.now())
+	if closeBatch {
+		f.batch.closingReason = reason
+	}
+	return closeBatch
 }
 
 // setNextForcedBatchDeadline sets the next forced batch deadline
 func (f *finalizer) setNextForcedBatchDeadline() {
-	f.nextForcedBatchDeadline = now().Unix() + int64(f.cfg.ForcedBatchDeadlineTimeout.Duration.Seconds())
+	f.nextForcedBatchDeadline = f.now().Unix() + int64(f.cfg.ForcedBatchDeadlineTimeout.Duration.Seconds())
 }
 
 // setNextGERDeadline sets the next Global Exit Root deadline
 func (f *finalizer) setNextGERDeadline() {
-	f.nextGERDeadline = now().Unix() + int64(f.cfg.GERDeadlineTimeout.Duration.Seconds())
-}
-
-// getConstraintThresholdUint64 returns the threshold for the given input
-func (f *finalizer) getConstraintThresholdUint64(input uint64) uint64 {
-	return input * uint64(f.cfg.ResourcePercentageToCloseBatch) / oneHundred
-}
-
-// getConstraintThresholdUint32 returns the threshold for the given input
-func (f *finalizer) getConstraintThresholdUint32(input uint32) uint32 {
-	return uint32(input*f.cfg.ResourcePercentageToCloseBatch) / oneHundred
+	f.nextGERDeadline = f.now().Unix() + int64(f.cfg.GERDeadlineTimeout.Duration.Seconds())
 }
 
 // getUsedBatchResources returns the used resources in the batch
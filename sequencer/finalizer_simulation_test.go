@@ -0,0 +1,267 @@
+package sequencer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/simulation"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulationBatchFull(t *testing.T) {
+	constraints := simBatchConstraints
+	constraints.MaxTxsPerBatch = 3
+	s := newSimulation(t, simFinalizerCfg, constraints)
+
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		s.addTxAt(0, s.tx(fmt.Sprintf("a%d", nonce), "alice", nonce))
+	}
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		s.addTxAt(0, s.tx(fmt.Sprintf("b%d", nonce), "bob", nonce))
+	}
+	s.run(time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1", "a2"}, closingReason: state.BatchFullClosingReason},
+		simLayoutBatch{txs: []string{"a3", "b0", "b1"}, closingReason: state.BatchFullClosingReason},
+		simLayoutBatch{txs: []string{"b2"}},
+	)
+}
+
+func TestSimulationBatchAlmostFull(t *testing.T) {
+	s := newSimulation(t, simFinalizerCfg, simBatchConstraints)
+
+	// each tx uses 300 of the 1000 steps of a batch, after the third tx
+	// the remaining steps are under the 10% threshold to close the batch
+	counters := simDefaultCounters
+	counters.UsedSteps = 300
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		tx := s.tx(fmt.Sprintf("a%d", nonce), "alice", nonce)
+		s.setTxResult(tx, simulation.TxResult{Counters: counters})
+		s.addTxAt(0, tx)
+	}
+	s.run(time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1", "a2"}, closingReason: state.BatchAlmostFullClosingReason},
+		simLayoutBatch{txs: []string{"a3"}},
+	)
+}
+
+func TestSimulationBatchClosingStrategies(t *testing.T) {
+	finalizerCfg := simFinalizerCfg
+	finalizerCfg.BatchClosing = BatchClosingCfg{
+		Strategies:      []string{BatchClosingTargetGas, BatchClosingTargetBatchTime},
+		TargetGas:       3 * simDefaultCounters.CumulativeGasUsed,
+		TargetBatchTime: cfgTypes.NewDuration(3 * time.Second),
+	}
+	s := newSimulation(t, finalizerCfg, simBatchConstraints)
+
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		s.addTxAt(0, s.tx(fmt.Sprintf("a%d", nonce), "alice", nonce))
+	}
+	s.addTxAt(5*time.Second, s.tx("a4", "alice", 4))
+	s.run(6 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1", "a2"}, closingReason: state.TargetGasClosingReason},
+		simLayoutBatch{txs: []string{"a3"}, closingReason: state.TargetBatchTimeClosingReason},
+		simLayoutBatch{txs: []string{"a4"}},
+	)
+}
+
+func TestSimulationQuotas(t *testing.T) {
+	constraints := simBatchConstraints
+	constraints.MaxTxsPerBatch = 4
+	quotasCfg := QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 2, MaxTxsPerIP: 3}
+	s := newSimulationWithQuotas(t, simFinalizerCfg, constraints, quotasCfg)
+
+	// alice spams, her txs over the quota are deferred behind the txs of the other senders
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		s.addTxAt(0, s.tx(fmt.Sprintf("a%d", nonce), "alice", nonce))
+	}
+	// bob and carol send from the same IP, its quota defers c1
+	for _, tx := range []types.Transaction{s.tx("b0", "bob", 0), s.tx("b1", "bob", 1), s.tx("c0", "carol", 0), s.tx("c1", "carol", 1)} {
+		s.setTxIP(tx, "1.1.1.1")
+		s.addTxAt(0, tx)
+	}
+	s.run(12 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1", "b0", "b1"}, closingReason: state.BatchFullClosingReason},
+		simLayoutBatch{txs: []string{"c0"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a2", "a3", "c1"}},
+	)
+
+	// a4 is deferred while alice is over her quota, once the window moves on an empty batch takes it
+	s.run(20 * time.Second)
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1", "b0", "b1"}, closingReason: state.BatchFullClosingReason},
+		simLayoutBatch{txs: []string{"c0"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a2", "a3", "c1"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a4"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{}},
+	)
+}
+
+func TestSimulationTimestampResolution(t *testing.T) {
+	s := newSimulation(t, simFinalizerCfg, simBatchConstraints)
+
+	s.addTxAt(0, s.tx("a0", "alice", 0))
+	s.addTxAt(4*time.Second, s.tx("a1", "alice", 1))
+	s.addTxAt(12*time.Second, s.tx("a2", "alice", 2))
+	s.run(15 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a2"}},
+	)
+
+	// an empty batch is never closed because of the timestamp resolution
+	s.run(30 * time.Second)
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a2"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{}},
+	)
+}
+
+func TestSimulationGERDeadline(t *testing.T) {
+	s := newSimulation(t, simFinalizerCfg, simBatchConstraints)
+	ger1 := common.HexToHash("0x1")
+	ger2 := common.HexToHash("0x2")
+
+	s.addTxAt(0, s.tx("a0", "alice", 0))
+	s.addGERAt(time.Second, ger1)
+	s.addTxAt(7*time.Second, s.tx("a1", "alice", 1))
+	// no more txs arrive after a1, the batch is closed anyway once the deadline of the second GER is reached
+	s.addGERAt(8*time.Second, ger2)
+	s.run(15 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0"}, closingReason: state.GlobalExitRootDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"a1"}, closingReason: state.GlobalExitRootDeadlineClosingReason, globalExitRoot: ger1},
+		simLayoutBatch{txs: []string{}, globalExitRoot: ger2},
+	)
+}
+
+func TestSimulationForcedBatch(t *testing.T) {
+	s := newSimulation(t, simFinalizerCfg, simBatchConstraints)
+
+	s.addTxAt(0, s.tx("a0", "alice", 0))
+	s.addForcedBatchAt(2*time.Second, s.tx("f0", "carol", 0), s.tx("f1", "carol", 1))
+	s.addTxAt(6*time.Second, s.tx("a1", "alice", 1))
+	s.run(7 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0"}, closingReason: state.ForcedBatchDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"f0", "f1"}, closingReason: state.ForcedBatchClosingReason, forced: true},
+		simLayoutBatch{txs: []string{"a1"}},
+	)
+}
+
+func TestSimulationOutOfCounters(t *testing.T) {
+	s := newSimulation(t, simFinalizerCfg, simBatchConstraints)
+
+	half := simDefaultCounters
+	half.UsedSteps = 500
+	a0 := s.tx("a0", "alice", 0)
+	s.setTxResult(a0, simulation.TxResult{Counters: half})
+	s.addTxAt(0, a0)
+
+	// the pool underestimates the counters of b0, it doesn't fit in the remaining resources of the
+	// first batch once executed so it waits in the worker until the batch is closed by the deadline
+	b0 := s.tx("b0", "bob", 0)
+	overHalf := simDefaultCounters
+	overHalf.UsedSteps = 600
+	s.setTxResult(b0, simulation.TxResult{Counters: overHalf})
+	s.addTxWithCountersAt(0, b0, simDefaultCounters)
+
+	// c0 runs out of counters in the executor, it's discarded
+	c0 := s.tx("c0", "carol", 0)
+	s.setTxResult(c0, simulation.TxResult{Counters: simDefaultCounters, RomError: executor.RomError_ROM_ERROR_OUT_OF_COUNTERS_STEP})
+	s.addTxAt(time.Second, c0)
+	s.addTxAt(time.Second, s.tx("d0", "dave", 0))
+	s.run(15 * time.Second)
+
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "d0"}, closingReason: state.TimeoutResolutionDeadlineClosingReason},
+		simLayoutBatch{txs: []string{"b0"}},
+	)
+	require.Eventually(t, func() bool { return s.txStatus(c0) == pool.TxStatusInvalid }, time.Second, 10*time.Millisecond)
+}
+
+func TestSimulationReprocessFullBatch(t *testing.T) {
+	constraints := simBatchConstraints
+	constraints.MaxTxsPerBatch = 2
+	s := newSimulation(t, simFinalizerCfg, constraints)
+
+	a0 := s.tx("a0", "alice", 0)
+	s.addTxAt(0, a0)
+	s.addTxAt(0, s.tx("a1", "alice", 1))
+	s.run(time.Second)
+	s.requireLayout(
+		simLayoutBatch{txs: []string{"a0", "a1"}, closingReason: state.BatchFullClosingReason},
+		simLayoutBatch{txs: []string{}},
+	)
+
+	// the sequential sanity check has already reprocessed the closed batch, reprocessing
+	// it again with an executor that now runs out of counters for a0 must fail
+	batch, err := s.db.GetBatchByNumber(s.ctx, 1, nil)
+	require.NoError(t, err)
+	_, err = s.finalizer.reprocessFullBatch(s.ctx, 1, simGenesisRoot, batch.StateRoot)
+	require.NoError(t, err)
+
+	s.setTxResult(a0, simulation.TxResult{Counters: simDefaultCounters, RomError: executor.RomError_ROM_ERROR_OUT_OF_COUNTERS_KECCAK})
+	_, err = s.finalizer.reprocessFullBatch(s.ctx, 1, simGenesisRoot, batch.StateRoot)
+	require.ErrorIs(t, err, ErrProcessBatchOOC)
+}
+
+func TestSimulationDeterministicReplay(t *testing.T) {
+	type replay struct {
+		layout     []simLayoutBatch
+		stateRoots []common.Hash
+		timestamps []time.Time
+	}
+
+	session := func() replay {
+		constraints := simBatchConstraints
+		constraints.MaxTxsPerBatch = 4
+		s := newSimulation(t, simFinalizerCfg, constraints)
+		s.fakeExecutor.SetExecutionTime(30 * time.Millisecond)
+
+		senders := []string{"alice", "bob", "carol"}
+		for i := 0; i < 30; i++ {
+			sender := senders[i%len(senders)]
+			nonce := uint64(i / len(senders))
+			s.addTxAt(time.Duration(i)*700*time.Millisecond, s.tx(fmt.Sprintf("%s%d", sender, nonce), sender, nonce))
+		}
+		s.addGERAt(3*time.Second, common.HexToHash("0x1"))
+		s.addForcedBatchAt(9*time.Second, s.tx("f0", "dave", 0))
+		s.addGERAt(14*time.Second, common.HexToHash("0x2"))
+		s.run(40 * time.Second)
+
+		r := replay{layout: s.layout()}
+		require.Empty(t, s.db.getViolations())
+		s.db.mutex.Lock()
+		defer s.db.mutex.Unlock()
+		for _, batch := range s.db.batches {
+			r.stateRoots = append(r.stateRoots, batch.StateRoot)
+			r.timestamps = append(r.timestamps, batch.Timestamp)
+		}
+		return r
+	}
+
+	first := session()
+	second := session()
+	assert.Equal(t, first, second)
+	assert.Greater(t, len(first.layout), 5)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/event/nileventstorage"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	stateMetrics "github.com/0xPolygonHermez/zkevm-node/state/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
@@ -22,6 +23,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/test/constants"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +32,8 @@ import (
 )
 
 var (
+	// now is the clock of the finalizer under test, the tests replace it to get deterministic timestamps
+	now           = time.Now
 	f             *finalizer
 	nilErr        error
 	dbManagerMock = new(DbManagerMock)
@@ -130,7 +134,7 @@ func TestNewFinalizer(t *testing.T) {
 	dbManagerMock.On("GetLastSentFlushID", context.Background()).Return(uint64(0), nil)
 
 	// arrange and act
-	f = newFinalizer(cfg, poolCfg, workerMock, dbManagerMock, executorMock, seqAddr, isSynced, closingSignalCh, bc, eventLog, nil)
+	f = newFinalizer(cfg, poolCfg, workerMock, dbManagerMock, executorMock, seqAddr, isSynced, closingSignalCh, bc, newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, bc), func() time.Time { return now() }, eventLog, nil)
 
 	// assert
 	assert.NotNil(t, f)
@@ -325,6 +329,8 @@ func TestFinalizer_handleProcessTransactionResponse(t *testing.T) {
 				workerMock.On("DeleteTx", txTracker.Hash, txTracker.From).Return().Once()
 				workerMock.On("UpdateAfterSingleSuccessfulTxExecution", txTracker.From, tc.executorResponse.ReadWriteAddresses).Return([]*TxTracker{}).Once()
 				workerMock.On("AddPendingTxToStore", txTracker.Hash, txTracker.From).Return().Once()
+				workerMock.On("AddTxToQuotas", txTracker, tc.executorResponse.UsedZkCounters).Return().Once()
+				workerMock.On("ChargeTxOrdering", txTracker).Return().Once()
 			}
 			if tc.expectedUpdateTxStatus != "" {
 				dbManagerMock.On("UpdateTxStatus", ctx, txHash, tc.expectedUpdateTxStatus, false, mock.Anything).Return(nil).Once()
@@ -559,6 +565,7 @@ func TestFinalizer_newWIPBatch(t *testing.T) {
 					dbManagerMock.On("OpenBatch", ctx, mock.Anything, dbTxMock).Return(tc.openBatchErr).Once()
 					if tc.openBatchErr == nil {
 						dbTxMock.On("Commit", ctx).Return(nilErr).Once()
+						workerMock.On("SlideQuotasWindow").Return().Once()
 					} else {
 						dbTxMock.On("Rollback", ctx).Return(nilErr).Once()
 					}
@@ -1291,7 +1298,7 @@ func TestFinalizer_isDeadlineEncountered(t *testing.T) {
 			// specifically for "Timestamp resolution deadline" test case
 			if tc.timestampResolutionDeadline == true {
 				// ensure that the batch is not empty and the timestamp is in the past
-				f.batch.timestamp = now().Add(-f.cfg.TimestampResolution.Duration * 2)
+				f.batch.timestamp = now().Add(-f.cfg.TimestampResolution.Duration - time.Second)
 				f.batch.countOfTxs = 1
 			}
 
@@ -1515,10 +1522,16 @@ func Test_processTransaction(t *testing.T) {
 	}
 	outOfCountersExecutorErrBatchResp := *outOfCountersErrBatchResp
 	outOfCountersExecutorErrBatchResp.IsRomOOCError = false
+	sponsoredTxTracker := *txTracker
+	sponsoredTxTracker.GasPrice = big.NewInt(0)
+	sponsoredTxTracker.Sponsored = true
+	budgetExhaustedTxTracker := *txTracker
+	budgetExhaustedTxTracker.Sponsored = true
 	testCases := []struct {
 		name                   string
 		ctx                    context.Context
 		tx                     *TxTracker
+		sponsorBudgetExhausted bool
 		expectedResponse       *state.ProcessBatchResponse
 		executorErr            error
 		expectedErr            error
@@ -1542,6 +1555,41 @@ func Test_processTransaction(t *testing.T) {
 				response:      successfulTxResponse,
 			},
 		},
+		{
+			name:             "Successful sponsored transaction processing",
+			ctx:              context.Background(),
+			tx:               &sponsoredTxTracker,
+			expectedResponse: successfulBatchResp,
+			expectedStoredTx: transactionToStore{
+				hash:          txHash,
+				from:          senderAddr,
+				batchNumber:   f.batch.batchNumber,
+				coinbase:      f.batch.coinbase,
+				timestamp:     f.batch.timestamp,
+				oldStateRoot:  newHash,
+				batchResponse: successfulBatchResp,
+				isForcedBatch: false,
+				response:      successfulTxResponse,
+			},
+		},
+		{
+			name:                   "Sponsor budget exhausted, the tx is processed as not sponsored",
+			ctx:                    context.Background(),
+			tx:                     &budgetExhaustedTxTracker,
+			sponsorBudgetExhausted: true,
+			expectedResponse:       successfulBatchResp,
+			expectedStoredTx: transactionToStore{
+				hash:          txHash,
+				from:          senderAddr,
+				batchNumber:   f.batch.batchNumber,
+				coinbase:      f.batch.coinbase,
+				timestamp:     f.batch.timestamp,
+				oldStateRoot:  newHash,
+				batchResponse: successfulBatchResp,
+				isForcedBatch: false,
+				response:      successfulTxResponse,
+			},
+		},
 		{
 			name:                   "Out Of Counters err",
 			ctx:                    context.Background(),
@@ -1576,6 +1624,10 @@ func Test_processTransaction(t *testing.T) {
 				}()
 			}
 
+			sponsored := tc.tx.Sponsored
+			if sponsored {
+				dbManagerMock.On("ChargeSponsor", tc.ctx, tc.tx.Hash, tc.tx.BatchResources.ZKCounters.CumulativeGasUsed).Return(!tc.sponsorBudgetExhausted, nilErr).Once()
+			}
 			dbManagerMock.On("GetL1AndL2GasPrice").Return(uint64(1000000), uint64(100000)).Once()
 			executorMock.On("ProcessBatch", tc.ctx, mock.Anything, true).Return(tc.expectedResponse, tc.executorErr).Once()
 			if tc.executorErr == nil {
@@ -1585,6 +1637,8 @@ func Test_processTransaction(t *testing.T) {
 			if tc.expectedErr == nil {
 				workerMock.On("UpdateAfterSingleSuccessfulTxExecution", tc.tx.From, tc.expectedResponse.ReadWriteAddresses).Return([]*TxTracker{}).Once()
 				workerMock.On("AddPendingTxToStore", tc.tx.Hash, tc.tx.From).Return().Once()
+				workerMock.On("AddTxToQuotas", tc.tx, tc.expectedResponse.UsedZkCounters).Return().Once()
+				workerMock.On("ChargeTxOrdering", tc.tx).Return().Once()
 			}
 
 			if tc.expectedUpdateTxStatus != "" {
@@ -1611,6 +1665,9 @@ func Test_processTransaction(t *testing.T) {
 			if errWg != nil {
 				errWg.Wait()
 			}
+			if sponsored {
+				assert.Equal(t, !tc.sponsorBudgetExhausted, tc.tx.Sponsored)
+			}
 
 			workerMock.AssertExpectations(t)
 			dbManagerMock.AssertExpectations(t)
@@ -1875,7 +1932,7 @@ func TestFinalizer_storeProcessedTx(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// arrange
-			dbManagerMock.On("StoreProcessedTxAndDeleteFromPool", ctx, tc.expectedTxToStore).Return(nilErr)
+			dbManagerMock.On("StoreProcessedTxAndDeleteFromPool", ctx, tc.expectedTxToStore).Return(uint64(0), nilErr)
 
 			// act
 			f.storeProcessedTx(ctx, tc.expectedTxToStore)
@@ -2205,8 +2262,9 @@ func TestFinalizer_getOldStateRootFromBatches(t *testing.T) {
 	}
 }
 
-func TestFinalizer_isBatchAlmostFull(t *testing.T) {
+func TestFinalizer_isClosingStrategyMet(t *testing.T) {
 	// arrange
+	threshold := newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, bc)
 	testCases := []struct {
 		name               string
 		modifyResourceFunc func(resources state.BatchResources) state.BatchResources
@@ -2215,7 +2273,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxBatchBytesSize",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.Bytes = f.getConstraintThresholdUint64(bc.MaxBatchBytesSize) - 1
+				resources.Bytes = threshold.getConstraintThresholdUint64(bc.MaxBatchBytesSize) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2223,7 +2281,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxBatchBytesSize",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.Bytes = f.getConstraintThresholdUint64(bc.MaxBatchBytesSize) + 1
+				resources.Bytes = threshold.getConstraintThresholdUint64(bc.MaxBatchBytesSize) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2231,7 +2289,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxCumulativeGasUsed",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.CumulativeGasUsed = f.getConstraintThresholdUint64(bc.MaxCumulativeGasUsed) - 1
+				resources.ZKCounters.CumulativeGasUsed = threshold.getConstraintThresholdUint64(bc.MaxCumulativeGasUsed) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2239,7 +2297,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxCumulativeGasUsed",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.CumulativeGasUsed = f.getConstraintThresholdUint64(bc.MaxCumulativeGasUsed) + 1
+				resources.ZKCounters.CumulativeGasUsed = threshold.getConstraintThresholdUint64(bc.MaxCumulativeGasUsed) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2247,7 +2305,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxSteps",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedSteps = f.getConstraintThresholdUint32(bc.MaxSteps) - 1
+				resources.ZKCounters.UsedSteps = threshold.getConstraintThresholdUint32(bc.MaxSteps) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2255,7 +2313,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxSteps",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedSteps = f.getConstraintThresholdUint32(bc.MaxSteps) + 1
+				resources.ZKCounters.UsedSteps = threshold.getConstraintThresholdUint32(bc.MaxSteps) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2263,7 +2321,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxPoseidonPaddings",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedPoseidonPaddings = f.getConstraintThresholdUint32(bc.MaxPoseidonPaddings) - 1
+				resources.ZKCounters.UsedPoseidonPaddings = threshold.getConstraintThresholdUint32(bc.MaxPoseidonPaddings) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2271,7 +2329,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxPoseidonPaddings",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedPoseidonPaddings = f.getConstraintThresholdUint32(bc.MaxPoseidonPaddings) + 1
+				resources.ZKCounters.UsedPoseidonPaddings = threshold.getConstraintThresholdUint32(bc.MaxPoseidonPaddings) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2279,7 +2337,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxBinaries",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedBinaries = f.getConstraintThresholdUint32(bc.MaxBinaries) - 1
+				resources.ZKCounters.UsedBinaries = threshold.getConstraintThresholdUint32(bc.MaxBinaries) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2287,7 +2345,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxBinaries",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedBinaries = f.getConstraintThresholdUint32(bc.MaxBinaries) + 1
+				resources.ZKCounters.UsedBinaries = threshold.getConstraintThresholdUint32(bc.MaxBinaries) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2295,7 +2353,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxKeccakHashes",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedKeccakHashes = f.getConstraintThresholdUint32(bc.MaxKeccakHashes) - 1
+				resources.ZKCounters.UsedKeccakHashes = threshold.getConstraintThresholdUint32(bc.MaxKeccakHashes) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2303,7 +2361,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxKeccakHashes",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedKeccakHashes = f.getConstraintThresholdUint32(bc.MaxKeccakHashes) + 1
+				resources.ZKCounters.UsedKeccakHashes = threshold.getConstraintThresholdUint32(bc.MaxKeccakHashes) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2311,7 +2369,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxArithmetics",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedArithmetics = f.getConstraintThresholdUint32(bc.MaxArithmetics) - 1
+				resources.ZKCounters.UsedArithmetics = threshold.getConstraintThresholdUint32(bc.MaxArithmetics) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2319,7 +2377,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxArithmetics",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedArithmetics = f.getConstraintThresholdUint32(bc.MaxArithmetics) + 1
+				resources.ZKCounters.UsedArithmetics = threshold.getConstraintThresholdUint32(bc.MaxArithmetics) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2327,7 +2385,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is ready - MaxMemAligns",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedMemAligns = f.getConstraintThresholdUint32(bc.MaxMemAligns) - 1
+				resources.ZKCounters.UsedMemAligns = threshold.getConstraintThresholdUint32(bc.MaxMemAligns) - 1
 				return resources
 			},
 			expectedResult: true,
@@ -2335,7 +2393,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 		{
 			name: "Is NOT ready - MaxMemAligns",
 			modifyResourceFunc: func(resources state.BatchResources) state.BatchResources {
-				resources.ZKCounters.UsedMemAligns = f.getConstraintThresholdUint32(bc.MaxMemAligns) + 1
+				resources.ZKCounters.UsedMemAligns = threshold.getConstraintThresholdUint32(bc.MaxMemAligns) + 1
 				return resources
 			},
 			expectedResult: false,
@@ -2350,7 +2408,7 @@ func TestFinalizer_isBatchAlmostFull(t *testing.T) {
 			f.batch.remainingResources = tc.modifyResourceFunc(maxRemainingResource)
 
 			// act
-			result := f.isBatchAlmostFull()
+			result := f.isClosingStrategyMet()
 
 			// assert
 			assert.Equal(t, tc.expectedResult, result)
@@ -2395,32 +2453,6 @@ func TestFinalizer_setNextGERDeadline(t *testing.T) {
 	assert.Equal(t, expected, f.nextGERDeadline)
 }
 
-func TestFinalizer_getConstraintThresholdUint64(t *testing.T) {
-	// arrange
-	f = setupFinalizer(false)
-	input := uint64(100)
-	expect := input * uint64(f.cfg.ResourcePercentageToCloseBatch) / 100
-
-	// act
-	result := f.getConstraintThresholdUint64(input)
-
-	// assert
-	assert.Equal(t, result, expect)
-}
-
-func TestFinalizer_getConstraintThresholdUint32(t *testing.T) {
-	// arrange
-	f = setupFinalizer(false)
-	input := uint32(100)
-	expect := uint32(input * f.cfg.ResourcePercentageToCloseBatch / 100)
-
-	// act
-	result := f.getConstraintThresholdUint32(input)
-
-	// assert
-	assert.Equal(t, result, expect)
-}
-
 func TestFinalizer_getRemainingResources(t *testing.T) {
 	// act
 	remainingResources := getMaxRemainingResources(bc)
@@ -2544,6 +2576,8 @@ func setupFinalizer(withWipBatch bool) *finalizer {
 		executor:           executorMock,
 		batch:              wipBatch,
 		batchConstraints:   bc,
+		batchClosing:       newResourceThresholdClosing(cfg.ResourcePercentageToCloseBatch, bc),
+		now:                func() time.Time { return now() },
 		processRequest:     state.ProcessRequest{},
 		sharedResourcesMux: new(sync.RWMutex),
 		currentGERHash:     common.Hash{},
@@ -2566,3 +2600,196 @@ func setupFinalizer(withWipBatch bool) *finalizer {
 		pendingFlushIDCond:           sync.NewCond(new(sync.Mutex)),
 	}
 }
+
+func TestFinalizer_addPendingTxToStorePublishesPreconfirmation(t *testing.T) {
+	f = setupFinalizer(true)
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	f.preconfKey = privateKey
+	f.l2BlockNumberBase, f.storeSeq = 19, 0
+
+	txToStore := transactionToStore{
+		hash:        common.HexToHash("0x1"),
+		from:        common.HexToAddress("0x3"),
+		batchNumber: 10,
+		position:    3,
+		response: &state.ProcessTransactionResponse{
+			TxHash:    common.HexToHash("0x1"),
+			StateRoot: common.HexToHash("0x2"),
+		},
+	}
+	forcedTxToStore := txToStore
+	forcedTxToStore.isForcedBatch = true
+
+	var published *preconf.Preconfirmation
+	dbManagerMock.On("AddPreconfirmation", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		// the preconfirmation is published before the tx is queued to be stored
+		require.Empty(t, f.pendingTransactionsToStore)
+		published = args.Get(1).(*preconf.Preconfirmation)
+	}).Return(nil).Once()
+	workerMock.On("AddPendingTxToStore", txToStore.hash, txToStore.from).Twice()
+
+	f.addPendingTxToStore(ctx, txToStore)
+	f.addPendingTxToStore(ctx, forcedTxToStore)
+
+	dbManagerMock.AssertExpectations(t)
+	require.NotNil(t, published)
+	require.Equal(t, txToStore.response.TxHash, published.TxHash)
+	require.Equal(t, hexutil.Uint64(10), published.BatchNumber)
+	require.Equal(t, hexutil.Uint64(20), published.L2BlockNumber)
+	require.Equal(t, hexutil.Uint64(3), published.Position)
+	require.Equal(t, txToStore.response.StateRoot, published.StateRoot)
+	require.NoError(t, published.Verify(crypto.PubkeyToAddress(privateKey.PublicKey)))
+	// the forced tx is not preconfirmed but it's stored in the next l2 block
+	require.Equal(t, uint64(21), f.l2BlockNumberBase+f.storeSeq)
+	require.Len(t, f.pendingTransactionsToStore, 2)
+
+	// the tx is stored in a later l2 block than expected, so the next txs are expected after it
+	storedTx := <-f.pendingTransactionsToStore
+	require.Equal(t, uint64(20), storedTx.l2BlockNumber)
+	dbManagerMock.On("StoreProcessedTxAndDeleteFromPool", ctx, storedTx).Return(uint64(22), nil).Once()
+	f.storeProcessedTx(ctx, storedTx)
+	require.Equal(t, uint64(23), f.l2BlockNumberBase+f.storeSeq)
+	storedTx = <-f.pendingTransactionsToStore
+	dbManagerMock.On("StoreProcessedTxAndDeleteFromPool", ctx, storedTx).Return(uint64(23), nil).Once()
+	f.storeProcessedTx(ctx, storedTx)
+	require.Equal(t, uint64(23), f.l2BlockNumberBase+f.storeSeq)
+	dbManagerMock.AssertExpectations(t)
+}
+
+func Test_processBundle(t *testing.T) {
+	newBundle := func() *BundleTracker {
+		bundle := &BundleTracker{Hash: common.Hash{0xb}}
+		for i := 0; i < 2; i++ {
+			bundle.Txs = append(bundle.Txs, &TxTracker{
+				Hash:              common.Hash{byte(i + 1)},
+				HashStr:           common.Hash{byte(i + 1)}.String(),
+				From:              senderAddr,
+				Nonce:             uint64(i),
+				GasPrice:          big.NewInt(10),
+				EffectiveGasPrice: new(big.Int),
+				EGPLog:            state.EffectiveGasPriceLog{ValueFinal: new(big.Int)},
+				RawTx:             []byte{0, 1, 2, byte(i)},
+			})
+		}
+		return bundle
+	}
+	successfulBatchResp := &state.ProcessBatchResponse{
+		NewStateRoot: newHash,
+		Responses: []*state.ProcessTransactionResponse{
+			{TxHash: common.Hash{1}, StateRoot: common.Hash{0xa}},
+			{TxHash: common.Hash{2}, StateRoot: newHash},
+		},
+	}
+	failedTxBatchResp := &state.ProcessBatchResponse{
+		NewStateRoot: oldHash,
+		Responses: []*state.ProcessTransactionResponse{
+			{TxHash: common.Hash{1}, StateRoot: common.Hash{0xa}},
+			{TxHash: common.Hash{2}, StateRoot: oldHash, RomError: runtime.ErrExecutionReverted},
+		},
+	}
+	oocBatchResp := &state.ProcessBatchResponse{
+		NewStateRoot:  oldHash,
+		IsRomOOCError: true,
+	}
+
+	testCases := []struct {
+		name                   string
+		batchCountOfTxs        int
+		expectedResponse       *state.ProcessBatchResponse
+		executorErr            error
+		expectedErr            error
+		expectedUpdateTxStatus pool.TxStatus
+		expectedRequeue        bool
+		expectedCountOfTxs     int
+		quotasErr              error
+	}{
+		{
+			name:               "Successful bundle processing",
+			expectedResponse:   successfulBatchResp,
+			expectedCountOfTxs: 2,
+		},
+		{
+			name:                   "Bundle over quota",
+			expectedResponse:       successfulBatchResp,
+			quotasErr:              ErrBundleOverQuota,
+			expectedUpdateTxStatus: pool.TxStatusFailed,
+		},
+		{
+			name:                   "Bundle tx reverted",
+			expectedResponse:       failedTxBatchResp,
+			expectedUpdateTxStatus: pool.TxStatusFailed,
+		},
+		{
+			name:             "Executor DB error",
+			expectedResponse: nil,
+			executorErr:      runtime.ErrExecutorDBError,
+			expectedErr:      runtime.ErrExecutorDBError,
+			expectedRequeue:  true,
+		},
+		{
+			name:                   "Out of counters in an empty batch",
+			expectedResponse:       oocBatchResp,
+			expectedUpdateTxStatus: pool.TxStatusInvalid,
+		},
+		{
+			name:               "Out of counters in a non empty batch",
+			batchCountOfTxs:    1,
+			expectedResponse:   oocBatchResp,
+			expectedErr:        ErrBundleDoesNotFit,
+			expectedRequeue:    true,
+			expectedCountOfTxs: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f = setupFinalizer(true)
+			f.batch.countOfTxs = tc.batchCountOfTxs
+			ctx := context.Background()
+			bundle := newBundle()
+
+			dbManagerMock.On("GetForkIDByBatchNumber", mock.Anything).Return(forkId5)
+			executorMock.On("ProcessBatch", ctx, mock.Anything, true).Return(tc.expectedResponse, tc.executorErr).Once()
+			if tc.expectedRequeue {
+				workerMock.On("RequeueBundle", bundle).Return().Once()
+			}
+			if tc.expectedUpdateTxStatus != "" {
+				for _, tx := range bundle.Txs {
+					dbManagerMock.On("UpdateTxStatus", ctx, tx.Hash, tc.expectedUpdateTxStatus, false, mock.Anything).Return(nil).Once()
+				}
+			}
+			if tc.expectedCountOfTxs > tc.batchCountOfTxs || tc.quotasErr != nil {
+				workerMock.On("AddBundleToQuotas", bundle, tc.expectedResponse.UsedZkCounters).Return(tc.quotasErr).Once()
+			}
+			if tc.expectedCountOfTxs > tc.batchCountOfTxs {
+				for _, tx := range bundle.Txs {
+					workerMock.On("AddPendingTxToStore", tx.Hash, tx.From).Return().Once()
+					workerMock.On("DeleteTx", tx.Hash, tx.From).Return().Once()
+					workerMock.On("ChargeTxOrdering", tx).Return().Once()
+				}
+				workerMock.On("UpdateAfterSingleSuccessfulTxExecution", senderAddr, tc.expectedResponse.ReadWriteAddresses).Return([]*TxTracker{})
+			}
+
+			err := f.processBundle(ctx, bundle)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCountOfTxs, f.batch.countOfTxs)
+			if tc.expectedCountOfTxs > tc.batchCountOfTxs {
+				require.Len(t, f.pendingTransactionsToStore, len(bundle.Txs))
+				first := <-f.pendingTransactionsToStore
+				second := <-f.pendingTransactionsToStore
+				assert.Equal(t, newHash, first.oldStateRoot)
+				assert.Equal(t, common.Hash{0xa}, second.oldStateRoot)
+				assert.Equal(t, newHash, f.batch.stateRoot)
+				assert.Equal(t, state.MaxEffectivePercentage, bundle.Txs[0].EGPLog.Percentage)
+			}
+			workerMock.AssertExpectations(t)
+			dbManagerMock.AssertExpectations(t)
+		})
+	}
+}
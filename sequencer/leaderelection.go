@@ -0,0 +1,133 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+)
+
+// ErrLeadershipLost is returned when the active sequencer can't keep its leadership lease
+var ErrLeadershipLost = errors.New("sequencer leadership lost")
+
+// leaderElector coordinates the sequencer instances running in active/passive mode through
+// a lease stored in the state DB. Only the instance holding the lease sequences, the standby
+// instances keep trying to acquire it and take over once the lease of the leader expires
+type leaderElector struct {
+	cfg         LeaderElectionCfg
+	instanceID  string
+	state       stateInterface
+	eventLog    *event.EventLog
+	lastRenewal time.Time
+}
+
+// newLeaderElector returns a leaderElector for the provided config
+func newLeaderElector(cfg LeaderElectionCfg, state stateInterface, eventLog *event.EventLog) (*leaderElector, error) {
+	if cfg.LeaseDuration.Duration <= 0 || cfg.RenewInterval.Duration <= 0 {
+		return nil, fmt.Errorf("the sequencer leader election requires a LeaseDuration and a RenewInterval")
+	}
+	if cfg.RenewInterval.Duration*2 > cfg.LeaseDuration.Duration { //nolint:gomnd
+		return nil, fmt.Errorf("the sequencer leader election RenewInterval must be at most half of the LeaseDuration")
+	}
+
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for the sequencer instance id, err: %w", err)
+		}
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return &leaderElector{
+		cfg:        cfg,
+		instanceID: instanceID,
+		state:      state,
+		eventLog:   eventLog,
+	}, nil
+}
+
+// waitForLeadership blocks while another instance holds the lease. In the meantime the
+// standby follows the batches sequenced by the leader, which are read from the shared state
+func (l *leaderElector) waitForLeadership(ctx context.Context) {
+	for {
+		acquired, err := l.state.TryAcquireSequencerLease(ctx, l.instanceID, l.cfg.LeaseDuration.Duration, nil)
+		if err != nil {
+			log.Errorf("sequencer instance %s failed to try to acquire the leadership lease, err: %v", l.instanceID, err)
+		} else if acquired {
+			l.lastRenewal = time.Now()
+			l.logEvent(event.Level_Notice, event.EventID_SequencerLeadershipAcquired, fmt.Sprintf("sequencer instance %s is the active sequencer", l.instanceID))
+			return
+		} else {
+			batchNumber, err := l.state.GetLastBatchNumber(ctx, nil)
+			if err != nil {
+				log.Warnf("standby sequencer instance %s failed to get last batch number, err: %v", l.instanceID, err)
+			} else {
+				log.Infof("standby sequencer instance %s waiting for the leadership lease, last batch number: %d", l.instanceID, batchNumber)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.cfg.RenewInterval.Duration):
+		}
+	}
+}
+
+// keepLeadership renews the lease until the context is done, then the lease is released so a
+// standby can take over without waiting for it to expire. It returns ErrLeadershipLost when the
+// lease is taken by another instance or can't be renewed before it expires
+func (l *leaderElector) keepLeadership(ctx context.Context) error {
+	ticker := time.NewTicker(l.cfg.RenewInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := l.state.ReleaseSequencerLease(context.Background(), l.instanceID, nil); err != nil {
+				log.Errorf("sequencer instance %s failed to release the leadership lease, err: %v", l.instanceID, err)
+			}
+			return nil
+		case <-ticker.C:
+		}
+
+		acquired, err := l.state.TryAcquireSequencerLease(ctx, l.instanceID, l.cfg.LeaseDuration.Duration, nil)
+		if err == nil && acquired {
+			l.lastRenewal = time.Now()
+			continue
+		} else if err == nil {
+			return fmt.Errorf("%w: lease taken by another instance", ErrLeadershipLost)
+		}
+
+		log.Errorf("sequencer instance %s failed to renew the leadership lease, err: %v", l.instanceID, err)
+		// step down before the lease expires, otherwise a standby could take over while this instance keeps sequencing
+		if time.Since(l.lastRenewal)+l.cfg.RenewInterval.Duration >= l.cfg.LeaseDuration.Duration {
+			return fmt.Errorf("%w: failed to renew the lease before it expires, err: %v", ErrLeadershipLost, err)
+		}
+	}
+}
+
+// logEvent stores the leadership change in the event log
+func (l *leaderElector) logEvent(level event.Level, eventID event.EventID, description string) {
+	log.Info(description)
+	if l.eventLog == nil {
+		return
+	}
+
+	event := &event.Event{
+		ReceivedAt:  time.Now(),
+		Source:      event.Source_Node,
+		Component:   event.Component_Sequencer,
+		Level:       level,
+		EventID:     eventID,
+		Description: description,
+	}
+	if err := l.eventLog.LogEvent(context.Background(), event); err != nil {
+		log.Errorf("error storing sequencer leadership event: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var leaderElectionCfg = LeaderElectionCfg{
+	Enabled:       true,
+	InstanceID:    "seq1",
+	LeaseDuration: types.NewDuration(40 * time.Millisecond),
+	RenewInterval: types.NewDuration(10 * time.Millisecond),
+}
+
+func TestNewLeaderElector(t *testing.T) {
+	cfg := leaderElectionCfg
+	cfg.RenewInterval = types.NewDuration(30 * time.Millisecond)
+	_, err := newLeaderElector(cfg, nil, nil)
+	require.Error(t, err)
+
+	cfg = leaderElectionCfg
+	cfg.InstanceID = ""
+	elector, err := newLeaderElector(cfg, nil, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, elector.instanceID)
+}
+
+func TestLeaderElectorWaitForLeadership(t *testing.T) {
+	stateMock := NewStateMock(t)
+	elector, err := newLeaderElector(leaderElectionCfg, stateMock, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	stateMock.On("TryAcquireSequencerLease", ctx, "seq1", leaderElectionCfg.LeaseDuration.Duration, nil).Return(false, nil).Twice()
+	stateMock.On("GetLastBatchNumber", ctx, nil).Return(uint64(10), nil).Twice()
+	stateMock.On("TryAcquireSequencerLease", ctx, "seq1", leaderElectionCfg.LeaseDuration.Duration, nil).Return(true, nil).Once()
+
+	elector.waitForLeadership(ctx)
+	assert.False(t, elector.lastRenewal.IsZero())
+}
+
+func TestLeaderElectorKeepLeadership(t *testing.T) {
+	testCases := []struct {
+		name        string
+		setupMocks  func(stateMock *StateMock, ctx context.Context)
+		cancel      bool
+		expectedErr error
+	}{
+		{
+			name: "lease taken by another instance",
+			setupMocks: func(stateMock *StateMock, ctx context.Context) {
+				stateMock.On("TryAcquireSequencerLease", ctx, "seq1", mock.Anything, nil).Return(true, nil).Once()
+				stateMock.On("TryAcquireSequencerLease", ctx, "seq1", mock.Anything, nil).Return(false, nil).Once()
+			},
+			expectedErr: ErrLeadershipLost,
+		},
+		{
+			name: "lease can't be renewed before it expires",
+			setupMocks: func(stateMock *StateMock, ctx context.Context) {
+				stateMock.On("TryAcquireSequencerLease", ctx, "seq1", mock.Anything, nil).Return(false, errors.New("db down"))
+			},
+			expectedErr: ErrLeadershipLost,
+		},
+		{
+			name: "lease released when the sequencer stops",
+			setupMocks: func(stateMock *StateMock, ctx context.Context) {
+				stateMock.On("TryAcquireSequencerLease", ctx, "seq1", mock.Anything, nil).Return(true, nil).Maybe()
+				stateMock.On("ReleaseSequencerLease", context.Background(), "seq1", nil).Return(nil).Once()
+			},
+			cancel: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stateMock := NewStateMock(t)
+			elector, err := newLeaderElector(leaderElectionCfg, stateMock, nil)
+			require.NoError(t, err)
+			elector.lastRenewal = time.Now()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			tc.setupMocks(stateMock, ctx)
+
+			if tc.cancel {
+				time.AfterFunc(25*time.Millisecond, cancel)
+			}
+
+			err = elector.keepLeadership(ctx)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -28,6 +28,12 @@ const (
 	WorkerPrefix = Prefix + "worker_"
 	// WorkerProcessingTimeName is the name of the metric that shows the worker processing time.
 	WorkerProcessingTimeName = WorkerPrefix + "processing_time"
+	// WorkerThrottledTxsName is the name of the metric that counts the txs deferred because they are over the quotas.
+	WorkerThrottledTxsName = WorkerPrefix + "throttled_txs"
+	// WorkerThrottledSendersName is the name of the metric that shows the senders with txs deferred in the WIP batch.
+	WorkerThrottledSendersName = WorkerPrefix + "throttled_senders"
+	// WorkerQuotaLabelName is the name of the label for the quota that throttles the txs.
+	WorkerQuotaLabelName = "quota"
 	// TxProcessedLabelName is the name of the label for the processed transactions.
 	TxProcessedLabelName = "status"
 )
@@ -73,6 +79,13 @@ func Register() {
 			},
 			Labels: []string{TxProcessedLabelName},
 		},
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: WorkerThrottledTxsName,
+				Help: "[SEQUENCER] number of times a tx has been deferred because it's over the sender or IP quota",
+			},
+			Labels: []string{WorkerQuotaLabelName},
+		},
 	}
 
 	gauges = []prometheus.GaugeOpts{
@@ -88,6 +101,10 @@ func Register() {
 			Name: SequenceRewardInMaticName,
 			Help: "[SEQUENCER] reward for a sequence in Matic",
 		},
+		{
+			Name: WorkerThrottledSendersName,
+			Help: "[SEQUENCER] number of senders with txs deferred by the quotas in the WIP batch",
+		},
 	}
 
 	histograms = []prometheus.HistogramOpts{
@@ -151,3 +168,14 @@ func WorkerProcessingTime(lastProcessTime time.Duration) {
 	execTimeInSeconds := float64(lastProcessTime) / float64(time.Second)
 	metrics.HistogramObserve(WorkerProcessingTimeName, execTimeInSeconds)
 }
+
+// TxThrottled increases the counter vector of the txs deferred by the quotas
+// for the given label (quota).
+func TxThrottled(quota string) {
+	metrics.CounterVecInc(WorkerThrottledTxsName, quota)
+}
+
+// ThrottledSenders sets the gauge for the senders throttled in the WIP batch.
+func ThrottledSenders(count float64) {
+	metrics.GaugeSet(WorkerThrottledSendersName, count)
+}
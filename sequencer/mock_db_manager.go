@@ -14,6 +14,8 @@ import (
 
 	pool "github.com/0xPolygonHermez/zkevm-node/pool"
 
+	preconf "github.com/0xPolygonHermez/zkevm-node/preconf"
+
 	state "github.com/0xPolygonHermez/zkevm-node/state"
 
 	time "time"
@@ -26,6 +28,20 @@ type DbManagerMock struct {
 	mock.Mock
 }
 
+// AddPreconfirmation provides a mock function with given fields: ctx, preconfirmation
+func (_m *DbManagerMock) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation) error {
+	ret := _m.Called(ctx, preconfirmation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *preconf.Preconfirmation) error); ok {
+		r0 = rf(ctx, preconfirmation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BeginStateTransaction provides a mock function with given fields: ctx
 func (_m *DbManagerMock) BeginStateTransaction(ctx context.Context) (pgx.Tx, error) {
 	ret := _m.Called(ctx)
@@ -52,6 +68,30 @@ func (_m *DbManagerMock) BeginStateTransaction(ctx context.Context) (pgx.Tx, err
 	return r0, r1
 }
 
+// ChargeSponsor provides a mock function with given fields: ctx, hash, gasUsed
+func (_m *DbManagerMock) ChargeSponsor(ctx context.Context, hash common.Hash, gasUsed uint64) (bool, error) {
+	ret := _m.Called(ctx, hash, gasUsed)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, uint64) (bool, error)); ok {
+		return rf(ctx, hash, gasUsed)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, uint64) bool); ok {
+		r0 = rf(ctx, hash, gasUsed)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, uint64) error); ok {
+		r1 = rf(ctx, hash, gasUsed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CloseBatch provides a mock function with given fields: ctx, params
 func (_m *DbManagerMock) CloseBatch(ctx context.Context, params ClosingBatchParameters) error {
 	ret := _m.Called(ctx, params)
@@ -702,17 +742,27 @@ func (_m *DbManagerMock) ProcessForcedBatch(ForcedBatchNumber uint64, request st
 }
 
 // StoreProcessedTxAndDeleteFromPool provides a mock function with given fields: ctx, tx
-func (_m *DbManagerMock) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx transactionToStore) error {
+func (_m *DbManagerMock) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx transactionToStore) (uint64, error) {
 	ret := _m.Called(ctx, tx)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, transactionToStore) error); ok {
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, transactionToStore) (uint64, error)); ok {
+		return rf(ctx, tx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, transactionToStore) uint64); ok {
 		r0 = rf(ctx, tx)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(uint64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, transactionToStore) error); ok {
+		r1 = rf(ctx, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // UpdateTxStatus provides a mock function with given fields: ctx, hash, newStatus, isWIP, reason
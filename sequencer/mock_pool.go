@@ -21,6 +21,30 @@ type PoolMock struct {
 	mock.Mock
 }
 
+// ChargeSponsor provides a mock function with given fields: ctx, hash, gasUsed
+func (_m *PoolMock) ChargeSponsor(ctx context.Context, hash common.Hash, gasUsed uint64) (bool, error) {
+	ret := _m.Called(ctx, hash, gasUsed)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, uint64) (bool, error)); ok {
+		return rf(ctx, hash, gasUsed)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, uint64) bool); ok {
+		r0 = rf(ctx, hash, gasUsed)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, uint64) error); ok {
+		r1 = rf(ctx, hash, gasUsed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteFailedTransactionsOlderThan provides a mock function with given fields: ctx, date
 func (_m *PoolMock) DeleteFailedTransactionsOlderThan(ctx context.Context, date time.Time) error {
 	ret := _m.Called(ctx, date)
@@ -125,6 +149,32 @@ func (_m *PoolMock) GetL1AndL2GasPrice() (uint64, uint64) {
 	return r0, r1
 }
 
+// GetNonWIPPendingBundles provides a mock function with given fields: ctx
+func (_m *PoolMock) GetNonWIPPendingBundles(ctx context.Context) ([]pool.Bundle, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []pool.Bundle
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]pool.Bundle, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []pool.Bundle); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pool.Bundle)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNonWIPPendingTxs provides a mock function with given fields: ctx
 func (_m *PoolMock) GetNonWIPPendingTxs(ctx context.Context) ([]pool.Transaction, error) {
 	ret := _m.Called(ctx)
@@ -191,6 +241,58 @@ func (_m *PoolMock) MarkWIPTxsAsPending(ctx context.Context) error {
 	return r0
 }
 
+// SubscribeEvictedTxs provides a mock function with given fields: ctx
+func (_m *PoolMock) SubscribeEvictedTxs(ctx context.Context) (<-chan common.Hash, error) {
+	ret := _m.Called(ctx)
+
+	var r0 <-chan common.Hash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan common.Hash, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan common.Hash); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan common.Hash)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribePendingTxs provides a mock function with given fields: ctx
+func (_m *PoolMock) SubscribePendingTxs(ctx context.Context) (<-chan struct{}, error) {
+	ret := _m.Called(ctx)
+
+	var r0 <-chan struct{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan struct{}, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan struct{}); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateTxStatus provides a mock function with given fields: ctx, hash, newStatus, isWIP, failedReason
 func (_m *PoolMock) UpdateTxStatus(ctx context.Context, hash common.Hash, newStatus pool.TxStatus, isWIP bool, failedReason *string) error {
 	ret := _m.Called(ctx, hash, newStatus, isWIP, failedReason)
@@ -16,6 +16,8 @@ import (
 
 	pgx "github.com/jackc/pgx/v4"
 
+	preconf "github.com/0xPolygonHermez/zkevm-node/preconf"
+
 	state "github.com/0xPolygonHermez/zkevm-node/state"
 
 	time "time"
@@ -28,6 +30,20 @@ type StateMock struct {
 	mock.Mock
 }
 
+// AddPreconfirmation provides a mock function with given fields: ctx, preconfirmation, dbTx
+func (_m *StateMock) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation, dbTx pgx.Tx) error {
+	ret := _m.Called(ctx, preconfirmation, dbTx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *preconf.Preconfirmation, pgx.Tx) error); ok {
+		r0 = rf(ctx, preconfirmation, dbTx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Begin provides a mock function with given fields: ctx
 func (_m *StateMock) Begin(ctx context.Context) (pgx.Tx, error) {
 	ret := _m.Called(ctx)
@@ -952,6 +968,20 @@ func (_m *StateMock) ProcessSequencerBatch(ctx context.Context, batchNumber uint
 	return r0, r1
 }
 
+// ReleaseSequencerLease provides a mock function with given fields: ctx, holder, dbTx
+func (_m *StateMock) ReleaseSequencerLease(ctx context.Context, holder string, dbTx pgx.Tx) error {
+	ret := _m.Called(ctx, holder, dbTx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, pgx.Tx) error); ok {
+		r0 = rf(ctx, holder, dbTx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreTransaction provides a mock function with given fields: ctx, batchNumber, processedTx, coinbase, timestamp, egpLog, dbTx
 func (_m *StateMock) StoreTransaction(ctx context.Context, batchNumber uint64, processedTx *state.ProcessTransactionResponse, coinbase common.Address, timestamp uint64, egpLog *state.EffectiveGasPriceLog, dbTx pgx.Tx) (*types.Header, error) {
 	ret := _m.Called(ctx, batchNumber, processedTx, coinbase, timestamp, egpLog, dbTx)
@@ -978,6 +1008,30 @@ func (_m *StateMock) StoreTransaction(ctx context.Context, batchNumber uint64, p
 	return r0, r1
 }
 
+// TryAcquireSequencerLease provides a mock function with given fields: ctx, holder, duration, dbTx
+func (_m *StateMock) TryAcquireSequencerLease(ctx context.Context, holder string, duration time.Duration, dbTx pgx.Tx) (bool, error) {
+	ret := _m.Called(ctx, holder, duration, dbTx)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, pgx.Tx) (bool, error)); ok {
+		return rf(ctx, holder, duration, dbTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, pgx.Tx) bool); ok {
+		r0 = rf(ctx, holder, duration, dbTx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration, pgx.Tx) error); ok {
+		r1 = rf(ctx, holder, duration, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateBatchL2Data provides a mock function with given fields: ctx, batchNumber, batchL2Data, dbTx
 func (_m *StateMock) UpdateBatchL2Data(ctx context.Context, batchNumber uint64, batchL2Data []byte, dbTx pgx.Tx) error {
 	ret := _m.Called(ctx, batchNumber, batchL2Data, dbTx)
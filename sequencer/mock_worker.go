@@ -20,11 +20,35 @@ type WorkerMock struct {
 	mock.Mock
 }
 
+// AddBundle provides a mock function with given fields: bundle
+func (_m *WorkerMock) AddBundle(bundle *BundleTracker) {
+	_m.Called(bundle)
+}
+
+// AddBundleToQuotas provides a mock function with given fields: bundle, counters
+func (_m *WorkerMock) AddBundleToQuotas(bundle *BundleTracker, counters state.ZKCounters) error {
+	ret := _m.Called(bundle, counters)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*BundleTracker, state.ZKCounters) error); ok {
+		r0 = rf(bundle, counters)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddForcedTx provides a mock function with given fields: txHash, addr
 func (_m *WorkerMock) AddForcedTx(txHash common.Hash, addr common.Address) {
 	_m.Called(txHash, addr)
 }
 
+// AddTxToQuotas provides a mock function with given fields: tx, counters
+func (_m *WorkerMock) AddTxToQuotas(tx *TxTracker, counters state.ZKCounters) {
+	_m.Called(tx, counters)
+}
+
 // AddPendingTxToStore provides a mock function with given fields: txHash, addr
 func (_m *WorkerMock) AddPendingTxToStore(txHash common.Hash, addr common.Address) {
 	_m.Called(txHash, addr)
@@ -56,6 +80,11 @@ func (_m *WorkerMock) AddTxTracker(ctx context.Context, txTracker *TxTracker) (*
 	return r0, r1
 }
 
+// ChargeTxOrdering provides a mock function with given fields: tx
+func (_m *WorkerMock) ChargeTxOrdering(tx *TxTracker) {
+	_m.Called(tx)
+}
+
 // DeleteForcedTx provides a mock function with given fields: txHash, addr
 func (_m *WorkerMock) DeleteForcedTx(txHash common.Hash, addr common.Address) {
 	_m.Called(txHash, addr)
@@ -71,6 +100,32 @@ func (_m *WorkerMock) DeleteTx(txHash common.Hash, from common.Address) {
 	_m.Called(txHash, from)
 }
 
+// DropTx provides a mock function with given fields: txHash
+func (_m *WorkerMock) DropTx(txHash common.Hash) (*TxTracker, error) {
+	ret := _m.Called(txHash)
+
+	var r0 *TxTracker
+	var r1 error
+	if rf, ok := ret.Get(0).(func(common.Hash) (*TxTracker, error)); ok {
+		return rf(txHash)
+	}
+	if rf, ok := ret.Get(0).(func(common.Hash) *TxTracker); ok {
+		r0 = rf(txHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TxTracker)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(common.Hash) error); ok {
+		r1 = rf(txHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBestFittingTx provides a mock function with given fields: resources
 func (_m *WorkerMock) GetBestFittingTx(resources state.BatchResources) *TxTracker {
 	ret := _m.Called(resources)
@@ -134,6 +189,32 @@ func (_m *WorkerMock) NewTxTracker(tx types.Transaction, counters state.ZKCounte
 	return r0, r1
 }
 
+// PopBundle provides a mock function with given fields:
+func (_m *WorkerMock) PopBundle() *BundleTracker {
+	ret := _m.Called()
+
+	var r0 *BundleTracker
+	if rf, ok := ret.Get(0).(func() *BundleTracker); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*BundleTracker)
+		}
+	}
+
+	return r0
+}
+
+// RequeueBundle provides a mock function with given fields: bundle
+func (_m *WorkerMock) RequeueBundle(bundle *BundleTracker) {
+	_m.Called(bundle)
+}
+
+// SlideQuotasWindow provides a mock function with given fields:
+func (_m *WorkerMock) SlideQuotasWindow() {
+	_m.Called()
+}
+
 // UpdateAfterSingleSuccessfulTxExecution provides a mock function with given fields: from, touchedAddresses
 func (_m *WorkerMock) UpdateAfterSingleSuccessfulTxExecution(from common.Address, touchedAddresses map[common.Address]*state.InfoReadWrite) []*TxTracker {
 	ret := _m.Called(from, touchedAddresses)
@@ -0,0 +1,232 @@
+package sequencer
+
+import (
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+)
+
+// quotaKind is the kind of quota that throttles a tx
+type quotaKind string
+
+const (
+	quotaKindSender quotaKind = "sender"
+	quotaKindIP     quotaKind = "ip"
+)
+
+// zkCountersValues contains the ZK counters as uint64 values, so the usage of several
+// batches can be added up without overflowing the uint32 counters
+type zkCountersValues [8]uint64
+
+func newZKCountersValues(counters state.ZKCounters) zkCountersValues {
+	return zkCountersValues{
+		counters.CumulativeGasUsed,
+		uint64(counters.UsedKeccakHashes),
+		uint64(counters.UsedPoseidonHashes),
+		uint64(counters.UsedPoseidonPaddings),
+		uint64(counters.UsedMemAligns),
+		uint64(counters.UsedArithmetics),
+		uint64(counters.UsedBinaries),
+		uint64(counters.UsedSteps),
+	}
+}
+
+func (v *zkCountersValues) add(other zkCountersValues) {
+	for i := range v {
+		v[i] += other[i]
+	}
+}
+
+// quotaUsage is the usage of a sender or an IP in the batches of the window
+type quotaUsage struct {
+	txs        uint64
+	zkCounters zkCountersValues
+}
+
+// quotaBatch is the usage of the senders and IPs in a batch of the window
+type quotaBatch struct {
+	senders map[string]*quotaUsage
+	ips     map[string]*quotaUsage
+}
+
+func newQuotaBatch() *quotaBatch {
+	return &quotaBatch{
+		senders: make(map[string]*quotaUsage),
+		ips:     make(map[string]*quotaUsage),
+	}
+}
+
+// txQuotas limits the txs sequenced per sender and per IP within a sliding window of the last batches,
+// the over-quota txs are deferred until the window moves on
+type txQuotas struct {
+	cfg QuotasCfg
+	// window contains the usage of the last WindowSize batches, the last one is the WIP batch
+	window []*quotaBatch
+	// zkCountersPerSender and zkCountersPerIP are the ZK counters a sender and an IP can use in the window
+	zkCountersPerSender zkCountersValues
+	zkCountersPerIP     zkCountersValues
+	// throttledSenders are the senders with txs deferred in the WIP batch
+	throttledSenders map[string]struct{}
+}
+
+// newTxQuotas creates the tx quotas set in the config, it returns nil if the quotas are disabled
+func newTxQuotas(cfg QuotasCfg, constraints state.BatchConstraintsCfg) (*txQuotas, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.WindowSize == 0 {
+		return nil, fmt.Errorf("invalid WindowSize for quotas: must be greater than 0")
+	}
+	if cfg.MaxZKCountersPercentagePerSender > oneHundred || cfg.MaxZKCountersPercentagePerIP > oneHundred {
+		return nil, fmt.Errorf("invalid MaxZKCountersPercentage for quotas: must not be greater than %d", oneHundred)
+	}
+
+	windowCapacity := newZKCountersValues(state.ZKCounters{
+		CumulativeGasUsed:    constraints.MaxCumulativeGasUsed,
+		UsedKeccakHashes:     constraints.MaxKeccakHashes,
+		UsedPoseidonHashes:   constraints.MaxPoseidonHashes,
+		UsedPoseidonPaddings: constraints.MaxPoseidonPaddings,
+		UsedMemAligns:        constraints.MaxMemAligns,
+		UsedArithmetics:      constraints.MaxArithmetics,
+		UsedBinaries:         constraints.MaxBinaries,
+		UsedSteps:            constraints.MaxSteps,
+	})
+	q := &txQuotas{
+		cfg:              cfg,
+		window:           []*quotaBatch{newQuotaBatch()},
+		throttledSenders: make(map[string]struct{}),
+	}
+	for i, capacity := range windowCapacity {
+		q.zkCountersPerSender[i] = capacity * cfg.WindowSize * uint64(cfg.MaxZKCountersPercentagePerSender) / oneHundred
+		q.zkCountersPerIP[i] = capacity * cfg.WindowSize * uint64(cfg.MaxZKCountersPercentagePerIP) / oneHundred
+	}
+
+	return q, nil
+}
+
+// isOverQuota returns true and the kind of the quota exceeded if sequencing the tx would exceed
+// the quota of its sender or its IP in the window
+func (q *txQuotas) isOverQuota(tx *TxTracker) (bool, quotaKind) {
+	txCounters := newZKCountersValues(tx.BatchResources.ZKCounters)
+
+	senderUsage := q.usage(tx.FromStr, sendersOf)
+	if exceedsQuota(senderUsage, 1, txCounters, q.cfg.MaxTxsPerSender, q.cfg.MaxZKCountersPercentagePerSender, q.zkCountersPerSender) {
+		return true, quotaKindSender
+	}
+
+	if tx.IP == "" {
+		return false, ""
+	}
+	ipUsage := q.usage(tx.IP, ipsOf)
+	if exceedsQuota(ipUsage, 1, txCounters, q.cfg.MaxTxsPerIP, q.cfg.MaxZKCountersPercentagePerIP, q.zkCountersPerIP) {
+		return true, quotaKindIP
+	}
+
+	return false, ""
+}
+
+// addBundle adds the usage of a bundle sequenced in the WIP batch if it doesn't exceed the quotas of its senders
+// and IPs, otherwise nothing is added and it returns true and the kind of the quota exceeded. The txs of a bundle
+// are executed together, so each sender and IP of the bundle is charged with the counters of the whole bundle
+func (q *txQuotas) addBundle(txs []*TxTracker, counters state.ZKCounters) (bool, quotaKind) {
+	senders, ips := map[string]uint64{}, map[string]uint64{}
+	for _, tx := range txs {
+		senders[tx.FromStr]++
+		if tx.IP != "" {
+			ips[tx.IP]++
+		}
+	}
+
+	bundleCounters := newZKCountersValues(counters)
+	for sender, senderTxs := range senders {
+		if exceedsQuota(q.usage(sender, sendersOf), senderTxs, bundleCounters, q.cfg.MaxTxsPerSender, q.cfg.MaxZKCountersPercentagePerSender, q.zkCountersPerSender) {
+			return true, quotaKindSender
+		}
+	}
+	for ip, ipTxs := range ips {
+		if exceedsQuota(q.usage(ip, ipsOf), ipTxs, bundleCounters, q.cfg.MaxTxsPerIP, q.cfg.MaxZKCountersPercentagePerIP, q.zkCountersPerIP) {
+			return true, quotaKindIP
+		}
+	}
+
+	batch := q.window[len(q.window)-1]
+	for sender, senderTxs := range senders {
+		addUsage(batch.senders, sender, senderTxs, bundleCounters)
+	}
+	for ip, ipTxs := range ips {
+		addUsage(batch.ips, ip, ipTxs, bundleCounters)
+	}
+	return false, ""
+}
+
+func sendersOf(b *quotaBatch) map[string]*quotaUsage { return b.senders }
+
+func ipsOf(b *quotaBatch) map[string]*quotaUsage { return b.ips }
+
+// usage returns the usage of the key in the whole window
+func (q *txQuotas) usage(key string, usages func(b *quotaBatch) map[string]*quotaUsage) quotaUsage {
+	total := quotaUsage{}
+	for _, batch := range q.window {
+		if u, found := usages(batch)[key]; found {
+			total.txs += u.txs
+			total.zkCounters.add(u.zkCounters)
+		}
+	}
+	return total
+}
+
+// exceedsQuota checks if adding txs with txCounters to the usage exceeds the max txs or the ZK counters
+// limits, a zero maxTxs or a zero percentage means no limit
+func exceedsQuota(usage quotaUsage, txs uint64, txCounters zkCountersValues, maxTxs uint64, percentage uint32, zkCountersLimits zkCountersValues) bool {
+	if maxTxs > 0 && usage.txs+txs > maxTxs {
+		return true
+	}
+	if percentage > 0 {
+		for i := range zkCountersLimits {
+			if usage.zkCounters[i]+txCounters[i] > zkCountersLimits[i] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// throttle records that the tx has been deferred because it's over the quota
+func (q *txQuotas) throttle(tx *TxTracker, kind quotaKind) {
+	metrics.TxThrottled(string(kind))
+	if _, found := q.throttledSenders[tx.FromStr]; !found {
+		q.throttledSenders[tx.FromStr] = struct{}{}
+		metrics.ThrottledSenders(float64(len(q.throttledSenders)))
+	}
+}
+
+// addTx adds the usage of a tx sequenced in the WIP batch
+func (q *txQuotas) addTx(tx *TxTracker, counters state.ZKCounters) {
+	batch := q.window[len(q.window)-1]
+	txCounters := newZKCountersValues(counters)
+	addUsage(batch.senders, tx.FromStr, 1, txCounters)
+	if tx.IP != "" {
+		addUsage(batch.ips, tx.IP, 1, txCounters)
+	}
+}
+
+func addUsage(usages map[string]*quotaUsage, key string, txs uint64, txCounters zkCountersValues) {
+	u, found := usages[key]
+	if !found {
+		u = &quotaUsage{}
+		usages[key] = u
+	}
+	u.txs += txs
+	u.zkCounters.add(txCounters)
+}
+
+// slideWindow moves the window to a new WIP batch, dropping the usage of the oldest batch when the window is full
+func (q *txQuotas) slideWindow() {
+	q.window = append(q.window, newQuotaBatch())
+	if uint64(len(q.window)) > q.cfg.WindowSize {
+		q.window = q.window[1:]
+	}
+	q.throttledSenders = make(map[string]struct{})
+	metrics.ThrottledSenders(0)
+}
@@ -0,0 +1,197 @@
+package sequencer
+
+import (
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTxQuotas(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         QuotasCfg
+		expectedNil bool
+		expectedErr bool
+	}{
+		{name: "disabled", cfg: QuotasCfg{WindowSize: 0}, expectedNil: true},
+		{name: "enabled", cfg: QuotasCfg{Enabled: true, WindowSize: 10, MaxTxsPerSender: 5, MaxZKCountersPercentagePerIP: 20}},
+		{name: "window size not set", cfg: QuotasCfg{Enabled: true}, expectedErr: true},
+		{name: "sender percentage over 100", cfg: QuotasCfg{Enabled: true, WindowSize: 10, MaxZKCountersPercentagePerSender: 101}, expectedErr: true},
+		{name: "IP percentage over 100", cfg: QuotasCfg{Enabled: true, WindowSize: 10, MaxZKCountersPercentagePerIP: 101}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotas, err := newTxQuotas(tc.cfg, bc)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNil, quotas == nil)
+		})
+	}
+}
+
+func TestTxQuotas(t *testing.T) {
+	constraints := state.BatchConstraintsCfg{MaxCumulativeGasUsed: 1000, MaxKeccakHashes: 10, MaxPoseidonHashes: 10, MaxPoseidonPaddings: 10, MaxMemAligns: 10, MaxArithmetics: 10, MaxBinaries: 10, MaxSteps: 100}
+	newTx := func(from string, ip string, gas uint64) *TxTracker {
+		return &TxTracker{FromStr: from, IP: ip, BatchResources: state.BatchResources{ZKCounters: state.ZKCounters{CumulativeGasUsed: gas}}}
+	}
+
+	testCases := []struct {
+		name         string
+		cfg          QuotasCfg
+		sequenced    [][]*TxTracker
+		tx           *TxTracker
+		expectedOver bool
+		expectedKind quotaKind
+	}{
+		{
+			name:      "sender under the max txs",
+			cfg:       QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 2},
+			sequenced: [][]*TxTracker{{newTx("alice", "", 1)}},
+			tx:        newTx("alice", "", 1),
+		},
+		{
+			name:         "sender over the max txs in the window",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 2},
+			sequenced:    [][]*TxTracker{{newTx("alice", "", 1)}, {newTx("alice", "", 1)}},
+			tx:           newTx("alice", "", 1),
+			expectedOver: true,
+			expectedKind: quotaKindSender,
+		},
+		{
+			name:      "sender usage out of the window",
+			cfg:       QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 2},
+			sequenced: [][]*TxTracker{{newTx("alice", "", 1), newTx("alice", "", 1)}, {}, {}},
+			tx:        newTx("alice", "", 1),
+		},
+		{
+			name:         "IP over the max txs",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerIP: 2},
+			sequenced:    [][]*TxTracker{{newTx("alice", "1.1.1.1", 1), newTx("bob", "1.1.1.1", 1)}},
+			tx:           newTx("carol", "1.1.1.1", 1),
+			expectedOver: true,
+			expectedKind: quotaKindIP,
+		},
+		{
+			name:      "tx without IP",
+			cfg:       QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerIP: 2},
+			sequenced: [][]*TxTracker{{newTx("alice", "", 1), newTx("bob", "", 1)}},
+			tx:        newTx("carol", "", 1),
+		},
+		{
+			name:      "sender under the ZK counters share",
+			cfg:       QuotasCfg{Enabled: true, WindowSize: 2, MaxZKCountersPercentagePerSender: 10},
+			sequenced: [][]*TxTracker{{newTx("alice", "", 150)}},
+			tx:        newTx("alice", "", 50),
+		},
+		{
+			name:         "sender over the ZK counters share",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxZKCountersPercentagePerSender: 10},
+			sequenced:    [][]*TxTracker{{newTx("alice", "", 150)}},
+			tx:           newTx("alice", "", 51),
+			expectedOver: true,
+			expectedKind: quotaKindSender,
+		},
+		{
+			name:         "IP over the ZK counters share",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxZKCountersPercentagePerIP: 10},
+			sequenced:    [][]*TxTracker{{newTx("alice", "1.1.1.1", 100)}, {newTx("bob", "1.1.1.1", 100)}},
+			tx:           newTx("carol", "1.1.1.1", 1),
+			expectedOver: true,
+			expectedKind: quotaKindIP,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotas, err := newTxQuotas(tc.cfg, constraints)
+			require.NoError(t, err)
+			for i, batch := range tc.sequenced {
+				if i > 0 {
+					quotas.slideWindow()
+				}
+				for _, tx := range batch {
+					quotas.addTx(tx, tx.BatchResources.ZKCounters)
+				}
+			}
+
+			over, kind := quotas.isOverQuota(tc.tx)
+			assert.Equal(t, tc.expectedOver, over)
+			assert.Equal(t, tc.expectedKind, kind)
+		})
+	}
+}
+
+func TestTxQuotasBundles(t *testing.T) {
+	constraints := state.BatchConstraintsCfg{MaxCumulativeGasUsed: 1000, MaxKeccakHashes: 10, MaxPoseidonHashes: 10, MaxPoseidonPaddings: 10, MaxMemAligns: 10, MaxArithmetics: 10, MaxBinaries: 10, MaxSteps: 100}
+	newTx := func(from string, ip string) *TxTracker {
+		return &TxTracker{FromStr: from, IP: ip}
+	}
+
+	testCases := []struct {
+		name         string
+		cfg          QuotasCfg
+		sequenced    []*TxTracker
+		bundle       []*TxTracker
+		gas          uint64
+		expectedOver bool
+		expectedKind quotaKind
+	}{
+		{
+			name:   "bundle under the max txs",
+			cfg:    QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 3},
+			bundle: []*TxTracker{newTx("alice", ""), newTx("alice", ""), newTx("bob", "")},
+		},
+		{
+			name:         "bundle over the max txs of a sender",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerSender: 3},
+			sequenced:    []*TxTracker{newTx("alice", "")},
+			bundle:       []*TxTracker{newTx("alice", ""), newTx("alice", ""), newTx("alice", "")},
+			expectedOver: true,
+			expectedKind: quotaKindSender,
+		},
+		{
+			name:         "bundle over the max txs of an IP",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxTxsPerIP: 2},
+			bundle:       []*TxTracker{newTx("alice", "1.1.1.1"), newTx("bob", "1.1.1.1"), newTx("carol", "1.1.1.1")},
+			expectedOver: true,
+			expectedKind: quotaKindIP,
+		},
+		{
+			name:         "bundle over the ZK counters share with the counters it used",
+			cfg:          QuotasCfg{Enabled: true, WindowSize: 2, MaxZKCountersPercentagePerSender: 10},
+			bundle:       []*TxTracker{newTx("alice", ""), newTx("bob", "")},
+			gas:          201,
+			expectedOver: true,
+			expectedKind: quotaKindSender,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotas, err := newTxQuotas(tc.cfg, constraints)
+			require.NoError(t, err)
+			for _, tx := range tc.sequenced {
+				quotas.addTx(tx, tx.BatchResources.ZKCounters)
+			}
+			before := quotas.usage("alice", sendersOf)
+
+			over, kind := quotas.addBundle(tc.bundle, state.ZKCounters{CumulativeGasUsed: tc.gas})
+			assert.Equal(t, tc.expectedOver, over)
+			assert.Equal(t, tc.expectedKind, kind)
+
+			// the bundle is only charged when it's within the quotas
+			after := quotas.usage("alice", sendersOf)
+			if tc.expectedOver {
+				assert.Equal(t, before, after)
+			} else {
+				assert.Equal(t, before.txs+2, after.txs)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/simulation"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// A replay re-feeds the txs of historical batches as a synthetic pool into a simulator, see newSimulator,
+// running against a real or stand-in executor. Each tx is received by the pool at the timestamp of its
+// historical batch and the virtual clock jumps to the next tx when the finalizer has nothing to process.
+// Replaying the same batches with different batch constraints or finalizer settings allows to compare the
+// resulting layouts
+
+// replayStepDuration is the virtual time taken by each iteration of the finalizer loop during a replay,
+// replayStatusPollInterval is the real time waited for the pending tx status updates at the end of the replay
+const (
+	replayStepDuration       = 100 * time.Millisecond
+	replayStatusPollInterval = 10 * time.Millisecond
+)
+
+// ReplayBatch is a historical batch, its txs are received by the pool at the batch timestamp
+type ReplayBatch struct {
+	Timestamp      time.Time
+	GlobalExitRoot common.Hash
+	Txs            []types.Transaction
+}
+
+// ReplayedBatch is a batch closed by the finalizer during a replay
+type ReplayedBatch struct {
+	BatchNumber   uint64
+	Txs           int
+	Resources     state.BatchResources
+	ClosingReason state.ClosingReason
+}
+
+// ResourceUtilization is the average percentage of a batch resource used by the replayed batches
+type ResourceUtilization struct {
+	Name       string
+	Percentage float64
+}
+
+// ReplayResult contains the batches closed during a replay and what happened to the replayed txs
+type ReplayResult struct {
+	Batches []ReplayedBatch
+	// ReceivedTxs is the number of txs fed to the pool
+	ReceivedTxs uint64
+	// SequencedTxs is the number of txs added to a batch
+	SequencedTxs uint64
+	// OOCTxs is the number of txs rejected because they run out of counters
+	OOCTxs uint64
+	// FailedTxs is the number of txs rejected because of any other error
+	FailedTxs uint64
+	// DroppedTxs is the number of txs not accepted by the worker
+	DroppedTxs uint64
+	// PendingTxs is the number of txs left in the worker when the replay ends
+	PendingTxs uint64
+}
+
+// Utilization returns the average percentage of each batch resource used by the replayed batches
+func (r *ReplayResult) Utilization(constraints state.BatchConstraintsCfg) []ResourceUtilization {
+	limits := []struct {
+		name  string
+		max   uint64
+		value func(state.BatchResources) uint64
+	}{
+		{"Bytes", constraints.MaxBatchBytesSize, func(r state.BatchResources) uint64 { return r.Bytes }},
+		{"CumulativeGasUsed", constraints.MaxCumulativeGasUsed, func(r state.BatchResources) uint64 { return r.ZKCounters.CumulativeGasUsed }},
+		{"KeccakHashes", uint64(constraints.MaxKeccakHashes), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedKeccakHashes) }},
+		{"PoseidonHashes", uint64(constraints.MaxPoseidonHashes), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedPoseidonHashes) }},
+		{"PoseidonPaddings", uint64(constraints.MaxPoseidonPaddings), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedPoseidonPaddings) }},
+		{"MemAligns", uint64(constraints.MaxMemAligns), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedMemAligns) }},
+		{"Arithmetics", uint64(constraints.MaxArithmetics), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedArithmetics) }},
+		{"Binaries", uint64(constraints.MaxBinaries), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedBinaries) }},
+		{"Steps", uint64(constraints.MaxSteps), func(r state.BatchResources) uint64 { return uint64(r.ZKCounters.UsedSteps) }},
+	}
+
+	utilization := make([]ResourceUtilization, 0, len(limits))
+	for _, limit := range limits {
+		percentage := float64(0)
+		if limit.max > 0 && len(r.Batches) > 0 {
+			for _, batch := range r.Batches {
+				percentage += float64(limit.value(batch.Resources)) * oneHundred / float64(limit.max)
+			}
+			percentage /= float64(len(r.Batches))
+		}
+		utilization = append(utilization, ResourceUtilization{Name: limit.name, Percentage: percentage})
+	}
+	return utilization
+}
+
+// replayResult returns the closed batches after lastBatch and counts the replayed txs by their final status
+func (d *memoryDBManager) replayResult(receivedTxs, droppedTxs, pendingTxs uint64) *ReplayResult {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	result := &ReplayResult{ReceivedTxs: receivedTxs, DroppedTxs: droppedTxs, PendingTxs: pendingTxs}
+	for _, batch := range d.batches[1:] {
+		if !batch.closed {
+			continue
+		}
+		result.Batches = append(result.Batches, ReplayedBatch{
+			BatchNumber:   batch.BatchNumber,
+			Txs:           len(batch.txs),
+			Resources:     batch.resources,
+			ClosingReason: batch.closingReason,
+		})
+	}
+	for hash, status := range d.txStatus {
+		switch {
+		case status == pool.TxStatusSelected:
+			result.SequencedTxs++
+		case status == pool.TxStatusInvalid:
+			if _, isOOC := d.oocTxs[hash]; isOOC {
+				result.OOCTxs++
+			} else {
+				result.FailedTxs++
+			}
+		case status == pool.TxStatusFailed:
+			result.FailedTxs++
+		}
+	}
+	return result
+}
+
+// Replay feeds the txs of the batches as a synthetic pool into a finalizer with the provided config. The replay
+// starts on top of lastBatch, which must be closed, and ends when all the txs have been received and the finalizer
+// has nothing else to process. The executor may write the resulting state in its hashDB
+func Replay(ctx context.Context, cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, st SimulatedState, lastBatch state.Batch, batches []ReplayBatch) (*ReplayResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if len(batches) == 0 {
+		return &ReplayResult{}, nil
+	}
+
+	// the effective gas price isn't relevant for the batch layout
+	poolCfg.EffectiveGasPrice.Enabled = false
+
+	txOrdering, err := newTxOrderingPolicy(cfg.TxOrdering)
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := newTxQuotas(cfg.Quotas, batchCfg.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	clock := simulation.NewClock(batches[0].Timestamp)
+	sim, err := newSimulator(ctx, cfg.Finalizer, poolCfg, batchCfg.Constraints, txOrdering, quotas, cfg.L2Coinbase, st, lastBatch, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		droppedTxs uint64
+		lastGER    common.Hash
+		next       int
+	)
+	// receive adds to the worker the txs of the batches whose timestamp has been reached,
+	// the txs received at the same time are processed in their historical order
+	receive := func() {
+		for ; next < len(batches) && !batches[next].Timestamp.After(clock.Now()); next++ {
+			batch := batches[next]
+			if batch.GlobalExitRoot != state.ZeroHash && batch.GlobalExitRoot != lastGER {
+				lastGER = batch.GlobalExitRoot
+				sim.finalizer.handleGERSignal(batch.GlobalExitRoot)
+			}
+			for _, tx := range batch.Txs {
+				if err := sim.addTx(ctx, tx, state.ZKCounters{}, ""); err != nil {
+					log.Warnf("replay: tx %s dropped by the worker, reason: %v", tx.Hash().String(), err)
+					droppedTxs++
+				}
+			}
+		}
+	}
+
+	for {
+		receive()
+		executed := sim.step(ctx)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if executed {
+			clock.Advance(replayStepDuration)
+			continue
+		}
+
+		// there is nothing to process in the WIP batch, wait for the next txs or close the WIP batch
+		// by the timestamp resolution deadline if there are no more txs to receive
+		if next < len(batches) {
+			clock.AdvanceTo(batches[next].Timestamp)
+		} else if !sim.finalizer.batch.isEmpty() {
+			clock.Advance(cfg.Finalizer.TimestampResolution.Duration + replayStepDuration)
+		} else {
+			break
+		}
+	}
+	sim.wait()
+	if violations := sim.db.getViolations(); len(violations) > 0 {
+		return nil, fmt.Errorf("replay left the batches in an inconsistent state: %s", strings.Join(violations, "; "))
+	}
+
+	// the status of the txs rejected by the finalizer is updated asynchronously, so wait
+	// until the status of all the txs that are no longer in the worker has been updated
+	receivedTxs := uint64(0)
+	for _, batch := range batches {
+		receivedTxs += uint64(len(batch.Txs))
+	}
+	pendingTxs := sim.worker.countTxs()
+	for sim.db.countTxStatus() < receivedTxs-droppedTxs-pendingTxs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		time.Sleep(replayStatusPollInterval)
+	}
+
+	return sim.db.replayResult(receivedTxs, droppedTxs, pendingTxs), nil
+}
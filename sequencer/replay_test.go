@@ -0,0 +1,94 @@
+package sequencer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/simulation"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	alice := make([]types.Transaction, 6)
+	for nonce := range alice {
+		alice[nonce] = newSimTx(t, "alice", uint64(nonce))
+	}
+	bob := newSimTx(t, "bob", 0)
+	batches := []ReplayBatch{
+		{Timestamp: simStartTime, Txs: alice[0:2]},
+		{Timestamp: simStartTime.Add(time.Second), Txs: alice[2:4]},
+		{Timestamp: simStartTime.Add(2 * time.Second), Txs: append(append([]types.Transaction{}, alice[4:6]...), bob)},
+	}
+	lastBatch := state.Batch{BatchNumber: 10, StateRoot: simGenesisRoot, Timestamp: simStartTime}
+
+	testCases := []struct {
+		name            string
+		maxTxsPerBatch  uint64
+		expectedBatches []ReplayedBatch
+	}{
+		{
+			name:           "all txs in a batch",
+			maxTxsPerBatch: 10,
+			expectedBatches: []ReplayedBatch{
+				{BatchNumber: 11, Txs: 6, ClosingReason: state.TimeoutResolutionDeadlineClosingReason},
+			},
+		},
+		{
+			name:           "batches closed by the max txs",
+			maxTxsPerBatch: 4,
+			expectedBatches: []ReplayedBatch{
+				{BatchNumber: 11, Txs: 4, ClosingReason: state.BatchFullClosingReason},
+				{BatchNumber: 12, Txs: 2, ClosingReason: state.TimeoutResolutionDeadlineClosingReason},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := simulation.NewExecutor(nil, forkId5, simBalance, simDefaultCounters)
+			st.SetTxResult(bob.Hash(), simulation.TxResult{Counters: simDefaultCounters, RomError: executor.RomError_ROM_ERROR_OUT_OF_COUNTERS_STEP})
+			constraints := simBatchConstraints
+			constraints.MaxTxsPerBatch = tc.maxTxsPerBatch
+
+			result, err := Replay(context.Background(), Config{Finalizer: simFinalizerCfg}, state.BatchConfig{Constraints: constraints}, simPoolCfg, st, lastBatch, batches)
+			require.NoError(t, err)
+
+			require.Len(t, result.Batches, len(tc.expectedBatches))
+			for i, expected := range tc.expectedBatches {
+				assert.Equal(t, expected.BatchNumber, result.Batches[i].BatchNumber)
+				assert.Equal(t, expected.Txs, result.Batches[i].Txs)
+				assert.Equal(t, expected.ClosingReason, result.Batches[i].ClosingReason)
+				assert.Equal(t, uint64(expected.Txs)*simDefaultCounters.CumulativeGasUsed, result.Batches[i].Resources.ZKCounters.CumulativeGasUsed)
+			}
+			assert.Equal(t, uint64(7), result.ReceivedTxs)
+			assert.Equal(t, uint64(6), result.SequencedTxs)
+			assert.Equal(t, uint64(1), result.OOCTxs)
+			assert.Zero(t, result.FailedTxs)
+			assert.Zero(t, result.DroppedTxs)
+			assert.Zero(t, result.PendingTxs)
+		})
+	}
+}
+
+func TestReplayResultUtilization(t *testing.T) {
+	result := ReplayResult{Batches: []ReplayedBatch{
+		{Resources: state.BatchResources{Bytes: 500, ZKCounters: state.ZKCounters{CumulativeGasUsed: 100000, UsedSteps: 1000}}},
+		{Resources: state.BatchResources{Bytes: 1500, ZKCounters: state.ZKCounters{CumulativeGasUsed: 300000, UsedSteps: 0}}},
+	}}
+	constraints := state.BatchConstraintsCfg{MaxBatchBytesSize: 2000, MaxCumulativeGasUsed: 1000000, MaxSteps: 1000}
+
+	utilization := result.Utilization(constraints)
+	percentages := make(map[string]float64)
+	for _, u := range utilization {
+		percentages[u.Name] = u.Percentage
+	}
+	assert.InDelta(t, 50, percentages["Bytes"], 0.001)
+	assert.InDelta(t, 20, percentages["CumulativeGasUsed"], 0.001)
+	assert.InDelta(t, 50, percentages["Steps"], 0.001)
+	assert.Zero(t, percentages["KeccakHashes"])
+}
@@ -2,18 +2,21 @@ package sequencer
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
 	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/jsonrpc"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	stateMetrics "github.com/0xPolygonHermez/zkevm-node/state/metrics"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // Sequencer represents a sequencer
@@ -27,7 +30,16 @@ type Sequencer struct {
 	eventLog *event.EventLog
 	etherman etherman
 
-	address common.Address
+	txOrdering txOrderingPolicy
+
+	batchClosing batchClosingStrategy
+
+	quotas *txQuotas
+
+	address    common.Address
+	preconfKey *ecdsa.PrivateKey
+
+	leaderElector *leaderElector
 }
 
 // L2ReorgEvent is the event that is triggered when a reorg happens in the L2
@@ -43,7 +55,7 @@ type ClosingSignalCh struct {
 }
 
 // New init sequencer
-func New(cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, txPool txPool, state stateInterface, etherman etherman, eventLog *event.EventLog) (*Sequencer, error) {
+func New(cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, txPool txPool, state stateInterface, etherman etherman, eventLog *event.EventLog, preconfKey *ecdsa.PrivateKey) (*Sequencer, error) {
 	//addr, err := etherman.TrustedSequencer()
 	//if err != nil {
 	//	return nil, fmt.Errorf("failed to get trusted sequencer address, err: %v", err)
@@ -56,6 +68,37 @@ func New(cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, txPool txP
 
 	log.Infof("Load L2 coinbase %v", addr)
 
+	txOrdering, err := newTxOrderingPolicy(cfg.TxOrdering)
+	if err != nil {
+		return nil, err
+	}
+
+	batchClosing, err := newBatchClosingStrategy(cfg.Finalizer, batchCfg.Constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := newTxQuotas(cfg.Quotas, batchCfg.Constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Admin.Enabled && cfg.Admin.APIKey == "" {
+		return nil, fmt.Errorf("the sequencer admin server requires an APIKey")
+	}
+
+	if cfg.Preconfirmations.Enabled && preconfKey == nil {
+		return nil, fmt.Errorf("the sequencer preconfirmations require a private key")
+	}
+
+	var elector *leaderElector
+	if cfg.LeaderElection.Enabled {
+		elector, err = newLeaderElector(cfg.LeaderElection, state, eventLog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	sequencer := &Sequencer{
 		cfg:      cfg,
 		batchCfg: batchCfg,
@@ -65,6 +108,14 @@ func New(cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, txPool txP
 		etherman: etherman,
 		address:  addr,
 		eventLog: eventLog,
+
+		preconfKey: preconfKey,
+
+		leaderElector: elector,
+
+		txOrdering:   txOrdering,
+		batchClosing: batchClosing,
+		quotas:       quotas,
 	}
 
 	return sequencer, nil
@@ -72,6 +123,11 @@ func New(cfg Config, batchCfg state.BatchConfig, poolCfg pool.Config, txPool txP
 
 // Start starts the sequencer
 func (s *Sequencer) Start(ctx context.Context) {
+	if s.leaderElector != nil {
+		s.leaderElector.waitForLeadership(ctx)
+		go s.keepLeadership(ctx)
+	}
+
 	for !s.isSynced(ctx) {
 		log.Infof("waiting for synchronizer to sync...")
 		time.Sleep(s.cfg.WaitPeriodPoolIsEmpty.Duration)
@@ -89,8 +145,9 @@ func (s *Sequencer) Start(ctx context.Context) {
 		log.Fatalf("failed to mark WIP txs as pending, err: %v", err)
 	}
 
-	worker := NewWorker(s.state, s.batchCfg.Constraints)
+	worker := NewWorker(s.state, s.batchCfg.Constraints, s.txOrdering, s.quotas)
 	dbManager := newDBManager(ctx, s.cfg.DBManager, s.pool, s.state, worker, closingSignalCh, s.batchCfg.Constraints)
+	dbManager.eventLog = s.eventLog
 
 	// Start stream server if enabled
 	if s.cfg.StreamServer.Enabled {
@@ -115,13 +172,21 @@ func (s *Sequencer) Start(ctx context.Context) {
 		streamServer = dbManager.streamServer
 	}
 
-	finalizer := newFinalizer(s.cfg.Finalizer, s.poolCfg, worker, dbManager, s.state, s.address, s.isSynced, closingSignalCh, s.batchCfg.Constraints, s.eventLog, streamServer)
+	finalizer := newFinalizer(s.cfg.Finalizer, s.poolCfg, worker, dbManager, s.state, s.address, s.isSynced, closingSignalCh, s.batchCfg.Constraints, s.batchClosing, time.Now, s.eventLog, streamServer)
+	if s.cfg.Preconfirmations.Enabled {
+		finalizer.preconfKey = s.preconfKey
+		log.Infof("signing preconfirmations with address %s", crypto.PubkeyToAddress(s.preconfKey.PublicKey).String())
+	}
 	currBatch, processingReq := s.bootstrap(ctx, dbManager, finalizer)
 	go finalizer.Start(ctx, currBatch, processingReq)
 
 	closingSignalsManager := newClosingSignalsManager(ctx, finalizer.dbManager, closingSignalCh, finalizer.cfg, s.etherman)
 	go closingSignalsManager.Start()
 
+	if s.cfg.Admin.Enabled {
+		go s.startAdminServer(newAdminEndpoints(s.cfg.Admin.APIKey, finalizer, worker, dbManager, s.eventLog))
+	}
+
 	go s.purgeOldPoolTxs(ctx)
 	tickerProcessTxs := time.NewTicker(s.cfg.WaitPeriodPoolIsEmpty.Duration)
 	defer tickerProcessTxs.Stop()
@@ -146,6 +211,29 @@ func (s *Sequencer) Start(ctx context.Context) {
 	<-ctx.Done()
 }
 
+// keepLeadership renews the leadership lease. The sequencer is restarted when the leadership
+// is lost to discard the in-memory WIP batch, the instance will join again as a standby
+func (s *Sequencer) keepLeadership(ctx context.Context) {
+	err := s.leaderElector.keepLeadership(ctx)
+	if err != nil {
+		s.leaderElector.logEvent(event.Level_Critical, event.EventID_SequencerLeadershipLost, fmt.Sprintf("sequencer instance %s: %v", s.leaderElector.instanceID, err))
+		log.Fatalf("restarting sequencer to discard current WIP batch, err: %v", err)
+	}
+}
+
+// startAdminServer serves the sequencer admin endpoints
+func (s *Sequencer) startAdminServer(admin *AdminEndpoints) {
+	cfg := jsonrpc.Config{
+		Host:                      s.cfg.Admin.Host,
+		Port:                      s.cfg.Admin.Port,
+		MaxRequestsPerIPAndSecond: adminMaxRequestsPerIPAndSecond,
+	}
+	server := jsonrpc.NewServer(cfg, 0, nil, nil, nil, nil, []jsonrpc.Service{{Name: AdminAPIName, Service: admin}})
+	if err := server.Start(); err != nil {
+		log.Errorf("failed to start sequencer admin server, err: %v", err)
+	}
+}
+
 func (s *Sequencer) updateDataStreamerFile(ctx context.Context, streamServer *datastreamer.StreamServer) {
 	err := state.GenerateDataStreamerFile(ctx, streamServer, s.state, true)
 	if err != nil {
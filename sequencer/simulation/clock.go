@@ -0,0 +1,41 @@
+package simulation
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a virtual clock that only moves forward when it's advanced, so the time seen by
+// a simulated sequencer doesn't depend on how long the simulation takes to run
+type Clock struct {
+	mutex   sync.Mutex
+	current time.Time
+}
+
+// NewClock creates a virtual clock set to the start time
+func NewClock(start time.Time) *Clock {
+	return &Clock{current: start}
+}
+
+// Now returns the current virtual time
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.current
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.current = c.current.Add(d)
+}
+
+// AdvanceTo moves the clock to t if it's after the current time
+func (c *Clock) AdvanceTo(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if t.After(c.current) {
+		c.current = t
+	}
+}
@@ -0,0 +1,180 @@
+// Package simulation provides the in-memory stand-ins used to run a sequencer in a deterministic way:
+// a virtual clock and an executor that returns configurable ZK counters and errors. The sequencer
+// package drives its finalizer on top of them to replay whole sequencing sessions
+package simulation
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TxResult is the result returned by the Executor when it executes a tx
+type TxResult struct {
+	Counters      state.ZKCounters
+	RomError      executor.RomError
+	ExecutorError error
+}
+
+// Executor is an in-memory executor. The new state root of a tx is derived from the previous state root
+// and the tx hash, so the executions of the same txs on top of the same state root always agree. The ZK
+// counters and errors of each tx are taken from the configured results, the txs without a result use the
+// default counters. Every address has the same balance and its nonce is tracked per state root
+type Executor struct {
+	mutex           sync.Mutex
+	clock           *Clock
+	executionTime   time.Duration
+	forkID          uint64
+	balance         *big.Int
+	defaultCounters state.ZKCounters
+	results         map[common.Hash]TxResult
+	nonces          map[common.Hash]map[common.Address]uint64
+}
+
+// NewExecutor creates an executor for the fork id whose addresses have the provided balance. If the clock
+// is not nil, it's advanced by the execution time on each execution
+func NewExecutor(clock *Clock, forkID uint64, balance *big.Int, defaultCounters state.ZKCounters) *Executor {
+	return &Executor{
+		clock:           clock,
+		forkID:          forkID,
+		balance:         new(big.Int).Set(balance),
+		defaultCounters: defaultCounters,
+		results:         make(map[common.Hash]TxResult),
+		nonces:          make(map[common.Hash]map[common.Address]uint64),
+	}
+}
+
+// SetExecutionTime sets the virtual time taken by each execution
+func (e *Executor) SetExecutionTime(d time.Duration) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.executionTime = d
+}
+
+// SetTxResult sets the result returned for the tx from now on
+func (e *Executor) SetTxResult(txHash common.Hash, result TxResult) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.results[txHash] = result
+}
+
+// TxResult returns the result returned for the tx
+func (e *Executor) TxResult(txHash common.Hash) TxResult {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.txResult(txHash)
+}
+
+func (e *Executor) txResult(txHash common.Hash) TxResult {
+	result, found := e.results[txHash]
+	if !found {
+		result.Counters = e.defaultCounters
+	}
+	if result.RomError == executor.RomError_ROM_ERROR_UNSPECIFIED {
+		result.RomError = executor.RomError_ROM_ERROR_NO_ERROR
+	}
+	return result
+}
+
+// ProcessBatch executes the txs of the request on top of its old state root
+func (e *Executor) ProcessBatch(ctx context.Context, request state.ProcessRequest, updateMerkleTree bool) (*state.ProcessBatchResponse, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.clock != nil {
+		e.clock.Advance(e.executionTime)
+	}
+
+	txs, _, effectivePercentages, err := state.DecodeTxs(request.Transactions, e.forkID)
+	if err != nil {
+		return nil, err
+	}
+
+	root := request.OldStateRoot
+	nonces := e.nonces[root]
+	if request.GlobalExitRoot != state.ZeroHash {
+		root = crypto.Keccak256Hash(root.Bytes(), request.GlobalExitRoot.Bytes())
+		e.nonces[root] = nonces
+	}
+	response := &state.ProcessBatchResponse{
+		ReadWriteAddresses: make(map[common.Address]*state.InfoReadWrite),
+	}
+	for i, tx := range txs {
+		result := e.txResult(tx.Hash())
+		if result.ExecutorError != nil {
+			response.IsExecutorLevelError = true
+			response.ExecutorError = result.ExecutorError
+			return response, nil
+		}
+		from, err := state.GetSender(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		txResponse := &state.ProcessTransactionResponse{
+			TxHash:              tx.Hash(),
+			Tx:                  tx,
+			GasUsed:             result.Counters.CumulativeGasUsed,
+			EffectivePercentage: uint32(effectivePercentages[i]),
+		}
+		if result.RomError != executor.RomError_ROM_ERROR_NO_ERROR {
+			txResponse.RomError = executor.RomErr(result.RomError)
+		}
+		if state.IsStateRootChanged(result.RomError) {
+			nonce := tx.Nonce() + 1
+			nonces = withNonce(nonces, from, nonce)
+			root = crypto.Keccak256Hash(root.Bytes(), tx.Hash().Bytes())
+			e.nonces[root] = nonces
+			response.ReadWriteAddresses[from] = &state.InfoReadWrite{Address: from, Nonce: &nonce, Balance: new(big.Int).Set(e.balance)}
+			txResponse.ChangesStateRoot = true
+		}
+		if executor.IsROMOutOfCountersError(result.RomError) {
+			response.IsRomOOCError = true
+		}
+		txResponse.StateRoot = root
+		response.UsedZkCounters.SumUp(result.Counters)
+		response.Responses = append(response.Responses, txResponse)
+	}
+	response.NewStateRoot = root
+
+	return response, nil
+}
+
+// withNonce returns a copy of the nonces with the nonce of the address updated, the nonces of
+// a state root are never modified since other state roots may share them
+func withNonce(nonces map[common.Address]uint64, address common.Address, nonce uint64) map[common.Address]uint64 {
+	updated := make(map[common.Address]uint64, len(nonces)+1)
+	for a, n := range nonces {
+		updated[a] = n
+	}
+	updated[address] = nonce
+	return updated
+}
+
+// GetNonceByStateRoot returns the nonce of the address in the state root, the
+// addresses that haven't sent any tx up to the state root have nonce 0
+func (e *Executor) GetNonceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return new(big.Int).SetUint64(e.nonces[root][address]), nil
+}
+
+// GetBalanceByStateRoot returns the balance of the address, it's the same for every address and state root
+func (e *Executor) GetBalanceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	return new(big.Int).Set(e.balance), nil
+}
+
+// GetStoredFlushID returns the flush id of the executor, the state is kept in memory so nothing is ever flushed
+func (e *Executor) GetStoredFlushID(ctx context.Context) (uint64, string, error) {
+	return 0, "", nil
+}
+
+// GetForkIDByBatchNumber returns the fork id of the executor for every batch
+func (e *Executor) GetForkIDByBatchNumber(batchNumber uint64) uint64 {
+	return e.forkID
+}
@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testForkID = 5
+
+func TestExecutorProcessBatch(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+	clock := NewClock(start)
+	counters := state.ZKCounters{CumulativeGasUsed: 21000, UsedSteps: 50}
+	e := NewExecutor(clock, testForkID, big.NewInt(1000), counters)
+	e.SetExecutionTime(time.Second)
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(privateKey.PublicKey)
+	signer := types.NewEIP155Signer(big.NewInt(1001))
+	txs := make([]types.Transaction, 3)
+	for nonce := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(nonce), common.HexToAddress("0x1"), big.NewInt(1), 100000, big.NewInt(1), nil), signer, privateKey)
+		require.NoError(t, err)
+		txs[nonce] = *tx
+	}
+	// the second tx reverts and the third one runs out of counters, only the first two change the state root
+	e.SetTxResult(txs[1].Hash(), TxResult{Counters: counters, RomError: executor.RomError_ROM_ERROR_EXECUTION_REVERTED})
+	e.SetTxResult(txs[2].Hash(), TxResult{Counters: counters, RomError: executor.RomError_ROM_ERROR_OUT_OF_COUNTERS_STEP})
+
+	rawTxs, err := state.EncodeTransactions(txs, []uint8{state.MaxEffectivePercentage, state.MaxEffectivePercentage, state.MaxEffectivePercentage}, testForkID)
+	require.NoError(t, err)
+	oldStateRoot := common.HexToHash("0xabc")
+	request := state.ProcessRequest{OldStateRoot: oldStateRoot, Transactions: rawTxs}
+	response, err := e.ProcessBatch(ctx, request, true)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+
+	require.Len(t, response.Responses, 3)
+	assert.True(t, response.IsRomOOCError)
+	assert.Equal(t, 3*counters.CumulativeGasUsed, response.UsedZkCounters.CumulativeGasUsed)
+	assert.True(t, response.Responses[0].ChangesStateRoot)
+	assert.True(t, response.Responses[1].ChangesStateRoot)
+	assert.False(t, response.Responses[2].ChangesStateRoot)
+	assert.Equal(t, response.Responses[1].StateRoot, response.NewStateRoot)
+
+	// the nonce of the sender is tracked per state root
+	nonce, err := e.GetNonceByStateRoot(ctx, sender, oldStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce.Uint64())
+	nonce, err = e.GetNonceByStateRoot(ctx, sender, response.Responses[0].StateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), nonce.Uint64())
+	nonce, err = e.GetNonceByStateRoot(ctx, sender, response.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce.Uint64())
+
+	// executing the same txs on top of the same state root gives the same result
+	again, err := e.ProcessBatch(ctx, request, true)
+	require.NoError(t, err)
+	assert.Equal(t, response.NewStateRoot, again.NewStateRoot)
+
+	// an executor error fails the whole batch
+	e.SetTxResult(txs[0].Hash(), TxResult{ExecutorError: executor.ExecutorErr(executor.ExecutorError_EXECUTOR_ERROR_DB_ERROR)})
+	response, err = e.ProcessBatch(ctx, request, true)
+	require.NoError(t, err)
+	assert.True(t, response.IsExecutorLevelError)
+	assert.Empty(t, response.Responses)
+}
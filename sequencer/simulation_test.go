@@ -0,0 +1,257 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/simulation"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// The simulation tests replay whole sequencing sessions through a simulator with a simulation.Executor,
+// the pool txs and L1 events (GERs and forced batches) are scripted at virtual times. Each simulation step
+// applies the due events, runs one iteration of the finalizer loop and advances the clock, so the same
+// script always produces the same batch layout
+
+var (
+	simStartTime   = time.Unix(1700000000, 0)
+	simGenesisRoot = crypto.Keccak256Hash([]byte("simulation genesis"))
+	simChainID     = big.NewInt(1001)
+	simReceiver    = common.HexToAddress("0x1234")
+	simBalance     = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+	simGasPrice    = big.NewInt(1000000000)
+
+	simFinalizerCfg = FinalizerCfg{
+		GERDeadlineTimeout:             cfgTypes.NewDuration(5 * time.Second),
+		ForcedBatchDeadlineTimeout:     cfgTypes.NewDuration(3 * time.Second),
+		TimestampResolution:            cfgTypes.NewDuration(10 * time.Second),
+		ResourcePercentageToCloseBatch: 10,
+		SequentialReprocessFullBatch:   true,
+	}
+	simBatchConstraints = state.BatchConstraintsCfg{
+		MaxTxsPerBatch:       10,
+		MaxBatchBytesSize:    100000,
+		MaxCumulativeGasUsed: 1000000,
+		MaxKeccakHashes:      1000,
+		MaxPoseidonHashes:    1000,
+		MaxPoseidonPaddings:  1000,
+		MaxMemAligns:         1000,
+		MaxArithmetics:       1000,
+		MaxBinaries:          1000,
+		MaxSteps:             1000,
+	}
+	simPoolCfg = pool.Config{
+		EffectiveGasPrice: pool.EffectiveGasPriceCfg{
+			Enabled:           false,
+			L1GasPriceFactor:  0.25,
+			ByteGasCost:       16,
+			ZeroByteGasCost:   4,
+			NetProfit:         1.0,
+			BreakEvenFactor:   1.1,
+			FinalDeviationPct: 10,
+		},
+		DefaultMinGasPriceAllowed: 1000000000,
+	}
+	simDefaultCounters = state.ZKCounters{
+		CumulativeGasUsed:    21000,
+		UsedKeccakHashes:     1,
+		UsedPoseidonHashes:   1,
+		UsedPoseidonPaddings: 1,
+		UsedMemAligns:        1,
+		UsedArithmetics:      1,
+		UsedBinaries:         1,
+		UsedSteps:            50,
+	}
+)
+
+// simEvent is a scripted event applied once the virtual clock reaches its time
+type simEvent struct {
+	at    time.Duration
+	apply func()
+}
+
+// simLayoutBatch describes a batch of the layout produced by a simulation,
+// the txs are identified by the labels used to create them in the script
+type simLayoutBatch struct {
+	txs            []string
+	closingReason  state.ClosingReason
+	forced         bool
+	globalExitRoot common.Hash
+}
+
+// simSession replays a script of pool txs and L1 events through a simulator
+type simSession struct {
+	*simulator
+	t             *testing.T
+	ctx           context.Context
+	fakeExecutor  *simulation.Executor
+	stepDuration  time.Duration
+	events        []simEvent
+	labels        map[common.Hash]string
+	ips           map[common.Hash]string
+	forcedBatches uint64
+}
+
+// newSimulation creates a simulation whose finalizer has the WIP batch 1 open at the start time
+func newSimulation(t *testing.T, cfg FinalizerCfg, constraints state.BatchConstraintsCfg) *simSession {
+	return newSimulationWithQuotas(t, cfg, constraints, QuotasCfg{})
+}
+
+// newSimulationWithQuotas creates a simulation whose worker applies the provided sender and IP quotas
+func newSimulationWithQuotas(t *testing.T, cfg FinalizerCfg, constraints state.BatchConstraintsCfg, quotasCfg QuotasCfg) *simSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := simulation.NewClock(simStartTime)
+	fakeExecutor := simulation.NewExecutor(clock, forkId5, simBalance, simDefaultCounters)
+	quotas, err := newTxQuotas(quotasCfg, constraints)
+	require.NoError(t, err)
+	genesis := state.Batch{BatchNumber: 0, StateRoot: simGenesisRoot, Timestamp: simStartTime}
+	sim, err := newSimulator(ctx, cfg, simPoolCfg, constraints, &fifoOrdering{}, quotas, common.Address{}, fakeExecutor, genesis, clock)
+	require.NoError(t, err)
+
+	return &simSession{
+		simulator:    sim,
+		t:            t,
+		ctx:          ctx,
+		fakeExecutor: fakeExecutor,
+		stepDuration: 100 * time.Millisecond,
+		labels:       make(map[common.Hash]string),
+		ips:          make(map[common.Hash]string),
+	}
+}
+
+// newSimTx creates a tx of the sender signed with a key derived from the sender name
+func newSimTx(t *testing.T, sender string, nonce uint64) types.Transaction {
+	privateKey, err := crypto.ToECDSA(crypto.Keccak256([]byte(sender)))
+	require.NoError(t, err)
+	tx := types.NewTransaction(nonce, simReceiver, big.NewInt(1), 100000, simGasPrice, nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(simChainID), privateKey)
+	require.NoError(t, err)
+	return *signedTx
+}
+
+// tx creates a signed tx of the sender identified with the label in the resulting layout
+func (s *simSession) tx(label string, sender string, nonce uint64) types.Transaction {
+	tx := newSimTx(s.t, sender, nonce)
+	s.labels[tx.Hash()] = label
+	return tx
+}
+
+// setTxIP sets the IP the tx is received from
+func (s *simSession) setTxIP(tx types.Transaction, ip string) {
+	s.ips[tx.Hash()] = ip
+}
+
+// setTxResult sets the result returned by the executor for the tx
+func (s *simSession) setTxResult(tx types.Transaction, result simulation.TxResult) {
+	s.fakeExecutor.SetTxResult(tx.Hash(), result)
+}
+
+// addTxAt adds the tx to the pool at the given time, the pool estimates the ZK counters of
+// the tx with the ones returned by the executor when the tx is added
+func (s *simSession) addTxAt(at time.Duration, tx types.Transaction) {
+	s.addTxWithCountersAt(at, tx, s.fakeExecutor.TxResult(tx.Hash()).Counters)
+}
+
+// addTxWithCountersAt adds the tx to the pool at the given time with the provided ZK counters estimation,
+// the txs scripted at the same time are received in the order of the script
+func (s *simSession) addTxWithCountersAt(at time.Duration, tx types.Transaction, counters state.ZKCounters) {
+	s.events = append(s.events, simEvent{at: at, apply: func() {
+		require.NoError(s.t, s.addTx(s.ctx, tx, counters, s.ips[tx.Hash()]))
+	}})
+}
+
+// addGERAt sends the GER closing signal at the given time
+func (s *simSession) addGERAt(at time.Duration, ger common.Hash) {
+	s.events = append(s.events, simEvent{at: at, apply: func() {
+		s.finalizer.handleGERSignal(ger)
+	}})
+}
+
+// addForcedBatchAt sends the closing signal of a new forced batch with the provided txs at the given time
+func (s *simSession) addForcedBatchAt(at time.Duration, txs ...types.Transaction) {
+	s.forcedBatches++
+	effectivePercentages := make([]uint8, len(txs))
+	for i := range effectivePercentages {
+		effectivePercentages[i] = state.MaxEffectivePercentage
+	}
+	rawTxs, err := state.EncodeTransactions(txs, effectivePercentages, forkId5)
+	require.NoError(s.t, err)
+	forcedBatch := state.ForcedBatch{
+		ForcedBatchNumber: s.forcedBatches,
+		GlobalExitRoot:    state.ZeroHash,
+		RawTxsData:        rawTxs,
+		ForcedAt:          simStartTime.Add(at),
+	}
+	s.events = append(s.events, simEvent{at: at, apply: func() {
+		s.addForcedBatch(forcedBatch)
+	}})
+}
+
+// applyEvents applies the events whose time has been reached in the order of the script
+func (s *simSession) applyEvents() {
+	elapsed := s.clock.Now().Sub(simStartTime)
+	sort.SliceStable(s.events, func(i, j int) bool { return s.events[i].at < s.events[j].at })
+	applied := 0
+	for _, e := range s.events {
+		if e.at > elapsed {
+			break
+		}
+		e.apply()
+		applied++
+	}
+	s.events = s.events[applied:]
+}
+
+// run replays the script until the virtual clock has advanced the given duration
+func (s *simSession) run(d time.Duration) {
+	end := s.clock.Now().Add(d)
+	for s.clock.Now().Before(end) {
+		s.applyEvents()
+		s.step(s.ctx)
+		s.clock.Advance(s.stepDuration)
+	}
+	s.wait()
+}
+
+// layout returns the batches sequenced after the genesis, including the WIP batch
+func (s *simSession) layout() []simLayoutBatch {
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	layout := make([]simLayoutBatch, 0, len(s.db.batches)-1)
+	for _, batch := range s.db.batches[1:] {
+		txs := make([]string, 0, len(batch.txs))
+		for _, tx := range batch.txs {
+			txs = append(txs, s.labels[tx.Hash()])
+		}
+		layout = append(layout, simLayoutBatch{
+			txs:            txs,
+			closingReason:  batch.closingReason,
+			forced:         batch.forced,
+			globalExitRoot: batch.GlobalExitRoot,
+		})
+	}
+	return layout
+}
+
+// requireLayout checks the resulting batch layout and that the state was never left inconsistent
+func (s *simSession) requireLayout(expected ...simLayoutBatch) {
+	require.Empty(s.t, s.db.getViolations())
+	require.Equal(s.t, expected, s.layout())
+}
+
+// txStatus returns the status set in the pool for the tx
+func (s *simSession) txStatus(tx types.Transaction) pool.TxStatus {
+	return s.db.txStatusOf(tx.Hash())
+}
@@ -0,0 +1,697 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	cfgTypes "github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/event"
+	"github.com/0xPolygonHermez/zkevm-node/event/nileventstorage"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer/simulation"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	stateMetrics "github.com/0xPolygonHermez/zkevm-node/state/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+)
+
+// A simulator runs the real finalizer and worker of the package on top of an in-memory state DB. The batches
+// sequenced by the finalizer are kept in memory, so the state DB is never modified, and the time is driven by
+// a virtual clock. The txs are executed by a SimulatedState, which can be the real state or a stand-in executor
+// such as simulation.Executor. The finalizer loop is run one step at a time by the caller, so the same txs and
+// L1 events at the same virtual times always produce the same batch layout for a deterministic executor.
+// It's used by the batch replays and by the simulation tests of the finalizer
+
+// errNotSimulated is returned by the calls that the finalizer and the worker don't do while sequencing
+// pool txs, since the simulator only keeps in memory what is needed to sequence them
+var errNotSimulated = errors.New("not supported by the simulator")
+
+func notSimulated(method string) error {
+	return fmt.Errorf("%s: %w", method, errNotSimulated)
+}
+
+// SimulatedState is the state used by a simulator to execute the txs and to get the nonces and balances of the senders
+type SimulatedState interface {
+	ProcessBatch(ctx context.Context, request state.ProcessRequest, updateMerkleTree bool) (*state.ProcessBatchResponse, error)
+	GetNonceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error)
+	GetBalanceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error)
+	GetStoredFlushID(ctx context.Context) (uint64, string, error)
+	GetForkIDByBatchNumber(batchNumber uint64) uint64
+}
+
+// simulator drives a finalizer on top of a memoryDBManager with a virtual clock
+type simulator struct {
+	clock             *simulation.Clock
+	executor          *memoryExecutor
+	db                *memoryDBManager
+	worker            *Worker
+	finalizer         *finalizer
+	receivedTxs       uint64
+	showNotFoundTxLog bool
+}
+
+// newSimulator creates a simulator whose finalizer opens its first WIP batch on top of lastBatch, which must be closed
+func newSimulator(ctx context.Context, cfg FinalizerCfg, poolCfg pool.Config, constraints state.BatchConstraintsCfg, txOrdering txOrderingPolicy,
+	quotas *txQuotas, coinbase common.Address, st SimulatedState, lastBatch state.Batch, clock *simulation.Clock) (*simulator, error) {
+	// the finalizer loop must never sleep, the time only moves through the virtual clock, and
+	// the batches are reprocessed in the loop so the result doesn't depend on the goroutines
+	cfg.SleepDuration = cfgTypes.NewDuration(0)
+	cfg.SequentialReprocessFullBatch = true
+
+	batchClosing, err := newBatchClosingStrategy(cfg, constraints)
+	if err != nil {
+		return nil, err
+	}
+	eventStorage, err := nileventstorage.NewNilEventStorage()
+	if err != nil {
+		return nil, err
+	}
+	eventLog := event.NewEventLog(event.Config{}, eventStorage)
+
+	memoryExecutor := &memoryExecutor{state: st, stateRoot: lastBatch.StateRoot}
+	db := newMemoryDBManager(memoryExecutor, lastBatch)
+	worker := NewWorker(memoryExecutor, constraints, txOrdering, quotas)
+	alwaysSynced := func(ctx context.Context) bool { return true }
+	f := newFinalizer(cfg, poolCfg, worker, db, memoryExecutor, coinbase, alwaysSynced, ClosingSignalCh{}, constraints, batchClosing, clock.Now, eventLog, nil)
+	if err := f.syncWithState(ctx, nil); err != nil {
+		return nil, err
+	}
+	go f.updateProverIdAndFlushId(ctx)
+	go f.storePendingTransactions(ctx)
+
+	return &simulator{
+		clock:             clock,
+		executor:          memoryExecutor,
+		db:                db,
+		worker:            worker,
+		finalizer:         f,
+		showNotFoundTxLog: true,
+	}, nil
+}
+
+// addTx adds the tx to the worker with the provided ZK counters estimation, the txs
+// added at the same virtual time are received in the order they are added
+func (s *simulator) addTx(ctx context.Context, tx types.Transaction, counters state.ZKCounters, ip string) error {
+	txTracker, err := s.worker.NewTxTracker(tx, counters, ip)
+	if err != nil {
+		return err
+	}
+	s.receivedTxs++
+	txTracker.ReceivedAt = s.clock.Now().Add(time.Duration(s.receivedTxs))
+	_, dropReason := s.worker.AddTxTracker(ctx, txTracker)
+	return dropReason
+}
+
+// addForcedBatch adds the forced batch to the state and sends its closing signal to the finalizer
+func (s *simulator) addForcedBatch(forcedBatch state.ForcedBatch) {
+	s.db.addForcedBatch(forcedBatch)
+	s.finalizer.handleForcedBatchSignal(forcedBatch)
+}
+
+// step runs an iteration of the finalizer loop, it returns true if the finalizer executed anything
+func (s *simulator) step(ctx context.Context) bool {
+	executions := s.executor.executions()
+	s.finalizer.finalizeBatchesStep(ctx, &s.showNotFoundTxLog)
+	return s.executor.executions() != executions
+}
+
+// wait waits until the txs sequenced by the finalizer have been stored
+func (s *simulator) wait() {
+	s.finalizer.pendingTransactionsToStoreWG.Wait()
+}
+
+// memoryExecutor executes the txs on top of the state root of the last tx stored in the memoryDBManager
+type memoryExecutor struct {
+	state     SimulatedState
+	mutex     sync.Mutex
+	stateRoot common.Hash
+	calls     uint64
+}
+
+func (e *memoryExecutor) executions() uint64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.calls
+}
+
+func (e *memoryExecutor) setStateRoot(stateRoot common.Hash) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stateRoot = stateRoot
+}
+
+func (e *memoryExecutor) ProcessBatch(ctx context.Context, request state.ProcessRequest, updateMerkleTree bool) (*state.ProcessBatchResponse, error) {
+	e.mutex.Lock()
+	e.calls++
+	e.mutex.Unlock()
+	return e.state.ProcessBatch(ctx, request, updateMerkleTree)
+}
+
+func (e *memoryExecutor) GetLastStateRoot(ctx context.Context, dbTx pgx.Tx) (common.Hash, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.stateRoot, nil
+}
+
+func (e *memoryExecutor) GetNonceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	return e.state.GetNonceByStateRoot(ctx, address, root)
+}
+
+func (e *memoryExecutor) GetBalanceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	return e.state.GetBalanceByStateRoot(ctx, address, root)
+}
+
+func (e *memoryExecutor) GetStoredFlushID(ctx context.Context) (uint64, string, error) {
+	return e.state.GetStoredFlushID(ctx)
+}
+
+func (e *memoryExecutor) GetForkIDByBatchNumber(batchNumber uint64) uint64 {
+	return e.state.GetForkIDByBatchNumber(batchNumber)
+}
+
+// The calls below aren't done by the finalizer or the worker while sequencing pool txs
+
+func (e *memoryExecutor) GetTimeForLatestBatchVirtualization(ctx context.Context, dbTx pgx.Tx) (time.Time, error) {
+	return time.Time{}, notSimulated("GetTimeForLatestBatchVirtualization")
+}
+
+func (e *memoryExecutor) GetTxsOlderThanNL1Blocks(ctx context.Context, nL1Blocks uint64, dbTx pgx.Tx) ([]common.Hash, error) {
+	return nil, notSimulated("GetTxsOlderThanNL1Blocks")
+}
+
+func (e *memoryExecutor) GetBatchByNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, error) {
+	return nil, notSimulated("GetBatchByNumber")
+}
+
+func (e *memoryExecutor) GetTransactionsByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (txs []types.Transaction, effectivePercentages []uint8, err error) {
+	return nil, nil, notSimulated("GetTransactionsByBatchNumber")
+}
+
+func (e *memoryExecutor) BeginStateTransaction(ctx context.Context) (pgx.Tx, error) {
+	return nil, notSimulated("BeginStateTransaction")
+}
+
+func (e *memoryExecutor) GetLastVirtualBatchNum(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	return 0, notSimulated("GetLastVirtualBatchNum")
+}
+
+func (e *memoryExecutor) IsBatchClosed(ctx context.Context, batchNum uint64, dbTx pgx.Tx) (bool, error) {
+	return false, notSimulated("IsBatchClosed")
+}
+
+func (e *memoryExecutor) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, notSimulated("Begin")
+}
+
+func (e *memoryExecutor) CloseBatch(ctx context.Context, receipt state.ProcessingReceipt, dbTx pgx.Tx) error {
+	return notSimulated("CloseBatch")
+}
+
+func (e *memoryExecutor) ExecuteBatch(ctx context.Context, batch state.Batch, updateMerkleTree bool, dbTx pgx.Tx) (*executor.ProcessBatchResponse, error) {
+	return nil, notSimulated("ExecuteBatch")
+}
+
+func (e *memoryExecutor) GetForcedBatch(ctx context.Context, forcedBatchNumber uint64, dbTx pgx.Tx) (*state.ForcedBatch, error) {
+	return nil, notSimulated("GetForcedBatch")
+}
+
+func (e *memoryExecutor) GetLastBatch(ctx context.Context, dbTx pgx.Tx) (*state.Batch, error) {
+	return nil, notSimulated("GetLastBatch")
+}
+
+func (e *memoryExecutor) GetLastBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	return 0, notSimulated("GetLastBatchNumber")
+}
+
+func (e *memoryExecutor) OpenBatch(ctx context.Context, processingContext state.ProcessingContext, dbTx pgx.Tx) error {
+	return notSimulated("OpenBatch")
+}
+
+func (e *memoryExecutor) GetLastNBatches(ctx context.Context, numBatches uint, dbTx pgx.Tx) ([]*state.Batch, error) {
+	return nil, notSimulated("GetLastNBatches")
+}
+
+func (e *memoryExecutor) StoreTransaction(ctx context.Context, batchNumber uint64, processedTx *state.ProcessTransactionResponse, coinbase common.Address, timestamp uint64, egpLog *state.EffectiveGasPriceLog, dbTx pgx.Tx) (*types.Header, error) {
+	return nil, notSimulated("StoreTransaction")
+}
+
+func (e *memoryExecutor) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation, dbTx pgx.Tx) error {
+	return notSimulated("AddPreconfirmation")
+}
+
+func (e *memoryExecutor) TryAcquireSequencerLease(ctx context.Context, holder string, duration time.Duration, dbTx pgx.Tx) (bool, error) {
+	return false, notSimulated("TryAcquireSequencerLease")
+}
+
+func (e *memoryExecutor) ReleaseSequencerLease(ctx context.Context, holder string, dbTx pgx.Tx) error {
+	return notSimulated("ReleaseSequencerLease")
+}
+
+func (e *memoryExecutor) GetLastClosedBatch(ctx context.Context, dbTx pgx.Tx) (*state.Batch, error) {
+	return nil, notSimulated("GetLastClosedBatch")
+}
+
+func (e *memoryExecutor) GetLastL2Block(ctx context.Context, dbTx pgx.Tx) (*types.Block, error) {
+	return nil, notSimulated("GetLastL2Block")
+}
+
+func (e *memoryExecutor) GetLastBlock(ctx context.Context, dbTx pgx.Tx) (*state.Block, error) {
+	return nil, notSimulated("GetLastBlock")
+}
+
+func (e *memoryExecutor) GetLatestGlobalExitRoot(ctx context.Context, maxBlockNumber uint64, dbTx pgx.Tx) (state.GlobalExitRoot, time.Time, error) {
+	return state.GlobalExitRoot{}, time.Time{}, notSimulated("GetLatestGlobalExitRoot")
+}
+
+func (e *memoryExecutor) GetLastL2BlockHeader(ctx context.Context, dbTx pgx.Tx) (*types.Header, error) {
+	return nil, notSimulated("GetLastL2BlockHeader")
+}
+
+func (e *memoryExecutor) UpdateBatchL2Data(ctx context.Context, batchNumber uint64, batchL2Data []byte, dbTx pgx.Tx) error {
+	return notSimulated("UpdateBatchL2Data")
+}
+
+func (e *memoryExecutor) ProcessSequencerBatch(ctx context.Context, batchNumber uint64, batchL2Data []byte, caller stateMetrics.CallerLabel, dbTx pgx.Tx) (*state.ProcessBatchResponse, error) {
+	return nil, notSimulated("ProcessSequencerBatch")
+}
+
+func (e *memoryExecutor) GetForcedBatchesSince(ctx context.Context, forcedBatchNumber, maxBlockNumber uint64, dbTx pgx.Tx) ([]*state.ForcedBatch, error) {
+	return nil, notSimulated("GetForcedBatchesSince")
+}
+
+func (e *memoryExecutor) GetLastTrustedForcedBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	return 0, notSimulated("GetLastTrustedForcedBatchNumber")
+}
+
+func (e *memoryExecutor) GetLatestVirtualBatchTimestamp(ctx context.Context, dbTx pgx.Tx) (time.Time, error) {
+	return time.Time{}, notSimulated("GetLatestVirtualBatchTimestamp")
+}
+
+func (e *memoryExecutor) CountReorgs(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	return 0, notSimulated("CountReorgs")
+}
+
+func (e *memoryExecutor) GetLatestGer(ctx context.Context, maxBlockNumber uint64) (state.GlobalExitRoot, time.Time, error) {
+	return state.GlobalExitRoot{}, time.Time{}, notSimulated("GetLatestGer")
+}
+
+func (e *memoryExecutor) FlushMerkleTree(ctx context.Context) error {
+	return notSimulated("FlushMerkleTree")
+}
+
+func (e *memoryExecutor) GetDSGenesisBlock(ctx context.Context, dbTx pgx.Tx) (*state.DSL2Block, error) {
+	return nil, notSimulated("GetDSGenesisBlock")
+}
+
+func (e *memoryExecutor) GetDSBatches(ctx context.Context, firstBatchNumber, lastBatchNumber uint64, readWIPBatch bool, dbTx pgx.Tx) ([]*state.DSBatch, error) {
+	return nil, notSimulated("GetDSBatches")
+}
+
+func (e *memoryExecutor) GetDSL2Blocks(ctx context.Context, firstBatchNumber, lastBatchNumber uint64, dbTx pgx.Tx) ([]*state.DSL2Block, error) {
+	return nil, notSimulated("GetDSL2Blocks")
+}
+
+func (e *memoryExecutor) GetDSL2Transactions(ctx context.Context, firstL2Block, lastL2Block uint64, dbTx pgx.Tx) ([]*state.DSL2Transaction, error) {
+	return nil, notSimulated("GetDSL2Transactions")
+}
+
+// memoryDBTx is the db tx used to open the batches, there is nothing to commit since they are kept in memory
+type memoryDBTx struct {
+	pgx.Tx
+}
+
+func (tx memoryDBTx) Commit(ctx context.Context) error   { return nil }
+func (tx memoryDBTx) Rollback(ctx context.Context) error { return nil }
+
+// memoryBatch is a batch stored by the memoryDBManager
+type memoryBatch struct {
+	state.Batch
+	txs                  []types.Transaction
+	effectivePercentages []uint8
+	forced               bool
+	closed               bool
+	resources            state.BatchResources
+	closingReason        state.ClosingReason
+}
+
+// memoryDBManager keeps in memory the batches sequenced by the finalizer, the forced batches and the status
+// of the txs. Instead of failing, the calls that would leave the state inconsistent are recorded as violations,
+// since the finalizer halts on the state errors. Only the calls done by the finalizer while sequencing pool
+// txs are implemented
+type memoryDBManager struct {
+	mutex                  sync.Mutex
+	executor               *memoryExecutor
+	batches                []*memoryBatch
+	forcedBatches          map[uint64]*state.ForcedBatch
+	lastTrustedForcedBatch uint64
+	txStatus               map[common.Hash]pool.TxStatus
+	oocTxs                 map[common.Hash]struct{}
+	oocReasons             map[string]struct{}
+	// lastL2BlockNumber counts the l2 blocks of the stored txs, one per tx
+	lastL2BlockNumber uint64
+	violations        []string
+}
+
+func newMemoryDBManager(memoryExecutor *memoryExecutor, lastBatch state.Batch) *memoryDBManager {
+	oocReasons := make(map[string]struct{})
+	for code := range executor.RomError_name {
+		if executor.IsROMOutOfCountersError(executor.RomError(code)) {
+			oocReasons[executor.RomErr(executor.RomError(code)).Error()] = struct{}{}
+		}
+	}
+	return &memoryDBManager{
+		executor:      memoryExecutor,
+		batches:       []*memoryBatch{{Batch: lastBatch, closed: true}},
+		forcedBatches: make(map[uint64]*state.ForcedBatch),
+		txStatus:      make(map[common.Hash]pool.TxStatus),
+		oocTxs:        make(map[common.Hash]struct{}),
+		oocReasons:    oocReasons,
+	}
+}
+
+func (d *memoryDBManager) violation(format string, args ...interface{}) {
+	d.violations = append(d.violations, fmt.Sprintf(format, args...))
+}
+
+// getViolations returns the calls that would have left the state inconsistent
+func (d *memoryDBManager) getViolations() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]string{}, d.violations...)
+}
+
+func (d *memoryDBManager) batch(batchNumber uint64) *memoryBatch {
+	first := d.batches[0].BatchNumber
+	if batchNumber < first || batchNumber-first >= uint64(len(d.batches)) {
+		return nil
+	}
+	return d.batches[batchNumber-first]
+}
+
+func (d *memoryDBManager) toStateBatch(batch *memoryBatch) (*state.Batch, error) {
+	stateBatch := batch.Batch
+	if len(batch.txs) > 0 {
+		batchL2Data, err := state.EncodeTransactions(batch.txs, batch.effectivePercentages, d.executor.GetForkIDByBatchNumber(batch.BatchNumber))
+		if err != nil {
+			return nil, err
+		}
+		stateBatch.BatchL2Data = batchL2Data
+	}
+	return &stateBatch, nil
+}
+
+// addForcedBatch adds a forced batch received from L1
+func (d *memoryDBManager) addForcedBatch(forcedBatch state.ForcedBatch) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.forcedBatches[forcedBatch.ForcedBatchNumber] = &forcedBatch
+}
+
+func (d *memoryDBManager) BeginStateTransaction(ctx context.Context) (pgx.Tx, error) {
+	return memoryDBTx{}, nil
+}
+
+func (d *memoryDBManager) OpenBatch(ctx context.Context, processingContext state.ProcessingContext, dbTx pgx.Tx) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	last := d.batches[len(d.batches)-1]
+	if !last.closed {
+		d.violation("batch %d opened while batch %d is still open", processingContext.BatchNumber, last.BatchNumber)
+	}
+	if processingContext.BatchNumber != last.BatchNumber+1 {
+		d.violation("batch %d opened after batch %d", processingContext.BatchNumber, last.BatchNumber)
+	}
+	d.batches = append(d.batches, &memoryBatch{
+		Batch: state.Batch{
+			BatchNumber:    processingContext.BatchNumber,
+			Coinbase:       processingContext.Coinbase,
+			GlobalExitRoot: processingContext.GlobalExitRoot,
+			Timestamp:      processingContext.Timestamp,
+			StateRoot:      last.StateRoot,
+		},
+	})
+	return nil
+}
+
+func (d *memoryDBManager) CloseBatch(ctx context.Context, params ClosingBatchParameters) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	batch := d.batch(params.BatchNumber)
+	if batch == nil || batch.closed {
+		d.violation("batch %d closed but it's not open", params.BatchNumber)
+		return nil
+	}
+	if len(params.Txs) != len(batch.txs) {
+		d.violation("batch %d closed with %d txs but it has %d txs stored", params.BatchNumber, len(params.Txs), len(batch.txs))
+	}
+	batch.closed = true
+	batch.StateRoot = params.StateRoot
+	batch.LocalExitRoot = params.LocalExitRoot
+	batch.resources = params.BatchResources
+	batch.closingReason = params.ClosingReason
+	return nil
+}
+
+func (d *memoryDBManager) StoreProcessedTxAndDeleteFromPool(ctx context.Context, tx transactionToStore) (uint64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	batch := d.batch(tx.batchNumber)
+	if batch == nil || (batch.closed && !tx.isForcedBatch) {
+		d.violation("tx %s stored in batch %d that is not open", tx.hash, tx.batchNumber)
+		return 0, nil
+	}
+	if !tx.isForcedBatch && tx.position != uint64(len(batch.txs)) {
+		d.violation("tx %s stored in position %d of batch %d that has %d txs", tx.hash, tx.position, tx.batchNumber, len(batch.txs))
+	}
+	batch.txs = append(batch.txs, tx.response.Tx)
+	batch.effectivePercentages = append(batch.effectivePercentages, uint8(tx.response.EffectivePercentage))
+	if !tx.isForcedBatch {
+		batch.StateRoot = tx.response.StateRoot
+		d.txStatus[tx.hash] = pool.TxStatusSelected
+	}
+	d.executor.setStateRoot(tx.response.StateRoot)
+	d.lastL2BlockNumber++
+	return d.lastL2BlockNumber, nil
+}
+
+func (d *memoryDBManager) ProcessForcedBatch(forcedBatchNumber uint64, request state.ProcessRequest) (*state.ProcessBatchResponse, error) {
+	d.mutex.Lock()
+	last := d.batches[len(d.batches)-1]
+	if !last.closed {
+		d.violation("forced batch %d processed while batch %d is still open", forcedBatchNumber, last.BatchNumber)
+	}
+	if forcedBatchNumber != d.lastTrustedForcedBatch+1 {
+		d.violation("forced batch %d processed after forced batch %d", forcedBatchNumber, d.lastTrustedForcedBatch)
+	}
+	batch := &memoryBatch{
+		Batch: state.Batch{
+			BatchNumber:    request.BatchNumber,
+			Coinbase:       request.Coinbase,
+			GlobalExitRoot: request.GlobalExitRoot,
+			Timestamp:      request.Timestamp,
+		},
+		forced:        true,
+		closed:        true,
+		closingReason: state.ForcedBatchClosingReason,
+	}
+	d.batches = append(d.batches, batch)
+	d.lastTrustedForcedBatch = forcedBatchNumber
+	d.mutex.Unlock()
+
+	response, err := d.executor.ProcessBatch(context.Background(), request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	batch.StateRoot = response.NewStateRoot
+	d.mutex.Unlock()
+	return response, nil
+}
+
+func (d *memoryDBManager) GetTransactionsByBatchNumber(ctx context.Context, batchNumber uint64) ([]types.Transaction, []uint8, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	batch := d.batch(batchNumber)
+	if batch == nil {
+		return nil, nil, state.ErrNotFound
+	}
+	return append([]types.Transaction{}, batch.txs...), append([]uint8{}, batch.effectivePercentages...), nil
+}
+
+func (d *memoryDBManager) GetLastBatch(ctx context.Context) (*state.Batch, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.toStateBatch(d.batches[len(d.batches)-1])
+}
+
+func (d *memoryDBManager) GetLastBatchNumber(ctx context.Context) (uint64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.batches[len(d.batches)-1].BatchNumber, nil
+}
+
+func (d *memoryDBManager) GetBatchByNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	batch := d.batch(batchNumber)
+	if batch == nil {
+		return nil, state.ErrNotFound
+	}
+	return d.toStateBatch(batch)
+}
+
+func (d *memoryDBManager) IsBatchClosed(ctx context.Context, batchNum uint64) (bool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	batch := d.batch(batchNum)
+	if batch == nil {
+		return false, state.ErrNotFound
+	}
+	return batch.closed, nil
+}
+
+func (d *memoryDBManager) GetLatestGer(ctx context.Context, maxBlockNumber uint64) (state.GlobalExitRoot, time.Time, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return state.GlobalExitRoot{GlobalExitRoot: d.batches[0].GlobalExitRoot}, d.batches[0].Timestamp, nil
+}
+
+func (d *memoryDBManager) GetLastTrustedForcedBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.lastTrustedForcedBatch, nil
+}
+
+func (d *memoryDBManager) GetForcedBatch(ctx context.Context, forcedBatchNumber uint64, dbTx pgx.Tx) (*state.ForcedBatch, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	forcedBatch, found := d.forcedBatches[forcedBatchNumber]
+	if !found {
+		return nil, state.ErrNotFound
+	}
+	return forcedBatch, nil
+}
+
+func (d *memoryDBManager) UpdateTxStatus(ctx context.Context, hash common.Hash, newStatus pool.TxStatus, isWIP bool, reason *string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.txStatus[hash] = newStatus
+	if reason != nil && newStatus == pool.TxStatusInvalid {
+		if _, isOOC := d.oocReasons[*reason]; isOOC {
+			d.oocTxs[hash] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// ChargeSponsor never charges a sponsor, the simulated txs aren't sponsored
+func (d *memoryDBManager) ChargeSponsor(ctx context.Context, hash common.Hash, gasUsed uint64) (bool, error) {
+	return false, nil
+}
+
+func (d *memoryDBManager) GetBalanceByStateRoot(ctx context.Context, address common.Address, root common.Hash) (*big.Int, error) {
+	return d.executor.GetBalanceByStateRoot(ctx, address, root)
+}
+
+func (d *memoryDBManager) GetL1AndL2GasPrice() (uint64, uint64) {
+	return 0, 0
+}
+
+func (d *memoryDBManager) GetStoredFlushID(ctx context.Context) (uint64, string, error) {
+	return d.executor.GetStoredFlushID(executor.WithCaller(ctx, stateMetrics.SequencerCallerLabel))
+}
+
+func (d *memoryDBManager) GetForkIDByBatchNumber(batchNumber uint64) uint64 {
+	return d.executor.GetForkIDByBatchNumber(batchNumber)
+}
+
+// txStatusOf returns the status set in the pool for the tx
+func (d *memoryDBManager) txStatusOf(hash common.Hash) pool.TxStatus {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.txStatus[hash]
+}
+
+// countTxStatus returns the number of txs whose status has been updated
+func (d *memoryDBManager) countTxStatus() uint64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return uint64(len(d.txStatus))
+}
+
+// The calls below aren't done by the finalizer while sequencing pool txs
+
+func (d *memoryDBManager) CreateFirstBatch(ctx context.Context, sequencerAddress common.Address) state.ProcessingContext {
+	log.Error(notSimulated("CreateFirstBatch"))
+	return state.ProcessingContext{}
+}
+
+func (d *memoryDBManager) DeleteTransactionFromPool(ctx context.Context, txHash common.Hash) error {
+	return notSimulated("DeleteTransactionFromPool")
+}
+
+func (d *memoryDBManager) GetWIPBatch(ctx context.Context) (*WipBatch, error) {
+	return nil, notSimulated("GetWIPBatch")
+}
+
+func (d *memoryDBManager) GetLastNBatches(ctx context.Context, numBatches uint) ([]*state.Batch, error) {
+	return nil, notSimulated("GetLastNBatches")
+}
+
+func (d *memoryDBManager) GetLastClosedBatch(ctx context.Context) (*state.Batch, error) {
+	return nil, notSimulated("GetLastClosedBatch")
+}
+
+func (d *memoryDBManager) GetForcedBatchesSince(ctx context.Context, forcedBatchNumber, maxBlockNumber uint64, dbTx pgx.Tx) ([]*state.ForcedBatch, error) {
+	return nil, notSimulated("GetForcedBatchesSince")
+}
+
+func (d *memoryDBManager) GetLastL2BlockHeader(ctx context.Context, dbTx pgx.Tx) (*types.Header, error) {
+	return nil, notSimulated("GetLastL2BlockHeader")
+}
+
+func (d *memoryDBManager) GetLastBlock(ctx context.Context, dbTx pgx.Tx) (*state.Block, error) {
+	return nil, notSimulated("GetLastBlock")
+}
+
+func (d *memoryDBManager) GetLatestVirtualBatchTimestamp(ctx context.Context, dbTx pgx.Tx) (time.Time, error) {
+	return time.Time{}, notSimulated("GetLatestVirtualBatchTimestamp")
+}
+
+func (d *memoryDBManager) CountReorgs(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	return 0, notSimulated("CountReorgs")
+}
+
+func (d *memoryDBManager) FlushMerkleTree(ctx context.Context) error {
+	return notSimulated("FlushMerkleTree")
+}
+
+func (d *memoryDBManager) GetGasPrices(ctx context.Context) (pool.GasPrices, error) {
+	return pool.GasPrices{}, notSimulated("GetGasPrices")
+}
+
+func (d *memoryDBManager) GetDefaultMinGasPriceAllowed() uint64 {
+	log.Error(notSimulated("GetDefaultMinGasPriceAllowed"))
+	return 0
+}
+
+func (d *memoryDBManager) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation) error {
+	return notSimulated("AddPreconfirmation")
+}
@@ -0,0 +1,157 @@
+package sequencer
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+const (
+	// TxOrderingGasPrice offers first the txs with higher gas price
+	TxOrderingGasPrice = "gasprice"
+	// TxOrderingFIFO offers the txs in the order they have been received
+	TxOrderingFIFO = "fifo"
+	// TxOrderingFairShare offers first the txs of the senders that have fewer txs sequenced
+	// in the last FairShareWindowSize sequenced txs, ties are offered in arrival order
+	TxOrderingFairShare = "fairshare"
+	// TxOrderingPriorityFee offers first the txs with higher priority fee (gas tip cap),
+	// ties are offered by higher gas price
+	TxOrderingPriorityFee = "priorityfee"
+)
+
+// txPriority is the priority of a tx in the txSortedList, txs are sorted by
+// primary and then by secondary, higher values are offered first
+type txPriority struct {
+	primary   *big.Int
+	secondary *big.Int
+}
+
+// cmp compares the priorities p and o, returning -1 if p is lower than o,
+// 0 if both are equal and +1 if p is higher than o
+func (p txPriority) cmp(o txPriority) int {
+	if c := cmpBigInt(p.primary, o.primary); c != 0 {
+		return c
+	}
+	return cmpBigInt(p.secondary, o.secondary)
+}
+
+func cmpBigInt(a, b *big.Int) int {
+	if a == nil {
+		a = big.NewInt(0)
+	}
+	if b == nil {
+		b = big.NewInt(0)
+	}
+	return a.Cmp(b)
+}
+
+// txOrderingPolicy defines the order in which the worker offers the ready txs
+type txOrderingPolicy interface {
+	// priority returns the priority of the tx when it's added to the txSortedList
+	priority(tx *TxTracker) txPriority
+	// txSequenced is called each time a tx is sequenced in the WIP batch, it returns the senders
+	// whose priority has changed, so their ready txs are sorted again
+	txSequenced(tx *TxTracker) []string
+}
+
+// newTxOrderingPolicy creates the tx ordering policy set in the config
+func newTxOrderingPolicy(cfg TxOrderingCfg) (txOrderingPolicy, error) {
+	switch cfg.Policy {
+	case "", TxOrderingGasPrice:
+		return &gasPriceOrdering{}, nil
+	case TxOrderingFIFO:
+		return &fifoOrdering{}, nil
+	case TxOrderingFairShare:
+		if cfg.FairShareWindowSize == 0 {
+			return nil, fmt.Errorf("invalid FairShareWindowSize for tx ordering policy %s: must be greater than 0", cfg.Policy)
+		}
+		return newFairShareOrdering(cfg.FairShareWindowSize), nil
+	case TxOrderingPriorityFee:
+		return &priorityFeeOrdering{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tx ordering policy: %s", cfg.Policy)
+	}
+}
+
+// gasPriceOrdering sorts the txs by gas price
+type gasPriceOrdering struct{}
+
+func (o *gasPriceOrdering) priority(tx *TxTracker) txPriority {
+	return txPriority{primary: tx.GasPrice}
+}
+
+func (o *gasPriceOrdering) txSequenced(tx *TxTracker) []string { return nil }
+
+// fifoOrdering sorts the txs by arrival time
+type fifoOrdering struct{}
+
+func (o *fifoOrdering) priority(tx *TxTracker) txPriority {
+	return txPriority{primary: arrivalPriority(tx)}
+}
+
+func (o *fifoOrdering) txSequenced(tx *TxTracker) []string { return nil }
+
+// priorityFeeOrdering sorts the txs by priority fee and then by gas price
+type priorityFeeOrdering struct{}
+
+func (o *priorityFeeOrdering) priority(tx *TxTracker) txPriority {
+	tip := tx.GasTipCap
+	if tip == nil {
+		tip = tx.GasPrice
+	}
+	return txPriority{primary: tip, secondary: tx.GasPrice}
+}
+
+func (o *priorityFeeOrdering) txSequenced(tx *TxTracker) []string { return nil }
+
+// fairShareOrdering sorts the txs by the number of txs of the same sender sequenced
+// in the last windowSize sequenced txs (fewer first) and then by arrival time
+type fairShareOrdering struct {
+	windowSize uint64
+	window     []string
+	selections map[string]uint64
+	mutex      sync.Mutex
+}
+
+func newFairShareOrdering(windowSize uint64) *fairShareOrdering {
+	return &fairShareOrdering{
+		windowSize: windowSize,
+		window:     make([]string, 0, windowSize),
+		selections: make(map[string]uint64),
+	}
+}
+
+func (o *fairShareOrdering) priority(tx *TxTracker) txPriority {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	selections := new(big.Int).SetUint64(o.selections[tx.FromStr])
+	return txPriority{primary: selections.Neg(selections), secondary: arrivalPriority(tx)}
+}
+
+func (o *fairShareOrdering) txSequenced(tx *TxTracker) []string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	changed := []string{tx.FromStr}
+	if uint64(len(o.window)) == o.windowSize {
+		oldest := o.window[0]
+		o.window = o.window[1:]
+		o.selections[oldest]--
+		if o.selections[oldest] == 0 {
+			delete(o.selections, oldest)
+		}
+		if oldest != tx.FromStr {
+			changed = append(changed, oldest)
+		}
+	}
+	o.window = append(o.window, tx.FromStr)
+	o.selections[tx.FromStr]++
+	return changed
+}
+
+// arrivalPriority returns a priority that is higher for the txs received earlier
+func arrivalPriority(tx *TxTracker) *big.Int {
+	receivedAt := big.NewInt(tx.ReceivedAt.UnixNano())
+	return receivedAt.Neg(receivedAt)
+}
@@ -0,0 +1,135 @@
+package sequencer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type txOrderingTestTx struct {
+	hash       string
+	from       string
+	gasPrice   int64
+	gasTipCap  int64
+	receivedAt int64
+}
+
+func newTxOrderingTestTracker(tx txOrderingTestTx) *TxTracker {
+	return &TxTracker{
+		HashStr:    tx.hash,
+		FromStr:    common.HexToAddress(tx.from).String(),
+		GasPrice:   big.NewInt(tx.gasPrice),
+		GasTipCap:  big.NewInt(tx.gasTipCap),
+		ReceivedAt: time.Unix(tx.receivedAt, 0),
+	}
+}
+
+func sortedHashes(list *txSortedList) []string {
+	hashes := []string{}
+	for _, tx := range list.GetSorted() {
+		hashes = append(hashes, tx.HashStr)
+	}
+	return hashes
+}
+
+func TestTxOrderingPolicies(t *testing.T) {
+	txs := []txOrderingTestTx{
+		{hash: "0x01", from: "0x1", gasPrice: 10, gasTipCap: 1, receivedAt: 3},
+		{hash: "0x02", from: "0x2", gasPrice: 30, gasTipCap: 1, receivedAt: 2},
+		{hash: "0x03", from: "0x3", gasPrice: 20, gasTipCap: 5, receivedAt: 1},
+		{hash: "0x04", from: "0x4", gasPrice: 40, gasTipCap: 5, receivedAt: 4},
+	}
+
+	testCases := []struct {
+		name     string
+		policy   string
+		expected []string
+	}{
+		{
+			name:     "gas price",
+			policy:   TxOrderingGasPrice,
+			expected: []string{"0x04", "0x02", "0x03", "0x01"},
+		},
+		{
+			name:     "default is gas price",
+			policy:   "",
+			expected: []string{"0x04", "0x02", "0x03", "0x01"},
+		},
+		{
+			name:     "fifo",
+			policy:   TxOrderingFIFO,
+			expected: []string{"0x03", "0x02", "0x01", "0x04"},
+		},
+		{
+			name:     "priority fee",
+			policy:   TxOrderingPriorityFee,
+			expected: []string{"0x04", "0x03", "0x02", "0x01"},
+		},
+		{
+			name:     "fair share without selections is fifo",
+			policy:   TxOrderingFairShare,
+			expected: []string{"0x03", "0x02", "0x01", "0x04"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := newTxOrderingPolicy(TxOrderingCfg{Policy: tc.policy, FairShareWindowSize: 10})
+			require.NoError(t, err)
+
+			list := newTxSortedList(policy)
+			for _, tx := range txs {
+				list.add(newTxOrderingTestTracker(tx))
+			}
+			assert.Equal(t, tc.expected, sortedHashes(list))
+
+			// deleting keeps the order of the remaining txs
+			assert.True(t, list.delete(list.getByIndex(1)))
+			assert.Equal(t, append([]string{tc.expected[0]}, tc.expected[2:]...), sortedHashes(list))
+		})
+	}
+}
+
+func TestTxOrderingFairShare(t *testing.T) {
+	policy := newFairShareOrdering(3)
+	list := newTxSortedList(policy)
+
+	spammer := txOrderingTestTx{from: "0x1", gasPrice: 100}
+	user := txOrderingTestTx{hash: "0x10", from: "0x2", gasPrice: 1, receivedAt: 10}
+	spammerAddr := common.HexToAddress("0x1").String()
+	userAddr := common.HexToAddress("0x2").String()
+
+	// the spammer had 2 txs sequenced in the window, the user none
+	assert.Equal(t, []string{spammerAddr}, policy.txSequenced(newTxOrderingTestTracker(spammer)))
+	assert.Equal(t, []string{spammerAddr}, policy.txSequenced(newTxOrderingTestTracker(spammer)))
+
+	spammer.hash = "0x03"
+	list.add(newTxOrderingTestTracker(spammer))
+	list.add(newTxOrderingTestTracker(user))
+	assert.Equal(t, []string{"0x10", "0x03"}, sortedHashes(list))
+
+	// the oldest sequenced txs leave the window, changing the priority of their senders too
+	assert.Equal(t, []string{userAddr}, policy.txSequenced(newTxOrderingTestTracker(user)))
+	assert.Equal(t, []string{userAddr, spammerAddr}, policy.txSequenced(newTxOrderingTestTracker(user)))
+	assert.Equal(t, []string{userAddr, spammerAddr}, policy.txSequenced(newTxOrderingTestTracker(user)))
+	assert.Equal(t, map[string]uint64{userAddr: 3}, policy.selections)
+
+	list = newTxSortedList(policy)
+	user.hash = "0x11"
+	spammer.hash = "0x04"
+	list.add(newTxOrderingTestTracker(user))
+	list.add(newTxOrderingTestTracker(spammer))
+	assert.Equal(t, []string{"0x04", "0x11"}, sortedHashes(list))
+}
+
+func TestNewTxOrderingPolicyErrors(t *testing.T) {
+	_, err := newTxOrderingPolicy(TxOrderingCfg{Policy: "random"})
+	assert.Error(t, err)
+
+	_, err = newTxOrderingPolicy(TxOrderingCfg{Policy: TxOrderingFairShare})
+	assert.Error(t, err)
+}
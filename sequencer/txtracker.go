@@ -18,6 +18,7 @@ type TxTracker struct {
 	Nonce             uint64
 	Gas               uint64 // To check if it fits into a batch
 	GasPrice          *big.Int
+	GasTipCap         *big.Int
 	Cost              *big.Int             // Cost = Amount + Benefit
 	BatchResources    state.BatchResources // To check if it fits into a batch
 	RawTx             []byte
@@ -29,6 +30,9 @@ type TxTracker struct {
 	EGPLog            state.EffectiveGasPriceLog
 	L1GasPrice        uint64
 	L2GasPrice        uint64
+	Sponsored         bool       // Sponsored txs are processed with their gas price, without the effective gas price calculation
+	sponsorCharged    bool       // sponsorCharged is set once the gas of the tx is charged to the budget of its sponsor
+	priority          txPriority // priority in the txSortedList given by the tx ordering policy
 }
 
 // newTxTracker creates and inti a TxTracker
@@ -44,14 +48,15 @@ func newTxTracker(tx types.Transaction, counters state.ZKCounters, ip string) (*
 	}
 
 	txTracker := &TxTracker{
-		Hash:     tx.Hash(),
-		HashStr:  tx.Hash().String(),
-		From:     addr,
-		FromStr:  addr.String(),
-		Nonce:    tx.Nonce(),
-		Gas:      tx.Gas(),
-		GasPrice: tx.GasPrice(),
-		Cost:     tx.Cost(),
+		Hash:      tx.Hash(),
+		HashStr:   tx.Hash().String(),
+		From:      addr,
+		FromStr:   addr.String(),
+		Nonce:     tx.Nonce(),
+		Gas:       tx.Gas(),
+		GasPrice:  tx.GasPrice(),
+		GasTipCap: tx.GasTipCap(),
+		Cost:      tx.Cost(),
 		BatchResources: state.BatchResources{
 			Bytes:      tx.Size(),
 			ZKCounters: counters,
@@ -19,16 +19,22 @@ import (
 type Worker struct {
 	pool             map[string]*addrQueue
 	txSortedList     *txSortedList
+	txOrdering       txOrderingPolicy
+	quotas           *txQuotas
+	bundles          []*BundleTracker
+	processingTx     common.Hash
 	workerMutex      sync.Mutex
 	state            stateInterface
 	batchConstraints state.BatchConstraintsCfg
 }
 
-// NewWorker creates an init a worker
-func NewWorker(state stateInterface, constraints state.BatchConstraintsCfg) *Worker {
+// NewWorker creates an init a worker, quotas can be nil to sequence the txs without sender and IP quotas
+func NewWorker(state stateInterface, constraints state.BatchConstraintsCfg, txOrdering txOrderingPolicy, quotas *txQuotas) *Worker {
 	w := Worker{
 		pool:             make(map[string]*addrQueue),
-		txSortedList:     newTxSortedList(),
+		txSortedList:     newTxSortedList(txOrdering),
+		txOrdering:       txOrdering,
+		quotas:           quotas,
 		state:            state,
 		batchConstraints: constraints,
 	}
@@ -36,6 +42,21 @@ func NewWorker(state stateInterface, constraints state.BatchConstraintsCfg) *Wor
 	return &w
 }
 
+// countTxs returns the number of ready and not ready txs in the worker
+func (w *Worker) countTxs() uint64 {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	count := uint64(0)
+	for _, addrQueue := range w.pool {
+		if addrQueue.readyTx != nil {
+			count++
+		}
+		count += uint64(len(addrQueue.notReadyTxs))
+	}
+	return count
+}
+
 // NewTxTracker creates and inits a TxTracker
 func (w *Worker) NewTxTracker(tx types.Transaction, counters state.ZKCounters, ip string) (*TxTracker, error) {
 	return newTxTracker(tx, counters, ip)
@@ -91,6 +112,13 @@ func (w *Worker) AddTxTracker(ctx context.Context, tx *TxTracker) (replacedTx *T
 		log.Infof("AddTx new addrQueue created for addr(%s) nonce(%d) balance(%s)", tx.FromStr, nonce.Uint64(), balance.String())
 	}
 
+	// The readyTx returned by the last GetBestFittingTx may be being executed in the WIP batch, so it can't be replaced
+	if addr.readyTx != nil && addr.readyTx.Hash == w.processingTx && addr.readyTx.Nonce == tx.Nonce && addr.readyTx.Hash != tx.Hash {
+		log.Infof("AddTx tx(%s) dropped from addrQueue(%s), reason: readyTx(%s) with the same nonce(%d) is being processed", tx.HashStr, tx.FromStr, addr.readyTx.HashStr, tx.Nonce)
+		w.workerMutex.Unlock()
+		return nil, ErrReplaceInProgress
+	}
+
 	// Add the txTracker to Addr and get the newReadyTx and prevReadyTx
 	log.Infof("AddTx new tx(%s) nonce(%d) gasPrice(%d) to addrQueue(%s) nonce(%d) balance(%d)", tx.HashStr, tx.Nonce, tx.GasPrice, addr.fromStr, addr.currentNonce, addr.currentBalance)
 	var newReadyTx, prevReadyTx, repTx *TxTracker
@@ -205,6 +233,84 @@ func (w *Worker) DeleteTx(txHash common.Hash, addr common.Address) {
 	}
 }
 
+// DropTx deletes a regular tx from the worker looking for it in all the addrQueues,
+// it returns the deleted tx or nil if the tx was not found. The tx returned by the last
+// GetBestFittingTx may be being executed in the WIP batch, so it can't be dropped
+func (w *Worker) DropTx(txHash common.Hash) (*TxTracker, error) {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	if txHash == w.processingTx {
+		return nil, ErrDropInProgress
+	}
+
+	for _, addrQueue := range w.pool {
+		var tx *TxTracker
+		if addrQueue.readyTx != nil && addrQueue.readyTx.Hash == txHash {
+			tx = addrQueue.readyTx
+		} else {
+			for _, notReadyTx := range addrQueue.notReadyTxs {
+				if notReadyTx.Hash == txHash {
+					tx = notReadyTx
+					break
+				}
+			}
+		}
+		if tx == nil {
+			continue
+		}
+
+		deletedReadyTx := addrQueue.deleteTx(txHash)
+		if deletedReadyTx != nil {
+			log.Infof("DropTx tx(%s) deleted from TxSortedList", deletedReadyTx.Hash.String())
+			w.txSortedList.delete(deletedReadyTx)
+		}
+		if addrQueue.IsEmpty() {
+			delete(w.pool, addrQueue.fromStr)
+		}
+		return tx, nil
+	}
+
+	return nil, nil
+}
+
+// AddBundle adds a bundle at the end of the queue of bundles to be processed,
+// bundles already in the queue are ignored
+func (w *Worker) AddBundle(bundle *BundleTracker) {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	for _, b := range w.bundles {
+		if b.Hash == bundle.Hash {
+			return
+		}
+	}
+	w.bundles = append(w.bundles, bundle)
+}
+
+// RequeueBundle adds a bundle at the front of the queue of bundles to be processed,
+// it's used to retry the bundle in the next batch when it doesn't fit in the current one
+func (w *Worker) RequeueBundle(bundle *BundleTracker) {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	w.bundles = append([]*BundleTracker{bundle}, w.bundles...)
+}
+
+// PopBundle removes and returns the next bundle to be processed, nil if there is none
+func (w *Worker) PopBundle() *BundleTracker {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	if len(w.bundles) == 0 {
+		return nil
+	}
+	bundle := w.bundles[0]
+	w.bundles = w.bundles[1:]
+	w.processingTx = common.Hash{}
+	return bundle
+}
+
 // DeleteForcedTx deletes a forced tx from the addrQueue
 func (w *Worker) DeleteForcedTx(txHash common.Hash, addr common.Address) {
 	w.workerMutex.Lock()
@@ -284,13 +390,21 @@ func (w *Worker) DeletePendingTxToStore(txHash common.Hash, addr common.Address)
 	}
 }
 
-// GetBestFittingTx gets the most efficient tx that fits in the available batch resources
+// GetBestFittingTx gets the most efficient tx that fits in the available batch resources, the tx
+// returned can't be replaced until the next call as it's the one being processed by the finalizer
 func (w *Worker) GetBestFittingTx(resources state.BatchResources) *TxTracker {
 	w.workerMutex.Lock()
 	defer w.workerMutex.Unlock()
 
+	type throttledTx struct {
+		index int
+		tx    *TxTracker
+		kind  quotaKind
+	}
+
 	var (
 		tx         *TxTracker
+		throttled  []throttledTx
 		foundMutex sync.RWMutex
 	)
 
@@ -319,6 +433,16 @@ func (w *Worker) GetBestFittingTx(resources state.BatchResources) *TxTracker {
 					continue
 				}
 
+				if w.quotas != nil {
+					// Over-quota txs are deferred until the quotas window moves on
+					if overQuota, kind := w.quotas.isOverQuota(txCandidate); overQuota {
+						foundMutex.Lock()
+						throttled = append(throttled, throttledTx{index: i, tx: txCandidate, kind: kind})
+						foundMutex.Unlock()
+						continue
+					}
+				}
+
 				foundMutex.Lock()
 				if foundAt == -1 || foundAt > i {
 					foundAt = i
@@ -332,13 +456,88 @@ func (w *Worker) GetBestFittingTx(resources state.BatchResources) *TxTracker {
 	}
 	wg.Wait()
 
+	// When only over-quota txs fit, an empty batch takes the best of them. Otherwise the empty
+	// batch would never be closed and the quotas window would never move on
+	if foundAt == -1 && len(throttled) > 0 && resources == getMaxRemainingResources(w.batchConstraints) {
+		best := 0
+		for i, t := range throttled {
+			if t.index < throttled[best].index {
+				best = i
+			}
+		}
+		foundAt = throttled[best].index
+		tx = throttled[best].tx
+		log.Infof("GetBestFittingTx over-quota tx(%s) offered because the batch is empty", tx.HashStr)
+	}
+
+	for _, t := range throttled {
+		if foundAt == -1 || t.index < foundAt {
+			log.Infof("GetBestFittingTx tx(%s) from(%s) ip(%s) deferred because it's over the %s quota", t.tx.HashStr, t.tx.FromStr, t.tx.IP, t.kind)
+			w.quotas.throttle(t.tx, t.kind)
+		}
+	}
+
+	w.processingTx = common.Hash{}
 	if foundAt != -1 {
 		log.Infof("GetBestFittingTx found tx(%s) at index(%d) with gasPrice(%d)", tx.Hash.String(), foundAt, tx.GasPrice)
+		w.processingTx = tx.Hash
 	}
 
 	return tx
 }
 
+// ChargeTxOrdering charges a tx sequenced in the WIP batch to the tx ordering policy, the ready txs of
+// the senders whose priority has changed are sorted again in the txSortedList
+func (w *Worker) ChargeTxOrdering(tx *TxTracker) {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	for _, from := range w.txOrdering.txSequenced(tx) {
+		addrQueue, found := w.pool[from]
+		if !found || addrQueue.readyTx == nil {
+			continue
+		}
+		if w.txSortedList.delete(addrQueue.readyTx) {
+			w.txSortedList.add(addrQueue.readyTx)
+		}
+	}
+}
+
+// AddTxToQuotas adds the counters used by a tx sequenced in the WIP batch to the quotas of its sender and IP
+func (w *Worker) AddTxToQuotas(tx *TxTracker, counters state.ZKCounters) {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	if w.quotas != nil {
+		w.quotas.addTx(tx, counters)
+	}
+}
+
+// AddBundleToQuotas adds the counters used by a bundle sequenced in the WIP batch to the quotas of its senders
+// and IPs, it returns ErrBundleOverQuota and adds nothing if the bundle exceeds any of them
+func (w *Worker) AddBundleToQuotas(bundle *BundleTracker, counters state.ZKCounters) error {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	if w.quotas == nil {
+		return nil
+	}
+	if overQuota, kind := w.quotas.addBundle(bundle.Txs, counters); overQuota {
+		return fmt.Errorf("%w: %s quota", ErrBundleOverQuota, kind)
+	}
+	return nil
+}
+
+// SlideQuotasWindow moves the quotas window to a new WIP batch
+func (w *Worker) SlideQuotasWindow() {
+	w.workerMutex.Lock()
+	defer w.workerMutex.Unlock()
+
+	if w.quotas != nil {
+		w.quotas.slideWindow()
+	}
+}
+
 // ExpireTransactions deletes old txs
 func (w *Worker) ExpireTransactions(maxTime time.Duration) []*TxTracker {
 	w.workerMutex.Lock()
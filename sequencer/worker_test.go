@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
@@ -286,7 +287,95 @@ func TestWorkerGetBestTx(t *testing.T) {
 	}
 }
 
+func TestWorkerReplaceProcessingTx(t *testing.T) {
+	var nilErr error
+
+	rc := state.BatchResources{
+		ZKCounters: state.ZKCounters{CumulativeGasUsed: 10, UsedKeccakHashes: 10, UsedPoseidonHashes: 10, UsedPoseidonPaddings: 10, UsedMemAligns: 10, UsedArithmetics: 10, UsedBinaries: 10, UsedSteps: 10},
+		Bytes:      10,
+	}
+
+	stateMock := NewStateMock(t)
+	worker := initWorker(stateMock, rcMax)
+
+	ctx := context.Background()
+
+	stateMock.On("GetLastStateRoot", ctx, nil).Return(common.Hash{0}, nilErr)
+	for _, from := range []common.Address{{1}, {2}} {
+		stateMock.On("GetNonceByStateRoot", ctx, from, common.Hash{0}).Return(new(big.Int).SetInt64(1), nilErr)
+		stateMock.On("GetBalanceByStateRoot", ctx, from, common.Hash{0}).Return(new(big.Int).SetInt64(10), nilErr)
+	}
+
+	newTx := func(from common.Address, hash common.Hash, gasPrice int64) *TxTracker {
+		tx := &TxTracker{Hash: hash, HashStr: hash.String(), From: from, FromStr: from.String(), Nonce: 1,
+			Cost: new(big.Int).SetInt64(5), GasPrice: new(big.Int).SetInt64(gasPrice), IP: validIP}
+		tx.BatchResources.Bytes = 1
+		tx.updateZKCounters(state.ZKCounters{CumulativeGasUsed: 1, UsedKeccakHashes: 1, UsedPoseidonHashes: 1, UsedPoseidonPaddings: 1, UsedMemAligns: 1, UsedArithmetics: 1, UsedBinaries: 1, UsedSteps: 1})
+		return tx
+	}
+
+	_, err := worker.AddTxTracker(ctx, newTx(common.Address{1}, common.Hash{1}, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{1}, worker.GetBestFittingTx(rc).Hash)
+
+	// the tx being processed can't be replaced
+	replacedTx, err := worker.AddTxTracker(ctx, newTx(common.Address{1}, common.Hash{2}, 20))
+	assert.ErrorIs(t, err, ErrReplaceInProgress)
+	assert.Nil(t, replacedTx)
+
+	// once another tx is being processed the tx can be replaced
+	_, err = worker.AddTxTracker(ctx, newTx(common.Address{2}, common.Hash{3}, 30))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{3}, worker.GetBestFittingTx(rc).Hash)
+	replacedTx, err = worker.AddTxTracker(ctx, newTx(common.Address{1}, common.Hash{2}, 20))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{1}, replacedTx.Hash)
+	assert.Equal(t, common.Hash{2}, worker.pool[common.Address{1}.String()].readyTx.Hash)
+}
+
+func TestWorkerChargeTxOrdering(t *testing.T) {
+	var nilErr error
+
+	rc := state.BatchResources{
+		ZKCounters: state.ZKCounters{CumulativeGasUsed: 10, UsedKeccakHashes: 10, UsedPoseidonHashes: 10, UsedPoseidonPaddings: 10, UsedMemAligns: 10, UsedArithmetics: 10, UsedBinaries: 10, UsedSteps: 10},
+		Bytes:      10,
+	}
+
+	stateMock := NewStateMock(t)
+	worker := NewWorker(stateMock, rcMax, newFairShareOrdering(10), nil)
+
+	ctx := context.Background()
+
+	stateMock.On("GetLastStateRoot", ctx, nil).Return(common.Hash{0}, nilErr)
+	for _, from := range []common.Address{{1}, {2}} {
+		stateMock.On("GetNonceByStateRoot", ctx, from, common.Hash{0}).Return(new(big.Int).SetInt64(1), nilErr)
+		stateMock.On("GetBalanceByStateRoot", ctx, from, common.Hash{0}).Return(new(big.Int).SetInt64(10), nilErr)
+	}
+
+	newTx := func(from common.Address, hash common.Hash, receivedAt int64) *TxTracker {
+		tx := &TxTracker{Hash: hash, HashStr: hash.String(), From: from, FromStr: from.String(), Nonce: 1,
+			Cost: new(big.Int).SetInt64(5), GasPrice: new(big.Int).SetInt64(1), IP: validIP, ReceivedAt: time.Unix(receivedAt, 0)}
+		tx.BatchResources.Bytes = 1
+		tx.updateZKCounters(state.ZKCounters{CumulativeGasUsed: 1, UsedKeccakHashes: 1, UsedPoseidonHashes: 1, UsedPoseidonPaddings: 1, UsedMemAligns: 1, UsedArithmetics: 1, UsedBinaries: 1, UsedSteps: 1})
+		return tx
+	}
+
+	_, err := worker.AddTxTracker(ctx, newTx(common.Address{1}, common.Hash{1}, 1))
+	assert.NoError(t, err)
+	_, err = worker.AddTxTracker(ctx, newTx(common.Address{2}, common.Hash{2}, 2))
+	assert.NoError(t, err)
+
+	// offering a tx doesn't charge its sender
+	tx := worker.GetBestFittingTx(rc)
+	assert.Equal(t, common.Hash{1}, tx.Hash)
+	assert.Equal(t, common.Hash{1}, worker.GetBestFittingTx(rc).Hash)
+
+	// once sequenced, the ready tx of its sender is sorted again behind the other sender
+	worker.ChargeTxOrdering(tx)
+	assert.Equal(t, common.Hash{2}, worker.GetBestFittingTx(rc).Hash)
+}
+
 func initWorker(stateMock *StateMock, rcMax state.BatchConstraintsCfg) *Worker {
-	worker := NewWorker(stateMock, rcMax)
+	worker := NewWorker(stateMock, rcMax, &gasPriceOrdering{}, nil)
 	return worker
 }
@@ -66,6 +66,14 @@ const (
 	TimeoutResolutionDeadlineClosingReason ClosingReason = "timeout resolution deadline"
 	// GlobalExitRootDeadlineClosingReason is the closing reason used when Global Exit Root deadline is reached
 	GlobalExitRootDeadlineClosingReason ClosingReason = "Global Exit Root deadline"
+	// AdminClosingReason is the closing reason used when the batch is closed through the sequencer admin API
+	AdminClosingReason ClosingReason = "Closed by the sequencer admin"
+	// TargetBatchTimeClosingReason is the closing reason used when the target batch time is reached
+	TargetBatchTimeClosingReason ClosingReason = "Target batch time"
+	// TargetL1DataSizeClosingReason is the closing reason used when the target L1 data size is reached
+	TargetL1DataSizeClosingReason ClosingReason = "Target L1 data size"
+	// TargetGasClosingReason is the closing reason used when the target gas is reached
+	TargetGasClosingReason ClosingReason = "Target gas"
 )
 
 // ProcessingReceipt indicates the outcome (StateRoot, AccInputHash) of processing a batch
@@ -344,7 +352,7 @@ func (s *State) sendBatchRequestToExecutor(ctx context.Context, processBatchRequ
 		log.Debugf("processBatch[processBatchRequest.ContextId]: %v", processBatchRequest.ContextId)
 	}
 	now := time.Now()
-	res, err := s.executorClient.ProcessBatch(ctx, processBatchRequest)
+	res, err := s.executorClient.ProcessBatch(executor.WithCaller(ctx, caller), processBatchRequest)
 	if err != nil {
 		log.Errorf("Error s.executorClient.ProcessBatch: %v", err)
 		log.Errorf("Error s.executorClient.ProcessBatch: %s", err.Error())
@@ -45,6 +45,25 @@ type Config struct {
 	// MaxNativeBlockHashBlockRange is a configuration to set the max range for block number when querying
 	// native block hashes in a single call to the state, if zero it means no limit
 	MaxNativeBlockHashBlockRange uint64
+
+	// Pruning is the configuration to prune the old data from the state
+	Pruning PruningCfg `mapstructure:"Pruning"`
+}
+
+// PruningCfg represents the configuration to prune the transactions, receipts and logs of the old
+// consolidated batches, the l2 block headers and the batches are always kept
+type PruningCfg struct {
+	// Enabled is a flag to prune the state periodically
+	Enabled bool `mapstructure:"Enabled"`
+
+	// Interval is the time to wait between prunings
+	Interval types.Duration `mapstructure:"Interval"`
+
+	// RetainedBatches is the number of the last consolidated batches whose data is never pruned
+	RetainedBatches uint64 `mapstructure:"RetainedBatches"`
+
+	// MaxBatchesPerPruning is the max number of batches pruned each time, 0 means no limit
+	MaxBatchesPerPruning uint64 `mapstructure:"MaxBatchesPerPruning"`
 }
 
 // BatchConfig represents the configuration of the batch constraints
@@ -69,6 +69,9 @@ var (
 	// ErrMaxNativeBlockHashBlockRangeLimitExceeded returned when the range between block number range
 	// to filter native block hashes is bigger than the configured limit
 	ErrMaxNativeBlockHashBlockRangeLimitExceeded = errors.New("native block hashes are limited to a %v block range")
+	// ErrPruned returned when the transactions, receipts and logs of an l2 block have been
+	// pruned from the state
+	ErrPruned = errors.New("data pruned")
 
 	zkCounterErrPrefix = "ZKCounter: "
 )
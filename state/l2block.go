@@ -23,14 +23,15 @@ type NewL2BlockEvent struct {
 	Logs  []*types.Log
 }
 
-// StartToMonitorNewL2Blocks starts 2 go routines that will
-// monitor new blocks and execute handlers registered to be executed
-// when a new l2 block is detected. This is used by the RPC WebSocket
-// filter subscription but can be used by any other component that
-// needs to react to a new L2 block added to the state.
+// StartToMonitorNewL2Blocks starts 3 go routines that will
+// monitor new blocks and preconfirmations and execute handlers registered
+// to be executed when a new l2 block or preconfirmation is detected. This
+// is used by the RPC WebSocket filter subscription but can be used by any
+// other component that needs to react to a new L2 block added to the state.
 func (s *State) StartToMonitorNewL2Blocks() {
 	go InfiniteSafeRun(s.monitorNewL2Blocks, "fail to monitor new l2 blocks: %v:", time.Second)
 	go InfiniteSafeRun(s.handleEvents, "fail to handle events: %v", time.Second)
+	go InfiniteSafeRun(s.monitorPreconfirmations, "fail to monitor preconfirmations: %v", time.Second)
 }
 
 // RegisterNewL2BlockEventHandler add the provided handler to the list of handlers
@@ -40,6 +41,9 @@ func (s *State) RegisterNewL2BlockEventHandler(h NewL2BlockEventHandler) {
 	s.newL2BlockEventHandlers = append(s.newL2BlockEventHandlers, h)
 }
 
+// monitorNewL2Blocks sends the events of the new l2 blocks as soon as they are notified by the storage.
+// The last l2 block is polled only to catch up after every (re)subscription, and on every cycle while
+// the subscription isn't available
 func (s *State) monitorNewL2Blocks() {
 	waitNextCycle := func() {
 		time.Sleep(newL2BlocksCheckInterval)
@@ -53,58 +57,86 @@ func (s *State) monitorNewL2Blocks() {
 	}
 	lastL2BlockNumberSeen := lastL2BlockNumber
 
+	var notifications <-chan uint64
+	catchUp := false
 	for {
 		if len(s.newL2BlockEventHandlers) == 0 {
 			waitNextCycle()
 			continue
 		}
 
-		lastL2BlockNumber, err := s.GetLastL2BlockNumber(context.Background(), nil)
-		if errors.Is(err, ErrStateNotSynchronized) {
-			waitNextCycle()
-			continue
-		} else if err != nil {
-			log.Errorf("failed to get last l2 block while monitoring new blocks: %v", err)
-			waitNextCycle()
-			continue
+		if notifications == nil {
+			notifications, err = s.SubscribeNewL2Blocks(context.Background())
+			if err != nil {
+				log.Errorf("failed to subscribe to new l2 blocks, polling instead: %v", err)
+				notifications = nil
+			}
+			// the blocks added while there wasn't a subscription are only found by polling
+			catchUp = true
 		}
 
-		// not updates until now
-		if lastL2BlockNumber == 0 || lastL2BlockNumberSeen >= lastL2BlockNumber {
-			waitNextCycle()
-			continue
+		var lastL2BlockNumber uint64
+		if notifications != nil && !catchUp {
+			blockNumber, ok := <-notifications
+			if !ok {
+				log.Warn("new l2 blocks subscription closed, subscribing again")
+				notifications = nil
+				continue
+			}
+			lastL2BlockNumber = blockNumber
+		} else {
+			lastL2BlockNumber, err = s.GetLastL2BlockNumber(context.Background(), nil)
+			if err != nil && !errors.Is(err, ErrStateNotSynchronized) {
+				log.Errorf("failed to get last l2 block while monitoring new blocks: %v", err)
+				waitNextCycle()
+				continue
+			}
+			catchUp = false
 		}
 
-		fromBlockNumber := lastL2BlockNumberSeen + uint64(1)
-		toBlockNumber := lastL2BlockNumber
-		log.Infof("[monitorNewL2Blocks] new l2 block detected from block %v to %v", fromBlockNumber, toBlockNumber)
+		// not updates until now
+		if lastL2BlockNumber != 0 && lastL2BlockNumberSeen < lastL2BlockNumber {
+			lastL2BlockNumberSeen = s.sendNewL2BlockEvents(lastL2BlockNumberSeen+1, lastL2BlockNumber)
+			// the blocks that failed to be sent are retried by polling
+			catchUp = lastL2BlockNumberSeen < lastL2BlockNumber
+		}
 
-		for bn := fromBlockNumber; bn <= toBlockNumber; bn++ {
-			block, err := s.GetL2BlockByNumber(context.Background(), bn, nil)
-			if err != nil {
-				log.Errorf("failed to get l2 block while monitoring new blocks: %v", err)
-				break
-			}
-			logs, err := s.GetLogsByBlockNumber(context.Background(), bn, nil)
-			if err != nil {
-				log.Errorf("failed to get l2 block while monitoring new blocks: %v", err)
-				break
-			}
+		// interval to check for new l2 blocks when they aren't notified
+		if notifications == nil || catchUp {
+			waitNextCycle()
+		}
+	}
+}
 
-			log.Debugf("[monitorNewL2Blocks] sending NewL2BlockEvent for block %v", block.NumberU64())
-			start := time.Now()
-			s.newL2BlockEvents <- NewL2BlockEvent{
-				Block: *block,
-				Logs:  logs,
-			}
-			lastL2BlockNumberSeen = block.NumberU64()
-			log.Infof("[monitorNewL2Blocks] NewL2BlockEvent for block %v took %v to be sent", block.NumberU64(), time.Since(start))
-			log.Infof("new l2 block detected: number %v, hash %v", block.NumberU64(), block.Hash().String())
+// sendNewL2BlockEvents sends the events of the l2 blocks in the provided range and returns
+// the number of the last l2 block sent
+func (s *State) sendNewL2BlockEvents(fromBlockNumber, toBlockNumber uint64) uint64 {
+	log.Infof("[monitorNewL2Blocks] new l2 block detected from block %v to %v", fromBlockNumber, toBlockNumber)
+
+	lastL2BlockNumberSeen := fromBlockNumber - 1
+	for bn := fromBlockNumber; bn <= toBlockNumber; bn++ {
+		block, err := s.GetL2BlockByNumber(context.Background(), bn, nil)
+		if err != nil {
+			log.Errorf("failed to get l2 block while monitoring new blocks: %v", err)
+			break
+		}
+		logs, err := s.GetLogsByBlockNumber(context.Background(), bn, nil)
+		if err != nil {
+			log.Errorf("failed to get l2 block while monitoring new blocks: %v", err)
+			break
 		}
 
-		// interval to check for new l2 blocks
-		waitNextCycle()
+		log.Debugf("[monitorNewL2Blocks] sending NewL2BlockEvent for block %v", block.NumberU64())
+		start := time.Now()
+		s.newL2BlockEvents <- NewL2BlockEvent{
+			Block: *block,
+			Logs:  logs,
+		}
+		lastL2BlockNumberSeen = block.NumberU64()
+		log.Infof("[monitorNewL2Blocks] NewL2BlockEvent for block %v took %v to be sent", block.NumberU64(), time.Since(start))
+		log.Infof("new l2 block detected: number %v, hash %v", block.NumberU64(), block.Hash().String())
 	}
+	return lastL2BlockNumberSeen
 }
 
 func (s *State) handleEvents() {
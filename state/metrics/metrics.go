@@ -22,6 +22,8 @@ const (
 	SequencerCallerLabel CallerLabel = "sequencer"
 	// SynchronizerCallerLabel is used when synchronizer is calling the function
 	SynchronizerCallerLabel CallerLabel = "synchronizer"
+	// RPCCallerLabel is used when the RPC is calling the function
+	RPCCallerLabel CallerLabel = "rpc"
 	// DiscardCallerLabel is used we want to skip measuring the execution time
 	DiscardCallerLabel CallerLabel = "discard"
 )
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/0xPolygonHermez/zkevm-node/db"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
+)
+
+// l2BlocksChannel is the postgres channel notified when a l2 block is added to the state,
+// the payload of the notification is the number of the l2 block
+const l2BlocksChannel = "state_l2blocks"
+
+// preconfirmationsChannel is the postgres channel notified when a preconfirmation is signed by the
+// trusted sequencer, the payload of the notification is the json encoded preconfirmation
+const preconfirmationsChannel = "state_preconfirmations"
+
+// preconfirmationsBufferSize is the number of notified preconfirmations buffered until they are consumed
+const preconfirmationsBufferSize = 100
+
+// notifyL2Block notifies the l2 block number through the l2 blocks channel, when e is a db transaction
+// the notification is only delivered if the db transaction is committed
+func notifyL2Block(ctx context.Context, e execQuerier, blockNumber uint64) error {
+	_, err := e.Exec(ctx, "SELECT pg_notify($1, $2)", l2BlocksChannel, strconv.FormatUint(blockNumber, 10))
+	return err
+}
+
+// SubscribeNewL2Blocks listens to the l2 blocks channel in a dedicated connection and sends the number of
+// the l2 blocks added to the state through the returned channel. The numbers are sent without blocking, so
+// when the previous number hasn't been consumed it is replaced by the highest of both. The returned channel
+// is closed when the connection drops or the context is done
+func (p *PostgresStorage) SubscribeNewL2Blocks(ctx context.Context) (<-chan uint64, error) {
+	notifications, err := db.Listen(ctx, p.Pool, l2BlocksChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	blockNumbers := make(chan uint64, 1)
+	go func() {
+		defer close(blockNumbers)
+
+		for notification := range notifications {
+			blockNumber, err := strconv.ParseUint(notification.Payload, 10, 64)
+			if err != nil {
+				log.Errorf("invalid l2 blocks notification payload %q: %v", notification.Payload, err)
+				continue
+			}
+			select {
+			case blockNumbers <- blockNumber:
+			default:
+				// this is the only sender, so after taking the pending number the send can't block
+				select {
+				case pending := <-blockNumbers:
+					if pending > blockNumber {
+						blockNumber = pending
+					}
+				default:
+				}
+				blockNumbers <- blockNumber
+			}
+		}
+	}()
+
+	return blockNumbers, nil
+}
+
+// notifyPreconfirmation notifies the preconfirmation through the preconfirmations channel, when e is a db
+// transaction the notification is only delivered if the db transaction is committed
+func notifyPreconfirmation(ctx context.Context, e execQuerier, preconfirmation *preconf.Preconfirmation) error {
+	payload, err := json.Marshal(preconfirmation)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec(ctx, "SELECT pg_notify($1, $2)", preconfirmationsChannel, string(payload))
+	return err
+}
+
+// SubscribePreconfirmations listens to the preconfirmations channel in a dedicated connection and sends the
+// preconfirmations signed by the trusted sequencer through the returned channel, in the order they are
+// notified. The returned channel is closed when the connection drops or the context is done
+func (p *PostgresStorage) SubscribePreconfirmations(ctx context.Context) (<-chan *preconf.Preconfirmation, error) {
+	notifications, err := db.Listen(ctx, p.Pool, preconfirmationsChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	preconfirmations := make(chan *preconf.Preconfirmation, preconfirmationsBufferSize)
+	go func() {
+		defer close(preconfirmations)
+
+		for notification := range notifications {
+			preconfirmation := &preconf.Preconfirmation{}
+			if err := json.Unmarshal([]byte(notification.Payload), preconfirmation); err != nil {
+				log.Errorf("invalid preconfirmations notification payload %q: %v", notification.Payload, err)
+				continue
+			}
+			select {
+			case preconfirmations <- preconfirmation:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return preconfirmations, nil
+}
@@ -10,7 +10,9 @@ import (
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -1111,7 +1113,7 @@ func (p *PostgresStorage) BatchNumberByL2BlockNumber(ctx context.Context, blockN
 	return batchNumber, nil
 }
 
-// GetL2BlockByNumber gets a l2 block by its number
+// GetL2BlockByNumber gets a l2 block by its number, the body of a pruned l2 block is empty
 func (p *PostgresStorage) GetL2BlockByNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (*types.Block, error) {
 	const query = "SELECT header, uncles, received_at FROM state.l2block b WHERE b.block_num = $1"
 
@@ -1136,7 +1138,7 @@ func (p *PostgresStorage) GetL2BlockByNumber(ctx context.Context, blockNumber ui
 }
 
 // GetL2BlocksByBatchNumber get all blocks associated to a batch
-// accordingly to the provided batch number
+// accordingly to the provided batch number, the bodies of the pruned blocks are empty
 func (p *PostgresStorage) GetL2BlocksByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]types.Block, error) {
 	const query = `
         SELECT bl.header, bl.uncles, bl.received_at
@@ -1227,6 +1229,9 @@ func (p *PostgresStorage) GetTransactionByHash(ctx context.Context, transactionH
 	err := q.QueryRow(ctx, getTransactionByHashSQL, transactionHash.String()).Scan(&encoded)
 
 	if errors.Is(err, pgx.ErrNoRows) {
+		if err := p.checkTransactionNotPruned(ctx, transactionHash, dbTx); err != nil {
+			return nil, err
+		}
 		return nil, ErrNotFound
 	} else if err != nil {
 		return nil, err
@@ -1285,6 +1290,9 @@ func (p *PostgresStorage) GetTransactionReceipt(ctx context.Context, transaction
 		)
 
 	if errors.Is(err, pgx.ErrNoRows) {
+		if err := p.checkTransactionNotPruned(ctx, transactionHash, dbTx); err != nil {
+			return nil, err
+		}
 		return nil, ErrNotFound
 	} else if err != nil {
 		return nil, err
@@ -1325,6 +1333,14 @@ func (p *PostgresStorage) GetTransactionByL2BlockHashAndIndex(ctx context.Contex
            AND r.tx_index = $2`
 	err := q.QueryRow(ctx, query, blockHash.String(), index).Scan(&encoded)
 	if errors.Is(err, pgx.ErrNoRows) {
+		header, err := p.GetL2BlockHeaderByHash(ctx, blockHash, dbTx)
+		if err == nil {
+			if err := p.CheckL2BlockNotPruned(ctx, header.Number.Uint64(), dbTx); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
 		return nil, ErrNotFound
 	} else if err != nil {
 		return nil, err
@@ -1347,6 +1363,9 @@ func (p *PostgresStorage) GetTransactionByL2BlockNumberAndIndex(ctx context.Cont
 	q := p.getExecQuerier(dbTx)
 	err := q.QueryRow(ctx, getTransactionByL2BlockNumberAndIndexSQL, blockNumber, index).Scan(&encoded)
 	if errors.Is(err, pgx.ErrNoRows) {
+		if err := p.CheckL2BlockNotPruned(ctx, blockNumber, dbTx); err != nil {
+			return nil, err
+		}
 		return nil, ErrNotFound
 	} else if err != nil {
 		return nil, err
@@ -1370,6 +1389,17 @@ func (p *PostgresStorage) GetL2BlockTransactionCountByHash(ctx context.Context,
 	if err != nil {
 		return 0, err
 	}
+	// the blocks without transactions may have been pruned
+	if count == 0 {
+		header, err := p.GetL2BlockHeaderByHash(ctx, blockHash, dbTx)
+		if err == nil {
+			if err := p.CheckL2BlockNotPruned(ctx, header.Number.Uint64(), dbTx); err != nil {
+				return 0, err
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+	}
 	return count, nil
 }
 
@@ -1383,6 +1413,12 @@ func (p *PostgresStorage) GetL2BlockTransactionCountByNumber(ctx context.Context
 	if err != nil {
 		return 0, err
 	}
+	// the blocks without transactions may have been pruned
+	if count == 0 {
+		if err := p.CheckL2BlockNotPruned(ctx, blockNumber, dbTx); err != nil {
+			return 0, err
+		}
+	}
 	return count, nil
 }
 
@@ -1476,6 +1512,10 @@ func (p *PostgresStorage) GetTransactionEGPLogByHash(ctx context.Context, transa
 	} else if err != nil {
 		return nil, err
 	}
+	// the txs that weren't processed by the sequencer of this node have no log
+	if egpLogData == nil {
+		return nil, ErrNotFound
+	}
 
 	err = json.Unmarshal(egpLogData, &egpLog)
 	if err != nil {
@@ -1485,6 +1525,49 @@ func (p *PostgresStorage) GetTransactionEGPLogByHash(ctx context.Context, transa
 	return &egpLog, nil
 }
 
+// AddPreconfirmation stores the signed preconfirmation of a tx and notifies it to the subscribers
+func (p *PostgresStorage) AddPreconfirmation(ctx context.Context, preconfirmation *preconf.Preconfirmation, dbTx pgx.Tx) error {
+	const addPreconfirmationSQL = `
+        INSERT INTO state.preconfirmation (tx_hash, batch_num, l2_block_num, position, state_root, signature)
+                                   VALUES (     $1,        $2,           $3,       $4,         $5,        $6)`
+
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, addPreconfirmationSQL,
+		preconfirmation.TxHash.String(), uint64(preconfirmation.BatchNumber), uint64(preconfirmation.L2BlockNumber),
+		uint64(preconfirmation.Position), preconfirmation.StateRoot.String(), []byte(preconfirmation.Signature))
+	if err != nil {
+		return err
+	}
+	return notifyPreconfirmation(ctx, e, preconfirmation)
+}
+
+// GetPreconfirmation gets the last signed preconfirmation of the provided transaction hash
+func (p *PostgresStorage) GetPreconfirmation(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*preconf.Preconfirmation, error) {
+	const getPreconfirmationSQL = "SELECT batch_num, l2_block_num, position, state_root, signature FROM state.preconfirmation WHERE tx_hash = $1 ORDER BY id DESC LIMIT 1"
+
+	var (
+		batchNumber, l2BlockNumber, position uint64
+		stateRoot                            string
+		signature                            []byte
+	)
+	q := p.getExecQuerier(dbTx)
+	err := q.QueryRow(ctx, getPreconfirmationSQL, transactionHash.String()).Scan(&batchNumber, &l2BlockNumber, &position, &stateRoot, &signature)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &preconf.Preconfirmation{
+		TxHash:        transactionHash,
+		BatchNumber:   hexutil.Uint64(batchNumber),
+		L2BlockNumber: hexutil.Uint64(l2BlockNumber),
+		Position:      hexutil.Uint64(position),
+		StateRoot:     common.HexToHash(stateRoot),
+		Signature:     signature,
+	}, nil
+}
+
 // AddL2Block adds a new L2 block to the State Store
 func (p *PostgresStorage) AddL2Block(ctx context.Context, batchNumber uint64, l2Block *types.Block, receipts []*types.Receipt, txsEGPData []StoreTxEGPData, dbTx pgx.Tx) error {
 	log.Infof("[AddL2Block] adding l2 block: %v", l2Block.NumberU64())
@@ -1563,7 +1646,7 @@ func (p *PostgresStorage) AddL2Block(ctx context.Context, batchNumber uint64, l2
 		}
 	}
 	log.Infof("[AddL2Block] l2 block %v took %v to be added", l2Block.NumberU64(), time.Since(start))
-	return nil
+	return notifyL2Block(ctx, e, l2Block.NumberU64())
 }
 
 // GetLastVirtualizedL2BlockNumber gets the last l2 block virtualized
@@ -1776,7 +1859,7 @@ func (p *PostgresStorage) GetBlockNumVirtualBatchByBatchNum(ctx context.Context,
 	return blockNum, nil
 }
 
-// GetL2BlockByHash gets a l2 block from its hash
+// GetL2BlockByHash gets a l2 block from its hash, the body of a pruned l2 block is empty
 func (p *PostgresStorage) GetL2BlockByHash(ctx context.Context, hash common.Hash, dbTx pgx.Tx) (*types.Block, error) {
 	const query = "SELECT header, uncles, received_at FROM state.l2block b WHERE b.block_hash = $1"
 
@@ -2055,6 +2138,16 @@ func (p *PostgresStorage) GetLogs(ctx context.Context, fromBlock uint64, toBlock
 	var queryToCount string
 	var queryToSelect string
 	if blockHash != nil {
+		// the logs of a pruned block are not available
+		header, err := p.GetL2BlockHeaderByHash(ctx, *blockHash, dbTx)
+		if err == nil {
+			if err := p.CheckL2BlockNotPruned(ctx, header.Number.Uint64(), dbTx); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
 		args = append(args, blockHash.String())
 		queryToCount = queryToCountLogsByBlockHash
 		queryToSelect = queryToSelectLogsByBlockHash
@@ -2068,6 +2161,11 @@ func (p *PostgresStorage) GetLogs(ctx context.Context, fromBlock uint64, toBlock
 			return nil, ErrMaxLogsBlockRangeLimitExceeded
 		}
 
+		// the logs of the pruned blocks are not available, so the range can't start in them
+		if err := p.CheckL2BlockNotPruned(ctx, fromBlock, dbTx); err != nil {
+			return nil, err
+		}
+
 		args = append(args, fromBlock, toBlock)
 		queryToCount = queryToCountLogsByBlockNumbers
 		queryToSelect = queryToSelectLogsByBlockNumbers
@@ -2131,7 +2229,15 @@ func (p *PostgresStorage) GetSyncingInfo(ctx context.Context, dbTx pgx.Tx) (Sync
 	}
 	info.CurrentBatchNumber = lastBatchNumber
 
-	return info, err
+	pruneInfo, err := p.GetPruneInfo(ctx, dbTx)
+	if err == nil {
+		info.LastPrunedBlockNumber = pruneInfo.L2BlockNumber
+		info.LastPrunedBatchNumber = pruneInfo.BatchNumber
+	} else if !errors.Is(err, ErrNotFound) {
+		return SyncingInfo{}, err
+	}
+
+	return info, nil
 }
 
 func (p *PostgresStorage) addressesToHex(addresses []common.Address) []string {
@@ -2919,3 +3025,143 @@ func (p *PostgresStorage) GetBatchL2DataByNumber(ctx context.Context, batchNumbe
 	}
 	return batchL2Data, nil
 }
+
+// TryAcquireSequencerLease acquires the sequencer leadership lease for the provided holder, or renews it
+// if the holder already owns it. The lease can only be taken from another holder once it has expired.
+// The expiration is computed with the DB clock so the sequencer instances don't need synchronized clocks
+func (p *PostgresStorage) TryAcquireSequencerLease(ctx context.Context, holder string, duration time.Duration, dbTx pgx.Tx) (bool, error) {
+	const tryAcquireSequencerLeaseSQL = `
+        INSERT INTO state.sequencer_lease (id, holder, acquired_at, expires_at)
+             VALUES (1, $1, NOW(), NOW() + $2 * INTERVAL '1 millisecond')
+        ON CONFLICT (id) DO UPDATE
+                SET holder = EXCLUDED.holder,
+                    acquired_at = CASE WHEN state.sequencer_lease.holder = EXCLUDED.holder THEN state.sequencer_lease.acquired_at ELSE EXCLUDED.acquired_at END,
+                    expires_at = EXCLUDED.expires_at
+              WHERE state.sequencer_lease.holder = EXCLUDED.holder OR state.sequencer_lease.expires_at < NOW()
+          RETURNING holder`
+
+	var leaseHolder string
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, tryAcquireSequencerLeaseSQL, holder, duration.Milliseconds()).Scan(&leaseHolder)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return leaseHolder == holder, nil
+}
+
+// ReleaseSequencerLease releases the sequencer leadership lease if it's owned by the provided holder
+func (p *PostgresStorage) ReleaseSequencerLease(ctx context.Context, holder string, dbTx pgx.Tx) error {
+	const releaseSequencerLeaseSQL = "DELETE FROM state.sequencer_lease WHERE holder = $1"
+
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, releaseSequencerLeaseSQL, holder)
+	return err
+}
+
+// PruneBatches deletes the transactions, receipts and logs of the l2 blocks of the batches up to the
+// provided batch number and records them as pruned. The l2 block headers, the batches and the hashes
+// of the pruned transactions are kept
+func (p *PostgresStorage) PruneBatches(ctx context.Context, toBatchNumber uint64, dbTx pgx.Tx) (PruneInfo, error) {
+	const recordPrunedTransactionsSQL = `
+        INSERT INTO state.pruned_transaction (hash, l2_block_num)
+             SELECT t.hash, t.l2_block_num
+               FROM state.transaction t
+              INNER JOIN state.l2block b
+                 ON t.l2_block_num = b.block_num
+              WHERE b.batch_num <= $1
+        ON CONFLICT (hash) DO NOTHING`
+	const pruneTransactionsSQL = `
+        DELETE FROM state.transaction t
+         USING state.l2block b
+         WHERE t.l2_block_num = b.block_num
+           AND b.batch_num <= $1`
+	const updatePruneInfoSQL = `
+        INSERT INTO state.prune_info (id, batch_num, l2_block_num, pruned_at)
+             SELECT 1, $1, coalesce(MAX(block_num), 0), NOW() FROM state.l2block WHERE batch_num <= $1
+        ON CONFLICT (id) DO UPDATE
+                SET batch_num = EXCLUDED.batch_num,
+                    l2_block_num = EXCLUDED.l2_block_num,
+                    pruned_at = EXCLUDED.pruned_at
+          RETURNING batch_num, l2_block_num, pruned_at`
+
+	e := p.getExecQuerier(dbTx)
+	if _, err := e.Exec(ctx, recordPrunedTransactionsSQL, toBatchNumber); err != nil {
+		return PruneInfo{}, err
+	}
+	if _, err := e.Exec(ctx, pruneTransactionsSQL, toBatchNumber); err != nil {
+		return PruneInfo{}, err
+	}
+
+	var pruneInfo PruneInfo
+	err := e.QueryRow(ctx, updatePruneInfoSQL, toBatchNumber).Scan(&pruneInfo.BatchNumber, &pruneInfo.L2BlockNumber, &pruneInfo.PrunedAt)
+	return pruneInfo, err
+}
+
+// GetPruneInfo returns the last batch and l2 block that have been pruned, ErrNotFound if the state was never pruned
+func (p *PostgresStorage) GetPruneInfo(ctx context.Context, dbTx pgx.Tx) (PruneInfo, error) {
+	const getPruneInfoSQL = "SELECT batch_num, l2_block_num, pruned_at FROM state.prune_info WHERE id = 1"
+
+	var pruneInfo PruneInfo
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, getPruneInfoSQL).Scan(&pruneInfo.BatchNumber, &pruneInfo.L2BlockNumber, &pruneInfo.PrunedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PruneInfo{}, ErrNotFound
+	} else if err != nil {
+		return PruneInfo{}, err
+	}
+	return pruneInfo, nil
+}
+
+// CheckL2BlockNotPruned returns ErrPruned if the transactions, receipts and logs of the l2 block have been pruned
+func (p *PostgresStorage) CheckL2BlockNotPruned(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) error {
+	pruneInfo, err := p.GetPruneInfo(ctx, dbTx)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if blockNumber <= pruneInfo.L2BlockNumber {
+		return fmt.Errorf("%w, the oldest l2 block with transactions, receipts and logs is %d", ErrPruned, pruneInfo.L2BlockNumber+1)
+	}
+	return nil
+}
+
+// checkTransactionNotPruned returns ErrPruned if the transaction has been pruned
+func (p *PostgresStorage) checkTransactionNotPruned(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) error {
+	const getPrunedTransactionSQL = "SELECT l2_block_num FROM state.pruned_transaction WHERE hash = $1"
+
+	var l2BlockNumber uint64
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, getPrunedTransactionSQL, transactionHash.String()).Scan(&l2BlockNumber)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w, the transaction was in the l2 block %d", ErrPruned, l2BlockNumber)
+}
+
+// GetPrunedTransactionHashesByL2BlockNumber returns the hashes of the pruned transactions of the l2 block,
+// empty if the l2 block wasn't pruned
+func (p *PostgresStorage) GetPrunedTransactionHashesByL2BlockNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) ([]common.Hash, error) {
+	const getPrunedTransactionHashesSQL = "SELECT hash FROM state.pruned_transaction WHERE l2_block_num = $1 ORDER BY hash"
+
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getPrunedTransactionHashesSQL, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := []common.Hash{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, common.HexToHash(hash))
+	}
+	return hashes, rows.Err()
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -849,6 +851,64 @@ func TestGetLogs(t *testing.T) {
 	require.NoError(t, dbTx.Commit(ctx))
 }
 
+func TestSubscribeNewL2Blocks(t *testing.T) {
+	initOrResetDB()
+	setup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockNumbers, err := pgStateStorage.SubscribeNewL2Blocks(ctx)
+	require.NoError(t, err)
+
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	err = testState.AddBlock(ctx, block, dbTx)
+	require.NoError(t, err)
+	batchNumber := uint64(1)
+	_, err = dbTx.Exec(ctx, "INSERT INTO state.batch (batch_num) VALUES ($1)", batchNumber)
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		header := &types.Header{
+			Number:     big.NewInt(int64(i)),
+			ParentHash: state.ZeroHash,
+			Coinbase:   state.ZeroAddress,
+			Root:       state.ZeroHash,
+			GasUsed:    1,
+			GasLimit:   10,
+			Time:       uint64(time.Now().Unix()),
+		}
+		l2Block := types.NewBlock(header, []*types.Transaction{}, []*types.Header{}, []*types.Receipt{}, &trie.StackTrie{})
+		err = pgStateStorage.AddL2Block(ctx, batchNumber, l2Block, []*types.Receipt{}, []state.StoreTxEGPData{}, dbTx)
+		require.NoError(t, err)
+	}
+
+	// the l2 blocks aren't notified until the db transaction is committed
+	select {
+	case blockNumber := <-blockNumbers:
+		require.Failf(t, "unexpected notification", "l2 block %d", blockNumber)
+	case <-time.After(time.Second):
+	}
+	require.NoError(t, dbTx.Commit(ctx))
+
+	// the notifications not consumed yet are replaced by the highest l2 block number
+	lastBlockNumber := uint64(0)
+	for lastBlockNumber < 2 {
+		select {
+		case blockNumber := <-blockNumbers:
+			require.Greater(t, blockNumber, lastBlockNumber)
+			lastBlockNumber = blockNumber
+		case <-time.After(time.Second):
+			require.Fail(t, "notification not received")
+		}
+	}
+
+	// the channel is closed when the subscription ends
+	cancel()
+	for range blockNumbers {
+	}
+}
+
 func TestGetNativeBlockHashesInRange(t *testing.T) {
 	initOrResetDB()
 
@@ -994,3 +1054,150 @@ func TestGetBatchL2DataByNumber(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expectedData, actualData)
 }
+
+func TestPreconfirmation(t *testing.T) {
+	initOrResetDB()
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dbTx.Commit(ctx)) }()
+
+	// the preconfirmations are stored before the tx, so they don't need the tx to be in the state
+	txHash := common.HexToHash("0x1")
+	_, err = testState.GetPreconfirmation(ctx, txHash, dbTx)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	preconfirmation := &preconf.Preconfirmation{
+		TxHash:        txHash,
+		BatchNumber:   1,
+		L2BlockNumber: 1,
+		Position:      0,
+		StateRoot:     common.HexToHash("0x2"),
+	}
+	require.NoError(t, preconfirmation.Sign(privateKey))
+	err = testState.AddPreconfirmation(ctx, preconfirmation, dbTx)
+	require.NoError(t, err)
+
+	stored, err := testState.GetPreconfirmation(ctx, txHash, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, preconfirmation, stored)
+	assert.NoError(t, stored.Verify(crypto.PubkeyToAddress(privateKey.PublicKey)))
+
+	// a tx reprocessed after a restart gets a new preconfirmation, the last one is returned
+	reprocessed := &preconf.Preconfirmation{
+		TxHash:        txHash,
+		BatchNumber:   2,
+		L2BlockNumber: 3,
+		Position:      1,
+		StateRoot:     common.HexToHash("0x3"),
+	}
+	require.NoError(t, reprocessed.Sign(privateKey))
+	err = testState.AddPreconfirmation(ctx, reprocessed, dbTx)
+	require.NoError(t, err)
+
+	stored, err = testState.GetPreconfirmation(ctx, txHash, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, reprocessed, stored)
+}
+
+func TestSequencerLease(t *testing.T) {
+	initOrResetDB()
+	ctx := context.Background()
+
+	acquired, err := testState.TryAcquireSequencerLease(ctx, "seq1", time.Minute, nil)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// the lease is owned by seq1 until it expires
+	acquired, err = testState.TryAcquireSequencerLease(ctx, "seq2", time.Minute, nil)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// seq1 renews the lease with a short duration and lets it expire
+	acquired, err = testState.TryAcquireSequencerLease(ctx, "seq1", time.Millisecond, nil)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	time.Sleep(10 * time.Millisecond)
+
+	acquired, err = testState.TryAcquireSequencerLease(ctx, "seq2", time.Minute, nil)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// releasing a lease owned by another holder has no effect
+	require.NoError(t, testState.ReleaseSequencerLease(ctx, "seq1", nil))
+	acquired, err = testState.TryAcquireSequencerLease(ctx, "seq1", time.Minute, nil)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, testState.ReleaseSequencerLease(ctx, "seq2", nil))
+	acquired, err = testState.TryAcquireSequencerLease(ctx, "seq1", time.Minute, nil)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestPruneBatches(t *testing.T) {
+	initOrResetDB()
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dbTx.Commit(ctx)) }()
+
+	_, err = testState.GetPruneInfo(ctx, dbTx)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	err = testState.AddBlock(ctx, block, dbTx)
+	require.NoError(t, err)
+	txs := make([]*types.Transaction, 0, 2)
+	for batchNumber := uint64(1); batchNumber <= 2; batchNumber++ {
+		_, err = dbTx.Exec(ctx, "INSERT INTO state.batch (batch_num) VALUES ($1)", batchNumber)
+		require.NoError(t, err)
+
+		tx := types.NewTx(&types.LegacyTx{Nonce: batchNumber, Value: new(big.Int), GasPrice: new(big.Int)})
+		receipt := &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful, EffectiveGasPrice: new(big.Int)}
+		header := &types.Header{Number: new(big.Int).SetUint64(batchNumber), Time: uint64(time.Now().Unix())}
+		l2Block := types.NewBlock(header, []*types.Transaction{tx}, []*types.Header{}, []*types.Receipt{receipt}, &trie.StackTrie{})
+		storeTxsEGPData := []state.StoreTxEGPData{{EGPLog: nil, EffectivePercentage: state.MaxEffectivePercentage}}
+		err = testState.AddL2Block(ctx, batchNumber, l2Block, []*types.Receipt{receipt}, storeTxsEGPData, dbTx)
+		require.NoError(t, err)
+		txs = append(txs, tx)
+	}
+
+	pruneInfo, err := testState.PruneBatches(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), pruneInfo.BatchNumber)
+	assert.Equal(t, uint64(1), pruneInfo.L2BlockNumber)
+
+	// the data of the pruned block is not available, its header is kept
+	prunedBlock, err := testState.GetL2BlockByNumber(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.Empty(t, prunedBlock.Transactions())
+	assert.ErrorIs(t, testState.CheckL2BlockNotPruned(ctx, 1, dbTx), state.ErrPruned)
+	_, err = testState.GetL2BlockTransactionCountByNumber(ctx, 1, dbTx)
+	assert.ErrorIs(t, err, state.ErrPruned)
+	_, err = testState.GetTransactionByL2BlockNumberAndIndex(ctx, 1, 0, dbTx)
+	assert.ErrorIs(t, err, state.ErrPruned)
+	_, err = testState.GetLogs(ctx, 1, 2, nil, nil, nil, nil, dbTx)
+	assert.ErrorIs(t, err, state.ErrPruned)
+	_, err = testState.GetTransactionByHash(ctx, txs[0].Hash(), dbTx)
+	assert.ErrorIs(t, err, state.ErrPruned)
+	_, err = testState.GetTransactionReceipt(ctx, txs[0].Hash(), dbTx)
+	assert.ErrorIs(t, err, state.ErrPruned)
+	header, err := testState.GetL2BlockHeaderByNumber(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), header.Number.Uint64())
+
+	// the data of the next block is kept
+	assert.NoError(t, testState.CheckL2BlockNotPruned(ctx, 2, dbTx))
+	l2Block, err := testState.GetL2BlockByNumber(ctx, 2, dbTx)
+	require.NoError(t, err)
+	require.Len(t, l2Block.Transactions(), 1)
+	assert.Equal(t, txs[1].Hash(), l2Block.Transactions()[0].Hash())
+	_, err = testState.GetTransactionReceipt(ctx, txs[1].Hash(), dbTx)
+	require.NoError(t, err)
+
+	stored, err := testState.GetPruneInfo(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, pruneInfo.L2BlockNumber, stored.L2BlockNumber)
+}
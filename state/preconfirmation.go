@@ -0,0 +1,45 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/preconf"
+)
+
+// PreconfirmationEventHandler represent a func that will be called by the state
+// when a preconfirmation signed by the trusted sequencer is notified
+type PreconfirmationEventHandler func(p *preconf.Preconfirmation)
+
+// RegisterPreconfirmationEventHandler add the provided handler to the list of handlers
+// that will be triggered when a preconfirmation is notified
+func (s *State) RegisterPreconfirmationEventHandler(h PreconfirmationEventHandler) {
+	log.Info("preconfirmation event handler registered")
+	s.preconfirmationEventHandlers = append(s.preconfirmationEventHandlers, h)
+}
+
+// monitorPreconfirmations triggers the handlers with the preconfirmations notified by the storage. They are
+// only available through the notifications, so the ones notified while resubscribing are not triggered
+func (s *State) monitorPreconfirmations() {
+	for {
+		if len(s.preconfirmationEventHandlers) == 0 {
+			time.Sleep(newL2BlocksCheckInterval)
+			continue
+		}
+
+		preconfirmations, err := s.SubscribePreconfirmations(context.Background())
+		if err != nil {
+			log.Errorf("failed to subscribe to preconfirmations: %v", err)
+			time.Sleep(newL2BlocksCheckInterval)
+			continue
+		}
+
+		for preconfirmation := range preconfirmations {
+			for _, handler := range s.preconfirmationEventHandlers {
+				handler(preconfirmation)
+			}
+		}
+		log.Warn("preconfirmations subscription closed, subscribing again")
+	}
+}
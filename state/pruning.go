@@ -0,0 +1,83 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+)
+
+// PruneInfo is the last batch and l2 block whose transactions, receipts and logs have been pruned
+type PruneInfo struct {
+	BatchNumber   uint64
+	L2BlockNumber uint64
+	PrunedAt      time.Time
+}
+
+// StartPruning starts pruning periodically the transactions, receipts and logs of the consolidated
+// batches older than the RetainedBatches, if the pruning is enabled
+func (s *State) StartPruning(ctx context.Context) {
+	if !s.cfg.Pruning.Enabled {
+		return
+	}
+	log.Infof("pruning the state keeping the last %d consolidated batches", s.cfg.Pruning.RetainedBatches)
+	go func() {
+		for {
+			if err := s.prune(ctx); err != nil {
+				log.Errorf("failed to prune the state: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.cfg.Pruning.Interval.Duration):
+			}
+		}
+	}()
+}
+
+// prune prunes the transactions, receipts and logs of the consolidated batches older than the
+// RetainedBatches, up to MaxBatchesPerPruning batches each time
+func (s *State) prune(ctx context.Context) error {
+	lastVerifiedBatch, err := s.GetLastVerifiedBatch(ctx, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if lastVerifiedBatch.BatchNumber <= s.cfg.Pruning.RetainedBatches {
+		return nil
+	}
+	toBatchNumber := lastVerifiedBatch.BatchNumber - s.cfg.Pruning.RetainedBatches
+
+	var lastPrunedBatchNumber uint64
+	pruneInfo, err := s.GetPruneInfo(ctx, nil)
+	if err == nil {
+		lastPrunedBatchNumber = pruneInfo.BatchNumber
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if toBatchNumber <= lastPrunedBatchNumber {
+		return nil
+	}
+	if s.cfg.Pruning.MaxBatchesPerPruning > 0 && toBatchNumber-lastPrunedBatchNumber > s.cfg.Pruning.MaxBatchesPerPruning {
+		toBatchNumber = lastPrunedBatchNumber + s.cfg.Pruning.MaxBatchesPerPruning
+	}
+
+	dbTx, err := s.BeginStateTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	pruneInfo, err = s.PruneBatches(ctx, toBatchNumber, dbTx)
+	if err != nil {
+		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+			log.Errorf("failed to rollback the state pruning: %v", rollbackErr)
+		}
+		return err
+	}
+	if err := dbTx.Commit(ctx); err != nil {
+		return err
+	}
+	log.Infof("state pruned up to batch %d and l2 block %d", pruneInfo.BatchNumber, pruneInfo.L2BlockNumber)
+	return nil
+}
@@ -10,4 +10,28 @@ type Config struct {
 	// WaitOnResourceExhaustion is the time to wait before retrying a transaction because of resource exhaustion
 	WaitOnResourceExhaustion types.Duration `mapstructure:"WaitOnResourceExhaustion"`
 	MaxGRPCMessageSize       int            `mapstructure:"MaxGRPCMessageSize"`
+
+	// URIs are the executors the requests are balanced between when the caller has no executors of its own.
+	// If they, SequencerURIs, SynchronizerURIs and RPCURIs are empty, a single executor client to URI is used
+	URIs []string `mapstructure:"URIs"`
+	// SequencerURIs are the executors used only by the sequencer, URIs are used if empty. All the requests of the
+	// sequencer are sent to the first of them that answers at startup, it only fails over to another one on a restart
+	SequencerURIs []string `mapstructure:"SequencerURIs"`
+	// SynchronizerURIs are the executors used only by the synchronizer, URIs are used if empty. Like the sequencer,
+	// the synchronizer sends all its requests to one of them
+	SynchronizerURIs []string `mapstructure:"SynchronizerURIs"`
+	// RPCURIs are the executors used only by the RPC (calls, gas estimations, traces and pool pre-executions), URIs are used if empty
+	RPCURIs []string `mapstructure:"RPCURIs"`
+	// HealthCheckInterval is the time between the health checks of the executors of the pools, 0 disables them
+	HealthCheckInterval types.Duration `mapstructure:"HealthCheckInterval"`
+	// MaxRetries is the max number of times a request failed with a transient error is retried on another executor.
+	// The requests that update the merkle tree are never retried
+	MaxRetries int `mapstructure:"MaxRetries"`
+	// RetryDelay is the time to wait before retrying a request failed with a transient error
+	RetryDelay types.Duration `mapstructure:"RetryDelay"`
+}
+
+// HasPools returns whether the requests must be balanced between several executors
+func (c Config) HasPools() bool {
+	return len(c.URIs) > 0 || len(c.SequencerURIs) > 0 || len(c.SynchronizerURIs) > 0 || len(c.RPCURIs) > 0
 }
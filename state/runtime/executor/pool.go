@@ -0,0 +1,349 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/state/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultPoolName = "default"
+	// pinProbeTimeout is the time an executor has to get its flush status to be pinned when the
+	// health checks are disabled
+	pinProbeTimeout = 5 * time.Second
+)
+
+// pinnedCallers are the callers whose requests are all sent to the same executor. They wait for the flush
+// ids returned by ProcessBatch to be stored, and the flush ids are counters of each executor, so the flush
+// status must be got from the executor that returned them. They only fail over to another executor when
+// they are restarted
+var pinnedCallers = map[metrics.CallerLabel]bool{
+	metrics.SequencerCallerLabel:    true,
+	metrics.SynchronizerCallerLabel: true,
+}
+
+type callerKey struct{}
+
+// WithCaller returns a copy of the context that makes a ClientPool send the requests made with it
+// to the executors of the caller
+func WithCaller(ctx context.Context, caller metrics.CallerLabel) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// endpoint is an executor the requests of one or more pools are sent to
+type endpoint struct {
+	uri    string
+	client ExecutorServiceClient
+	// inFlight is the number of requests sent to the executor waiting for a response
+	inFlight int64
+	// unhealthy is 1 since a request to the executor fails with a transient error until it passes a health check
+	unhealthy int32
+}
+
+func (e *endpoint) isHealthy() bool {
+	return atomic.LoadInt32(&e.unhealthy) == 0
+}
+
+func (e *endpoint) setHealthy(healthy bool) {
+	var unhealthy int32
+	if !healthy {
+		unhealthy = 1
+	}
+	if atomic.SwapInt32(&e.unhealthy, unhealthy) != unhealthy {
+		if healthy {
+			log.Infof("executor %s is healthy again", e.uri)
+		} else {
+			log.Warnf("executor %s is unhealthy", e.uri)
+		}
+	}
+}
+
+// endpointPool is a set of executors the requests are balanced between
+type endpointPool struct {
+	name      string
+	endpoints []*endpoint
+	next      uint32
+}
+
+// pick returns the executor to send a request to: an executor the request hasn't been tried on yet over
+// the ones it has, a healthy executor over an unhealthy one and then the one with the least requests in
+// flight. The ties are broken in round robin
+func (p *endpointPool) pick(tried map[*endpoint]bool) *endpoint {
+	start := int(atomic.AddUint32(&p.next, 1))
+	var picked *endpoint
+	for i := range p.endpoints {
+		e := p.endpoints[(start+i)%len(p.endpoints)]
+		if picked == nil || isPreferred(e, picked, tried) {
+			picked = e
+		}
+	}
+	return picked
+}
+
+func isPreferred(e, than *endpoint, tried map[*endpoint]bool) bool {
+	if tried[e] != tried[than] {
+		return !tried[e]
+	}
+	if e.isHealthy() != than.isHealthy() {
+		return e.isHealthy()
+	}
+	return atomic.LoadInt64(&e.inFlight) < atomic.LoadInt64(&than.inFlight)
+}
+
+// ClientPool is an executor client that balances the requests between several executors, sending each of them
+// to the least loaded healthy executor and retrying on another executor the ones failed with a transient error.
+// The sequencer, the synchronizer and the RPC can have executors of their own, so the load of one of them
+// doesn't slow down the others; the caller of a request is taken from the context, see WithCaller.
+// The requests of the sequencer and the synchronizer are not balanced, see pinnedCallers
+type ClientPool struct {
+	cfg         Config
+	endpoints   []*endpoint
+	defaultPool *endpointPool
+	callerPools map[metrics.CallerLabel]*endpointPool
+
+	pinnedMu sync.Mutex
+	pinned   map[metrics.CallerLabel]*endpoint
+}
+
+// NewClientPool is the executor client pool constructor, the connections to the executors are established
+// in the background and they are health checked until the context is done
+func NewClientPool(ctx context.Context, c Config) (*ClientPool, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(c.MaxGRPCMessageSize)),
+	}
+	conns := []*grpc.ClientConn{}
+	p, err := newClientPool(c, func(uri string) (ExecutorServiceClient, error) {
+		conn, err := grpc.DialContext(ctx, uri, opts...)
+		if err != nil {
+			return nil, err
+		}
+		conns = append(conns, conn)
+		return NewExecutorServiceClient(conn), nil
+	})
+	if err != nil {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	p.startHealthChecks(ctx)
+	return p, nil
+}
+
+func newClientPool(c Config, dial func(uri string) (ExecutorServiceClient, error)) (*ClientPool, error) {
+	p := &ClientPool{
+		cfg:         c,
+		callerPools: map[metrics.CallerLabel]*endpointPool{},
+		pinned:      map[metrics.CallerLabel]*endpoint{},
+	}
+
+	// the executors shared by several pools are the same endpoint, so their load is tracked as a whole
+	endpoints := map[string]*endpoint{}
+	newPool := func(name string, uris []string) (*endpointPool, error) {
+		pool := &endpointPool{name: name}
+		for _, uri := range uris {
+			e, found := endpoints[uri]
+			if !found {
+				client, err := dial(uri)
+				if err != nil {
+					return nil, fmt.Errorf("failed to dial executor %s: %w", uri, err)
+				}
+				e = &endpoint{uri: uri, client: client}
+				endpoints[uri] = e
+				p.endpoints = append(p.endpoints, e)
+			}
+			pool.endpoints = append(pool.endpoints, e)
+		}
+		log.Infof("executor pool %s: %v", name, uris)
+		return pool, nil
+	}
+
+	defaultURIs := c.URIs
+	if len(defaultURIs) == 0 {
+		defaultURIs = []string{c.URI}
+	}
+	var err error
+	p.defaultPool, err = newPool(defaultPoolName, defaultURIs)
+	if err != nil {
+		return nil, err
+	}
+	for caller, uris := range map[metrics.CallerLabel][]string{
+		metrics.SequencerCallerLabel:    c.SequencerURIs,
+		metrics.SynchronizerCallerLabel: c.SynchronizerURIs,
+		metrics.RPCCallerLabel:          c.RPCURIs,
+	} {
+		if len(uris) == 0 {
+			continue
+		}
+		p.callerPools[caller], err = newPool(string(caller), uris)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ProcessBatch sends the batch to the least loaded executor of the caller, or to the pinned one. The requests that update the merkle
+// tree are not retried, the executor may have processed them before failing
+func (p *ClientPool) ProcessBatch(ctx context.Context, in *ProcessBatchRequest, opts ...grpc.CallOption) (*ProcessBatchResponse, error) {
+	var res *ProcessBatchResponse
+	err := p.send(ctx, in.UpdateMerkleTree == 0, func(client ExecutorServiceClient) error {
+		var err error
+		res, err = client.ProcessBatch(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+// GetFlushStatus gets the flush status from the least loaded executor of the caller, or from the pinned one
+func (p *ClientPool) GetFlushStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetFlushStatusResponse, error) {
+	var res *GetFlushStatusResponse
+	err := p.send(ctx, true, func(client ExecutorServiceClient) error {
+		var err error
+		res, err = client.GetFlushStatus(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+// poolFor returns the caller of the context and its pool of executors, or the default one if it has none
+func (p *ClientPool) poolFor(ctx context.Context) (metrics.CallerLabel, *endpointPool) {
+	caller, _ := ctx.Value(callerKey{}).(metrics.CallerLabel)
+	if pool, found := p.callerPools[caller]; found {
+		return caller, pool
+	}
+	return caller, p.defaultPool
+}
+
+// pin returns the executor all the requests of the caller are sent to. The first time it's the first executor
+// of the pool that gets its flush status, or the first one of the pool if none of them does
+func (p *ClientPool) pin(ctx context.Context, caller metrics.CallerLabel, pool *endpointPool) *endpoint {
+	p.pinnedMu.Lock()
+	defer p.pinnedMu.Unlock()
+	if e, found := p.pinned[caller]; found {
+		return e
+	}
+
+	timeout := p.cfg.HealthCheckInterval.Duration
+	if timeout <= 0 {
+		timeout = pinProbeTimeout
+	}
+	pinned := pool.endpoints[0]
+	for _, e := range pool.endpoints {
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err := e.client.GetFlushStatus(probeCtx, &emptypb.Empty{})
+		cancel()
+		if err == nil {
+			pinned = e
+			break
+		}
+		log.Warnf("executor %s can't be pinned for the %s requests: %v", e.uri, caller, err)
+	}
+	log.Infof("the %s requests are sent to the executor %s until it's restarted", caller, pinned.uri)
+	p.pinned[caller] = pinned
+	return pinned
+}
+
+// send sends the request to the executors of the caller, retrying it up to MaxRetries times on other executors
+// while it fails with a transient error, if the request can be retried. The requests of the pinned callers are
+// always sent to the same executor, so they are retried on it
+func (p *ClientPool) send(ctx context.Context, retryable bool, request func(client ExecutorServiceClient) error) error {
+	caller, pool := p.poolFor(ctx)
+	pick := pool.pick
+	if pinnedCallers[caller] {
+		pinned := p.pin(ctx, caller, pool)
+		pick = func(map[*endpoint]bool) *endpoint { return pinned }
+	}
+
+	tried := map[*endpoint]bool{}
+	for attempt := 0; ; attempt++ {
+		e := pick(tried)
+		tried[e] = true
+
+		atomic.AddInt64(&e.inFlight, 1)
+		err := request(e.client)
+		atomic.AddInt64(&e.inFlight, -1)
+		if err == nil || !isTransient(err) {
+			return err
+		}
+
+		// the executors are only marked as unhealthy if there are health checks to mark them as healthy again
+		if status.Code(err) == codes.Unavailable && p.cfg.HealthCheckInterval.Duration > 0 {
+			e.setHealthy(false)
+		}
+		if !retryable || attempt >= p.cfg.MaxRetries {
+			return err
+		}
+		log.Warnf("request to executor %s of the %s pool failed, retrying: %v", e.uri, pool.name, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.cfg.RetryDelay.Duration):
+		}
+	}
+}
+
+// isTransient returns whether the request failed for a reason that may not happen on a retry. The requests
+// failed because the executor is out of resources are not retried here, the callers already retry them after
+// waiting for the executor to recover, see Config.MaxResourceExhaustedAttempts
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// startHealthChecks checks periodically the health of the executors getting their flush status,
+// if the health checks are enabled
+func (p *ClientPool) startHealthChecks(ctx context.Context) {
+	if p.cfg.HealthCheckInterval.Duration <= 0 {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.cfg.HealthCheckInterval.Duration):
+				p.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+// checkHealth marks as healthy the executors that get their flush status within the HealthCheckInterval
+// and as unhealthy the ones that don't
+func (p *ClientPool) checkHealth(ctx context.Context) {
+	for _, e := range p.endpoints {
+		checkCtx, cancel := context.WithTimeout(ctx, p.cfg.HealthCheckInterval.Duration)
+		_, err := e.client.GetFlushStatus(checkCtx, &emptypb.Empty{})
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Debugf("executor %s health check failed: %v", e.uri, err)
+		}
+		e.setHealthy(err == nil)
+	}
+}
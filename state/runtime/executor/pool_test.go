@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/state/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeExecutorClient struct {
+	uri      string
+	requests int
+	flushID  uint64
+	err      error
+}
+
+func (c *fakeExecutorClient) ProcessBatch(ctx context.Context, in *ProcessBatchRequest, opts ...grpc.CallOption) (*ProcessBatchResponse, error) {
+	c.requests++
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.flushID++
+	return &ProcessBatchResponse{FlushId: c.flushID, ProverId: c.uri}, nil
+}
+
+func (c *fakeExecutorClient) GetFlushStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetFlushStatusResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &GetFlushStatusResponse{StoredFlushId: c.flushID, LastFlushId: c.flushID, ProverId: c.uri}, nil
+}
+
+func newTestClientPool(t *testing.T, cfg Config) (*ClientPool, map[string]*fakeExecutorClient) {
+	clients := map[string]*fakeExecutorClient{}
+	p, err := newClientPool(cfg, func(uri string) (ExecutorServiceClient, error) {
+		clients[uri] = &fakeExecutorClient{uri: uri}
+		return clients[uri], nil
+	})
+	require.NoError(t, err)
+	return p, clients
+}
+
+func endpointByURI(p *ClientPool, uri string) *endpoint {
+	for _, e := range p.endpoints {
+		if e.uri == uri {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestClientPoolRoutesByCaller(t *testing.T) {
+	p, clients := newTestClientPool(t, Config{
+		URIs:          []string{"shared1", "shared2"},
+		SequencerURIs: []string{"sequencer"},
+		RPCURIs:       []string{"rpc", "shared2"},
+	})
+	require.Len(t, clients, 4)
+
+	ctx := context.Background()
+	testCases := []struct {
+		name         string
+		ctx          context.Context
+		expectedURIs []string
+	}{
+		{"no caller", ctx, []string{"shared1", "shared2"}},
+		{"sequencer", WithCaller(ctx, metrics.SequencerCallerLabel), []string{"sequencer"}},
+		{"synchronizer without executors", WithCaller(ctx, metrics.SynchronizerCallerLabel), []string{"shared1", "shared2"}},
+		{"rpc", WithCaller(ctx, metrics.RPCCallerLabel), []string{"rpc", "shared2"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for i := 0; i < 4; i++ {
+				res, err := p.GetFlushStatus(testCase.ctx, &emptypb.Empty{})
+				require.NoError(t, err)
+				assert.Contains(t, testCase.expectedURIs, res.ProverId)
+			}
+		})
+	}
+}
+
+func TestClientPoolDefaultsToURI(t *testing.T) {
+	p, clients := newTestClientPool(t, Config{URI: "executor", RPCURIs: []string{"rpc"}})
+	require.Len(t, clients, 2)
+
+	_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, clients["executor"].requests)
+}
+
+func TestClientPoolPicksLeastLoadedHealthyExecutor(t *testing.T) {
+	p, clients := newTestClientPool(t, Config{URIs: []string{"executor1", "executor2", "executor3"}})
+
+	loads := map[string]int64{"executor1": 2, "executor2": 1, "executor3": 0}
+	for uri, inFlight := range loads {
+		endpointByURI(p, uri).inFlight = inFlight
+	}
+	endpointByURI(p, "executor3").setHealthy(false)
+
+	for i := 0; i < 3; i++ {
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, clients["executor1"].requests)
+	assert.Equal(t, 3, clients["executor2"].requests)
+	assert.Equal(t, 0, clients["executor3"].requests)
+}
+
+func TestClientPoolRetriesTransientErrors(t *testing.T) {
+	cfg := Config{
+		URIs:                []string{"executor1", "executor2"},
+		HealthCheckInterval: types.NewDuration(time.Second),
+		MaxRetries:          1,
+	}
+
+	t.Run("transient error is retried on another executor", func(t *testing.T) {
+		p, clients := newTestClientPool(t, cfg)
+		clients["executor1"].err = status.Error(codes.Unavailable, "connection refused")
+		endpointByURI(p, "executor2").inFlight = 1
+
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, clients["executor1"].requests)
+		assert.Equal(t, 1, clients["executor2"].requests)
+		assert.False(t, endpointByURI(p, "executor1").isHealthy())
+
+		p.checkHealth(context.Background())
+		assert.False(t, endpointByURI(p, "executor1").isHealthy())
+		clients["executor1"].err = nil
+		p.checkHealth(context.Background())
+		assert.True(t, endpointByURI(p, "executor1").isHealthy())
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		p, clients := newTestClientPool(t, cfg)
+		transientErr := status.Error(codes.Aborted, "aborted")
+		clients["executor1"].err = transientErr
+		clients["executor2"].err = transientErr
+
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+		assert.Equal(t, transientErr, err)
+		assert.Equal(t, 2, clients["executor1"].requests+clients["executor2"].requests)
+		assert.True(t, endpointByURI(p, "executor1").isHealthy())
+		assert.True(t, endpointByURI(p, "executor2").isHealthy())
+	})
+
+	t.Run("non transient error is not retried", func(t *testing.T) {
+		p, clients := newTestClientPool(t, cfg)
+		nonTransientErr := errors.New("invalid request")
+		clients["executor1"].err = nonTransientErr
+		clients["executor2"].err = nonTransientErr
+
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+		assert.Equal(t, nonTransientErr, err)
+		assert.Equal(t, 1, clients["executor1"].requests+clients["executor2"].requests)
+	})
+
+	t.Run("resource exhausted is left to the callers", func(t *testing.T) {
+		p, clients := newTestClientPool(t, cfg)
+		resourceExhaustedErr := status.Error(codes.ResourceExhausted, "busy")
+		clients["executor1"].err = resourceExhaustedErr
+		clients["executor2"].err = resourceExhaustedErr
+
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{})
+		assert.Equal(t, resourceExhaustedErr, err)
+		assert.Equal(t, 1, clients["executor1"].requests+clients["executor2"].requests)
+	})
+
+	t.Run("requests updating the merkle tree are not retried", func(t *testing.T) {
+		p, clients := newTestClientPool(t, cfg)
+		transientErr := status.Error(codes.Unavailable, "connection reset")
+		clients["executor1"].err = transientErr
+		clients["executor2"].err = transientErr
+
+		_, err := p.ProcessBatch(context.Background(), &ProcessBatchRequest{UpdateMerkleTree: 1})
+		assert.Equal(t, transientErr, err)
+		assert.Equal(t, 1, clients["executor1"].requests+clients["executor2"].requests)
+	})
+}
+
+func TestClientPoolPinsFlushIDConsumers(t *testing.T) {
+	p, clients := newTestClientPool(t, Config{
+		URIs:                []string{"shared1", "shared2"},
+		SequencerURIs:       []string{"sequencer1", "sequencer2"},
+		HealthCheckInterval: types.NewDuration(time.Second),
+		MaxRetries:          1,
+	})
+	// the first executor doesn't answer, so the sequencer is pinned to the second one
+	clients["sequencer1"].err = status.Error(codes.Unavailable, "connection refused")
+
+	sequencerCtx := WithCaller(context.Background(), metrics.SequencerCallerLabel)
+	for i := 0; i < 4; i++ {
+		res, err := p.ProcessBatch(sequencerCtx, &ProcessBatchRequest{UpdateMerkleTree: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "sequencer2", res.ProverId)
+
+		// the flush status is got from the executor that returned the flush id, even if it's the most loaded one
+		endpointByURI(p, "sequencer2").inFlight = 10
+		status, err := p.GetFlushStatus(sequencerCtx, &emptypb.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, "sequencer2", status.ProverId)
+		assert.Equal(t, res.FlushId, status.StoredFlushId)
+		endpointByURI(p, "sequencer2").inFlight = 0
+	}
+	assert.Equal(t, 0, clients["sequencer1"].requests)
+
+	// the pinned executor only changes on a restart, the requests fail meanwhile
+	clients["sequencer1"].err = nil
+	clients["sequencer2"].err = status.Error(codes.Unavailable, "connection refused")
+	_, err := p.ProcessBatch(sequencerCtx, &ProcessBatchRequest{UpdateMerkleTree: 1})
+	require.Error(t, err)
+	_, err = p.GetFlushStatus(sequencerCtx, &emptypb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, 0, clients["sequencer1"].requests)
+
+	// the synchronizer has no executors of its own, it's pinned to one of the default pool
+	synchronizerCtx := WithCaller(context.Background(), metrics.SynchronizerCallerLabel)
+	res, err := p.ProcessBatch(synchronizerCtx, &ProcessBatchRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		status, err := p.GetFlushStatus(synchronizerCtx, &emptypb.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, res.ProverId, status.ProverId)
+	}
+}
@@ -0,0 +1,128 @@
+package fakeexecutor
+
+import (
+	"math/big"
+
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakevm"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The costs of the counters are a rough approximation of the ones of the ROM of the prover, enough
+// for the batches to be closed by counters in a similar way, but not to check the counters limits
+const (
+	stepsPerTx           = 5000
+	stepsPerOpcode       = 30
+	stepsPerDataByte     = 10
+	keccaksPerTx         = 2
+	keccakBytesPerHash   = 136
+	poseidonsPerTx       = 10
+	poseidonsPerRead     = 30
+	poseidonsPerWrite    = 60
+	paddingBytesPerHash  = 56
+	binariesPerTx        = 100
+	arithmeticsPerTx     = 5
+	memAlignsPerMemoryOp = 1
+)
+
+var (
+	binaryOpcodes = map[fakevm.OpCode]bool{
+		fakevm.ADD: true, fakevm.SUB: true, fakevm.LT: true, fakevm.GT: true, fakevm.SLT: true, fakevm.SGT: true,
+		fakevm.EQ: true, fakevm.ISZERO: true, fakevm.AND: true, fakevm.OR: true, fakevm.XOR: true, fakevm.NOT: true,
+		fakevm.BYTE: true, fakevm.SHL: true, fakevm.SHR: true, fakevm.SAR: true, fakevm.SIGNEXTEND: true,
+	}
+	arithmeticOpcodes = map[fakevm.OpCode]bool{
+		fakevm.MUL: true, fakevm.DIV: true, fakevm.SDIV: true, fakevm.MOD: true, fakevm.SMOD: true,
+		fakevm.ADDMOD: true, fakevm.MULMOD: true, fakevm.EXP: true,
+	}
+	memoryOpcodes = map[fakevm.OpCode]bool{
+		fakevm.MLOAD: true, fakevm.MSTORE: true, fakevm.MSTORE8: true,
+	}
+)
+
+// counters counts the approximate zk counters used by a batch. It's a fakevm.EVMLogger to count the
+// opcodes executed, and the stateDB notifies it of the reads and writes of the state tree
+type counters struct {
+	steps            uint32
+	keccakHashes     uint32
+	poseidonHashes   uint32
+	poseidonPaddings uint32
+	memAligns        uint32
+	arithmetics      uint32
+	binaries         uint32
+
+	// hasGaspriceOpcode and hasBalanceOpcode are whether the tx being executed used those opcodes
+	hasGaspriceOpcode bool
+	hasBalanceOpcode  bool
+}
+
+func (c *counters) processTx(txDataLen int) {
+	c.steps += stepsPerTx + stepsPerDataByte*uint32(txDataLen)
+	c.keccakHashes += keccaksPerTx + uint32(txDataLen/keccakBytesPerHash)
+	c.poseidonHashes += poseidonsPerTx
+	c.binaries += binariesPerTx
+	c.arithmetics += arithmeticsPerTx
+}
+
+func (c *counters) readState() {
+	c.poseidonHashes += poseidonsPerRead
+}
+
+func (c *counters) writeState() {
+	c.poseidonHashes += poseidonsPerWrite
+}
+
+func (c *counters) loadCode(codeLen int) {
+	c.poseidonPaddings += 1 + uint32(codeLen/paddingBytesPerHash)
+}
+
+// CaptureTxStart does nothing
+func (c *counters) CaptureTxStart(gasLimit uint64) {}
+
+// CaptureTxEnd does nothing
+func (c *counters) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart does nothing
+func (c *counters) CaptureStart(env *fakevm.FakeEVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd does nothing
+func (c *counters) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+// CaptureEnter does nothing
+func (c *counters) CaptureEnter(typ fakevm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit does nothing
+func (c *counters) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState counts the opcode
+func (c *counters) CaptureState(pc uint64, op fakevm.OpCode, gas, cost uint64, scope *fakevm.ScopeContext, rData []byte, depth int, err error) {
+	c.steps += stepsPerOpcode
+	switch {
+	case op == fakevm.GASPRICE:
+		c.hasGaspriceOpcode = true
+	case op == fakevm.BALANCE:
+		c.hasBalanceOpcode = true
+	case binaryOpcodes[op]:
+		c.binaries++
+	case arithmeticOpcodes[op]:
+		c.arithmetics++
+	case memoryOpcodes[op]:
+		c.memAligns += memAlignsPerMemoryOp
+	case op == fakevm.KECCAK256 || op == fakevm.CREATE || op == fakevm.CREATE2:
+		// the size of the hashed data is on the stack, as second argument of the hash and third of the creates
+		sizePos := 1
+		if op != fakevm.KECCAK256 {
+			sizePos = 2
+		}
+		var size uint64
+		if stack := scope.Stack.Data(); len(stack) > sizePos {
+			size = stack[len(stack)-1-sizePos].Uint64()
+		}
+		c.keccakHashes += 1 + uint32(size/keccakBytesPerHash)
+	}
+}
+
+// CaptureFault does nothing
+func (c *counters) CaptureFault(pc uint64, op fakevm.OpCode, gas, cost uint64, scope *fakevm.ScopeContext, depth int, err error) {
+}
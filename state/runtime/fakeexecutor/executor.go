@@ -0,0 +1,408 @@
+package fakeexecutor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"os"
+
+	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree/hashdb"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakevm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// blockGasLimit is the value of the GASLIMIT opcode in the zkEVM
+	blockGasLimit = 1125899906842624
+	// effectivePercentageDenominator divides the effective percentage plus one to get the share of the
+	// gas price paid by a transaction
+	effectivePercentageDenominator = 256
+)
+
+var (
+	// systemAddress is the address whose storage keeps, as in the zkEVM, the number of the last l2 block
+	// in the slot 0 and the state root of each l2 block in a mapping in the slot 1
+	systemAddress         = common.HexToAddress("0x000000000000000000000000000000005ca1ab1e")
+	lastL2BlockNumberSlot = common.Hash{}
+	stateRootsSlot        = common.BigToHash(big.NewInt(1))
+)
+
+// Executor is a stand-in of the executor of the prover written in Go, to run the tests without the prover.
+// It decodes the transactions of the batch and executes them on the fakevm over the state of the state tree
+// of the hashdb, usually a HashDB, returning responses with approximate zk counters. Since the state tree
+// of the HashDB isn't a sparse merkle tree, the state roots aren't the ones of the zkEVM. The execution
+// traces, the out of counters errors and the test databases of the requests aren't supported
+type Executor struct {
+	hashDB hashdb.HashDBServiceClient
+	tree   *merkletree.StateTree
+}
+
+// NewExecutor creates an Executor over the state tree of the hashdb
+func NewExecutor(hashDB hashdb.HashDBServiceClient) *Executor {
+	return &Executor{
+		hashDB: hashDB,
+		tree:   merkletree.NewStateTree(hashDB),
+	}
+}
+
+// EnvVar is the environment variable that makes NewFromEnv return the fake executor
+const EnvVar = "FAKE_EXECUTOR"
+
+// NewFromEnv creates an Executor over a new HashDB when the FAKE_EXECUTOR environment variable is set, so the
+// tests run on the go executor instead of the prover. The state roots aren't the ones of the prover. It returns
+// false when the variable isn't set, and the prover has to be used
+func NewFromEnv() (hashdb.HashDBServiceClient, executor.ExecutorServiceClient, bool) {
+	if os.Getenv(EnvVar) == "" {
+		return nil, nil, false
+	}
+	hashDB := NewHashDB()
+	return hashDB, NewExecutor(hashDB), true
+}
+
+// ProcessBatch executes the transactions of the batch on the state of the old state root
+func (e *Executor) ProcessBatch(ctx context.Context, in *executor.ProcessBatchRequest, opts ...grpc.CallOption) (*executor.ProcessBatchResponse, error) {
+	if len(in.Db) > 0 || len(in.ContractsBytecode) > 0 {
+		return nil, status.Error(codes.Unimplemented, "loading test databases is not supported by the fake executor")
+	}
+	if in.TraceConfig != nil {
+		log.Warn("the fake executor doesn't support execution traces, the trace config is ignored")
+	}
+
+	counters := &counters{}
+	db := newStateDB(ctx, e.tree, in.OldStateRoot, in.ContextId, counters)
+	res := &executor.ProcessBatchResponse{
+		NewAccInputHash: calculateAccInputHash(in),
+		NewBatchNum:     in.OldBatchNum + 1,
+		Error:           executor.ExecutorError_EXECUTOR_ERROR_NO_ERROR,
+	}
+
+	txs, _, effectivePercentages, err := state.DecodeTxs(in.BatchL2Data, in.ForkId)
+	if err != nil {
+		log.Debugf("fake executor failed to decode the batch l2 data: %v", err)
+		res.Responses = []*executor.ProcessTransactionResponse{{
+			Error:     executor.RomError_ROM_ERROR_INVALID_RLP,
+			StateRoot: in.OldStateRoot,
+		}}
+	}
+	for i := range txs {
+		effectivePercentage := state.MaxEffectivePercentage
+		if i < len(effectivePercentages) {
+			effectivePercentage = effectivePercentages[i]
+		}
+		txRes, err := e.processTx(in, db, counters, &txs[i], uint32(i), effectivePercentage)
+		if err != nil {
+			return nil, err
+		}
+		// keep the state root of the transaction, the next transactions would drop it otherwise
+		if _, err := e.hashDB.SemiFlush(ctx, &hashdb.SemiFlushRequest{BatchUuid: in.ContextId, NewStateRoot: hex.EncodeToHex(db.root), Persistence: hashdb.Persistence_PERSISTENCE_DATABASE}); err != nil {
+			return nil, err
+		}
+		res.Responses = append(res.Responses, txRes)
+		res.CumulativeGasUsed += txRes.GasUsed
+	}
+
+	res.NewStateRoot = db.root
+	res.CntKeccakHashes = counters.keccakHashes
+	res.CntPoseidonHashes = counters.poseidonHashes
+	res.CntPoseidonPaddings = counters.poseidonPaddings
+	res.CntMemAligns = counters.memAligns
+	res.CntArithmetics = counters.arithmetics
+	res.CntBinaries = counters.binaries
+	res.CntSteps = counters.steps
+	res.ReadWriteAddresses = readWriteAddresses(db)
+
+	if in.UpdateMerkleTree == 1 {
+		flushRes, err := e.hashDB.Flush(ctx, &hashdb.FlushRequest{BatchUuid: in.ContextId, Persistence: hashdb.Persistence_PERSISTENCE_DATABASE})
+		if err != nil {
+			return nil, err
+		}
+		res.FlushId = flushRes.FlushId
+	}
+	flushStatus, err := e.hashDB.GetFlushStatus(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	res.StoredFlushId = flushStatus.StoredFlushId
+	res.ProverId = flushStatus.ProverId
+
+	return res, nil
+}
+
+// GetFlushStatus returns the flush status of the hashdb
+func (e *Executor) GetFlushStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*executor.GetFlushStatusResponse, error) {
+	flushStatus, err := e.hashDB.GetFlushStatus(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &executor.GetFlushStatusResponse{
+		StoredFlushId:         flushStatus.StoredFlushId,
+		StoringFlushId:        flushStatus.StoringFlushId,
+		LastFlushId:           flushStatus.LastFlushId,
+		PendingToFlushNodes:   flushStatus.PendingToFlushNodes,
+		PendingToFlushProgram: flushStatus.PendingToFlushProgram,
+		StoringNodes:          flushStatus.StoringNodes,
+		StoringProgram:        flushStatus.StoringProgram,
+		ProverId:              flushStatus.ProverId,
+	}, nil
+}
+
+// processTx executes the tx, in its own l2 block, and commits its changes to the state
+func (e *Executor) processTx(in *executor.ProcessBatchRequest, db *stateDB, counters *counters, tx *types.Transaction, txIndex uint32, effectivePercentage uint8) (*executor.ProcessTransactionResponse, error) {
+	rlpTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	counters.processTx(len(tx.Data()))
+	counters.hasGaspriceOpcode, counters.hasBalanceOpcode = false, false
+
+	effectiveGasPrice := new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(effectivePercentage)+1))
+	effectiveGasPrice.Div(effectiveGasPrice, big.NewInt(effectivePercentageDenominator))
+	res := &executor.ProcessTransactionResponse{
+		TxHash:              tx.Hash().Bytes(),
+		RlpTx:               rlpTx,
+		StateRoot:           db.root,
+		EffectiveGasPrice:   effectiveGasPrice.String(),
+		EffectivePercentage: uint32(effectivePercentage),
+	}
+
+	sender, romErr := e.checkIntrinsic(in, db, tx)
+	if romErr != executor.RomError_ROM_ERROR_NO_ERROR {
+		res.Error = romErr
+		return res, nil
+	}
+
+	// the l2 block of the tx is recorded in the storage of the system address before executing it
+	lastL2BlockNumber := db.GetState(systemAddress, lastL2BlockNumberSlot).Big().Uint64()
+	l2BlockNumber := lastL2BlockNumber + 1
+	db.SetState(systemAddress, lastL2BlockNumberSlot, common.BigToHash(new(big.Int).SetUint64(l2BlockNumber)))
+	db.SetState(systemAddress, stateRootSlot(lastL2BlockNumber), common.BytesToHash(db.root))
+
+	coinbase := common.HexToAddress(in.Coinbase)
+	chainConfig := newChainConfig(in.ChainId)
+	blockCtx := fakevm.BlockContext{
+		CanTransfer: canTransfer,
+		Transfer:    transfer,
+		GetHash: func(n uint64) common.Hash {
+			return db.GetState(systemAddress, stateRootSlot(n))
+		},
+		Coinbase:    coinbase,
+		GasLimit:    blockGasLimit,
+		BlockNumber: new(big.Int).SetUint64(l2BlockNumber),
+		Time:        in.EthTimestamp,
+		Difficulty:  big.NewInt(0),
+		BaseFee:     big.NewInt(0),
+	}
+	evm := fakevm.NewFakeEVM(blockCtx, fakevm.TxContext{Origin: sender, GasPrice: effectiveGasPrice}, db, chainConfig, fakevm.Config{Debug: true, Tracer: counters})
+	rules := chainConfig.Rules(blockCtx.BlockNumber, false, blockCtx.Time)
+	db.Prepare(rules, sender, coinbase, tx.To(), fakevm.ActivePrecompiles(rules), tx.AccessList())
+
+	intrinsicGas, err := intrinsicGas(tx)
+	if err != nil {
+		return nil, err
+	}
+	db.SubBalance(sender, new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), effectiveGasPrice))
+
+	var (
+		ret      []byte
+		gasLeft  uint64
+		vmErr    error
+		gasLimit = tx.Gas() - intrinsicGas
+	)
+	if tx.To() == nil {
+		var contractAddress common.Address
+		ret, contractAddress, gasLeft, vmErr = evm.Create(fakevm.AccountRef(sender), tx.Data(), gasLimit, tx.Value())
+		res.CreateAddress = crypto.CreateAddress(sender, tx.Nonce()).Hex()
+		if vmErr == nil {
+			res.CreateAddress = contractAddress.Hex()
+		}
+	} else {
+		db.SetNonce(sender, db.GetNonce(sender)+1)
+		ret, gasLeft, vmErr = evm.Call(fakevm.AccountRef(sender), *tx.To(), tx.Data(), gasLimit, tx.Value())
+	}
+
+	gasUsed := tx.Gas() - gasLeft
+	refund := db.GetRefund()
+	if maxRefund := gasUsed / params.RefundQuotient; refund > maxRefund {
+		refund = maxRefund
+	}
+	gasLeft += refund
+	gasUsed -= refund
+	db.AddBalance(sender, new(big.Int).Mul(new(big.Int).SetUint64(gasLeft), effectiveGasPrice))
+	db.AddBalance(coinbase, new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), effectiveGasPrice))
+
+	for i, l := range db.logs {
+		topics := make([][]byte, 0, len(l.Topics))
+		for _, topic := range l.Topics {
+			topics = append(topics, topic.Bytes())
+		}
+		res.Logs = append(res.Logs, &executor.Log{
+			Address:     l.Address.Hex(),
+			Topics:      topics,
+			Data:        l.Data,
+			BatchNumber: in.OldBatchNum + 1,
+			TxHash:      res.TxHash,
+			TxIndex:     txIndex,
+			Index:       uint32(i),
+		})
+	}
+	if err := db.commit(); err != nil {
+		return nil, err
+	}
+
+	res.ReturnValue = ret
+	res.GasLeft = gasLeft
+	res.GasUsed = gasUsed
+	res.GasRefunded = refund
+	res.Error = romError(vmErr)
+	res.StateRoot = db.root
+	res.HasGaspriceOpcode = boolToUint32(counters.hasGaspriceOpcode)
+	res.HasBalanceOpcode = boolToUint32(counters.hasBalanceOpcode)
+	return res, nil
+}
+
+// checkIntrinsic returns the sender of the tx, or the intrinsic error that prevents it from being executed
+func (e *Executor) checkIntrinsic(in *executor.ProcessBatchRequest, db *stateDB, tx *types.Transaction) (common.Address, executor.RomError) {
+	var sender common.Address
+	if in.From != "" {
+		// the unsigned txs are sent with the sender they have to be executed for
+		sender = common.HexToAddress(in.From)
+	} else {
+		if tx.Protected() && tx.ChainId().Uint64() != in.ChainId {
+			return sender, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_CHAIN_ID
+		}
+		var err error
+		if sender, err = state.GetSender(*tx); err != nil {
+			return sender, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_SIGNATURE
+		}
+	}
+
+	if tx.Nonce() != db.GetNonce(sender) {
+		return sender, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_NONCE
+	}
+	if gas, err := intrinsicGas(tx); err != nil || tx.Gas() < gas {
+		return sender, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_GAS_LIMIT
+	}
+	if db.GetBalance(sender).Cmp(tx.Cost()) < 0 {
+		return sender, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_BALANCE
+	}
+	return sender, executor.RomError_ROM_ERROR_NO_ERROR
+}
+
+func intrinsicGas(tx *types.Transaction) (uint64, error) {
+	return core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, true, false)
+}
+
+// newChainConfig returns the config of a chain with the rules of Berlin, the closest to the ones of the zkEVM
+func newChainConfig(chainID uint64) *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:             new(big.Int).SetUint64(chainID),
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(0),
+		ConstantinopleBlock: big.NewInt(0),
+		PetersburgBlock:     big.NewInt(0),
+		IstanbulBlock:       big.NewInt(0),
+		BerlinBlock:         big.NewInt(0),
+	}
+}
+
+func canTransfer(db fakevm.FakeDB, address common.Address, amount *big.Int) bool {
+	return db.GetBalance(address).Cmp(amount) >= 0
+}
+
+func transfer(db fakevm.FakeDB, sender, recipient common.Address, amount *big.Int) {
+	db.SubBalance(sender, amount)
+	db.AddBalance(recipient, amount)
+}
+
+// stateRootSlot returns the slot of the system address storage that keeps the state root of the l2 block
+func stateRootSlot(l2BlockNumber uint64) common.Hash {
+	return crypto.Keccak256Hash(common.BigToHash(new(big.Int).SetUint64(l2BlockNumber)).Bytes(), stateRootsSlot.Bytes())
+}
+
+// calculateAccInputHash returns the accumulated input hash of the batch, as calculated by the rollup contract
+func calculateAccInputHash(in *executor.ProcessBatchRequest) []byte {
+	timestamp := make([]byte, 8) //nolint:gomnd
+	binary.BigEndian.PutUint64(timestamp, in.EthTimestamp)
+	return crypto.Keccak256(
+		common.BytesToHash(in.OldAccInputHash).Bytes(),
+		crypto.Keccak256(in.BatchL2Data),
+		common.BytesToHash(in.GlobalExitRoot).Bytes(),
+		timestamp,
+		common.HexToAddress(in.Coinbase).Bytes(),
+	)
+}
+
+// readWriteAddresses returns the nonces and balances of the accounts read or written by the batch
+func readWriteAddresses(db *stateDB) map[string]*executor.InfoReadWrite {
+	addresses := map[string]*executor.InfoReadWrite{}
+	info := func(address common.Address) *executor.InfoReadWrite {
+		if _, found := addresses[address.Hex()]; !found {
+			addresses[address.Hex()] = &executor.InfoReadWrite{}
+		}
+		return addresses[address.Hex()]
+	}
+	for address, nonce := range db.committedNonces {
+		info(address).Nonce = new(big.Int).SetUint64(nonce).String()
+	}
+	for address, balance := range db.committedBalances {
+		info(address).Balance = balance.String()
+	}
+	return addresses
+}
+
+// romError returns the rom error the zkEVM returns for the error of the fakevm
+func romError(err error) executor.RomError {
+	var (
+		stackUnderflow *fakevm.ErrStackUnderflow
+		stackOverflow  *fakevm.ErrStackOverflow
+		invalidOpCode  *fakevm.ErrInvalidOpCode
+	)
+	switch {
+	case err == nil:
+		return executor.RomError_ROM_ERROR_NO_ERROR
+	case errors.Is(err, fakevm.ErrOutOfGas), errors.Is(err, fakevm.ErrCodeStoreOutOfGas), errors.Is(err, fakevm.ErrGasUintOverflow):
+		return executor.RomError_ROM_ERROR_OUT_OF_GAS
+	case errors.Is(err, fakevm.ErrContractAddressCollision):
+		return executor.RomError_ROM_ERROR_CONTRACT_ADDRESS_COLLISION
+	case errors.Is(err, fakevm.ErrMaxCodeSizeExceeded), errors.Is(err, fakevm.ErrMaxInitCodeSizeExceeded):
+		return executor.RomError_ROM_ERROR_MAX_CODE_SIZE_EXCEEDED
+	case errors.Is(err, fakevm.ErrInvalidJump):
+		return executor.RomError_ROM_ERROR_INVALID_JUMP
+	case errors.Is(err, fakevm.ErrWriteProtection):
+		return executor.RomError_ROM_ERROR_INVALID_STATIC
+	case errors.Is(err, fakevm.ErrInvalidCode):
+		return executor.RomError_ROM_ERROR_INVALID_BYTECODE_STARTS_EF
+	case errors.As(err, &stackUnderflow):
+		return executor.RomError_ROM_ERROR_STACK_UNDERFLOW
+	case errors.As(err, &stackOverflow):
+		return executor.RomError_ROM_ERROR_STACK_OVERFLOW
+	case errors.As(err, &invalidOpCode):
+		return executor.RomError_ROM_ERROR_INVALID_OPCODE
+	default:
+		return executor.RomError_ROM_ERROR_EXECUTION_REVERTED
+	}
+}
+
+func boolToUint32(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
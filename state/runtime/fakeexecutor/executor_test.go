@@ -0,0 +1,292 @@
+package fakeexecutor_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree/hashdb"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakeexecutor"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	chainID = 1000
+	forkID  = 5
+	// senderPvtKey is the private key of 0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D
+	senderPvtKey = "28b2b0318721be8c8339199172cd7cc8f5e273800a35616ec893083a4b32c02e"
+)
+
+var (
+	coinbase  = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	recipient = common.HexToAddress("0x4d5Cf5032B2a844602278b01199ED191A86c93ff")
+	gasPrice  = big.NewInt(1000000000)
+	// initCode stores 42 in the slot 0, emits a log and deploys runtimeCode
+	initCode = hex.MustDecodeHex("0x602a60005560006000a0600b601660003960" + "0b6000f3" + runtimeCodeHex)
+	// runtimeCode returns the value of the slot 0
+	runtimeCode    = hex.MustDecodeHex("0x" + runtimeCodeHex)
+	runtimeCodeHex = "60005460005260206000f3"
+	// revertingInitCode stores 42 in the slot 0 and reverts
+	revertingInitCode = hex.MustDecodeHex("0x602a60005560006000fd")
+)
+
+type testEnv struct {
+	ctx      context.Context
+	executor *fakeexecutor.Executor
+	tree     *merkletree.StateTree
+	sender   common.Address
+	signer   func(tx *types.Transaction) *types.Transaction
+	root     []byte
+}
+
+func newTestEnv(t *testing.T, senderBalance *big.Int) *testEnv {
+	ctx := context.Background()
+	hashDB := fakeexecutor.NewHashDB()
+	tree := merkletree.NewStateTree(hashDB)
+
+	privateKey, err := crypto.HexToECDSA(senderPvtKey)
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(privateKey.PublicKey)
+	root, _, err := tree.SetBalance(ctx, sender, senderBalance, common.Hash{}.Bytes(), "")
+	require.NoError(t, err)
+
+	return &testEnv{
+		ctx:      ctx,
+		executor: fakeexecutor.NewExecutor(hashDB),
+		tree:     tree,
+		sender:   sender,
+		signer: func(tx *types.Transaction) *types.Transaction {
+			signedTx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(chainID)), privateKey)
+			require.NoError(t, err)
+			return signedTx
+		},
+		root: root,
+	}
+}
+
+func (env *testEnv) processBatch(t *testing.T, txs []types.Transaction, from string) *executor.ProcessBatchResponse {
+	effectivePercentages := make([]uint8, len(txs))
+	for i := range txs {
+		effectivePercentages[i] = state.MaxEffectivePercentage
+	}
+	batchL2Data, err := state.EncodeTransactions(txs, effectivePercentages, forkID)
+	require.NoError(t, err)
+
+	res, err := env.executor.ProcessBatch(env.ctx, &executor.ProcessBatchRequest{
+		OldStateRoot:     env.root,
+		OldBatchNum:      1,
+		ChainId:          chainID,
+		ForkId:           forkID,
+		BatchL2Data:      batchL2Data,
+		EthTimestamp:     1700000000,
+		Coinbase:         coinbase.String(),
+		UpdateMerkleTree: 1,
+		From:             from,
+	})
+	require.NoError(t, err)
+	require.Equal(t, executor.ExecutorError_EXECUTOR_ERROR_NO_ERROR, res.Error)
+	require.Len(t, res.Responses, len(txs))
+	return res
+}
+
+func TestProcessBatchTransfers(t *testing.T) {
+	env := newTestEnv(t, big.NewInt(1e18))
+	value := big.NewInt(1000)
+	txs := []types.Transaction{
+		*env.signer(types.NewTransaction(0, recipient, value, 21000, gasPrice, nil)),
+		*env.signer(types.NewTransaction(1, recipient, value, 21000, gasPrice, nil)),
+	}
+
+	res := env.processBatch(t, txs, "")
+	for i, txRes := range res.Responses {
+		assert.Equal(t, executor.RomError_ROM_ERROR_NO_ERROR, txRes.Error)
+		assert.Equal(t, txs[i].Hash().Bytes(), txRes.TxHash)
+		assert.Equal(t, uint64(21000), txRes.GasUsed)
+	}
+	assert.Equal(t, uint64(42000), res.CumulativeGasUsed)
+	assert.Equal(t, res.Responses[1].StateRoot, res.NewStateRoot)
+	assert.NotZero(t, res.CntSteps)
+	assert.NotZero(t, res.CntPoseidonHashes)
+	assert.Equal(t, uint64(1), res.FlushId)
+
+	fee := new(big.Int).Mul(big.NewInt(42000), gasPrice)
+	balance, err := env.tree.GetBalance(env.ctx, recipient, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2000), balance)
+	balance, err = env.tree.GetBalance(env.ctx, coinbase, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, fee, balance)
+	balance, err = env.tree.GetBalance(env.ctx, env.sender, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Sub(big.NewInt(1e18), new(big.Int).Add(fee, big.NewInt(2000))), balance)
+	nonce, err := env.tree.GetNonce(env.ctx, env.sender, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce.Uint64())
+
+	senderInfo := res.ReadWriteAddresses[env.sender.Hex()]
+	require.NotNil(t, senderInfo)
+	assert.Equal(t, "2", senderInfo.Nonce)
+	assert.Equal(t, balance.String(), senderInfo.Balance)
+
+	// the responses of the executor can be converted by the state
+	st := state.NewState(state.Config{}, nil, env.executor, env.tree, nil)
+	converted, err := st.TestConvertToProcessBatchResponse(res)
+	require.NoError(t, err)
+	assert.Equal(t, txs[1].Hash(), converted.Responses[1].Tx.Hash())
+}
+
+func TestProcessBatchIntrinsicErrors(t *testing.T) {
+	env := newTestEnv(t, big.NewInt(1e15))
+	txs := []types.Transaction{
+		*env.signer(types.NewTransaction(1, recipient, big.NewInt(1), 21000, gasPrice, nil)),
+		*env.signer(types.NewTransaction(0, recipient, big.NewInt(1), 20000, gasPrice, nil)),
+		*env.signer(types.NewTransaction(0, recipient, big.NewInt(1e15), 21000, gasPrice, nil)),
+	}
+
+	res := env.processBatch(t, txs, "")
+	assert.Equal(t, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_NONCE, res.Responses[0].Error)
+	assert.Equal(t, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_GAS_LIMIT, res.Responses[1].Error)
+	assert.Equal(t, executor.RomError_ROM_ERROR_INTRINSIC_INVALID_BALANCE, res.Responses[2].Error)
+	assert.Equal(t, env.root, res.NewStateRoot)
+	assert.Zero(t, res.CumulativeGasUsed)
+}
+
+func TestProcessBatchContracts(t *testing.T) {
+	env := newTestEnv(t, big.NewInt(1e18))
+
+	res := env.processBatch(t, []types.Transaction{*env.signer(types.NewContractCreation(0, big.NewInt(0), 200000, gasPrice, initCode))}, "")
+	deployRes := res.Responses[0]
+	require.Equal(t, executor.RomError_ROM_ERROR_NO_ERROR, deployRes.Error)
+	contract := crypto.CreateAddress(env.sender, 0)
+	assert.Equal(t, contract.Hex(), deployRes.CreateAddress)
+	require.Len(t, deployRes.Logs, 1)
+	assert.Equal(t, contract.Hex(), deployRes.Logs[0].Address)
+	assert.Equal(t, uint64(2), deployRes.Logs[0].BatchNumber)
+
+	code, err := env.tree.GetCode(env.ctx, contract, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, runtimeCode, code)
+	value, err := env.tree.GetStorageAt(env.ctx, contract, big.NewInt(0), res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), value)
+
+	// call the contract with an unsigned tx, as the RPC does
+	env.root = res.NewStateRoot
+	unsignedTx := types.NewTransaction(1, contract, big.NewInt(0), 100000, big.NewInt(0), nil)
+	batchL2Data, err := state.EncodeUnsignedTransaction(*unsignedTx, chainID, nil, forkID)
+	require.NoError(t, err)
+	callRes, err := env.executor.ProcessBatch(env.ctx, &executor.ProcessBatchRequest{
+		OldStateRoot: env.root,
+		ChainId:      chainID,
+		ForkId:       forkID,
+		BatchL2Data:  batchL2Data,
+		Coinbase:     coinbase.String(),
+		From:         env.sender.String(),
+	})
+	require.NoError(t, err)
+	require.Len(t, callRes.Responses, 1)
+	assert.Equal(t, executor.RomError_ROM_ERROR_NO_ERROR, callRes.Responses[0].Error)
+	assert.Equal(t, common.BigToHash(big.NewInt(42)).Bytes(), callRes.Responses[0].ReturnValue)
+
+	// a reverted deployment doesn't change the storage, but the sender pays for it
+	res = env.processBatch(t, []types.Transaction{*env.signer(types.NewContractCreation(1, big.NewInt(0), 200000, gasPrice, revertingInitCode))}, "")
+	assert.Equal(t, executor.RomError_ROM_ERROR_EXECUTION_REVERTED, res.Responses[0].Error)
+	assert.NotZero(t, res.Responses[0].GasUsed)
+	value, err = env.tree.GetStorageAt(env.ctx, crypto.CreateAddress(env.sender, 1), big.NewInt(0), res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Zero(t, value.Sign())
+	nonce, err := env.tree.GetNonce(env.ctx, env.sender, res.NewStateRoot)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce.Uint64())
+}
+
+func TestProcessBatchInvalidRLP(t *testing.T) {
+	env := newTestEnv(t, big.NewInt(1e18))
+	res, err := env.executor.ProcessBatch(env.ctx, &executor.ProcessBatchRequest{
+		OldStateRoot: env.root,
+		ChainId:      chainID,
+		ForkId:       forkID,
+		BatchL2Data:  []byte{0xf8, 0x01},
+		Coinbase:     coinbase.String(),
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Responses, 1)
+	assert.Equal(t, executor.RomError_ROM_ERROR_INVALID_RLP, res.Responses[0].Error)
+	assert.Equal(t, env.root, res.NewStateRoot)
+}
+
+func TestHashDBRootsDependOnTheStateOnly(t *testing.T) {
+	ctx := context.Background()
+	hashDB := fakeexecutor.NewHashDB()
+	set := func(root *hashdb.Fea, key uint64, value string) *hashdb.Fea {
+		res, err := hashDB.Set(ctx, &hashdb.SetRequest{OldRoot: root, Key: &hashdb.Fea{Fe0: key}, Value: value})
+		require.NoError(t, err)
+		return res.NewRoot
+	}
+	zero := &hashdb.Fea{}
+
+	root1 := set(set(zero, 1, "a"), 2, "b")
+	root2 := set(set(zero, 2, "b"), 1, "a")
+	assert.Equal(t, root1.String(), root2.String())
+	assert.Equal(t, zero.String(), set(set(root1, 1, "0"), 2, "0").String())
+
+	res, err := hashDB.Get(ctx, &hashdb.GetRequest{Root: root1, Key: &hashdb.Fea{Fe0: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, "b", res.Value)
+	res, err = hashDB.Get(ctx, &hashdb.GetRequest{Root: zero, Key: &hashdb.Fea{Fe0: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, "0", res.Value)
+}
+
+func TestHashDBDropsTheIntermediateRootsOfTheBatch(t *testing.T) {
+	ctx := context.Background()
+	hashDB := fakeexecutor.NewHashDB()
+	set := func(root *hashdb.Fea, key uint64, value string) *hashdb.Fea {
+		res, err := hashDB.Set(ctx, &hashdb.SetRequest{OldRoot: root, Key: &hashdb.Fea{Fe0: key}, Value: value, BatchUuid: "batch"})
+		require.NoError(t, err)
+		return res.NewRoot
+	}
+	get := func(root *hashdb.Fea, key uint64) (string, error) {
+		res, err := hashDB.Get(ctx, &hashdb.GetRequest{Root: root, Key: &hashdb.Fea{Fe0: key}})
+		if err != nil {
+			return "", err
+		}
+		return res.Value, nil
+	}
+
+	intermediate := set(&hashdb.Fea{}, 1, "a")
+	txRoot := set(intermediate, 2, "b")
+	_, err := hashDB.SemiFlush(ctx, &hashdb.SemiFlushRequest{BatchUuid: "batch"})
+	require.NoError(t, err)
+	_, err = get(intermediate, 1)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// the roots of the following sets are on top of the semi flushed one, which is kept
+	root := txRoot
+	for i := uint64(0); i < 200; i++ {
+		root = set(root, 3+i, "c")
+		_, err = hashDB.Flush(ctx, &hashdb.FlushRequest{BatchUuid: "batch"})
+		require.NoError(t, err)
+	}
+	value, err := get(txRoot, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "b", value)
+	value, err = get(txRoot, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "0", value)
+	for _, key := range []uint64{1, 3, 202} {
+		value, err = get(root, key)
+		require.NoError(t, err)
+		assert.NotEqual(t, "0", value)
+	}
+}
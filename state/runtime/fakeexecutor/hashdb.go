@@ -0,0 +1,304 @@
+package fakeexecutor
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree/hashdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	proverID = "fakeexecutor"
+	feaLimbs = 4
+	limbBits = 64
+)
+
+// rootModulus is the modulus of the root arithmetic, roots are 256 bits numbers
+var rootModulus = new(big.Int).Lsh(big.NewInt(1), feaLimbs*limbBits)
+
+type fea [feaLimbs]uint64
+
+// maxStateDepth is the number of diffs a state can be on top of before its values are copied into a
+// state of its own, bounding the parents a read walks through
+const maxStateDepth = 64
+
+// HashDB is an in memory stand-in of the hashdb service of the prover. Instead of a sparse merkle tree it
+// keeps the key-value state by root, and the root of a state is the sum of the hashes of its key-values,
+// so the same state always has the same root, but it's not the one of the sparse merkle tree.
+// Each state only keeps the values changed on top of its parent state. The sets of a batch change its
+// open state in place, dropping the intermediate roots, until the batch is flushed or semi flushed
+type HashDB struct {
+	mu       sync.RWMutex
+	states   map[fea]*hashDBState
+	open     map[string]map[fea]bool
+	programs map[fea][]byte
+	flushID  uint64
+}
+
+// hashDBState is a state of the HashDB, the values changed on top of its parent, zero if deleted
+type hashDBState struct {
+	parent *hashDBState
+	values map[fea]*big.Int
+	depth  int
+	batch  string
+}
+
+// get gets the value of the key in the state, nil if it's not set
+func (s *hashDBState) get(key fea) *big.Int {
+	for ; s != nil; s = s.parent {
+		if value, found := s.values[key]; found {
+			return value
+		}
+	}
+	return nil
+}
+
+// child returns a new state on top of the state, opened by the batch
+func (s *hashDBState) child(batch string) *hashDBState {
+	if s.depth < maxStateDepth {
+		return &hashDBState{parent: s, values: map[fea]*big.Int{}, depth: s.depth + 1, batch: batch}
+	}
+	values := map[fea]*big.Int{}
+	for p := s; p != nil; p = p.parent {
+		for key, value := range p.values {
+			if _, found := values[key]; !found {
+				values[key] = value
+			}
+		}
+	}
+	for key, value := range values {
+		if value.Sign() == 0 {
+			delete(values, key)
+		}
+	}
+	return &hashDBState{values: values, batch: batch}
+}
+
+// NewHashDB creates an empty HashDB, whose root is zero
+func NewHashDB() *HashDB {
+	return &HashDB{
+		states:   map[fea]*hashDBState{{}: {values: map[fea]*big.Int{}}},
+		open:     map[string]map[fea]bool{},
+		programs: map[fea][]byte{},
+	}
+}
+
+// Set sets the value of the key in the state of the old root, returning the root of the new state.
+// When the old root is the open state of the batch the state is changed in place and the old root dropped
+func (db *HashDB) Set(ctx context.Context, in *hashdb.SetRequest, opts ...grpc.CallOption) (*hashdb.SetResponse, error) {
+	oldRoot, key := toFea(in.OldRoot), toFea(in.Key)
+	value, ok := new(big.Int).SetString(in.Value, hex.Base)
+	if !ok || value.Sign() < 0 || value.BitLen() > feaLimbs*limbBits {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid value %q", in.Value)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	state, found := db.states[oldRoot]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "root %v not found", oldRoot)
+	}
+
+	oldValue := state.get(key)
+	if oldValue == nil {
+		oldValue = big.NewInt(0)
+	}
+	newRoot := oldRoot
+	if oldValue.Cmp(value) != 0 {
+		root := scalarFromFea(oldRoot)
+		root.Sub(root, entryHash(key, oldValue))
+		root.Add(root, entryHash(key, value))
+		newRoot = feaFromScalar(root.Mod(root, rootModulus))
+
+		inPlace := in.BatchUuid != "" && state.batch == in.BatchUuid
+		if inPlace {
+			delete(db.states, oldRoot)
+			delete(db.open[in.BatchUuid], oldRoot)
+		}
+		if _, found := db.states[newRoot]; !found {
+			if !inPlace {
+				// the open state of another batch can't change under its child anymore
+				if state.batch != "" {
+					delete(db.open[state.batch], oldRoot)
+					state.batch = ""
+				}
+				state = state.child(in.BatchUuid)
+			}
+			state.values[key] = value
+			db.states[newRoot] = state
+			if state.batch != "" {
+				if db.open[state.batch] == nil {
+					db.open[state.batch] = map[fea]bool{}
+				}
+				db.open[state.batch][newRoot] = true
+			}
+		}
+	}
+
+	return &hashdb.SetResponse{
+		OldRoot:  in.OldRoot,
+		NewRoot:  toProtoFea(newRoot),
+		Key:      in.Key,
+		OldValue: oldValue.Text(hex.Base),
+		NewValue: value.Text(hex.Base),
+		Result:   &hashdb.ResultCode{Code: hashdb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// Get gets the value of the key in the state of the root, zero if it's not set
+func (db *HashDB) Get(ctx context.Context, in *hashdb.GetRequest, opts ...grpc.CallOption) (*hashdb.GetResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	state, found := db.states[toFea(in.Root)]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "root %v not found", toFea(in.Root))
+	}
+
+	value := state.get(toFea(in.Key))
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return &hashdb.GetResponse{
+		Root:   in.Root,
+		Key:    in.Key,
+		Value:  value.Text(hex.Base),
+		Result: &hashdb.ResultCode{Code: hashdb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// SetProgram stores the program by its key
+func (db *HashDB) SetProgram(ctx context.Context, in *hashdb.SetProgramRequest, opts ...grpc.CallOption) (*hashdb.SetProgramResponse, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.programs[toFea(in.Key)] = in.Data
+	return &hashdb.SetProgramResponse{Result: &hashdb.ResultCode{Code: hashdb.ResultCode_CODE_SUCCESS}}, nil
+}
+
+// GetProgram gets the program stored by its key, empty if there is none
+func (db *HashDB) GetProgram(ctx context.Context, in *hashdb.GetProgramRequest, opts ...grpc.CallOption) (*hashdb.GetProgramResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return &hashdb.GetProgramResponse{
+		Data:   db.programs[toFea(in.Key)],
+		Result: &hashdb.ResultCode{Code: hashdb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// LoadDB is not supported, the nodes of the sparse merkle tree have no meaning in the HashDB
+func (db *HashDB) LoadDB(ctx context.Context, in *hashdb.LoadDBRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "loading merkle tree nodes is not supported by the fake hashdb")
+}
+
+// LoadProgramDB stores the programs by their hex encoded keys
+func (db *HashDB) LoadProgramDB(ctx context.Context, in *hashdb.LoadProgramDBRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for key, data := range in.InputProgramDb {
+		k, ok := new(big.Int).SetString(strings.TrimPrefix(key, "0x"), hex.Base)
+		if !ok || k.Sign() < 0 || k.BitLen() > feaLimbs*limbBits {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid program key %q", key)
+		}
+		db.programs[feaFromScalar(k)] = data
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Flush keeps the roots of the batch and assigns a new flush id, which is stored right away since
+// everything is in memory
+func (db *HashDB) Flush(ctx context.Context, in *hashdb.FlushRequest, opts ...grpc.CallOption) (*hashdb.FlushResponse, error) {
+	flushID := db.flush(in.BatchUuid)
+	return &hashdb.FlushResponse{
+		FlushId:       flushID,
+		StoredFlushId: flushID,
+		Result:        &hashdb.ResultCode{Code: hashdb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// SemiFlush keeps the roots of the batch, so the next sets of the batch don't drop them
+func (db *HashDB) SemiFlush(ctx context.Context, in *hashdb.SemiFlushRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.seal(in.BatchUuid)
+	return &emptypb.Empty{}, nil
+}
+
+// GetFlushStatus returns the last flush id as the stored one
+func (db *HashDB) GetFlushStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*hashdb.GetFlushStatusResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return &hashdb.GetFlushStatusResponse{
+		StoredFlushId: db.flushID,
+		LastFlushId:   db.flushID,
+		ProverId:      proverID,
+	}, nil
+}
+
+// GetFlushData is not supported, there is no data to flush to a database
+func (db *HashDB) GetFlushData(ctx context.Context, in *hashdb.GetFlushDataRequest, opts ...grpc.CallOption) (*hashdb.GetFlushDataResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "getting the flush data is not supported by the fake hashdb")
+}
+
+func (db *HashDB) flush(batch string) uint64 {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.seal(batch)
+	db.flushID++
+	return db.flushID
+}
+
+// seal closes the open states of the batch, the caller must hold the lock
+func (db *HashDB) seal(batch string) {
+	for root := range db.open[batch] {
+		db.states[root].batch = ""
+	}
+	delete(db.open, batch)
+}
+
+// entryHash returns the hash a key-value contributes with to the root of the state
+func entryHash(key fea, value *big.Int) *big.Int {
+	if value.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	k := scalarFromFea(key)
+	return new(big.Int).SetBytes(crypto.Keccak256(k.FillBytes(make([]byte, 32)), value.FillBytes(make([]byte, 32)))) //nolint:gomnd
+}
+
+func toFea(f *hashdb.Fea) fea {
+	if f == nil {
+		return fea{}
+	}
+	return fea{f.Fe0, f.Fe1, f.Fe2, f.Fe3}
+}
+
+func toProtoFea(f fea) *hashdb.Fea {
+	return &hashdb.Fea{Fe0: f[0], Fe1: f[1], Fe2: f[2], Fe3: f[3]}
+}
+
+// scalarFromFea converts the field elements into a scalar, the first one being the least significant
+func scalarFromFea(f fea) *big.Int {
+	s := new(big.Int)
+	for i := feaLimbs - 1; i >= 0; i-- {
+		s.Lsh(s, limbBits)
+		s.Or(s, new(big.Int).SetUint64(f[i]))
+	}
+	return s
+}
+
+// feaFromScalar converts the scalar into field elements, the first one being the least significant
+func feaFromScalar(s *big.Int) fea {
+	var f fea
+	b := s.FillBytes(make([]byte, feaLimbs*limbBits/8)) //nolint:gomnd
+	for i := 0; i < feaLimbs; i++ {
+		end := len(b) - i*limbBits/8
+		f[i] = new(big.Int).SetBytes(b[end-limbBits/8 : end]).Uint64()
+	}
+	return f
+}
@@ -0,0 +1,489 @@
+package fakeexecutor
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type storageKey struct {
+	address common.Address
+	slot    common.Hash
+}
+
+// stateDB is the fakevm.FakeDB the transactions are executed on. It reads the state of the root from the
+// state tree, keeps the changes in memory, undoing them when the execution reverts, and writes them to the
+// state tree when a transaction is committed
+type stateDB struct {
+	ctx  context.Context
+	tree *merkletree.StateTree
+	root []byte
+	uuid string
+	// err is the first error reading from or writing to the state tree
+	err error
+
+	// the values in the state tree of the root, loaded on demand
+	committedBalances map[common.Address]*big.Int
+	committedNonces   map[common.Address]uint64
+	committedCodes    map[common.Address][]byte
+	committedStorage  map[storageKey]common.Hash
+
+	// the values changed by the transaction being executed
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	codes    map[common.Address][]byte
+	storage  map[storageKey]common.Hash
+
+	transientStorage map[storageKey]common.Hash
+	suicided         map[common.Address]bool
+	accessedAccounts map[common.Address]bool
+	accessedSlots    map[storageKey]bool
+	refund           uint64
+	logs             []*types.Log
+
+	// journal has the functions undoing the changes, in the order they were made
+	journal []func()
+	// counters are notified of the reads and writes of the state tree
+	counters *counters
+}
+
+func newStateDB(ctx context.Context, tree *merkletree.StateTree, root []byte, uuid string, counters *counters) *stateDB {
+	s := &stateDB{
+		ctx:               ctx,
+		tree:              tree,
+		root:              root,
+		uuid:              uuid,
+		committedBalances: map[common.Address]*big.Int{},
+		committedNonces:   map[common.Address]uint64{},
+		committedCodes:    map[common.Address][]byte{},
+		committedStorage:  map[storageKey]common.Hash{},
+		counters:          counters,
+	}
+	s.reset()
+	return s
+}
+
+// reset discards the changes of the transaction being executed
+func (s *stateDB) reset() {
+	s.balances = map[common.Address]*big.Int{}
+	s.nonces = map[common.Address]uint64{}
+	s.codes = map[common.Address][]byte{}
+	s.storage = map[storageKey]common.Hash{}
+	s.transientStorage = map[storageKey]common.Hash{}
+	s.suicided = map[common.Address]bool{}
+	s.accessedAccounts = map[common.Address]bool{}
+	s.accessedSlots = map[storageKey]bool{}
+	s.refund = 0
+	s.logs = nil
+	s.journal = nil
+}
+
+func (s *stateDB) setErr(err error) {
+	if s.err == nil && err != nil {
+		s.err = err
+	}
+}
+
+// SetStateRoot sets the root the state is read from, discarding the changes of the transaction being executed
+func (s *stateDB) SetStateRoot(stateRoot []byte) {
+	s.root = stateRoot
+	s.committedBalances = map[common.Address]*big.Int{}
+	s.committedNonces = map[common.Address]uint64{}
+	s.committedCodes = map[common.Address][]byte{}
+	s.committedStorage = map[storageKey]common.Hash{}
+	s.reset()
+}
+
+// CreateAccount does nothing, the accounts exist as soon as any of their values isn't zero
+func (s *stateDB) CreateAccount(common.Address) {}
+
+func (s *stateDB) committedBalance(address common.Address) *big.Int {
+	if balance, found := s.committedBalances[address]; found {
+		return balance
+	}
+	s.counters.readState()
+	balance, err := s.tree.GetBalance(s.ctx, address, s.root)
+	s.setErr(err)
+	if balance == nil {
+		balance = big.NewInt(0)
+	}
+	s.committedBalances[address] = balance
+	return balance
+}
+
+// GetBalance returns the balance of the address
+func (s *stateDB) GetBalance(address common.Address) *big.Int {
+	if balance, found := s.balances[address]; found {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int).Set(s.committedBalance(address))
+}
+
+func (s *stateDB) setBalance(address common.Address, balance *big.Int) {
+	prev, found := s.balances[address]
+	s.journal = append(s.journal, func() {
+		if found {
+			s.balances[address] = prev
+		} else {
+			delete(s.balances, address)
+		}
+	})
+	s.balances[address] = balance
+}
+
+// SubBalance subtracts the amount from the balance of the address
+func (s *stateDB) SubBalance(address common.Address, amount *big.Int) {
+	s.setBalance(address, new(big.Int).Sub(s.GetBalance(address), amount))
+}
+
+// AddBalance adds the amount to the balance of the address
+func (s *stateDB) AddBalance(address common.Address, amount *big.Int) {
+	s.setBalance(address, new(big.Int).Add(s.GetBalance(address), amount))
+}
+
+func (s *stateDB) committedNonce(address common.Address) uint64 {
+	if nonce, found := s.committedNonces[address]; found {
+		return nonce
+	}
+	s.counters.readState()
+	nonce, err := s.tree.GetNonce(s.ctx, address, s.root)
+	s.setErr(err)
+	var n uint64
+	if nonce != nil {
+		n = nonce.Uint64()
+	}
+	s.committedNonces[address] = n
+	return n
+}
+
+// GetNonce returns the nonce of the address
+func (s *stateDB) GetNonce(address common.Address) uint64 {
+	if nonce, found := s.nonces[address]; found {
+		return nonce
+	}
+	return s.committedNonce(address)
+}
+
+// SetNonce sets the nonce of the address
+func (s *stateDB) SetNonce(address common.Address, nonce uint64) {
+	prev, found := s.nonces[address]
+	s.journal = append(s.journal, func() {
+		if found {
+			s.nonces[address] = prev
+		} else {
+			delete(s.nonces, address)
+		}
+	})
+	s.nonces[address] = nonce
+}
+
+func (s *stateDB) committedCode(address common.Address) []byte {
+	if code, found := s.committedCodes[address]; found {
+		return code
+	}
+	s.counters.readState()
+	code, err := s.tree.GetCode(s.ctx, address, s.root)
+	s.setErr(err)
+	s.counters.loadCode(len(code))
+	s.committedCodes[address] = code
+	return code
+}
+
+// GetCode returns the code of the address
+func (s *stateDB) GetCode(address common.Address) []byte {
+	if code, found := s.codes[address]; found {
+		return code
+	}
+	return s.committedCode(address)
+}
+
+// SetCode sets the code of the address
+func (s *stateDB) SetCode(address common.Address, code []byte) {
+	prev, found := s.codes[address]
+	s.journal = append(s.journal, func() {
+		if found {
+			s.codes[address] = prev
+		} else {
+			delete(s.codes, address)
+		}
+	})
+	s.codes[address] = code
+}
+
+// GetCodeHash returns the keccak hash of the code of the address, zero if the account doesn't exist
+func (s *stateDB) GetCodeHash(address common.Address) common.Hash {
+	if !s.Exist(address) {
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(s.GetCode(address))
+}
+
+// GetCodeSize returns the size of the code of the address
+func (s *stateDB) GetCodeSize(address common.Address) int {
+	return len(s.GetCode(address))
+}
+
+// AddRefund adds gas to the refund counter
+func (s *stateDB) AddRefund(gas uint64) {
+	prev := s.refund
+	s.journal = append(s.journal, func() { s.refund = prev })
+	s.refund += gas
+}
+
+// SubRefund removes gas from the refund counter
+func (s *stateDB) SubRefund(gas uint64) {
+	prev := s.refund
+	s.journal = append(s.journal, func() { s.refund = prev })
+	if gas > s.refund {
+		s.refund = 0
+		return
+	}
+	s.refund -= gas
+}
+
+// GetRefund returns the refund counter
+func (s *stateDB) GetRefund() uint64 {
+	return s.refund
+}
+
+// GetCommittedState returns the value of the storage slot before the transaction being executed
+func (s *stateDB) GetCommittedState(address common.Address, slot common.Hash) common.Hash {
+	key := storageKey{address: address, slot: slot}
+	if value, found := s.committedStorage[key]; found {
+		return value
+	}
+	s.counters.readState()
+	value, err := s.tree.GetStorageAt(s.ctx, address, slot.Big(), s.root)
+	s.setErr(err)
+	var v common.Hash
+	if value != nil {
+		v = common.BigToHash(value)
+	}
+	s.committedStorage[key] = v
+	return v
+}
+
+// GetState returns the value of the storage slot
+func (s *stateDB) GetState(address common.Address, slot common.Hash) common.Hash {
+	if value, found := s.storage[storageKey{address: address, slot: slot}]; found {
+		return value
+	}
+	return s.GetCommittedState(address, slot)
+}
+
+// SetState sets the value of the storage slot
+func (s *stateDB) SetState(address common.Address, slot common.Hash, value common.Hash) {
+	key := storageKey{address: address, slot: slot}
+	prev, found := s.storage[key]
+	s.journal = append(s.journal, func() {
+		if found {
+			s.storage[key] = prev
+		} else {
+			delete(s.storage, key)
+		}
+	})
+	s.storage[key] = value
+}
+
+// GetTransientState returns the value of the transient storage slot
+func (s *stateDB) GetTransientState(address common.Address, slot common.Hash) common.Hash {
+	return s.transientStorage[storageKey{address: address, slot: slot}]
+}
+
+// SetTransientState sets the value of the transient storage slot
+func (s *stateDB) SetTransientState(address common.Address, slot, value common.Hash) {
+	key := storageKey{address: address, slot: slot}
+	prev := s.transientStorage[key]
+	s.journal = append(s.journal, func() { s.transientStorage[key] = prev })
+	s.transientStorage[key] = value
+}
+
+// Suicide marks the address as suicided and clears its balance. As in the zkEVM, the code and the
+// storage of the account are kept
+func (s *stateDB) Suicide(address common.Address) bool {
+	if !s.Exist(address) {
+		return false
+	}
+	prev := s.suicided[address]
+	s.journal = append(s.journal, func() { s.suicided[address] = prev })
+	s.suicided[address] = true
+	s.setBalance(address, big.NewInt(0))
+	return true
+}
+
+// HasSuicided returns whether the address has suicided in the transaction being executed
+func (s *stateDB) HasSuicided(address common.Address) bool {
+	return s.suicided[address]
+}
+
+// Exist reports whether the given account exists in state.
+// Notably this should also return true for suicided accounts.
+func (s *stateDB) Exist(address common.Address) bool {
+	return s.suicided[address] || !s.Empty(address)
+}
+
+// Empty returns whether the given account is empty. Empty
+// is defined according to EIP161 (balance = nonce = code = 0).
+func (s *stateDB) Empty(address common.Address) bool {
+	return s.GetNonce(address) == 0 && s.GetBalance(address).Sign() == 0 && len(s.GetCode(address)) == 0
+}
+
+// AddressInAccessList returns whether the address is in the access list
+func (s *stateDB) AddressInAccessList(address common.Address) bool {
+	return s.accessedAccounts[address]
+}
+
+// SlotInAccessList returns whether the address and the slot are in the access list
+func (s *stateDB) SlotInAccessList(address common.Address, slot common.Hash) (addressOk bool, slotOk bool) {
+	return s.accessedAccounts[address], s.accessedSlots[storageKey{address: address, slot: slot}]
+}
+
+// AddAddressToAccessList adds the given address to the access list
+func (s *stateDB) AddAddressToAccessList(address common.Address) {
+	if s.accessedAccounts[address] {
+		return
+	}
+	s.journal = append(s.journal, func() { delete(s.accessedAccounts, address) })
+	s.accessedAccounts[address] = true
+}
+
+// AddSlotToAccessList adds the given address and slot to the access list
+func (s *stateDB) AddSlotToAccessList(address common.Address, slot common.Hash) {
+	s.AddAddressToAccessList(address)
+	key := storageKey{address: address, slot: slot}
+	if s.accessedSlots[key] {
+		return
+	}
+	s.journal = append(s.journal, func() { delete(s.accessedSlots, key) })
+	s.accessedSlots[key] = true
+}
+
+// Prepare fills the access list with the sender, the destination, the precompiles and the tx access list
+func (s *stateDB) Prepare(rules params.Rules, sender, coinbase common.Address, dest *common.Address, precompiles []common.Address, txAccesses types.AccessList) {
+	if !rules.IsBerlin {
+		return
+	}
+	s.AddAddressToAccessList(sender)
+	if dest != nil {
+		s.AddAddressToAccessList(*dest)
+	}
+	for _, address := range precompiles {
+		s.AddAddressToAccessList(address)
+	}
+	for _, access := range txAccesses {
+		s.AddAddressToAccessList(access.Address)
+		for _, slot := range access.StorageKeys {
+			s.AddSlotToAccessList(access.Address, slot)
+		}
+	}
+	if rules.IsShanghai {
+		s.AddAddressToAccessList(coinbase)
+	}
+}
+
+// RevertToSnapshot undoes the changes made since the snapshot was taken
+func (s *stateDB) RevertToSnapshot(snapshot int) {
+	for i := len(s.journal) - 1; i >= snapshot; i-- {
+		s.journal[i]()
+	}
+	s.journal = s.journal[:snapshot]
+}
+
+// Snapshot returns an identifier of the current state to revert to
+func (s *stateDB) Snapshot() int {
+	return len(s.journal)
+}
+
+// AddLog adds a log emitted by the transaction being executed
+func (s *stateDB) AddLog(l *types.Log) {
+	s.journal = append(s.journal, func() { s.logs = s.logs[:len(s.logs)-1] })
+	s.logs = append(s.logs, l)
+}
+
+// AddPreimage does nothing, the preimages aren't recorded
+func (s *stateDB) AddPreimage(common.Hash, []byte) {}
+
+// commit writes to the state tree the values changed by the transaction being executed, in a
+// deterministic order, and starts reading the state from the new root
+func (s *stateDB) commit() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	addresses := map[common.Address]bool{}
+	for address := range s.balances {
+		addresses[address] = true
+	}
+	for address := range s.nonces {
+		addresses[address] = true
+	}
+	for address := range s.codes {
+		addresses[address] = true
+	}
+	sortedAddresses := make([]common.Address, 0, len(addresses))
+	for address := range addresses {
+		sortedAddresses = append(sortedAddresses, address)
+	}
+	sort.Slice(sortedAddresses, func(i, j int) bool {
+		return bytes.Compare(sortedAddresses[i][:], sortedAddresses[j][:]) < 0
+	})
+	storageKeys := make([]storageKey, 0, len(s.storage))
+	for key := range s.storage {
+		storageKeys = append(storageKeys, key)
+	}
+	sort.Slice(storageKeys, func(i, j int) bool {
+		if c := bytes.Compare(storageKeys[i].address[:], storageKeys[j].address[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(storageKeys[i].slot[:], storageKeys[j].slot[:]) < 0
+	})
+
+	root := s.root
+	var err error
+	for _, address := range sortedAddresses {
+		if balance, found := s.balances[address]; found && balance.Cmp(s.committedBalance(address)) != 0 {
+			s.counters.writeState()
+			if root, _, err = s.tree.SetBalance(s.ctx, address, balance, root, s.uuid); err != nil {
+				return err
+			}
+			s.committedBalances[address] = balance
+		}
+		if nonce, found := s.nonces[address]; found && nonce != s.committedNonce(address) {
+			s.counters.writeState()
+			if root, _, err = s.tree.SetNonce(s.ctx, address, new(big.Int).SetUint64(nonce), root, s.uuid); err != nil {
+				return err
+			}
+			s.committedNonces[address] = nonce
+		}
+		if code, found := s.codes[address]; found && !bytes.Equal(code, s.committedCode(address)) {
+			s.counters.writeState()
+			if root, _, err = s.tree.SetCode(s.ctx, address, code, root, s.uuid); err != nil {
+				return err
+			}
+			s.committedCodes[address] = code
+		}
+	}
+	for _, key := range storageKeys {
+		if value := s.storage[key]; value != s.GetCommittedState(key.address, key.slot) {
+			s.counters.writeState()
+			if root, _, err = s.tree.SetStorageAt(s.ctx, key.address, key.slot.Big(), value.Big(), root, s.uuid); err != nil {
+				return err
+			}
+			s.committedStorage[key] = value
+		}
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	s.root = root
+	s.reset()
+	return nil
+}
@@ -33,6 +33,8 @@ type State struct {
 
 	newL2BlockEvents        chan NewL2BlockEvent
 	newL2BlockEventHandlers []NewL2BlockEventHandler
+
+	preconfirmationEventHandlers []PreconfirmationEventHandler
 }
 
 // NewState creates a new State
@@ -142,7 +144,8 @@ func (s *State) FlushMerkleTree(ctx context.Context) error {
 	return s.tree.Flush(ctx, "")
 }
 
-// GetStoredFlushID returns the stored flush ID and Prover ID
+// GetStoredFlushID returns the stored flush ID and Prover ID. The flush IDs are counters of each executor, so
+// they are got from the executor of the caller of the context, the one its batches are processed by
 func (s *State) GetStoredFlushID(ctx context.Context) (uint64, string, error) {
 	if s.executorClient == nil {
 		return 0, "", ErrExecutorNil
@@ -26,6 +26,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/state/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime/fakeexecutor"
 	"github.com/0xPolygonHermez/zkevm-node/test/constants"
 	"github.com/0xPolygonHermez/zkevm-node/test/contracts/bin/Counter"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
@@ -90,27 +91,30 @@ func TestMain(m *testing.M) {
 	}
 	defer stateDb.Close()
 
-	zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
-
-	executorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
-	var executorCancel context.CancelFunc
-	executorClient, executorClientConn, executorCancel = executor.NewExecutorClient(ctx, executorServerConfig)
-	s := executorClientConn.GetState()
-	log.Infof("executorClientConn state: %s", s.String())
-	defer func() {
-		executorCancel()
-		executorClientConn.Close()
-	}()
-
-	mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
-	var mtDBCancel context.CancelFunc
-	mtDBServiceClient, mtDBClientConn, mtDBCancel = merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
-	s = mtDBClientConn.GetState()
-	log.Infof("stateDbClientConn state: %s", s.String())
-	defer func() {
-		mtDBCancel()
-		mtDBClientConn.Close()
-	}()
+	var fake bool
+	if mtDBServiceClient, executorClient, fake = fakeexecutor.NewFromEnv(); !fake {
+		zkProverURI := testutils.GetEnv("ZKPROVER_URI", "localhost")
+
+		executorServerConfig := executor.Config{URI: fmt.Sprintf("%s:50071", zkProverURI), MaxGRPCMessageSize: 100000000}
+		var executorCancel context.CancelFunc
+		executorClient, executorClientConn, executorCancel = executor.NewExecutorClient(ctx, executorServerConfig)
+		s := executorClientConn.GetState()
+		log.Infof("executorClientConn state: %s", s.String())
+		defer func() {
+			executorCancel()
+			executorClientConn.Close()
+		}()
+
+		mtDBServerConfig := merkletree.Config{URI: fmt.Sprintf("%s:50061", zkProverURI)}
+		var mtDBCancel context.CancelFunc
+		mtDBServiceClient, mtDBClientConn, mtDBCancel = merkletree.NewMTDBServiceClient(ctx, mtDBServerConfig)
+		s = mtDBClientConn.GetState()
+		log.Infof("stateDbClientConn state: %s", s.String())
+		defer func() {
+			mtDBCancel()
+			mtDBClientConn.Close()
+		}()
+	}
 
 	stateTree = merkletree.NewStateTree(mtDBServiceClient)
 
@@ -11,4 +11,9 @@ type SyncingInfo struct {
 	LastBatchNumberSeen         uint64
 	LastBatchNumberConsolidated uint64
 	CurrentBatchNumber          uint64
+
+	// LastPrunedBlockNumber and LastPrunedBatchNumber are the last l2 block and batch whose
+	// transactions, receipts and logs have been pruned, both zero if the state was never pruned
+	LastPrunedBlockNumber uint64
+	LastPrunedBatchNumber uint64
 }
@@ -87,7 +87,8 @@ func NewSynchronizer(
 	runInDevelopmentMode bool,
 	clientFactory client.ClientFactoryInterface,
 ) (Synchronizer, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	// the flush ids are got from the executor the batches are processed by
+	ctx, cancel := context.WithCancel(executor.WithCaller(context.Background(), stateMetrics.SynchronizerCallerLabel))
 	metrics.Register()
 
 	res := &ClientSynchronizer{
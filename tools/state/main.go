@@ -90,6 +90,13 @@ func main() {
 			Flags: []cli.Flag{&configFileFlag, &networkFlag, &customNetworkFlag, &configChainIDFlag, &firstBatchNumberFlag,
 				&lastBatchNumberFlag, &writeOnHashDBFlag, &dontStopOnErrorFlag, &preferExecutionStateRootFlag},
 		},
+		{
+			Name:    "replay",
+			Aliases: []string{},
+			Usage:   "replay the txs of a range of batches through the sequencer finalizer",
+			Action:  replayCmd,
+			Flags:   []cli.Flag{&configFileFlag, &networkFlag, &customNetworkFlag, &configChainIDFlag, &firstBatchNumberFlag, &lastBatchNumberFlag},
+		},
 	}
 	err := app.Run(os.Args)
 	if err != nil {
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-node/config"
+	"github.com/0xPolygonHermez/zkevm-node/db"
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/urfave/cli/v2"
+)
+
+func replayCmd(cliCtx *cli.Context) error {
+	cfg, err := config.Load(cliCtx, isNetworkConfigNeeded(cliCtx))
+	if err != nil {
+		return err
+	}
+	log.Init(cfg.Log)
+	stateSqlDB, err := db.NewSQLDB(cfg.State.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	l2ChainID := getL2ChainID(cliCtx, cfg)
+	st := newState(cliCtx.Context, cfg, l2ChainID, []state.ForkIDInterval{}, stateSqlDB, nil, true, true)
+
+	forksIdIntervals, err := getforkIDIntervalsFromDB(cliCtx.Context, st)
+	if err != nil {
+		log.Errorf("error getting forkIDs from db. Error: %v", err)
+		return err
+	}
+	st.UpdateForkIDIntervalsInMemory(forksIdIntervals)
+
+	firstBatchNumber := getFirstBatchNumber(cliCtx)
+	lastBatchNumber := getLastBatchNumber(cliCtx, cliCtx.Context, st)
+	previousBatch, err := getBatchByNumber(cliCtx.Context, st, firstBatchNumber-1)
+	if err != nil {
+		return err
+	}
+
+	batches := make([]sequencer.ReplayBatch, 0, lastBatchNumber-firstBatchNumber+1)
+	historicalTxs := 0
+	for batchNumber := firstBatchNumber; batchNumber <= lastBatchNumber; batchNumber++ {
+		batch, err := getBatchByNumber(cliCtx.Context, st, batchNumber)
+		if err != nil {
+			return err
+		}
+		txs, _, _, err := state.DecodeTxs(batch.BatchL2Data, st.GetForkIDByBatchNumber(batchNumber))
+		if err != nil {
+			log.Errorf("error decoding txs of batch %d. Error: %v", batchNumber, err)
+			return err
+		}
+		historicalTxs += len(txs)
+		batches = append(batches, sequencer.ReplayBatch{
+			Timestamp:      batch.Timestamp,
+			GlobalExitRoot: batch.GlobalExitRoot,
+			Txs:            txs,
+		})
+	}
+
+	fmt.Printf("START: replaying batches [%d to %d] with %d txs l2ChainId=[%d]\n", firstBatchNumber, lastBatchNumber, historicalTxs, l2ChainID)
+	result, err := sequencer.Replay(cliCtx.Context, cfg.Sequencer, cfg.State.Batch, cfg.Pool, st, *previousBatch, batches)
+	if err != nil {
+		log.Errorf("error replaying batches. Error: %v", err)
+		return err
+	}
+	printReplayResult(result, len(batches), cfg.State.Batch.Constraints)
+	return nil
+}
+
+func printReplayResult(result *sequencer.ReplayResult, historicalBatches int, constraints state.BatchConstraintsCfg) {
+	fmt.Printf("batches: historical %d replayed %d\n", historicalBatches, len(result.Batches))
+	fmt.Printf("txs: received %d sequenced %d ooc %d failed %d dropped %d pending %d\n",
+		result.ReceivedTxs, result.SequencedTxs, result.OOCTxs, result.FailedTxs, result.DroppedTxs, result.PendingTxs)
+
+	closingReasons := make(map[state.ClosingReason]int)
+	for _, batch := range result.Batches {
+		closingReasons[batch.ClosingReason]++
+	}
+	reasons := make([]string, 0, len(closingReasons))
+	for reason := range closingReasons {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+	fmt.Println("closing reasons:")
+	for _, reason := range reasons {
+		fmt.Printf("\t%-30s %d\n", reason, closingReasons[state.ClosingReason(reason)])
+	}
+
+	fmt.Println("average batch utilization:")
+	for _, utilization := range result.Utilization(constraints) {
+		fmt.Printf("\t%-30s %6.2f%%\n", utilization.Name, utilization.Percentage)
+	}
+}